@@ -87,6 +87,14 @@ type FetchRecentRowsRequest struct {
 	Where           *WhereSpec `json:"where"`
 	Since           string     `json:"since"`
 	Limit           int        `json:"limit"`
+	OrderColumn     string     `json:"orderColumn,omitempty"`
+	MinOrder        *int64     `json:"minOrder,omitempty"`
+	MaxOrder        *int64     `json:"maxOrder,omitempty"`
+	Until           string     `json:"until,omitempty"`
+	Ascending       bool       `json:"ascending,omitempty"`
+	KeyColumn       string     `json:"keyColumn,omitempty"`
+	AfterTS         string     `json:"afterTs,omitempty"`
+	AfterKey        *int64     `json:"afterKey,omitempty"`
 }
 
 type LatestValueResult struct {
@@ -444,12 +452,40 @@ func queryAggregate(ctx context.Context, cfg dbconnector.ConnectionConfig, dbTyp
 }
 
 func fetchRecentRows(ctx context.Context, cfg dbconnector.ConnectionConfig, dbType string, req FetchRecentRowsRequest) (FetchRecentRowsResult, error) {
-	if req.Since == "" {
+	ordered := req.OrderColumn != ""
+	if req.Since == "" && !ordered {
 		return FetchRecentRowsResult{}, errors.New("since required")
 	}
-	if !isSafeIdentifier(req.Table) || !isSafeIdentifier(req.TimestampColumn) {
+	if !isSafeIdentifier(req.Table) {
 		return FetchRecentRowsResult{}, errors.New("unsafe identifier")
 	}
+	if req.TimestampColumn == "" && !ordered {
+		return FetchRecentRowsResult{}, errors.New("timestampColumn required")
+	}
+	if req.TimestampColumn != "" && !isSafeIdentifier(req.TimestampColumn) {
+		return FetchRecentRowsResult{}, errors.New("unsafe identifier")
+	}
+	if ordered && !isSafeIdentifier(req.OrderColumn) {
+		return FetchRecentRowsResult{}, errors.New("unsafe identifier")
+	}
+	if req.Since != "" && req.TimestampColumn == "" {
+		return FetchRecentRowsResult{}, errors.New("since requires timestampColumn")
+	}
+	if req.Until != "" && req.TimestampColumn == "" {
+		return FetchRecentRowsResult{}, errors.New("until requires timestampColumn")
+	}
+	if (req.MinOrder != nil || req.MaxOrder != nil) && !ordered {
+		return FetchRecentRowsResult{}, errors.New("order bounds require orderColumn")
+	}
+	if req.KeyColumn != "" && !isSafeIdentifier(req.KeyColumn) {
+		return FetchRecentRowsResult{}, errors.New("unsafe identifier")
+	}
+	if (req.AfterTS != "") != (req.AfterKey != nil) {
+		return FetchRecentRowsResult{}, errors.New("afterTs and afterKey must be set together")
+	}
+	if req.AfterTS != "" && (req.KeyColumn == "" || req.TimestampColumn == "") {
+		return FetchRecentRowsResult{}, errors.New("afterTs requires timestampColumn and keyColumn")
+	}
 	if len(req.Columns) == 0 {
 		return FetchRecentRowsResult{}, errors.New("columns required")
 	}
@@ -457,24 +493,13 @@ func fetchRecentRows(ctx context.Context, cfg dbconnector.ConnectionConfig, dbTy
 	if limit <= 0 || limit > 2000 {
 		limit = 2000
 	}
-	parsedSince, err := time.Parse(time.RFC3339, req.Since)
-	if err != nil {
-		parsedSince, err = time.Parse(time.RFC3339Nano, req.Since)
-		if err != nil {
-			return FetchRecentRowsResult{}, errors.New("invalid since timestamp")
-		}
-	}
 
 	table, err := quoteIdent(dbType, req.Table)
 	if err != nil {
 		return FetchRecentRowsResult{}, err
 	}
-	tsCol, err := quoteIdent(dbType, req.TimestampColumn)
-	if err != nil {
-		return FetchRecentRowsResult{}, err
-	}
-	selectCols := make([]string, 0, len(req.Columns)+1)
-	colNames := make([]string, 0, len(req.Columns)+1)
+	selectCols := make([]string, 0, len(req.Columns)+2)
+	colNames := make([]string, 0, len(req.Columns)+2)
 	seen := map[string]struct{}{}
 	for _, col := range req.Columns {
 		if !isSafeIdentifier(col) {
@@ -491,21 +516,115 @@ func fetchRecentRows(ctx context.Context, cfg dbconnector.ConnectionConfig, dbTy
 		selectCols = append(selectCols, quoted)
 		colNames = append(colNames, col)
 	}
-	if _, ok := seen[req.TimestampColumn]; !ok {
-		selectCols = append(selectCols, tsCol)
-		colNames = append(colNames, req.TimestampColumn)
+	for _, col := range []string{req.TimestampColumn, req.OrderColumn, req.KeyColumn} {
+		if col == "" {
+			continue
+		}
+		if _, ok := seen[col]; ok {
+			continue
+		}
+		seen[col] = struct{}{}
+		quoted, err := quoteIdent(dbType, col)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		selectCols = append(selectCols, quoted)
+		colNames = append(colNames, col)
 	}
-	whereSQL, args, _, err := buildWhereClause(dbType, req.Where, 2)
+	sortCol := req.TimestampColumn
+	if ordered {
+		sortCol = req.OrderColumn
+	}
+	sortIdent, err := quoteIdent(dbType, sortCol)
+	if err != nil {
+		return FetchRecentRowsResult{}, err
+	}
+	clauses := []string{}
+	args := []any{}
+	idx := 1
+	if req.Since != "" {
+		parsedSince, err := time.Parse(time.RFC3339, req.Since)
+		if err != nil {
+			parsedSince, err = time.Parse(time.RFC3339Nano, req.Since)
+			if err != nil {
+				return FetchRecentRowsResult{}, errors.New("invalid since timestamp")
+			}
+		}
+		tsCol, err := quoteIdent(dbType, req.TimestampColumn)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		clauses = append(clauses, fmt.Sprintf("%s >= %s", tsCol, placeholder(dbType, idx)))
+		args = append(args, parsedSince)
+		idx++
+	}
+	if req.Until != "" {
+		parsedUntil, err := time.Parse(time.RFC3339Nano, req.Until)
+		if err != nil {
+			return FetchRecentRowsResult{}, errors.New("invalid until timestamp")
+		}
+		tsCol, err := quoteIdent(dbType, req.TimestampColumn)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		clauses = append(clauses, fmt.Sprintf("%s <= %s", tsCol, placeholder(dbType, idx)))
+		args = append(args, parsedUntil)
+		idx++
+	}
+	if req.MinOrder != nil {
+		clauses = append(clauses, fmt.Sprintf("%s >= %s", sortIdent, placeholder(dbType, idx)))
+		args = append(args, *req.MinOrder)
+		idx++
+	}
+	if req.MaxOrder != nil {
+		clauses = append(clauses, fmt.Sprintf("%s <= %s", sortIdent, placeholder(dbType, idx)))
+		args = append(args, *req.MaxOrder)
+		idx++
+	}
+	if req.AfterTS != "" {
+		parsedAfter, err := time.Parse(time.RFC3339Nano, req.AfterTS)
+		if err != nil {
+			return FetchRecentRowsResult{}, errors.New("invalid afterTs timestamp")
+		}
+		tsCol, err := quoteIdent(dbType, req.TimestampColumn)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		keyCol, err := quoteIdent(dbType, req.KeyColumn)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		clauses = append(clauses, fmt.Sprintf("(%s > %s OR (%s = %s AND %s > %s))", tsCol, placeholder(dbType, idx), tsCol, placeholder(dbType, idx+1), keyCol, placeholder(dbType, idx+2)))
+		args = append(args, parsedAfter, parsedAfter, *req.AfterKey)
+		idx += 3
+	}
+	whereSQL, whereArgs, _, err := buildWhereClause(dbType, req.Where, idx)
 	if err != nil {
 		return FetchRecentRowsResult{}, err
 	}
-	timeClause := fmt.Sprintf("%s >= %s", tsCol, placeholder(dbType, 1))
-	clauses := []string{timeClause}
 	if whereSQL != "" {
 		clauses = append(clauses, "("+whereSQL+")")
+		args = append(args, whereArgs...)
 	}
-	where := "WHERE " + strings.Join(clauses, " AND ")
-	query := fmt.Sprintf("SELECT %s FROM %s %s ORDER BY %s DESC LIMIT %d", strings.Join(selectCols, ", "), table, where, tsCol, limit)
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	direction := "DESC"
+	if req.Ascending {
+		direction = "ASC"
+	}
+	orderBy := sortIdent + " " + direction
+	if req.KeyColumn != "" {
+		// The key breaks ties between rows sharing the sort value, so pages
+		// and watermarks see them in a fixed order.
+		keyIdent, err := quoteIdent(dbType, req.KeyColumn)
+		if err != nil {
+			return FetchRecentRowsResult{}, err
+		}
+		orderBy += ", " + keyIdent + " " + direction
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s%s ORDER BY %s LIMIT %d", strings.Join(selectCols, ", "), table, where, orderBy, limit)
 
 	db, err := openTargetDB(ctx, cfg)
 	if err != nil {
@@ -513,7 +632,6 @@ func fetchRecentRows(ctx context.Context, cfg dbconnector.ConnectionConfig, dbTy
 	}
 	defer db.Close()
 
-	args = append([]any{parsedSince}, args...)
 	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return FetchRecentRowsResult{}, err
@@ -0,0 +1,4 @@
+ALTER TABLE ui_rules
+  ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'DRAFT',
+  ADD COLUMN IF NOT EXISTS last_error jsonb,
+  ADD COLUMN IF NOT EXISTS last_validated_at timestamptz;
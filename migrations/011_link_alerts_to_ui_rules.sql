@@ -0,0 +1,5 @@
+ALTER TABLE alerts
+  ALTER COLUMN rule_id DROP NOT NULL,
+  ADD COLUMN IF NOT EXISTS ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_ui_rule_id ON alerts (ui_rule_id, ts_utc DESC);
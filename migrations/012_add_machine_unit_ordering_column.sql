@@ -0,0 +1,2 @@
+ALTER TABLE machine_units
+ADD COLUMN IF NOT EXISTS ordering_column text NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS rule_runs (
+  id bigserial PRIMARY KEY,
+  rule_id uuid REFERENCES rules(id) ON DELETE CASCADE,
+  ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE,
+  parameter_name text NOT NULL,
+  detector_type text NOT NULL,
+  started_at timestamptz NOT NULL,
+  finished_at timestamptz NOT NULL,
+  duration_ms bigint NOT NULL,
+  status text NOT NULL,
+  error text,
+  sample_count integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_rule_runs_rule_id ON rule_runs (rule_id, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_rule_runs_ui_rule_id ON rule_runs (ui_rule_id, started_at DESC);
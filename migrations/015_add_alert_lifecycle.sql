@@ -0,0 +1,27 @@
+ALTER TABLE alerts
+  ADD COLUMN IF NOT EXISTS state text NOT NULL DEFAULT 'OPEN',
+  ADD COLUMN IF NOT EXISTS opened_at timestamptz,
+  ADD COLUMN IF NOT EXISTS last_seen_at timestamptz,
+  ADD COLUMN IF NOT EXISTS acknowledged_at timestamptz,
+  ADD COLUMN IF NOT EXISTS resolved_at timestamptz,
+  ADD COLUMN IF NOT EXISTS occurrences integer NOT NULL DEFAULT 1,
+  ADD COLUMN IF NOT EXISTS ok_streak integer NOT NULL DEFAULT 0;
+
+UPDATE alerts SET opened_at = ts_utc WHERE opened_at IS NULL;
+UPDATE alerts SET last_seen_at = ts_utc WHERE last_seen_at IS NULL;
+
+UPDATE alerts SET state = 'RESOLVED', resolved_at = ts_utc
+WHERE state = 'OPEN' AND treated = true;
+
+UPDATE alerts a SET state = 'RESOLVED', resolved_at = a.ts_utc
+WHERE a.state = 'OPEN' AND EXISTS (
+  SELECT 1 FROM alerts b
+  WHERE b.id <> a.id
+    AND b.parameter_name = a.parameter_name
+    AND b.detector_type IS NOT DISTINCT FROM a.detector_type
+    AND COALESCE(b.rule_id, b.ui_rule_id) = COALESCE(a.rule_id, a.ui_rule_id)
+    AND (b.ts_utc, b.id) > (a.ts_utc, a.id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_open_rule_key ON alerts (rule_id, parameter_name, detector_type) WHERE state IN ('OPEN','ACKNOWLEDGED');
+CREATE INDEX IF NOT EXISTS idx_alerts_open_ui_rule_key ON alerts (ui_rule_id, parameter_name, detector_type) WHERE state IN ('OPEN','ACKNOWLEDGED');
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS detector_state (
+  id bigserial PRIMARY KEY,
+  rule_id uuid REFERENCES rules(id) ON DELETE CASCADE,
+  ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE,
+  parameter_name text NOT NULL,
+  detector_type text NOT NULL,
+  state jsonb NOT NULL,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_detector_state_key ON detector_state ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS baselines (
+  id bigserial PRIMARY KEY,
+  rule_id uuid REFERENCES rules(id) ON DELETE CASCADE,
+  ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE,
+  parameter_name text NOT NULL,
+  detector_type text NOT NULL,
+  status text NOT NULL DEFAULT 'PENDING',
+  source text NOT NULL,
+  mu double precision,
+  sigma double precision,
+  rbar double precision,
+  median double precision,
+  mad double precision,
+  n integer NOT NULL,
+  subgroup_count integer NOT NULL DEFAULT 0,
+  window_start timestamptz,
+  window_end timestamptz,
+  order_start bigint,
+  order_end bigint,
+  data_hash text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  approved_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_baselines_active ON baselines ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type) WHERE status = 'ACTIVE';
+CREATE INDEX IF NOT EXISTS idx_baselines_rule ON baselines ((COALESCE(rule_id, ui_rule_id)), created_at DESC);
@@ -0,0 +1,35 @@
+ALTER TABLE rules
+  ADD COLUMN IF NOT EXISTS shadow boolean NOT NULL DEFAULT false;
+
+ALTER TABLE ui_rules
+  ADD COLUMN IF NOT EXISTS shadow boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS shadow_alerts (
+  id bigserial PRIMARY KEY,
+  rule_id uuid REFERENCES rules(id) ON DELETE CASCADE,
+  ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE,
+  ts_utc timestamptz NOT NULL,
+  parameter_name text NOT NULL,
+  observed_value text NOT NULL,
+  limit_expression text NOT NULL,
+  detector_type text,
+  severity text,
+  anomaly_score numeric,
+  baseline_median numeric,
+  baseline_mad numeric,
+  hit boolean NOT NULL,
+  treated boolean NOT NULL DEFAULT false,
+  metadata jsonb,
+  state text NOT NULL DEFAULT 'OPEN',
+  opened_at timestamptz,
+  last_seen_at timestamptz,
+  acknowledged_at timestamptz,
+  resolved_at timestamptz,
+  occurrences integer NOT NULL DEFAULT 1,
+  ok_streak integer NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_shadow_alerts_rule_id ON shadow_alerts (rule_id, ts_utc DESC);
+CREATE INDEX IF NOT EXISTS idx_shadow_alerts_ui_rule_id ON shadow_alerts (ui_rule_id, ts_utc DESC);
+CREATE INDEX IF NOT EXISTS idx_shadow_alerts_open_rule_key ON shadow_alerts (rule_id, parameter_name, detector_type) WHERE state IN ('OPEN','ACKNOWLEDGED');
+CREATE INDEX IF NOT EXISTS idx_shadow_alerts_open_ui_rule_key ON shadow_alerts (ui_rule_id, parameter_name, detector_type) WHERE state IN ('OPEN','ACKNOWLEDGED');
@@ -0,0 +1,2 @@
+ALTER TABLE machine_units
+ADD COLUMN IF NOT EXISTS derived_parameters jsonb NOT NULL DEFAULT '[]'::jsonb;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS rule_watermarks (
+  id bigserial PRIMARY KEY,
+  rule_id uuid REFERENCES rules(id) ON DELETE CASCADE,
+  ui_rule_id uuid REFERENCES ui_rules(id) ON DELETE CASCADE,
+  parameter_name text NOT NULL,
+  detector_type text NOT NULL,
+  config_hash text NOT NULL,
+  last_ts timestamptz,
+  last_order bigint,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rule_watermarks_key ON rule_watermarks ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type);
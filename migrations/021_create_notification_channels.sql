@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS notification_channels (
+  id uuid PRIMARY KEY,
+  name text NOT NULL,
+  type text NOT NULL,
+  config jsonb NOT NULL DEFAULT '{}'::jsonb,
+  enabled boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS notification_subscriptions (
+  id bigserial PRIMARY KEY,
+  channel_id uuid NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+  rule_id uuid,
+  unit_id text,
+  min_severity text,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_subscriptions_channel_id ON notification_subscriptions (channel_id);
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+  id bigserial PRIMARY KEY,
+  channel_id uuid NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+  alert_id bigint REFERENCES alerts(id) ON DELETE CASCADE,
+  event text NOT NULL,
+  payload jsonb NOT NULL,
+  status text NOT NULL DEFAULT 'PENDING',
+  attempts integer NOT NULL DEFAULT 0,
+  response_code integer,
+  last_error text,
+  next_attempt_at timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now(),
+  delivered_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due ON notification_deliveries (next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_channel_id ON notification_deliveries (channel_id, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE alerts
+  DROP CONSTRAINT IF EXISTS alerts_ui_rule_id_fkey,
+  ADD CONSTRAINT alerts_ui_rule_id_fkey FOREIGN KEY (ui_rule_id) REFERENCES ui_rules(id) ON DELETE SET NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE rule_watermarks
+  ADD COLUMN IF NOT EXISTS seen_at_ts integer NOT NULL DEFAULT 1;
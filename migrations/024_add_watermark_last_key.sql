@@ -0,0 +1,2 @@
+ALTER TABLE rule_watermarks
+  ADD COLUMN IF NOT EXISTS last_key bigint;
@@ -1,3 +1,5 @@
+// Package crypto encrypts secrets stored by the rule service, such as
+// connection passwords and notification channel secrets, with AES-GCM.
 package crypto
 
 import (
@@ -0,0 +1,3 @@
+module predixaai-backend/pkg/crypto
+
+go 1.25
@@ -0,0 +1,54 @@
+// Package events defines the messages services exchange over NATS. The
+// scheduler publishes alert and rule status events; rule-service publishes
+// manual alert transitions and consumes all alert events for its live stream.
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SchemaVersion is bumped on any breaking change to the published payloads.
+// Consumers should ignore messages with a version they do not know.
+const SchemaVersion = 1
+
+const (
+	SubjectAlertCreated      = "alert.created"
+	SubjectAlertUpdated      = "alert.updated"
+	SubjectAlertAcknowledged = "alert.acknowledged"
+	SubjectAlertResolved     = "alert.resolved"
+	SubjectRuleStatusChanged = "rule.status_changed"
+)
+
+// AlertMessage is published on alert.* subjects. Exactly one of RuleID and
+// UIRuleID is set.
+type AlertMessage struct {
+	SchemaVersion   int             `json:"schemaVersion"`
+	Event           string          `json:"event"`
+	EmittedAt       time.Time       `json:"emittedAt"`
+	AlertID         int64           `json:"alertId"`
+	RuleID          string          `json:"ruleId,omitempty"`
+	UIRuleID        string          `json:"uiRuleId,omitempty"`
+	ParameterName   string          `json:"parameterName"`
+	DetectorType    string          `json:"detectorType"`
+	Severity        string          `json:"severity,omitempty"`
+	ObservedValue   string          `json:"observedValue,omitempty"`
+	LimitExpression string          `json:"limitExpression,omitempty"`
+	TS              *time.Time      `json:"ts,omitempty"`
+	AcknowledgedAt  *time.Time      `json:"acknowledgedAt,omitempty"`
+	ResolvedAt      *time.Time      `json:"resolvedAt,omitempty"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+}
+
+// RuleStatusMessage is published on rule.status_changed when validation moves
+// a rule between statuses.
+type RuleStatusMessage struct {
+	SchemaVersion  int             `json:"schemaVersion"`
+	Event          string          `json:"event"`
+	EmittedAt      time.Time       `json:"emittedAt"`
+	RuleID         string          `json:"ruleId,omitempty"`
+	UIRuleID       string          `json:"uiRuleId,omitempty"`
+	PreviousStatus string          `json:"previousStatus"`
+	Status         string          `json:"status"`
+	LastError      json.RawMessage `json:"lastError,omitempty"`
+}
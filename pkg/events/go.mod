@@ -0,0 +1,3 @@
+module predixaai-backend/pkg/events
+
+go 1.25
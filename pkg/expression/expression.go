@@ -0,0 +1,325 @@
+// Package expression parses the derived-parameter formulas that the rule
+// service validates and the scheduler evaluates.
+package expression
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxExpressionLength = 256
+	maxExpressionDepth  = 16
+)
+
+// Expression is a parsed derived-parameter formula. Only arithmetic over
+// column values, numeric literals and a few pure functions is supported;
+// the formula is evaluated in process and never sent to the database.
+type Expression struct {
+	root    exprNode
+	columns []string
+}
+
+type exprNode interface {
+	eval(values map[string]float64) (float64, error)
+}
+
+type numberNode float64
+
+type columnNode string
+
+type negateNode struct {
+	operand exprNode
+}
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+type callNode struct {
+	fn   string
+	args []exprNode
+}
+
+var expressionFunctions = map[string]int{"abs": 1, "sqrt": 1, "min": 2, "max": 2}
+
+func Parse(src string) (*Expression, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, errors.New("expression is empty")
+	}
+	if len(src) > maxExpressionLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", maxExpressionLength)
+	}
+	tokens, err := tokenizeExpression(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens, seen: map[string]bool{}}
+	root, err := p.parseSum(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if len(p.columns) == 0 {
+		return nil, errors.New("expression must reference at least one column")
+	}
+	return &Expression{root: root, columns: p.columns}, nil
+}
+
+// Columns lists the referenced columns in order of first appearance.
+func (e *Expression) Columns() []string {
+	return e.columns
+}
+
+func (e *Expression) Eval(values map[string]float64) (float64, error) {
+	value, err := e.root.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, errors.New("expression result is not finite")
+	}
+	return value, nil
+}
+
+func (n numberNode) eval(map[string]float64) (float64, error) {
+	return float64(n), nil
+}
+
+func (n columnNode) eval(values map[string]float64) (float64, error) {
+	value, ok := values[string(n)]
+	if !ok {
+		return 0, fmt.Errorf("column %s has no value", string(n))
+	}
+	return value, nil
+}
+
+func (n negateNode) eval(values map[string]float64) (float64, error) {
+	value, err := n.operand.eval(values)
+	return -value, err
+}
+
+func (n binaryNode) eval(values map[string]float64) (float64, error) {
+	left, err := n.left.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(values)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, errors.New("division by zero")
+		}
+		return left / right, nil
+	}
+}
+
+func (n callNode) eval(values map[string]float64) (float64, error) {
+	args := make([]float64, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(values)
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, value)
+	}
+	switch n.fn {
+	case "abs":
+		return math.Abs(args[0]), nil
+	case "sqrt":
+		if args[0] < 0 {
+			return 0, errors.New("sqrt of negative value")
+		}
+		return math.Sqrt(args[0]), nil
+	case "min":
+		return math.Min(args[0], args[1]), nil
+	default:
+		return math.Max(args[0], args[1]), nil
+	}
+}
+
+type expressionParser struct {
+	tokens  []string
+	pos     int
+	columns []string
+	seen    map[string]bool
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) parseSum(depth int) (exprNode, error) {
+	left, err := p.parseProduct(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.tokens[p.pos][0]
+		p.pos++
+		right, err := p.parseProduct(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseProduct(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.tokens[p.pos][0]
+		p.pos++
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseUnary(depth int) (exprNode, error) {
+	if depth > maxExpressionDepth {
+		return nil, errors.New("expression is nested too deeply")
+	}
+	if p.peek() == "-" {
+		p.pos++
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *expressionParser) parsePrimary(depth int) (exprNode, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of expression")
+	case token == "(":
+		p.pos++
+		node, err := p.parseSum(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case isExpressionDigit(token[0]) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		p.pos++
+		return numberNode(value), nil
+	case isExpressionIdentStart(token[0]):
+		p.pos++
+		if p.peek() != "(" {
+			if !p.seen[token] {
+				p.seen[token] = true
+				p.columns = append(p.columns, token)
+			}
+			return columnNode(token), nil
+		}
+		arity, ok := expressionFunctions[strings.ToLower(token)]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %q", token)
+		}
+		p.pos++
+		args := []exprNode{}
+		for {
+			arg, err := p.parseSum(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek() != "," {
+				break
+			}
+			p.pos++
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		if len(args) != arity {
+			return nil, fmt.Errorf("%s expects %d argument(s)", token, arity)
+		}
+		return callNode{fn: strings.ToLower(token), args: args}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+}
+
+func tokenizeExpression(src string) ([]string, error) {
+	tokens := []string{}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.IndexByte("+-*/(),", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case isExpressionDigit(c) || c == '.':
+			start := i
+			for i < len(src) && (isExpressionDigit(src[i]) || src[i] == '.') {
+				i++
+			}
+			if i < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				i++
+				if i < len(src) && (src[i] == '+' || src[i] == '-') {
+					i++
+				}
+				for i < len(src) && isExpressionDigit(src[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, src[start:i])
+		case isExpressionIdentStart(c):
+			start := i
+			for i < len(src) && (isExpressionIdentStart(src[i]) || isExpressionDigit(src[i])) {
+				i++
+			}
+			tokens = append(tokens, src[start:i])
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isExpressionDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isExpressionIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
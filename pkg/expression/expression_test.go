@@ -0,0 +1,42 @@
+package expression
+
+import "testing"
+
+func TestParseEval(t *testing.T) {
+	cases := []struct {
+		src   string
+		want  float64
+		cols  int
+		input map[string]float64
+	}{
+		{"gas_cl2_flow / gas_ar_flow", 0.5, 2, map[string]float64{"gas_cl2_flow": 10, "gas_ar_flow": 20}},
+		{"trench_depth - trench_width * 2", 4, 2, map[string]float64{"trench_depth": 10, "trench_width": 3}},
+		{"-(a - b) + abs(a - 2 * b)", 4, 2, map[string]float64{"a": 1, "b": 2}},
+		{"max(a, 1.5e1) / sqrt(a)", 5, 1, map[string]float64{"a": 9}},
+	}
+	for _, tc := range cases {
+		expr, err := Parse(tc.src)
+		if err != nil {
+			t.Fatalf("%s: parse failed: %v", tc.src, err)
+		}
+		if len(expr.Columns()) != tc.cols {
+			t.Fatalf("%s: expected %d columns, got %v", tc.src, tc.cols, expr.Columns())
+		}
+		got, err := expr.Eval(tc.input)
+		if err != nil || got != tc.want {
+			t.Fatalf("%s: expected %v, got %v (%v)", tc.src, tc.want, got, err)
+		}
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	for _, src := range []string{"", "1 + 2", "a +", "a; drop table x", "(a", "pow(a, 2)", "abs(a, b)", "a b"} {
+		if _, err := Parse(src); err == nil {
+			t.Fatalf("expected %q to be rejected", src)
+		}
+	}
+	expr, _ := Parse("a / b")
+	if _, err := expr.Eval(map[string]float64{"a": 1, "b": 0}); err == nil {
+		t.Fatalf("expected division by zero error")
+	}
+}
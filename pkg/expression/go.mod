@@ -0,0 +1,3 @@
+module predixaai-backend/pkg/expression
+
+go 1.25
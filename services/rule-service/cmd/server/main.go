@@ -13,9 +13,9 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 
+	"predixaai-backend/pkg/crypto"
 	"predixaai-backend/services/rule-service/internal/api"
 	"predixaai-backend/services/rule-service/internal/bus"
-	"predixaai-backend/services/rule-service/internal/crypto"
 	"predixaai-backend/services/rule-service/internal/storage"
 )
 
@@ -56,6 +56,7 @@ func main() {
 	handler := &api.Handler{
 		Repo:      repo,
 		Bus:       publisher,
+		Alerts:    api.NewAlertHub(),
 		Encryptor: enc,
 		MinPoll:   minPoll,
 		MaxPoll:   maxPoll,
@@ -64,13 +65,25 @@ func main() {
 		SchedulerURL:   schedulerURL,
 	}
 
+	if _, err := publisher.SubscribeAlerts(func(msg bus.AlertMessage) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		handler.ForwardAlertEvent(ctx, msg)
+	}); err != nil {
+		logger.Error("failed to subscribe to alert events", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	r := chi.NewRouter()
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(10 * time.Second))
 
-	handler.RegisterRoutes(r)
+	handler.RegisterAlertStreamRoutes(r)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(10 * time.Second))
+		handler.RegisterRoutes(r)
+	})
 
 	srv := &http.Server{
 		Addr:         ":" + port,
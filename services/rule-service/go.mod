@@ -7,6 +7,9 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/jackc/pgx/v5 v5.6.0
 	github.com/nats-io/nats.go v1.37.0
+	predixaai-backend/pkg/crypto v0.0.0
+	predixaai-backend/pkg/events v0.0.0
+	predixaai-backend/pkg/expression v0.0.0
 )
 
 require (
@@ -21,3 +24,9 @@ require (
 	golang.org/x/sys v0.18.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
+
+replace (
+	predixaai-backend/pkg/crypto => ../../pkg/crypto
+	predixaai-backend/pkg/events => ../../pkg/events
+	predixaai-backend/pkg/expression => ../../pkg/expression
+)
@@ -0,0 +1,300 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/services/rule-service/internal/bus"
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+const (
+	alertStreamBuffer    = 64
+	alertStreamKeepAlive = 15 * time.Second
+	alertStreamRetryMS   = 3000
+	alertReplayPageSize  = 200
+)
+
+type alertStreamEvent struct {
+	Event string
+	Alert storage.AlertRecord
+}
+
+type alertStreamResponse struct {
+	ID              int64           `json:"id"`
+	RuleID          string          `json:"ruleId,omitempty"`
+	UIRuleID        string          `json:"uiRuleId,omitempty"`
+	UnitID          string          `json:"unitId,omitempty"`
+	UnitIDs         []string        `json:"unitIds,omitempty"`
+	Timestamp       string          `json:"timestamp"`
+	ParameterName   string          `json:"parameterName"`
+	ObservedValue   string          `json:"observedValue"`
+	LimitExpression string          `json:"limitExpression"`
+	DetectorType    string          `json:"detectorType"`
+	Severity        string          `json:"severity"`
+	Treated         bool            `json:"treated"`
+	State           string          `json:"state"`
+	OpenedAt        string          `json:"openedAt,omitempty"`
+	LastSeenAt      string          `json:"lastSeenAt,omitempty"`
+	AcknowledgedAt  string          `json:"acknowledgedAt,omitempty"`
+	ResolvedAt      string          `json:"resolvedAt,omitempty"`
+	Occurrences     int             `json:"occurrences"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+}
+
+// AlertHub fans alert events out to connected stream clients. A client that
+// falls a full buffer behind is disconnected and resumes with Last-Event-ID.
+type AlertHub struct {
+	mu      sync.Mutex
+	clients map[chan alertStreamEvent]struct{}
+}
+
+func NewAlertHub() *AlertHub {
+	return &AlertHub{clients: map[chan alertStreamEvent]struct{}{}}
+}
+
+func (h *AlertHub) subscribe() chan alertStreamEvent {
+	ch := make(chan alertStreamEvent, alertStreamBuffer)
+	h.mu.Lock()
+	h.clients[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *AlertHub) unsubscribe(ch chan alertStreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+func (h *AlertHub) publish(evt alertStreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		select {
+		case ch <- evt:
+		default:
+			delete(h.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+// RegisterAlertStreamRoutes registers the long-lived stream endpoints. They
+// must not be wrapped in a request timeout.
+func (h *Handler) RegisterAlertStreamRoutes(r chi.Router) {
+	r.Get("/alerts/stream", h.handleAlertStream)
+	r.Get("/api/machine-units/{unitId}/alerts/stream", h.handleAlertStream)
+}
+
+// ForwardAlertEvent loads the alert named by a bus event and pushes its
+// current state to stream clients.
+func (h *Handler) ForwardAlertEvent(ctx context.Context, msg bus.AlertMessage) {
+	if h.Alerts == nil || msg.AlertID <= 0 {
+		return
+	}
+	alert, err := h.Repo.GetAlert(ctx, msg.AlertID)
+	if err != nil {
+		return
+	}
+	h.Alerts.publish(alertStreamEvent{Event: msg.Event, Alert: alert})
+}
+
+func (h *Handler) handleAlertStream(w http.ResponseWriter, r *http.Request) {
+	filter := storage.AlertFilter{
+		UnitID:      chi.URLParam(r, "unitId"),
+		RuleID:      strings.TrimSpace(r.URL.Query().Get("ruleId")),
+		MinSeverity: strings.TrimSpace(r.URL.Query().Get("minSeverity")),
+	}
+	if filter.UnitID == "" {
+		filter.UnitID = strings.TrimSpace(r.URL.Query().Get("unitId"))
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	lastID, fieldErrors := parseAlertStreamRequest(filter, lastEventID)
+	if len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid alert stream request", fieldErrors)
+		return
+	}
+	if h.Alerts == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"ok": false, "message": "alert stream unavailable"})
+		return
+	}
+	events := h.Alerts.subscribe()
+	defer h.Alerts.unsubscribe(events)
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", alertStreamRetryMS); err != nil {
+		return
+	}
+	if lastID > 0 {
+		replayed, err := h.replayAlerts(r.Context(), w, filter, lastID)
+		if err != nil {
+			return
+		}
+		lastID = replayed
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(alertStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			if evt.Event == "alert.created" && evt.Alert.ID <= lastID {
+				continue
+			}
+			if !alertMatchesFilter(evt.Alert, filter) {
+				continue
+			}
+			if err := writeAlertStreamEvent(w, evt); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// replayAlerts sends every alert created after lastID, page by page, and
+// returns the highest id sent. Each page is flushed so a long backlog reaches
+// the client while the rest is still being read.
+func (h *Handler) replayAlerts(ctx context.Context, w http.ResponseWriter, filter storage.AlertFilter, lastID int64) (int64, error) {
+	rc := http.NewResponseController(w)
+	for {
+		queryCtx, cancel := context.WithTimeout(ctx, h.Timeout)
+		alerts, err := h.Repo.ListAlertsAfter(queryCtx, lastID, filter, alertReplayPageSize)
+		cancel()
+		if err != nil {
+			return lastID, err
+		}
+		for _, alert := range alerts {
+			if err := writeAlertStreamEvent(w, alertStreamEvent{Event: "alert.created", Alert: alert}); err != nil {
+				return lastID, err
+			}
+			lastID = alert.ID
+		}
+		if len(alerts) < alertReplayPageSize {
+			return lastID, nil
+		}
+		if err := rc.Flush(); err != nil {
+			return lastID, err
+		}
+	}
+}
+
+// writeAlertStreamEvent writes one SSE event. Only alert.created carries an
+// id, so Last-Event-ID is always the newest alert the client has seen.
+func writeAlertStreamEvent(w http.ResponseWriter, evt alertStreamEvent) error {
+	data, err := json.Marshal(toAlertStreamResponse(evt.Alert))
+	if err != nil {
+		return err
+	}
+	if evt.Event == "alert.created" {
+		if _, err := fmt.Fprintf(w, "id: %d\n", evt.Alert.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.Event, data)
+	return err
+}
+
+func parseAlertStreamRequest(filter storage.AlertFilter, lastEventID string) (int64, []FieldError) {
+	fields := []FieldError{}
+	if filter.MinSeverity != "" && filter.MinSeverity != "medium" && filter.MinSeverity != "high" {
+		fields = append(fields, FieldError{Field: "minSeverity", Problem: "invalid", Hint: "Use medium or high"})
+	}
+	var lastID int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			fields = append(fields, FieldError{Field: "lastEventId", Problem: "invalid", Hint: "Use an alert id"})
+		}
+		lastID = parsed
+	}
+	return lastID, fields
+}
+
+func alertMatchesFilter(alert storage.AlertRecord, filter storage.AlertFilter) bool {
+	if filter.UnitID != "" && alert.UnitID != filter.UnitID && !slices.Contains(alert.UnitIDs, filter.UnitID) {
+		return false
+	}
+	if filter.RuleID != "" && alert.RuleID != filter.RuleID && alert.UIRuleID != filter.RuleID {
+		return false
+	}
+	return filter.MinSeverity != "high" || alert.Severity == "high"
+}
+
+func toAlertStreamResponse(alert storage.AlertRecord) alertStreamResponse {
+	return alertStreamResponse{
+		ID:              alert.ID,
+		RuleID:          alert.RuleID,
+		UIRuleID:        alert.UIRuleID,
+		UnitID:          alert.UnitID,
+		UnitIDs:         alert.UnitIDs,
+		Timestamp:       alert.TSUTC.UTC().Format(time.RFC3339),
+		ParameterName:   alert.ParameterName,
+		ObservedValue:   alert.ObservedValue,
+		LimitExpression: alert.LimitExpr,
+		DetectorType:    alert.DetectorType,
+		Severity:        alert.Severity,
+		Treated:         alert.Treated,
+		State:           alert.State,
+		OpenedAt:        formatOptionalTime(alert.OpenedAt),
+		LastSeenAt:      formatOptionalTime(alert.LastSeenAt),
+		AcknowledgedAt:  formatOptionalTime(alert.AcknowledgedAt),
+		ResolvedAt:      formatOptionalTime(alert.ResolvedAt),
+		Occurrences:     alert.Occurrences,
+		Metadata:        alert.Metadata,
+	}
+}
+
+// alertTransitionMessage builds the bus event for a manual acknowledge or
+// resolve, in the same schema the scheduler publishes.
+func alertTransitionMessage(event string, alert storage.AlertRecord, now time.Time) bus.AlertMessage {
+	return bus.AlertMessage{
+		SchemaVersion:  bus.SchemaVersion,
+		Event:          event,
+		EmittedAt:      now.UTC(),
+		AlertID:        alert.ID,
+		RuleID:         alert.RuleID,
+		UIRuleID:       alert.UIRuleID,
+		ParameterName:  alert.ParameterName,
+		DetectorType:   alert.DetectorType,
+		Severity:       alert.Severity,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		ResolvedAt:     alert.ResolvedAt,
+	}
+}
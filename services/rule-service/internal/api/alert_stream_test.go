@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+func newAlertStreamServer(t *testing.T) (*Handler, *httptest.Server) {
+	t.Helper()
+	h := &Handler{Alerts: NewAlertHub(), Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterAlertStreamRoutes(r)
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.Timeout(10 * time.Second))
+		h.RegisterRoutes(r)
+	})
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return h, srv
+}
+
+func TestAlertStreamFiltersAndFormatsEvents(t *testing.T) {
+	h, srv := newAlertStreamServer(t)
+	resp, err := http.Get(srv.URL + "/api/machine-units/unit-1/alerts/stream?minSeverity=high")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	if block := readSSEBlock(t, reader); block != "retry: 3000" {
+		t.Fatalf("expected retry block, got %q", block)
+	}
+
+	h.Alerts.publish(alertStreamEvent{Event: "alert.created", Alert: storage.AlertRecord{ID: 5, UnitID: "unit-2", Severity: "high"}})
+	h.Alerts.publish(alertStreamEvent{Event: "alert.created", Alert: storage.AlertRecord{ID: 6, UnitID: "unit-1", Severity: "medium"}})
+	h.Alerts.publish(alertStreamEvent{Event: "alert.created", Alert: storage.AlertRecord{ID: 7, UIRuleID: "rule-1", UnitID: "unit-1", Severity: "high", State: "OPEN", ParameterName: "rf_power"}})
+	h.Alerts.publish(alertStreamEvent{Event: "alert.acknowledged", Alert: storage.AlertRecord{ID: 7, UIRuleID: "rule-1", UnitID: "unit-1", Severity: "high", State: "ACKNOWLEDGED", ParameterName: "rf_power"}})
+
+	created := readSSEBlock(t, reader)
+	if !strings.HasPrefix(created, "id: 7\nevent: alert.created\ndata: {\"id\":7,\"uiRuleId\":\"rule-1\",\"unitId\":\"unit-1\"") || !strings.Contains(created, `"state":"OPEN"`) {
+		t.Fatalf("unexpected created event %q", created)
+	}
+	acknowledged := readSSEBlock(t, reader)
+	if !strings.HasPrefix(acknowledged, "event: alert.acknowledged\ndata: ") || !strings.Contains(acknowledged, `"state":"ACKNOWLEDGED"`) {
+		t.Fatalf("unexpected acknowledged event %q", acknowledged)
+	}
+}
+
+func TestAlertStreamRejectsInvalidFilters(t *testing.T) {
+	_, srv := newAlertStreamServer(t)
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/alerts/stream?minSeverity=low", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestAlertMatchesFilterResolvesLegacyUnits(t *testing.T) {
+	legacy := storage.AlertRecord{ID: 8, RuleID: "legacy-1", UnitIDs: []string{"unit-1", "unit-3"}, Severity: "medium"}
+	for _, unitID := range []string{"unit-1", "unit-3"} {
+		if !alertMatchesFilter(legacy, storage.AlertFilter{UnitID: unitID}) {
+			t.Fatalf("expected a legacy rule alert to match unit %s", unitID)
+		}
+	}
+	if alertMatchesFilter(legacy, storage.AlertFilter{UnitID: "unit-2"}) {
+		t.Fatal("expected a legacy rule alert not to match an unrelated unit")
+	}
+}
+
+func TestAlertHubDropsSlowClients(t *testing.T) {
+	hub := NewAlertHub()
+	slow := hub.subscribe()
+	for i := 0; i <= alertStreamBuffer; i++ {
+		hub.publish(alertStreamEvent{Event: "alert.created", Alert: storage.AlertRecord{ID: int64(i + 1)}})
+	}
+	received := 0
+	for range slow {
+		received++
+	}
+	if received != alertStreamBuffer {
+		t.Fatalf("expected %d buffered events before disconnect, got %d", alertStreamBuffer, received)
+	}
+	hub.unsubscribe(slow)
+}
+
+func readSSEBlock(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+	done := make(chan string, 1)
+	go func() {
+		lines := []string{}
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				done <- strings.Join(lines, "\n")
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				done <- strings.Join(lines, "\n")
+				return
+			}
+			lines = append(lines, line)
+		}
+	}()
+	select {
+	case block := <-done:
+		return block
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return ""
+	}
+}
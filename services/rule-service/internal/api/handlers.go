@@ -10,8 +10,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"predixaai-backend/pkg/crypto"
 	"predixaai-backend/services/rule-service/internal/bus"
-	"predixaai-backend/services/rule-service/internal/crypto"
 	"predixaai-backend/services/rule-service/internal/rules"
 	"predixaai-backend/services/rule-service/internal/storage"
 )
@@ -19,6 +19,7 @@ import (
 type Handler struct {
 	Repo      *storage.Repository
 	Bus       *bus.Publisher
+	Alerts    *AlertHub
 	Encryptor crypto.Encryptor
 	MinPoll   int
 	MaxPoll   int
@@ -108,6 +109,7 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/rules/validate", h.handleRulesValidate)
 	h.RegisterStepperRoutes(r)
 	h.RegisterMachineUnitRoutes(r)
+	h.RegisterNotificationRoutes(r)
 	r.Route("/rules", func(r chi.Router) {
 		r.Post("/", h.handleRulesCreate)
 		r.Get("/", h.handleRulesList)
@@ -115,9 +117,13 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Put("/{id}", h.handleRuleUpdateByID)
 		r.Post("/{id}/enable", h.handleRuleEnable)
 		r.Post("/{id}/disable", h.handleRuleDisable)
+		r.Post("/{id}/shadow", h.handleRuleShadow)
 		r.Get("/{id}/alerts", h.handleRuleAlerts)
+		r.Get("/{id}/shadow-alerts", h.handleRuleShadowAlerts)
+		r.Get("/{id}/runs", h.handleRuleRuns)
 	})
-	r.Post("/alerts/{id}/treated", h.handleAlertUpdate)
+	r.Post("/alerts/{id}/acknowledge", h.handleAlertAcknowledge)
+	r.Post("/alerts/{id}/resolve", h.handleAlertResolve)
 }
 
 func (h *Handler) handleConnections(w http.ResponseWriter, r *http.Request) {
@@ -322,7 +328,7 @@ func (h *Handler) handleRuleUpdateByID(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleRuleEnable(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if err := h.Repo.SetRuleEnabled(r.Context(), id, true, "DRAFT"); err != nil {
+	if err := h.Repo.SetRuleMode(r.Context(), id, true, false, "DRAFT"); err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to enable rule"})
 		return
 	}
@@ -332,7 +338,7 @@ func (h *Handler) handleRuleEnable(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) handleRuleDisable(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
-	if err := h.Repo.SetRuleEnabled(r.Context(), id, false, "DISABLED"); err != nil {
+	if err := h.Repo.SetRuleMode(r.Context(), id, false, false, "DISABLED"); err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to disable rule"})
 		return
 	}
@@ -340,6 +346,16 @@ func (h *Handler) handleRuleDisable(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
+func (h *Handler) handleRuleShadow(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := h.Repo.SetRuleMode(r.Context(), id, true, true, "DRAFT"); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to shadow rule"})
+		return
+	}
+	_ = h.Bus.Publish("rule.shadowed", map[string]any{"rule_id": id})
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
 func (h *Handler) handleRuleAlerts(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
@@ -352,26 +368,53 @@ func (h *Handler) handleRuleAlerts(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, alerts)
 }
 
-func (h *Handler) handleAlertUpdate(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) handleRuleShadowAlerts(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetRule(ctx, id); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	alerts, err := h.Repo.ListShadowAlerts(ctx, id)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch shadow alerts"})
+		return
+	}
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+func (h *Handler) handleAlertAcknowledge(w http.ResponseWriter, r *http.Request) {
+	h.transitionAlert(w, r, h.Repo.AcknowledgeAlert, "alert.acknowledged", "alert is not open")
+}
+
+func (h *Handler) handleAlertResolve(w http.ResponseWriter, r *http.Request) {
+	h.transitionAlert(w, r, h.Repo.ResolveAlert, "alert.resolved", "alert already resolved")
+}
+
+func (h *Handler) transitionAlert(w http.ResponseWriter, r *http.Request, transition func(context.Context, int64) error, event, conflictMessage string) {
 	idStr := chi.URLParam(r, "id")
 	alertID, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "invalid alert id"})
 		return
 	}
-	var req struct {
-		Treated bool `json:"treated"`
-	}
-	if err := decodeJSON(r, &req); err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
-		return
-	}
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
 	defer cancel()
-	if err := h.Repo.UpdateAlertTreated(ctx, alertID, req.Treated); err != nil {
-		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update alert"})
+	if err := transition(ctx, alertID); err != nil {
+		switch err {
+		case storage.ErrNotFound:
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "alert not found"})
+		case storage.ErrAlertState:
+			writeJSON(w, http.StatusConflict, map[string]any{"ok": false, "message": conflictMessage})
+		default:
+			writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update alert"})
+		}
 		return
 	}
+	if alert, err := h.Repo.GetAlert(ctx, alertID); err == nil {
+		_ = h.Bus.Publish(event, alertTransitionMessage(event, alert, time.Now()))
+	}
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
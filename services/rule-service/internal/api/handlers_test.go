@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestAlertTransitionInvalidID(t *testing.T) {
+	h := &Handler{}
+	router := chi.NewRouter()
+	h.RegisterRoutes(router)
+	for _, path := range []string{"/alerts/abc/acknowledge", "/alerts/abc/resolve"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", path, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/alerts/1/treated", nil))
+	if rec.Code != http.StatusNotFound && rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected legacy treated route to be removed, got %d", rec.Code)
+	}
+}
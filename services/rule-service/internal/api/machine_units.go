@@ -12,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 
+	"predixaai-backend/pkg/expression"
 	"predixaai-backend/services/rule-service/internal/rules"
 	"predixaai-backend/services/rule-service/internal/storage"
 )
@@ -19,32 +20,39 @@ import (
 const (
 	maxSelectedColumns = 200
 	maxRuleIDs         = 500
+	maxDerivedParams   = 50
 )
 
 var identifierRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
 type machineUnitRequest struct {
-	UnitID          string          `json:"unitId"`
-	UnitName        string          `json:"unitName"`
-	ConnectionRef   string          `json:"connectionRef"`
-	SelectedTable   string          `json:"selectedTable"`
-	TimestampColumn string          `json:"timestampColumn"`
-	SelectedColumns []string        `json:"selectedColumns"`
-	LiveParameters  json.RawMessage `json:"liveParameters"`
-	RuleIDs         ruleIDList      `json:"rule"`
-	Pos             *positionInput  `json:"pos"`
+	UnitID            string                     `json:"unitId"`
+	UnitName          string                     `json:"unitName"`
+	ConnectionRef     string                     `json:"connectionRef"`
+	SelectedTable     string                     `json:"selectedTable"`
+	TimestampColumn   string                     `json:"timestampColumn"`
+	OrderingColumn    string                     `json:"orderingColumn"`
+	RowFilter         *rules.WhereSpec           `json:"rowFilter"`
+	SelectedColumns   []string                   `json:"selectedColumns"`
+	DerivedParameters []storage.DerivedParameter `json:"derivedParameters"`
+	LiveParameters    json.RawMessage            `json:"liveParameters"`
+	RuleIDs           ruleIDList                 `json:"rule"`
+	Pos               *positionInput             `json:"pos"`
 }
 
 type machineUnitResponse struct {
-	UnitID          string          `json:"unitId"`
-	UnitName        string          `json:"unitName"`
-	ConnectionRef   string          `json:"connectionRef"`
-	SelectedTable   string          `json:"selectedTable"`
-	TimestampColumn string          `json:"timestampColumn"`
-	SelectedColumns []string        `json:"selectedColumns"`
-	LiveParameters  json.RawMessage `json:"liveParameters"`
-	RuleIDs         []string        `json:"rule"`
-	Pos             positionInput   `json:"pos"`
+	UnitID            string                     `json:"unitId"`
+	UnitName          string                     `json:"unitName"`
+	ConnectionRef     string                     `json:"connectionRef"`
+	SelectedTable     string                     `json:"selectedTable"`
+	TimestampColumn   string                     `json:"timestampColumn"`
+	OrderingColumn    string                     `json:"orderingColumn"`
+	RowFilter         *rules.WhereSpec           `json:"rowFilter,omitempty"`
+	SelectedColumns   []string                   `json:"selectedColumns"`
+	DerivedParameters []storage.DerivedParameter `json:"derivedParameters"`
+	LiveParameters    json.RawMessage            `json:"liveParameters"`
+	RuleIDs           []string                   `json:"rule"`
+	Pos               positionInput              `json:"pos"`
 }
 
 type updateRulesRequest struct {
@@ -143,6 +151,7 @@ func (h *Handler) handleMachineUnitCreate(w http.ResponseWriter, r *http.Request
 			writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update machine unit"})
 			return
 		}
+		h.publishUnitRulesUpdated(r.Context(), req.UnitID)
 		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 		return
 	}
@@ -199,6 +208,9 @@ func (h *Handler) handleMachineUnitUpdate(w http.ResponseWriter, r *http.Request
 		return
 	}
 	unit.UnitID = unitID
+	if !h.checkDerivedParametersRemovable(r.Context(), w, unitID, unit.DerivedParameters) {
+		return
+	}
 	updated, err := h.Repo.UpdateMachineUnit(r.Context(), unit)
 	if err != nil {
 		if err == storage.ErrNotFound {
@@ -208,6 +220,7 @@ func (h *Handler) handleMachineUnitUpdate(w http.ResponseWriter, r *http.Request
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update machine unit"})
 		return
 	}
+	h.publishUnitRulesUpdated(r.Context(), unitID)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
@@ -314,6 +327,7 @@ func (h *Handler) handleMachineUnitColumns(w http.ResponseWriter, r *http.Reques
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update selected columns"})
 		return
 	}
+	h.publishUnitRulesUpdated(r.Context(), unitID)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
@@ -351,6 +365,7 @@ func (h *Handler) handleMachineUnitColumnsReplace(w http.ResponseWriter, r *http
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update selected columns"})
 		return
 	}
+	h.publishUnitRulesUpdated(r.Context(), unitID)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
@@ -378,6 +393,9 @@ func (h *Handler) handleMachineUnitTable(w http.ResponseWriter, r *http.Request)
 		}
 		columns = &clean
 	}
+	if !h.checkDerivedParametersRemovable(r.Context(), w, unitID, nil) {
+		return
+	}
 	updated, err := h.Repo.UpdateTable(r.Context(), unitID, req.SelectedTable, columns, req.KeepColumns)
 	if err != nil {
 		if err == storage.ErrNotFound {
@@ -387,6 +405,7 @@ func (h *Handler) handleMachineUnitTable(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update selected table"})
 		return
 	}
+	h.publishUnitRulesUpdated(r.Context(), unitID)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
@@ -423,6 +442,7 @@ func (h *Handler) handleMachineUnitConnection(w http.ResponseWriter, r *http.Req
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update connection"})
 		return
 	}
+	h.publishUnitRulesUpdated(r.Context(), unitID)
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
@@ -449,6 +469,69 @@ func (h *Handler) handleMachineUnitPosition(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "unit": buildMachineUnitResponse(updated)})
 }
 
+// publishUnitRulesUpdated asks the scheduler to reload every rule of the
+// unit, so table, connection, ordering and derived parameter edits take
+// effect without touching the rules themselves.
+func (h *Handler) publishUnitRulesUpdated(ctx context.Context, unitID string) {
+	if h.Bus == nil {
+		return
+	}
+	recs, err := h.Repo.ListStepperRules(ctx, unitID)
+	if err != nil {
+		return
+	}
+	for _, rec := range recs {
+		_ = h.Bus.Publish("ui_rule.updated", map[string]any{"rule_id": rec.ID})
+	}
+}
+
+// checkDerivedParametersRemovable writes a 409 and returns false when
+// replacing the unit's derived parameters with next would drop one that a
+// rule still uses. A missing unit passes, so the update reports the 404.
+func (h *Handler) checkDerivedParametersRemovable(ctx context.Context, w http.ResponseWriter, unitID string, next []storage.DerivedParameter) bool {
+	current, err := h.Repo.GetMachineUnit(ctx, unitID)
+	if err == storage.ErrNotFound {
+		return true
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to load machine unit"})
+		return false
+	}
+	if len(current.DerivedParameters) == 0 {
+		return true
+	}
+	recs, err := h.Repo.ListStepperRules(ctx, unitID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to list rules"})
+		return false
+	}
+	if details := removedDerivedParametersInUse(current, next, recs); len(details) > 0 {
+		writeJSON(w, http.StatusConflict, errorResponse{Ok: false, Code: "PARAMETER_IN_USE", Message: "derived parameter is used by rules", Details: details})
+		return false
+	}
+	return true
+}
+
+func removedDerivedParametersInUse(current storage.MachineUnit, next []storage.DerivedParameter, recs []storage.StepperRule) []rules.ErrorDetail {
+	kept := map[string]bool{}
+	for _, param := range next {
+		kept[param.Name] = true
+	}
+	removed := map[string]bool{}
+	for _, param := range current.DerivedParameters {
+		if !kept[param.Name] {
+			removed[current.SelectedTable+"."+param.Name] = true
+		}
+	}
+	details := []rules.ErrorDetail{}
+	for _, rec := range recs {
+		if removed[rec.ParameterID] {
+			details = append(details, rules.ErrorDetail{Field: "derivedParameters", Problem: "in_use", Hint: "Rule " + rec.ID + " uses " + rec.ParameterID})
+		}
+	}
+	return details
+}
+
 func (h *Handler) validateMachineUnitRequest(ctx context.Context, req machineUnitRequest) (storage.MachineUnit, []rules.ErrorDetail) {
 	details := []rules.ErrorDetail{}
 	unitName := strings.TrimSpace(req.UnitName)
@@ -475,12 +558,36 @@ func (h *Handler) validateMachineUnitRequest(ctx context.Context, req machineUni
 	if timestampColumn != "" && !identifierRe.MatchString(timestampColumn) {
 		details = append(details, rules.ErrorDetail{Field: "timestampColumn", Problem: "invalid", Hint: "Use a valid column identifier"})
 	}
+	orderingColumn := strings.TrimSpace(req.OrderingColumn)
+	if orderingColumn != "" && !identifierRe.MatchString(orderingColumn) {
+		details = append(details, rules.ErrorDetail{Field: "orderingColumn", Problem: "invalid", Hint: "Use a valid column identifier"})
+	}
+	var rowFilter json.RawMessage
+	if req.RowFilter != nil {
+		filterDetails := rules.ValidateWhereSpec(req.RowFilter, "rowFilter")
+		if len(filterDetails) == 0 && len(details) == 0 {
+			filterDetails = h.validateRowFilterSchema(ctx, connectionRef, selectedTable, req.RowFilter)
+		}
+		details = append(details, filterDetails...)
+		if len(filterDetails) == 0 {
+			rowFilter, _ = json.Marshal(req.RowFilter)
+		}
+	}
 	columns := dedupePreserveOrder(req.SelectedColumns)
 	if len(columns) > maxSelectedColumns {
 		details = append(details, rules.ErrorDetail{Field: "selectedColumns", Problem: "max", Hint: "Maximum 200 columns"})
 	} else {
 		details = append(details, validateIdentifierList("selectedColumns", columns)...)
 	}
+	derived := req.DerivedParameters
+	if derived == nil {
+		derived = []storage.DerivedParameter{}
+	}
+	derivedDetails := validateDerivedParameters(derived, columns)
+	if len(derivedDetails) == 0 && len(details) == 0 && len(derived) > 0 {
+		derivedDetails = h.validateDerivedParametersSchema(ctx, connectionRef, selectedTable, derived)
+	}
+	details = append(details, derivedDetails...)
 	ruleIDs := dedupePreserveOrder([]string(req.RuleIDs))
 	if len(ruleIDs) > maxRuleIDs {
 		details = append(details, rules.ErrorDetail{Field: "rule", Problem: "max", Hint: "Maximum 500 rules"})
@@ -508,18 +615,115 @@ func (h *Handler) validateMachineUnitRequest(ctx context.Context, req machineUni
 		}
 	}
 	return storage.MachineUnit{
-		UnitName:        unitName,
-		ConnectionRef:   connectionRef,
-		SelectedTable:   selectedTable,
-		TimestampColumn: timestampColumn,
-		SelectedColumns: columns,
-		LiveParameters:  liveParams,
-		RuleIDs:         ruleIDs,
-		PosX:            posX,
-		PosY:            posY,
+		UnitName:          unitName,
+		ConnectionRef:     connectionRef,
+		SelectedTable:     selectedTable,
+		TimestampColumn:   timestampColumn,
+		OrderingColumn:    orderingColumn,
+		RowFilter:         rowFilter,
+		SelectedColumns:   columns,
+		DerivedParameters: derived,
+		LiveParameters:    liveParams,
+		RuleIDs:           ruleIDs,
+		PosX:              posX,
+		PosY:              posY,
 	}, details
 }
 
+func (h *Handler) validateRowFilterSchema(ctx context.Context, connectionRef, table string, filter *rules.WhereSpec) []rules.ErrorDetail {
+	connector := dbConnectorClient{BaseURL: h.DBConnectorURL, Client: defaultHTTPClient(h.Timeout)}
+	schema, err := connector.DescribeTable(ctx, connectionRef, table)
+	if err != nil {
+		return []rules.ErrorDetail{{Field: "rowFilter", Problem: "invalid", Hint: "Failed to describe selectedTable"}}
+	}
+	columns := map[string]string{}
+	for _, col := range schema.Columns {
+		columns[col.Name] = col.Type
+	}
+	details := []rules.ErrorDetail{}
+	for idx, clause := range filter.Clauses {
+		if _, ok := columns[clause.Column]; !ok {
+			details = append(details, rules.ErrorDetail{Field: "rowFilter.clauses[" + itoa(idx) + "].column", Problem: "not_found", Hint: "Column not found in selectedTable"})
+		}
+	}
+	return details
+}
+
+func validateDerivedParameters(params []storage.DerivedParameter, columns []string) []rules.ErrorDetail {
+	details := []rules.ErrorDetail{}
+	if len(params) > maxDerivedParams {
+		return append(details, rules.ErrorDetail{Field: "derivedParameters", Problem: "max", Hint: "Maximum 50 derived parameters"})
+	}
+	taken := map[string]bool{}
+	for _, col := range columns {
+		taken[col] = true
+	}
+	for idx, param := range params {
+		field := "derivedParameters[" + itoa(idx) + "]"
+		if !identifierRe.MatchString(param.Name) {
+			details = append(details, rules.ErrorDetail{Field: field + ".name", Problem: "invalid", Hint: "Must match ^[a-zA-Z_][a-zA-Z0-9_]*$"})
+		} else if taken[param.Name] {
+			details = append(details, rules.ErrorDetail{Field: field + ".name", Problem: "duplicate", Hint: "Name must differ from selected columns and other derived parameters"})
+		}
+		taken[param.Name] = true
+		if _, err := expression.Parse(param.Expression); err != nil {
+			details = append(details, rules.ErrorDetail{Field: field + ".expression", Problem: "invalid", Hint: err.Error()})
+		}
+	}
+	return details
+}
+
+func (h *Handler) validateDerivedParametersSchema(ctx context.Context, connectionRef, table string, params []storage.DerivedParameter) []rules.ErrorDetail {
+	connector := dbConnectorClient{BaseURL: h.DBConnectorURL, Client: defaultHTTPClient(h.Timeout)}
+	schema, err := connector.DescribeTable(ctx, connectionRef, table)
+	if err != nil {
+		return []rules.ErrorDetail{{Field: "derivedParameters", Problem: "invalid", Hint: "Failed to describe selectedTable"}}
+	}
+	columns := map[string]string{}
+	for _, col := range schema.Columns {
+		columns[col.Name] = col.Type
+	}
+	details := []rules.ErrorDetail{}
+	for idx, param := range params {
+		expr, err := expression.Parse(param.Expression)
+		if err != nil {
+			continue
+		}
+		for _, col := range expr.Columns() {
+			colType, ok := columns[col]
+			if !ok {
+				details = append(details, rules.ErrorDetail{Field: "derivedParameters[" + itoa(idx) + "].expression", Problem: "not_found", Hint: "Column " + col + " not found in selectedTable"})
+			} else if !isNumericType(colType) {
+				details = append(details, rules.ErrorDetail{Field: "derivedParameters[" + itoa(idx) + "].expression", Problem: "invalid", Hint: "Column " + col + " must be numeric"})
+			}
+		}
+	}
+	return details
+}
+
+func decodeRowFilter(raw json.RawMessage) (*rules.WhereSpec, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var filter rules.WhereSpec
+	if err := json.Unmarshal(raw, &filter); err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func rowFilterMatchesSchema(filter *rules.WhereSpec, columns map[string]string) bool {
+	if filter == nil {
+		return true
+	}
+	for _, clause := range filter.Clauses {
+		if _, ok := columns[clause.Column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *Handler) findMissingRuleIDs(ctx context.Context, ids []string) ([]string, error) {
 	existing, err := h.Repo.ListRuleIDs(ctx, ids)
 	if err != nil {
@@ -603,15 +807,23 @@ func normalizeRawMessage(raw json.RawMessage) json.RawMessage {
 
 func buildMachineUnitResponse(unit storage.MachineUnit) machineUnitResponse {
 	live := normalizeRawMessage(unit.LiveParameters)
+	rowFilter, _ := decodeRowFilter(unit.RowFilter)
+	derived := unit.DerivedParameters
+	if derived == nil {
+		derived = []storage.DerivedParameter{}
+	}
 	return machineUnitResponse{
-		UnitID:          unit.UnitID,
-		UnitName:        unit.UnitName,
-		ConnectionRef:   unit.ConnectionRef,
-		SelectedTable:   unit.SelectedTable,
-		TimestampColumn: unit.TimestampColumn,
-		SelectedColumns: unit.SelectedColumns,
-		LiveParameters:  live,
-		RuleIDs:         unit.RuleIDs,
+		UnitID:            unit.UnitID,
+		UnitName:          unit.UnitName,
+		ConnectionRef:     unit.ConnectionRef,
+		SelectedTable:     unit.SelectedTable,
+		TimestampColumn:   unit.TimestampColumn,
+		OrderingColumn:    unit.OrderingColumn,
+		RowFilter:         rowFilter,
+		SelectedColumns:   unit.SelectedColumns,
+		DerivedParameters: derived,
+		LiveParameters:    live,
+		RuleIDs:           unit.RuleIDs,
 		Pos: positionInput{
 			X: unit.PosX,
 			Y: unit.PosY,
@@ -79,6 +79,8 @@ func setupMachineUnitFixture(t *testing.T) machineUnitTestFixture {
 		connection_ref uuid NOT NULL REFERENCES db_connections(id),
 		selected_table text NOT NULL,
 		timestamp_column text NOT NULL DEFAULT '',
+		ordering_column text NOT NULL DEFAULT '',
+		row_filter jsonb,
 		selected_columns jsonb NOT NULL DEFAULT '[]'::jsonb,
 		live_parameters jsonb NOT NULL DEFAULT '[]'::jsonb,
 		rule_ids jsonb NOT NULL DEFAULT '[]'::jsonb,
@@ -102,6 +104,20 @@ func setupMachineUnitFixture(t *testing.T) machineUnitTestFixture {
 	if err != nil {
 		t.Fatalf("failed to ensure ui_rules: %v", err)
 	}
+	_, err = repo.Store.Pool.Exec(context.Background(), `ALTER TABLE ui_rules
+		ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'DRAFT',
+		ADD COLUMN IF NOT EXISTS last_error jsonb,
+		ADD COLUMN IF NOT EXISTS last_validated_at timestamptz`)
+	if err != nil {
+		t.Fatalf("failed to extend ui_rules: %v", err)
+	}
+	_, err = repo.Store.Pool.Exec(context.Background(), `ALTER TABLE machine_units
+		ADD COLUMN IF NOT EXISTS ordering_column text NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS row_filter jsonb,
+		ADD COLUMN IF NOT EXISTS derived_parameters jsonb NOT NULL DEFAULT '[]'::jsonb`)
+	if err != nil {
+		t.Fatalf("failed to extend machine_units: %v", err)
+	}
 
 	connectionRef, err := repo.CreateConnection(context.Background(), storage.DBConnection{
 		Name:     "test",
@@ -417,3 +433,50 @@ func TestMachineUnitPositionNotFound(t *testing.T) {
 		t.Fatalf("expected 404, got %d", resp.Code)
 	}
 }
+
+func TestValidateDerivedParameters(t *testing.T) {
+	params := []storage.DerivedParameter{
+		{Name: "power_ratio", Expression: "rf_power / gas_ar_flow"},
+		{Name: "gas_ar_flow", Expression: "gas_ar_flow * 2"},
+		{Name: "power_ratio", Expression: "abs(rf_power"},
+		{Name: "bad-name", Expression: "drop(rf_power)"},
+	}
+	details := validateDerivedParameters(params, []string{"gas_ar_flow"})
+	fields := map[string]string{}
+	for _, detail := range details {
+		fields[detail.Field] = detail.Problem
+	}
+	if len(details) != 5 {
+		t.Fatalf("expected 5 details, got %+v", details)
+	}
+	if fields["derivedParameters[1].name"] != "duplicate" || fields["derivedParameters[2].name"] != "duplicate" {
+		t.Fatalf("expected duplicate name details, got %+v", details)
+	}
+	if fields["derivedParameters[2].expression"] != "invalid" || fields["derivedParameters[3].expression"] != "invalid" || fields["derivedParameters[3].name"] != "invalid" {
+		t.Fatalf("expected invalid details, got %+v", details)
+	}
+	if _, ok := fields["derivedParameters[0].name"]; ok {
+		t.Fatalf("expected first derived parameter to be valid, got %+v", details)
+	}
+}
+
+func TestRemovedDerivedParametersInUse(t *testing.T) {
+	current := storage.MachineUnit{SelectedTable: "etchers_data", DerivedParameters: []storage.DerivedParameter{
+		{Name: "power_ratio", Expression: "rf_power / gas_ar_flow"},
+		{Name: "depth_gap", Expression: "trench_depth - trench_width"},
+	}}
+	recs := []storage.StepperRule{
+		{ID: "r1", ParameterID: "etchers_data.power_ratio"},
+		{ID: "r2", ParameterID: "etchers_data.rf_power"},
+	}
+	if details := removedDerivedParametersInUse(current, current.DerivedParameters[:1], recs); len(details) != 0 {
+		t.Fatalf("expected removing an unused parameter to pass, got %+v", details)
+	}
+	details := removedDerivedParametersInUse(current, current.DerivedParameters[1:], recs)
+	if len(details) != 1 || details[0].Problem != "in_use" {
+		t.Fatalf("expected power_ratio to be in use, got %+v", details)
+	}
+	if details := removedDerivedParametersInUse(current, nil, recs); len(details) != 1 {
+		t.Fatalf("expected clearing parameters to be refused, got %+v", details)
+	}
+}
@@ -0,0 +1,638 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"predixaai-backend/pkg/crypto"
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+const (
+	channelTypeWebhook = "webhook"
+	channelTypeEmail   = "email"
+)
+
+var headerNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+type notificationChannelRequest struct {
+	Name    string                `json:"name"`
+	Type    string                `json:"type"`
+	Enabled *bool                 `json:"enabled"`
+	Webhook *webhookConfigRequest `json:"webhook"`
+	Email   *emailConfigRequest   `json:"email"`
+}
+
+type webhookConfigRequest struct {
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+	Template string            `json:"template"`
+	Secret   *string           `json:"secret"`
+}
+
+// webhookConfig is the stored form of a webhook channel and is read by the
+// scheduler's dispatcher.
+type webhookConfig struct {
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Secret   string            `json:"secret,omitempty"`
+}
+
+type emailConfigRequest struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username"`
+	Password *string  `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	TLS      string   `json:"tls"`
+	Digest   string   `json:"digest"`
+}
+
+// emailConfig is the stored form of an email channel. TLS is "starttls"
+// (default), "tls" or "none"; Digest is "" for one email per alert, "hourly"
+// or "daily".
+type emailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	TLS      string   `json:"tls,omitempty"`
+	Digest   string   `json:"digest,omitempty"`
+}
+
+type notificationChannelResponse struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	Enabled   bool                   `json:"enabled"`
+	Webhook   *webhookConfigResponse `json:"webhook,omitempty"`
+	Email     *emailConfigResponse   `json:"email,omitempty"`
+	CreatedAt string                 `json:"createdAt"`
+	UpdatedAt string                 `json:"updatedAt"`
+}
+
+type webhookConfigResponse struct {
+	URL       string            `json:"url"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	HasSecret bool              `json:"hasSecret"`
+}
+
+type emailConfigResponse struct {
+	Host        string   `json:"host"`
+	Port        int      `json:"port"`
+	Username    string   `json:"username,omitempty"`
+	HasPassword bool     `json:"hasPassword"`
+	From        string   `json:"from"`
+	To          []string `json:"to"`
+	TLS         string   `json:"tls"`
+	Digest      string   `json:"digest,omitempty"`
+}
+
+type notificationSubscriptionRequest struct {
+	RuleID      string `json:"ruleId"`
+	UnitID      string `json:"unitId"`
+	MinSeverity string `json:"minSeverity"`
+}
+
+type notificationSubscriptionResponse struct {
+	ID          int64  `json:"id"`
+	ChannelID   string `json:"channelId"`
+	RuleID      string `json:"ruleId,omitempty"`
+	UnitID      string `json:"unitId,omitempty"`
+	MinSeverity string `json:"minSeverity,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type notificationDeliveryResponse struct {
+	ID            int64  `json:"id"`
+	ChannelID     string `json:"channelId"`
+	AlertID       *int64 `json:"alertId,omitempty"`
+	Event         string `json:"event"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	ResponseCode  *int   `json:"responseCode,omitempty"`
+	LastError     string `json:"lastError,omitempty"`
+	NextAttemptAt string `json:"nextAttemptAt,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+	DeliveredAt   string `json:"deliveredAt,omitempty"`
+}
+
+// webhookTemplateData mirrors the alert event the scheduler renders webhook
+// templates with, so templates can be checked when a channel is saved.
+type webhookTemplateData struct {
+	Event           string          `json:"event"`
+	AlertID         int64           `json:"alertId"`
+	RuleID          string          `json:"ruleId,omitempty"`
+	UIRuleID        string          `json:"uiRuleId,omitempty"`
+	UnitID          string          `json:"unitId,omitempty"`
+	UnitName        string          `json:"unitName,omitempty"`
+	ParameterName   string          `json:"parameterName"`
+	DetectorType    string          `json:"detectorType"`
+	Severity        string          `json:"severity"`
+	ObservedValue   string          `json:"observedValue"`
+	LimitExpression string          `json:"limitExpression"`
+	TS              time.Time       `json:"ts"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+}
+
+func (h *Handler) RegisterNotificationRoutes(r chi.Router) {
+	r.Route("/api/notification-channels", func(r chi.Router) {
+		r.Post("/", h.handleNotificationChannelCreate)
+		r.Get("/", h.handleNotificationChannelList)
+		r.Get("/{channelId}", h.handleNotificationChannelGet)
+		r.Put("/{channelId}", h.handleNotificationChannelUpdate)
+		r.Delete("/{channelId}", h.handleNotificationChannelDelete)
+		r.Post("/{channelId}/subscriptions", h.handleNotificationSubscriptionCreate)
+		r.Get("/{channelId}/subscriptions", h.handleNotificationSubscriptionList)
+		r.Delete("/{channelId}/subscriptions/{subscriptionId}", h.handleNotificationSubscriptionDelete)
+		r.Get("/{channelId}/deliveries", h.handleNotificationDeliveries)
+	})
+}
+
+func (h *Handler) handleNotificationChannelCreate(w http.ResponseWriter, r *http.Request) {
+	var req notificationChannelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if fieldErrors := validateNotificationChannelRequest(req); len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid notification channel request", fieldErrors)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channel, err := buildNotificationChannel(req, storage.NotificationChannel{Enabled: true}, h.Encryptor)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "encryption failed"})
+		return
+	}
+	created, err := h.Repo.CreateNotificationChannel(ctx, channel)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to create notification channel"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": toNotificationChannelResponse(created)})
+}
+
+func (h *Handler) handleNotificationChannelList(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channels, err := h.Repo.ListNotificationChannels(ctx)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to list notification channels"})
+		return
+	}
+	resp := make([]notificationChannelResponse, 0, len(channels))
+	for _, channel := range channels {
+		resp = append(resp, toNotificationChannelResponse(channel))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channels": resp})
+}
+
+func (h *Handler) handleNotificationChannelGet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channel, ok := h.loadNotificationChannel(ctx, w, chi.URLParam(r, "channelId"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": toNotificationChannelResponse(channel)})
+}
+
+func (h *Handler) handleNotificationChannelUpdate(w http.ResponseWriter, r *http.Request) {
+	var req notificationChannelRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if fieldErrors := validateNotificationChannelRequest(req); len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid notification channel request", fieldErrors)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	existing, ok := h.loadNotificationChannel(ctx, w, chi.URLParam(r, "channelId"))
+	if !ok {
+		return
+	}
+	channel, err := buildNotificationChannel(req, existing, h.Encryptor)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "encryption failed"})
+		return
+	}
+	updated, err := h.Repo.UpdateNotificationChannel(ctx, channel)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update notification channel"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "channel": toNotificationChannelResponse(updated)})
+}
+
+func (h *Handler) handleNotificationChannelDelete(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelId")
+	if _, err := uuid.Parse(channelID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification channel not found"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if err := h.Repo.DeleteNotificationChannel(ctx, channelID); err != nil {
+		if err == storage.ErrNotFound {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification channel not found"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to delete notification channel"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *Handler) handleNotificationSubscriptionCreate(w http.ResponseWriter, r *http.Request) {
+	var req notificationSubscriptionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if fieldErrors := validateNotificationSubscriptionRequest(req); len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid notification subscription request", fieldErrors)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channel, ok := h.loadNotificationChannel(ctx, w, chi.URLParam(r, "channelId"))
+	if !ok {
+		return
+	}
+	if req.RuleID != "" && !h.ruleExists(ctx, req.RuleID) {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	if req.UnitID != "" {
+		if _, err := h.Repo.GetMachineUnit(ctx, req.UnitID); err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "machine unit not found"})
+			return
+		}
+	}
+	created, err := h.Repo.CreateNotificationSubscription(ctx, storage.NotificationSubscription{ChannelID: channel.ID, RuleID: req.RuleID, UnitID: req.UnitID, MinSeverity: req.MinSeverity})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to create notification subscription"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "subscription": toNotificationSubscriptionResponse(created)})
+}
+
+func (h *Handler) handleNotificationSubscriptionList(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channel, ok := h.loadNotificationChannel(ctx, w, chi.URLParam(r, "channelId"))
+	if !ok {
+		return
+	}
+	subscriptions, err := h.Repo.ListNotificationSubscriptions(ctx, channel.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to list notification subscriptions"})
+		return
+	}
+	resp := make([]notificationSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		resp = append(resp, toNotificationSubscriptionResponse(subscription))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "subscriptions": resp})
+}
+
+func (h *Handler) handleNotificationSubscriptionDelete(w http.ResponseWriter, r *http.Request) {
+	channelID := chi.URLParam(r, "channelId")
+	subscriptionID, err := strconv.ParseInt(chi.URLParam(r, "subscriptionId"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "invalid subscription id"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := uuid.Parse(channelID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification subscription not found"})
+		return
+	}
+	if err := h.Repo.DeleteNotificationSubscription(ctx, channelID, subscriptionID); err != nil {
+		if err == storage.ErrNotFound {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification subscription not found"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to delete notification subscription"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *Handler) handleNotificationDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePaging(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	channel, ok := h.loadNotificationChannel(ctx, w, chi.URLParam(r, "channelId"))
+	if !ok {
+		return
+	}
+	deliveries, total, err := h.Repo.ListNotificationDeliveries(ctx, channel.ID, limit, offset)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch deliveries"})
+		return
+	}
+	resp := make([]notificationDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		resp = append(resp, toNotificationDeliveryResponse(delivery))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deliveries": resp, "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *Handler) loadNotificationChannel(ctx context.Context, w http.ResponseWriter, id string) (storage.NotificationChannel, bool) {
+	if _, err := uuid.Parse(id); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification channel not found"})
+		return storage.NotificationChannel{}, false
+	}
+	channel, err := h.Repo.GetNotificationChannel(ctx, id)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "notification channel not found"})
+			return storage.NotificationChannel{}, false
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch notification channel"})
+		return storage.NotificationChannel{}, false
+	}
+	return channel, true
+}
+
+// ruleExists accepts both legacy rules and stepper rules, which share the
+// alert tables.
+func (h *Handler) ruleExists(ctx context.Context, id string) bool {
+	if _, err := h.Repo.GetStepperRule(ctx, id); err == nil {
+		return true
+	}
+	_, err := h.Repo.GetRule(ctx, id)
+	return err == nil
+}
+
+func validateNotificationChannelRequest(req notificationChannelRequest) []FieldError {
+	fields := []FieldError{}
+	if strings.TrimSpace(req.Name) == "" {
+		fields = append(fields, FieldError{Field: "name", Problem: "missing"})
+	}
+	switch req.Type {
+	case "":
+		fields = append(fields, FieldError{Field: "type", Problem: "missing"})
+	case channelTypeWebhook:
+		fields = append(fields, validateWebhookConfig(req.Webhook)...)
+	case channelTypeEmail:
+		fields = append(fields, validateEmailConfig(req.Email)...)
+	default:
+		fields = append(fields, FieldError{Field: "type", Problem: "invalid", Hint: "Use webhook or email"})
+	}
+	return fields
+}
+
+func validateWebhookConfig(cfg *webhookConfigRequest) []FieldError {
+	if cfg == nil {
+		return []FieldError{{Field: "webhook", Problem: "missing"}}
+	}
+	fields := []FieldError{}
+	parsed, err := url.Parse(strings.TrimSpace(cfg.URL))
+	if strings.TrimSpace(cfg.URL) == "" {
+		fields = append(fields, FieldError{Field: "webhook.url", Problem: "missing"})
+	} else if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fields = append(fields, FieldError{Field: "webhook.url", Problem: "invalid", Hint: "Use an absolute http or https URL"})
+	}
+	for name := range cfg.Headers {
+		if !headerNameRe.MatchString(name) {
+			fields = append(fields, FieldError{Field: "webhook.headers." + name, Problem: "invalid"})
+		}
+	}
+	if cfg.Template != "" {
+		if problem := checkWebhookTemplate(cfg.Template); problem != "" {
+			fields = append(fields, FieldError{Field: "webhook.template", Problem: problem, Hint: "The template must render valid JSON; use {{json .Field}} to quote values"})
+		}
+	}
+	return fields
+}
+
+func validateEmailConfig(cfg *emailConfigRequest) []FieldError {
+	if cfg == nil {
+		return []FieldError{{Field: "email", Problem: "missing"}}
+	}
+	fields := []FieldError{}
+	if strings.TrimSpace(cfg.Host) == "" {
+		fields = append(fields, FieldError{Field: "email.host", Problem: "missing"})
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		fields = append(fields, FieldError{Field: "email.port", Problem: "invalid"})
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		fields = append(fields, FieldError{Field: "email.from", Problem: "invalid"})
+	}
+	if len(cfg.To) == 0 {
+		fields = append(fields, FieldError{Field: "email.to", Problem: "missing"})
+	}
+	for i, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			fields = append(fields, FieldError{Field: "email.to[" + itoa(i) + "]", Problem: "invalid"})
+		}
+	}
+	if cfg.TLS != "" && cfg.TLS != "starttls" && cfg.TLS != "tls" && cfg.TLS != "none" {
+		fields = append(fields, FieldError{Field: "email.tls", Problem: "invalid", Hint: "Use starttls, tls or none"})
+	}
+	host := strings.TrimSpace(cfg.Host)
+	if cfg.TLS == "none" && cfg.Username != "" && host != "localhost" && host != "127.0.0.1" && host != "::1" {
+		fields = append(fields, FieldError{Field: "email.tls", Problem: "invalid", Hint: "Credentials are only sent over starttls or tls, except to localhost"})
+	}
+	if cfg.Digest != "" && cfg.Digest != "hourly" && cfg.Digest != "daily" {
+		fields = append(fields, FieldError{Field: "email.digest", Problem: "invalid", Hint: "Use hourly or daily, or omit it for one email per alert"})
+	}
+	return fields
+}
+
+// checkWebhookTemplate parses the template and renders it against a sample
+// alert event. It returns the problem, or "" when the output is valid JSON.
+func checkWebhookTemplate(text string) string {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(text)
+	if err != nil {
+		return "invalid"
+	}
+	sample := webhookTemplateData{
+		Event:           "alert.created",
+		AlertID:         1,
+		RuleID:          uuid.NewString(),
+		UnitID:          "machine-" + uuid.NewString(),
+		UnitName:        "etcher-1",
+		ParameterName:   "temperature",
+		DetectorType:    "threshold",
+		Severity:        "high",
+		ObservedValue:   "101.5",
+		LimitExpression: "value > 100",
+		TS:              time.Now().UTC(),
+		Metadata:        json.RawMessage(`{}`),
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, sample); err != nil {
+		return "invalid"
+	}
+	if !json.Valid(out.Bytes()) {
+		return "invalid_json"
+	}
+	return ""
+}
+
+func templateJSON(value any) (string, error) {
+	raw, err := json.Marshal(value)
+	return string(raw), err
+}
+
+func validateNotificationSubscriptionRequest(req notificationSubscriptionRequest) []FieldError {
+	fields := []FieldError{}
+	if req.RuleID != "" {
+		if _, err := uuid.Parse(req.RuleID); err != nil {
+			fields = append(fields, FieldError{Field: "ruleId", Problem: "invalid"})
+		}
+	}
+	if req.MinSeverity != "" && req.MinSeverity != "medium" && req.MinSeverity != "high" {
+		fields = append(fields, FieldError{Field: "minSeverity", Problem: "invalid", Hint: "Use medium or high"})
+	}
+	return fields
+}
+
+// buildNotificationChannel applies req on top of existing. A webhook secret
+// or SMTP password left out of the request keeps the stored one; an empty
+// value clears it. New secrets are stored encrypted with enc.
+func buildNotificationChannel(req notificationChannelRequest, existing storage.NotificationChannel, enc crypto.Encryptor) (storage.NotificationChannel, error) {
+	channel := existing
+	channel.Name = strings.TrimSpace(req.Name)
+	channel.Type = req.Type
+	if req.Enabled != nil {
+		channel.Enabled = *req.Enabled
+	}
+	if req.Webhook != nil {
+		var previous webhookConfig
+		if existing.Type == channelTypeWebhook {
+			_ = json.Unmarshal(existing.Config, &previous)
+		}
+		cfg := webhookConfig{URL: strings.TrimSpace(req.Webhook.URL), Headers: req.Webhook.Headers, Template: req.Webhook.Template, Secret: previous.Secret}
+		if req.Webhook.Secret != nil {
+			secret, err := encryptChannelSecret(enc, *req.Webhook.Secret)
+			if err != nil {
+				return storage.NotificationChannel{}, err
+			}
+			cfg.Secret = secret
+		}
+		channel.Config, _ = json.Marshal(cfg)
+	}
+	if req.Email != nil {
+		var previous emailConfig
+		if existing.Type == channelTypeEmail {
+			_ = json.Unmarshal(existing.Config, &previous)
+		}
+		cfg := emailConfig{
+			Host:     strings.TrimSpace(req.Email.Host),
+			Port:     req.Email.Port,
+			Username: req.Email.Username,
+			Password: previous.Password,
+			From:     req.Email.From,
+			To:       req.Email.To,
+			TLS:      req.Email.TLS,
+			Digest:   req.Email.Digest,
+		}
+		if req.Email.Password != nil {
+			password, err := encryptChannelSecret(enc, *req.Email.Password)
+			if err != nil {
+				return storage.NotificationChannel{}, err
+			}
+			cfg.Password = password
+		}
+		channel.Config, _ = json.Marshal(cfg)
+	}
+	return channel, nil
+}
+
+func encryptChannelSecret(enc crypto.Encryptor, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	return enc.Encrypt(secret)
+}
+
+func toNotificationChannelResponse(channel storage.NotificationChannel) notificationChannelResponse {
+	resp := notificationChannelResponse{
+		ID:        channel.ID,
+		Name:      channel.Name,
+		Type:      channel.Type,
+		Enabled:   channel.Enabled,
+		CreatedAt: channel.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: channel.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if channel.Type == channelTypeWebhook {
+		var cfg webhookConfig
+		_ = json.Unmarshal(channel.Config, &cfg)
+		resp.Webhook = &webhookConfigResponse{URL: cfg.URL, Headers: cfg.Headers, Template: cfg.Template, HasSecret: cfg.Secret != ""}
+	}
+	if channel.Type == channelTypeEmail {
+		var cfg emailConfig
+		_ = json.Unmarshal(channel.Config, &cfg)
+		tlsMode := cfg.TLS
+		if tlsMode == "" {
+			tlsMode = "starttls"
+		}
+		resp.Email = &emailConfigResponse{Host: cfg.Host, Port: cfg.Port, Username: cfg.Username, HasPassword: cfg.Password != "", From: cfg.From, To: cfg.To, TLS: tlsMode, Digest: cfg.Digest}
+	}
+	return resp
+}
+
+func toNotificationSubscriptionResponse(rec storage.NotificationSubscription) notificationSubscriptionResponse {
+	return notificationSubscriptionResponse{
+		ID:          rec.ID,
+		ChannelID:   rec.ChannelID,
+		RuleID:      rec.RuleID,
+		UnitID:      rec.UnitID,
+		MinSeverity: rec.MinSeverity,
+		CreatedAt:   rec.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toNotificationDeliveryResponse(rec storage.NotificationDelivery) notificationDeliveryResponse {
+	resp := notificationDeliveryResponse{
+		ID:           rec.ID,
+		ChannelID:    rec.ChannelID,
+		AlertID:      rec.AlertID,
+		Event:        rec.Event,
+		Status:       rec.Status,
+		Attempts:     rec.Attempts,
+		ResponseCode: rec.ResponseCode,
+		CreatedAt:    rec.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if rec.LastError != nil {
+		resp.LastError = *rec.LastError
+	}
+	if rec.Status == "PENDING" {
+		resp.NextAttemptAt = rec.NextAttemptAt.UTC().Format(time.RFC3339)
+	}
+	if rec.DeliveredAt != nil {
+		resp.DeliveredAt = rec.DeliveredAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
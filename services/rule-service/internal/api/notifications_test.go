@@ -0,0 +1,143 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/pkg/crypto"
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+func TestValidateNotificationChannelRequest(t *testing.T) {
+	fields := validateNotificationChannelRequest(notificationChannelRequest{Type: "pager"})
+	if len(fields) != 2 || fields[0].Field != "name" || fields[1].Field != "type" {
+		t.Fatalf("expected name and type errors, got %+v", fields)
+	}
+	fields = validateNotificationChannelRequest(notificationChannelRequest{Name: "ops", Type: channelTypeWebhook, Webhook: &webhookConfigRequest{
+		URL:      "ftp://example.com",
+		Headers:  map[string]string{"Bad Header": "x"},
+		Template: `{"alert": {{.ParameterName}}}`,
+	}})
+	if len(fields) != 3 {
+		t.Fatalf("expected url, header and template errors, got %+v", fields)
+	}
+	valid := notificationChannelRequest{Name: "ops", Type: channelTypeWebhook, Webhook: &webhookConfigRequest{
+		URL:      "https://hooks.example.com/alerts",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Template: `{"text": {{json (printf "%s %s on %s" .Severity .DetectorType .ParameterName)}}, "value": {{json .ObservedValue}}}`,
+	}}
+	if fields := validateNotificationChannelRequest(valid); len(fields) != 0 {
+		t.Fatalf("unexpected errors %+v", fields)
+	}
+}
+
+func TestValidateEmailConfig(t *testing.T) {
+	fields := validateNotificationChannelRequest(notificationChannelRequest{Name: "shift", Type: channelTypeEmail, Email: &emailConfigRequest{
+		Host:   "smtp.example.com",
+		Port:   0,
+		From:   "not an address",
+		To:     []string{"lead@example.com", "bad"},
+		TLS:    "ssl",
+		Digest: "weekly",
+	}})
+	want := []string{"email.port", "email.from", "email.to[1]", "email.tls", "email.digest"}
+	if len(fields) != len(want) {
+		t.Fatalf("expected %v, got %+v", want, fields)
+	}
+	for i, field := range want {
+		if fields[i].Field != field {
+			t.Fatalf("expected %s at %d, got %+v", field, i, fields)
+		}
+	}
+	plain := notificationChannelRequest{Name: "shift", Type: channelTypeEmail, Email: &emailConfigRequest{Host: "smtp.example.com", Port: 25, Username: "alerts", From: "alerts@example.com", To: []string{"lead@example.com"}, TLS: "none"}}
+	if fields := validateNotificationChannelRequest(plain); len(fields) != 1 || fields[0].Field != "email.tls" {
+		t.Fatalf("expected credentials without TLS to be rejected, got %+v", fields)
+	}
+	plain.Email.Host = "localhost"
+	if fields := validateNotificationChannelRequest(plain); len(fields) != 0 {
+		t.Fatalf("unexpected errors for a local relay %+v", fields)
+	}
+	valid := notificationChannelRequest{Name: "shift", Type: channelTypeEmail, Email: &emailConfigRequest{Host: "smtp.example.com", Port: 587, From: "Predixa <alerts@example.com>", To: []string{"lead@example.com"}, Digest: "hourly"}}
+	if fields := validateNotificationChannelRequest(valid); len(fields) != 0 {
+		t.Fatalf("unexpected errors %+v", fields)
+	}
+	password := "pw"
+	valid.Email.Password = &password
+	enc, _ := crypto.NewAesGcmEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	channel, err := buildNotificationChannel(valid, storage.NotificationChannel{}, enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored emailConfig
+	_ = json.Unmarshal(channel.Config, &stored)
+	if plain, err := enc.Decrypt(stored.Password); err != nil || plain != "pw" || bytes.Contains(channel.Config, []byte(`"pw"`)) {
+		t.Fatalf("expected the password to be stored encrypted, got %s", channel.Config)
+	}
+	resp := toNotificationChannelResponse(channel)
+	if resp.Email == nil || !resp.Email.HasPassword || resp.Email.TLS != "starttls" || resp.Email.Digest != "hourly" {
+		t.Fatalf("unexpected email response %+v", resp.Email)
+	}
+}
+
+func TestBuildNotificationChannelKeepsSecret(t *testing.T) {
+	existing := storage.NotificationChannel{ID: "c1", Type: channelTypeWebhook, Enabled: true, Config: json.RawMessage(`{"url":"https://a.example.com","secret":"s3cret"}`)}
+	req := notificationChannelRequest{Name: "ops", Type: channelTypeWebhook, Webhook: &webhookConfigRequest{URL: "https://b.example.com"}}
+	enc, _ := crypto.NewAesGcmEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	channel, _ := buildNotificationChannel(req, existing, enc)
+	var cfg webhookConfig
+	_ = json.Unmarshal(channel.Config, &cfg)
+	if cfg.URL != "https://b.example.com" || cfg.Secret != "s3cret" || !channel.Enabled {
+		t.Fatalf("expected the stored secret to be kept, got %+v", cfg)
+	}
+	empty := ""
+	req.Webhook.Secret = &empty
+	var cleared webhookConfig
+	updated, _ := buildNotificationChannel(req, existing, enc)
+	_ = json.Unmarshal(updated.Config, &cleared)
+	if cleared.Secret != "" {
+		t.Fatalf("expected an empty secret to clear it")
+	}
+	rotated := "n3w"
+	req.Webhook.Secret = &rotated
+	var encrypted webhookConfig
+	updated, _ = buildNotificationChannel(req, existing, enc)
+	_ = json.Unmarshal(updated.Config, &encrypted)
+	if plain, err := enc.Decrypt(encrypted.Secret); err != nil || plain != "n3w" {
+		t.Fatalf("expected a new secret to be stored encrypted, got %+v", encrypted)
+	}
+	resp := toNotificationChannelResponse(channel)
+	raw, _ := json.Marshal(resp)
+	if resp.Webhook == nil || !resp.Webhook.HasSecret || bytes.Contains(raw, []byte("s3cret")) {
+		t.Fatalf("expected the secret to be hidden, got %s", raw)
+	}
+}
+
+func TestNotificationRoutesRejectInvalidRequests(t *testing.T) {
+	h := &Handler{Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterNotificationRoutes(r)
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{http.MethodPost, "/api/notification-channels", `{"name":"ops","type":"webhook"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/notification-channels/c1/subscriptions", `{"minSeverity":"low"}`, http.StatusBadRequest},
+		{http.MethodDelete, "/api/notification-channels/c1/subscriptions/abc", "", http.StatusBadRequest},
+		{http.MethodGet, "/api/notification-channels/not-a-uuid", "", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body)))
+		if resp.Code != tc.want {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.path, tc.want, resp.Code)
+		}
+	}
+}
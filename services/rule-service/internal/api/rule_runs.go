@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+const (
+	defaultRunsLimit = 50
+	maxRunsLimit     = 500
+)
+
+func (h *Handler) handleRuleRuns(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	limit, offset, err := parsePaging(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetRule(ctx, id); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	runs, total, err := h.Repo.ListRuleRuns(ctx, id, limit, offset)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch runs"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"runs": toRuleRunResponses(runs), "total": total, "limit": limit, "offset": offset})
+}
+
+func (h *Handler) handleStepperRuleRuns(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	limit, offset, err := parsePaging(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetStepperRule(ctx, ruleID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	runs, total, err := h.Repo.ListStepperRuleRuns(ctx, ruleID, limit, offset)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch runs"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"runs": toRuleRunResponses(runs), "total": total, "limit": limit, "offset": offset})
+}
+
+func parsePaging(r *http.Request) (int, int, error) {
+	limit := defaultRunsLimit
+	offset := 0
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			return 0, 0, errors.New("limit must be a positive integer")
+		}
+		if value > maxRunsLimit {
+			value = maxRunsLimit
+		}
+		limit = value
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("offset")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = value
+	}
+	return limit, offset, nil
+}
+
+func toRuleRunResponses(runs []storage.RuleRunRecord) []ruleRunResponse {
+	responses := make([]ruleRunResponse, 0, len(runs))
+	for _, run := range runs {
+		resp := ruleRunResponse{
+			ID:            run.ID,
+			RuleID:        run.RuleID,
+			ParameterName: run.ParameterName,
+			DetectorType:  run.DetectorType,
+			Status:        run.Status,
+			StartedAt:     run.StartedAt.UTC().Format(time.RFC3339),
+			FinishedAt:    run.FinishedAt.UTC().Format(time.RFC3339),
+			DurationMS:    run.DurationMS,
+			SampleCount:   run.SampleCount,
+		}
+		if run.Error != nil {
+			resp.Error = *run.Error
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
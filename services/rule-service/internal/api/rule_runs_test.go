@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePaging(t *testing.T) {
+	limit, offset, err := parsePaging(httptest.NewRequest("GET", "/rules/r1/runs", nil))
+	if err != nil || limit != defaultRunsLimit || offset != 0 {
+		t.Fatalf("unexpected defaults limit=%d offset=%d err=%v", limit, offset, err)
+	}
+	limit, offset, err = parsePaging(httptest.NewRequest("GET", "/rules/r1/runs?limit=5000&offset=20", nil))
+	if err != nil || limit != maxRunsLimit || offset != 20 {
+		t.Fatalf("unexpected paging limit=%d offset=%d err=%v", limit, offset, err)
+	}
+	if _, _, err := parsePaging(httptest.NewRequest("GET", "/rules/r1/runs?limit=0", nil)); err == nil {
+		t.Fatalf("expected invalid limit error")
+	}
+	if _, _, err := parsePaging(httptest.NewRequest("GET", "/rules/r1/runs?offset=-1", nil)); err == nil {
+		t.Fatalf("expected invalid offset error")
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"predixaai-backend/services/rule-service/internal/rules"
+)
+
+type backtestRequest struct {
+	RuleID        string          `json:"ruleId,omitempty"`
+	UnitID        string          `json:"unitId,omitempty"`
+	ParameterID   string          `json:"parameterId,omitempty"`
+	RuleType      string          `json:"ruleType,omitempty"`
+	ConnectionRef string          `json:"connectionRef,omitempty"`
+	Config        json.RawMessage `json:"config,omitempty"`
+	Range         selectorSpec    `json:"range"`
+	Incidents     []selectorSpec  `json:"incidents,omitempty"`
+}
+
+type backtestResponse struct {
+	Status            string            `json:"status"`
+	Window            map[string]string `json:"window"`
+	Baseline          map[string]any    `json:"baseline"`
+	Settings          map[string]int    `json:"settings"`
+	Polls             int               `json:"polls"`
+	Evaluations       int               `json:"evaluations"`
+	Insufficient      int               `json:"insufficient"`
+	Alerts            []map[string]any  `json:"alerts"`
+	CountsBySeverity  map[string]int    `json:"countsBySeverity"`
+	Suppressed        int               `json:"suppressedByCooldown"`
+	FalseAlarms       int               `json:"falseAlarms"`
+	FalseAlarmRate    float64           `json:"falseAlarmRate"`
+	Incidents         int               `json:"incidents"`
+	IncidentsDetected int               `json:"incidentsDetected"`
+	Messages          []string          `json:"messages"`
+}
+
+type schedulerBacktestRequest struct {
+	ConnectionRef   string           `json:"connectionRef"`
+	Table           string           `json:"table"`
+	TimestampColumn string           `json:"timestampColumn"`
+	OrderingColumn  string           `json:"orderingColumn,omitempty"`
+	Where           *rules.WhereSpec `json:"where,omitempty"`
+	ValueColumn     string           `json:"valueColumn"`
+	Expression      string           `json:"expression,omitempty"`
+	RuleType        string           `json:"ruleType"`
+	Config          json.RawMessage  `json:"config"`
+	Range           selectorSpec     `json:"range"`
+	Incidents       []selectorSpec   `json:"incidents,omitempty"`
+}
+
+func (h *Handler) handleRuleBacktest(w http.ResponseWriter, r *http.Request) {
+	var req backtestRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if fieldErrors := validateBacktestRequest(req); len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid backtest request", fieldErrors)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if req.RuleID != "" {
+		rule, err := h.Repo.GetStepperRule(ctx, req.RuleID)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+			return
+		}
+		unit, err := h.Repo.GetMachineUnit(ctx, rule.UnitID)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "machine unit not found"})
+			return
+		}
+		req.UnitID, req.ParameterID, req.RuleType, req.ConnectionRef = rule.UnitID, rule.ParameterID, rule.RuleType, unit.ConnectionRef
+		if len(req.Config) == 0 {
+			req.Config = rule.Config
+		}
+	} else if ok, err := h.Repo.ConnectionExists(ctx, req.ConnectionRef); err != nil || !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "connectionRef not found"})
+		return
+	}
+	paramInfo, err := h.resolveParameter(ctx, req.UnitID, req.ParameterID)
+	if err != nil {
+		writeParameterResolutionError(w, err)
+		return
+	}
+	client := schedulerClient{BaseURL: h.SchedulerURL, Client: defaultHTTPClient(h.Timeout)}
+	var resp backtestResponse
+	payload := schedulerBacktestRequest{
+		ConnectionRef:   req.ConnectionRef,
+		Table:           paramInfo.Table,
+		TimestampColumn: paramInfo.TimestampColumn,
+		OrderingColumn:  paramInfo.OrderingColumn,
+		Where:           paramInfo.Where,
+		ValueColumn:     paramInfo.ValueColumn,
+		Expression:      paramInfo.Expression,
+		RuleType:        req.RuleType,
+		Config:          req.Config,
+		Range:           req.Range,
+		Incidents:       req.Incidents,
+	}
+	if err := client.PostJSON(ctx, "/api/rules/backtest", payload, &resp); err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"ok": false, "message": "backtest failed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func validateBacktestRequest(req backtestRequest) []FieldError {
+	fields := []FieldError{}
+	if strings.TrimSpace(req.RuleID) == "" {
+		if strings.TrimSpace(req.UnitID) == "" {
+			fields = append(fields, FieldError{Field: "unitId", Problem: "missing", Hint: "Provide ruleId or a draft rule"})
+		}
+		if strings.TrimSpace(req.ParameterID) == "" {
+			fields = append(fields, FieldError{Field: "parameterId", Problem: "missing"})
+		}
+		if strings.TrimSpace(req.RuleType) == "" {
+			fields = append(fields, FieldError{Field: "ruleType", Problem: "missing"})
+		}
+		if strings.TrimSpace(req.ConnectionRef) == "" {
+			fields = append(fields, FieldError{Field: "connectionRef", Problem: "missing"})
+		}
+		if len(req.Config) == 0 {
+			fields = append(fields, FieldError{Field: "config", Problem: "missing"})
+		}
+	}
+	if req.Range.Kind == "" {
+		fields = append(fields, FieldError{Field: "range", Problem: "missing"})
+	} else if !isSelectorKind(req.Range.Kind) {
+		fields = append(fields, FieldError{Field: "range.kind", Problem: "invalid"})
+	}
+	for _, incident := range req.Incidents {
+		if incident.Kind != "timeRange" && incident.Kind != "runRange" {
+			fields = append(fields, FieldError{Field: "incidents.kind", Problem: "invalid", Hint: "Use timeRange or runRange"})
+			break
+		}
+	}
+	return fields
+}
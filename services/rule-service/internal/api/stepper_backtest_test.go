@@ -0,0 +1,44 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestBacktestRequestValidation(t *testing.T) {
+	h := &Handler{Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterStepperRoutes(r)
+
+	cases := []struct {
+		body  map[string]any
+		field string
+	}{
+		{map[string]any{"ruleId": "rule-1"}, "range"},
+		{map[string]any{"ruleId": "rule-1", "range": map[string]any{"kind": "lastN", "value": 500}, "incidents": []any{map[string]any{"kind": "lastN"}}}, "incidents.kind"},
+		{map[string]any{"range": map[string]any{"kind": "lastN", "value": 500}}, "unitId"},
+	}
+	for _, tc := range cases {
+		body, _ := json.Marshal(tc.body)
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/rules/backtest", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(resp, req)
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.Code)
+		}
+		var parsed validationErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if len(parsed.FieldErrors) == 0 || parsed.FieldErrors[0].Field != tc.field {
+			t.Fatalf("expected %s error, got %+v", tc.field, parsed.FieldErrors)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+type baselineStats struct {
+	ParameterName string     `json:"parameterName"`
+	DetectorType  string     `json:"detectorType"`
+	Mu            *float64   `json:"mu,omitempty"`
+	Sigma         *float64   `json:"sigma,omitempty"`
+	RBar          *float64   `json:"rbar,omitempty"`
+	Median        *float64   `json:"median,omitempty"`
+	MAD           *float64   `json:"mad,omitempty"`
+	N             int        `json:"n"`
+	SubgroupCount int        `json:"subgroupCount,omitempty"`
+	WindowStart   *time.Time `json:"windowStart,omitempty"`
+	WindowEnd     *time.Time `json:"windowEnd,omitempty"`
+	OrderStart    *int64     `json:"orderStart,omitempty"`
+	OrderEnd      *int64     `json:"orderEnd,omitempty"`
+	DataHash      string     `json:"dataHash"`
+}
+
+type baselineResponse struct {
+	ID            int64    `json:"id"`
+	RuleID        string   `json:"ruleId"`
+	ParameterName string   `json:"parameterName"`
+	DetectorType  string   `json:"detectorType"`
+	Status        string   `json:"status"`
+	Source        string   `json:"source"`
+	Mu            *float64 `json:"mu,omitempty"`
+	Sigma         *float64 `json:"sigma,omitempty"`
+	RBar          *float64 `json:"rbar,omitempty"`
+	Median        *float64 `json:"median,omitempty"`
+	MAD           *float64 `json:"mad,omitempty"`
+	N             int      `json:"n"`
+	SubgroupCount int      `json:"subgroupCount,omitempty"`
+	WindowStart   string   `json:"windowStart,omitempty"`
+	WindowEnd     string   `json:"windowEnd,omitempty"`
+	OrderStart    *int64   `json:"orderStart,omitempty"`
+	OrderEnd      *int64   `json:"orderEnd,omitempty"`
+	DataHash      string   `json:"dataHash"`
+	CreatedAt     string   `json:"createdAt"`
+	ApprovedAt    string   `json:"approvedAt,omitempty"`
+}
+
+type stepperBaselineConfig struct {
+	Baseline *struct {
+		Selector *selectorSpec `json:"selector"`
+	} `json:"baseline"`
+	Subgrouping *subgroupSpec `json:"subgrouping"`
+}
+
+func (h *Handler) handleStepperBaselines(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetStepperRule(ctx, ruleID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	baselines, err := h.Repo.ListStepperBaselines(ctx, ruleID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch baselines"})
+		return
+	}
+	resp := make([]baselineResponse, 0, len(baselines))
+	for _, baseline := range baselines {
+		resp = append(resp, toBaselineResponse(baseline))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"baselines": resp})
+}
+
+func (h *Handler) handleStepperBaselineRecompute(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	rule, err := h.Repo.GetStepperRule(ctx, ruleID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	unit, err := h.Repo.GetMachineUnit(ctx, rule.UnitID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "machine unit not found"})
+		return
+	}
+	paramInfo, err := h.resolveParameter(ctx, rule.UnitID, rule.ParameterID)
+	if err != nil {
+		writeParameterResolutionError(w, err)
+		return
+	}
+	check, err := h.checkStepperBaseline(ctx, unit.ConnectionRef, paramInfo, rule.RuleType, rule.Config)
+	if err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"ok": false, "message": "baseline check failed"})
+		return
+	}
+	if check.Stats == nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"ok": false, "message": "baseline not available", "status": check.Status, "available": check.Available, "required": check.Required})
+		return
+	}
+	rec, err := h.Repo.CreateStepperBaseline(ctx, toBaselineRecord(ruleID, *check.Stats, storage.BaselineStatusPending, "recompute"))
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to save baseline"})
+		return
+	}
+	writeJSON(w, http.StatusOK, toBaselineResponse(rec))
+}
+
+// checkStepperBaseline asks the scheduler for the statistics of the rule's
+// baseline selector, lastN 50 when the config has none.
+func (h *Handler) checkStepperBaseline(ctx context.Context, connectionRef string, paramInfo parameterInfo, ruleType string, config json.RawMessage) (baselineCheckResponse, error) {
+	var cfg stepperBaselineConfig
+	_ = json.Unmarshal(config, &cfg)
+	selector := selectorSpec{Kind: "lastN", Value: 50}
+	if cfg.Baseline != nil && cfg.Baseline.Selector != nil {
+		selector = *cfg.Baseline.Selector
+	}
+	client := schedulerClient{BaseURL: h.SchedulerURL, Client: defaultHTTPClient(h.Timeout)}
+	var check baselineCheckResponse
+	payload := schedulerBaselineRequest{
+		ConnectionRef:    connectionRef,
+		Table:            paramInfo.Table,
+		TimestampColumn:  paramInfo.TimestampColumn,
+		OrderingColumn:   paramInfo.OrderingColumn,
+		Where:            paramInfo.Where,
+		ValueColumn:      paramInfo.ValueColumn,
+		Expression:       paramInfo.Expression,
+		RuleType:         ruleType,
+		Config:           config,
+		BaselineSelector: selector,
+		Subgrouping:      cfg.Subgrouping,
+	}
+	err := client.PostJSON(ctx, "/api/rules/baseline/check", payload, &check)
+	return check, err
+}
+
+func (h *Handler) handleStepperBaselineApprove(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	baselineID, err := strconv.ParseInt(chi.URLParam(r, "baselineId"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "invalid baseline id"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	rec, err := h.Repo.ApproveStepperBaseline(ctx, ruleID, baselineID)
+	if err == storage.ErrNotFound {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "baseline not found"})
+		return
+	}
+	if err == storage.ErrBaselineNotPending {
+		writeJSON(w, http.StatusConflict, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to approve baseline"})
+		return
+	}
+	writeJSON(w, http.StatusOK, toBaselineResponse(rec))
+}
+
+func validateBaselineStats(stats *baselineStats) []FieldError {
+	fields := []FieldError{}
+	if stats == nil {
+		return fields
+	}
+	if strings.TrimSpace(stats.DetectorType) == "" {
+		fields = append(fields, FieldError{Field: "baselineStats.detectorType", Problem: "missing"})
+	}
+	if stats.N <= 0 {
+		fields = append(fields, FieldError{Field: "baselineStats.n", Problem: "invalid", Hint: "n must be > 0"})
+	}
+	if stats.Sigma != nil && *stats.Sigma < 0 {
+		fields = append(fields, FieldError{Field: "baselineStats.sigma", Problem: "invalid", Hint: "sigma must be >= 0"})
+	}
+	if strings.TrimSpace(stats.DataHash) == "" {
+		fields = append(fields, FieldError{Field: "baselineStats.dataHash", Problem: "missing"})
+	}
+	return fields
+}
+
+// degenerateBaselineStats reports a baseline without spread, which would
+// flag every later point.
+func degenerateBaselineStats(stats baselineStats) bool {
+	switch stats.DetectorType {
+	case "robust_zscore":
+		return stats.MAD == nil || *stats.MAD <= 0
+	case "range_chart":
+		return stats.RBar == nil || *stats.RBar <= 0
+	default:
+		return stats.Sigma == nil || *stats.Sigma <= 0
+	}
+}
+
+func toBaselineRecord(ruleID string, stats baselineStats, status, source string) storage.Baseline {
+	return storage.Baseline{
+		UIRuleID:      ruleID,
+		ParameterName: stats.ParameterName,
+		DetectorType:  stats.DetectorType,
+		Status:        status,
+		Source:        source,
+		Mu:            stats.Mu,
+		Sigma:         stats.Sigma,
+		RBar:          stats.RBar,
+		Median:        stats.Median,
+		MAD:           stats.MAD,
+		N:             stats.N,
+		SubgroupCount: stats.SubgroupCount,
+		WindowStart:   stats.WindowStart,
+		WindowEnd:     stats.WindowEnd,
+		OrderStart:    stats.OrderStart,
+		OrderEnd:      stats.OrderEnd,
+		DataHash:      stats.DataHash,
+	}
+}
+
+func toBaselineResponse(rec storage.Baseline) baselineResponse {
+	return baselineResponse{
+		ID:            rec.ID,
+		RuleID:        rec.UIRuleID,
+		ParameterName: rec.ParameterName,
+		DetectorType:  rec.DetectorType,
+		Status:        rec.Status,
+		Source:        rec.Source,
+		Mu:            rec.Mu,
+		Sigma:         rec.Sigma,
+		RBar:          rec.RBar,
+		Median:        rec.Median,
+		MAD:           rec.MAD,
+		N:             rec.N,
+		SubgroupCount: rec.SubgroupCount,
+		WindowStart:   formatOptionalTime(rec.WindowStart),
+		WindowEnd:     formatOptionalTime(rec.WindowEnd),
+		OrderStart:    rec.OrderStart,
+		OrderEnd:      rec.OrderEnd,
+		DataHash:      rec.DataHash,
+		CreatedAt:     rec.CreatedAt.UTC().Format(time.RFC3339),
+		ApprovedAt:    formatOptionalTime(rec.ApprovedAt),
+	}
+}
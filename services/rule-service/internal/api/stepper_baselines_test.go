@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestValidateBaselineStats(t *testing.T) {
+	if fields := validateBaselineStats(nil); len(fields) != 0 {
+		t.Fatalf("expected no errors without stats, got %+v", fields)
+	}
+	mu, sigma := 10.0, -1.0
+	fields := validateBaselineStats(&baselineStats{Mu: &mu, Sigma: &sigma})
+	if len(fields) != 4 {
+		t.Fatalf("expected detectorType, n, sigma and dataHash errors, got %+v", fields)
+	}
+	sigma = 1
+	if fields := validateBaselineStats(&baselineStats{DetectorType: "shewhart", Mu: &mu, Sigma: &sigma, N: 50, DataHash: "abc"}); len(fields) != 0 {
+		t.Fatalf("unexpected errors %+v", fields)
+	}
+}
+
+func TestBaselineApproveRejectsInvalidID(t *testing.T) {
+	h := &Handler{Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterStepperRoutes(r)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/api/rules/r1/baselines/abc/approve", nil))
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"predixaai-backend/services/rule-service/internal/rules"
+)
+
+type capabilityRequest struct {
+	UnitID          string                `json:"unitId"`
+	ParameterID     string                `json:"parameterId"`
+	ConnectionRef   string                `json:"connectionRef"`
+	SpecLimits      rules.SpecLimitBounds `json:"specLimits"`
+	ConfidenceLevel float64               `json:"confidenceLevel,omitempty"`
+	Selector        *selectorSpec         `json:"selector,omitempty"`
+}
+
+type capabilityResponse struct {
+	Status     string            `json:"status"`
+	Window     map[string]string `json:"window"`
+	Capability map[string]any    `json:"capability,omitempty"`
+	Messages   []string          `json:"messages"`
+}
+
+type schedulerCapabilityRequest struct {
+	ConnectionRef   string                `json:"connectionRef"`
+	Table           string                `json:"table"`
+	TimestampColumn string                `json:"timestampColumn"`
+	OrderingColumn  string                `json:"orderingColumn,omitempty"`
+	Where           *rules.WhereSpec      `json:"where,omitempty"`
+	ValueColumn     string                `json:"valueColumn"`
+	Expression      string                `json:"expression,omitempty"`
+	SpecLimits      rules.SpecLimitBounds `json:"specLimits"`
+	ConfidenceLevel float64               `json:"confidenceLevel,omitempty"`
+	Selector        *selectorSpec         `json:"selector,omitempty"`
+}
+
+func (h *Handler) handleRuleCapability(w http.ResponseWriter, r *http.Request) {
+	var req capabilityRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
+		return
+	}
+	if fieldErrors := validateCapabilityRequest(req); len(fieldErrors) > 0 {
+		writeStepperValidationError(w, "INVALID_REQUEST", "invalid capability request", fieldErrors)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if ok, err := h.Repo.ConnectionExists(ctx, req.ConnectionRef); err != nil || !ok {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "connectionRef not found"})
+		return
+	}
+	paramInfo, err := h.resolveParameter(ctx, req.UnitID, req.ParameterID)
+	if err != nil {
+		writeParameterResolutionError(w, err)
+		return
+	}
+	client := schedulerClient{BaseURL: h.SchedulerURL, Client: defaultHTTPClient(h.Timeout)}
+	var resp capabilityResponse
+	payload := schedulerCapabilityRequest{
+		ConnectionRef:   req.ConnectionRef,
+		Table:           paramInfo.Table,
+		TimestampColumn: paramInfo.TimestampColumn,
+		OrderingColumn:  paramInfo.OrderingColumn,
+		Where:           paramInfo.Where,
+		ValueColumn:     paramInfo.ValueColumn,
+		Expression:      paramInfo.Expression,
+		SpecLimits:      req.SpecLimits,
+		ConfidenceLevel: req.ConfidenceLevel,
+		Selector:        req.Selector,
+	}
+	if err := client.PostJSON(ctx, "/api/rules/capability", payload, &resp); err != nil {
+		writeJSON(w, http.StatusBadGateway, map[string]any{"ok": false, "message": "capability failed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func validateCapabilityRequest(req capabilityRequest) []FieldError {
+	fields := []FieldError{}
+	if strings.TrimSpace(req.UnitID) == "" {
+		fields = append(fields, FieldError{Field: "unitId", Problem: "missing"})
+	}
+	if strings.TrimSpace(req.ParameterID) == "" {
+		fields = append(fields, FieldError{Field: "parameterId", Problem: "missing"})
+	}
+	if strings.TrimSpace(req.ConnectionRef) == "" {
+		fields = append(fields, FieldError{Field: "connectionRef", Problem: "missing"})
+	}
+	limits := req.SpecLimits
+	if limits.USL == nil && limits.LSL == nil && limits.USLColumn == "" && limits.LSLColumn == "" {
+		fields = append(fields, FieldError{Field: "specLimits", Problem: "missing", Hint: "Provide USL/LSL or uslColumn/lslColumn"})
+	}
+	if limits.USL != nil && limits.LSL != nil && *limits.USL <= *limits.LSL {
+		fields = append(fields, FieldError{Field: "specLimits", Problem: "invalid", Hint: "usl must be greater than lsl"})
+	}
+	if req.ConfidenceLevel < 0 || req.ConfidenceLevel >= 1 {
+		fields = append(fields, FieldError{Field: "confidenceLevel", Problem: "invalid"})
+	}
+	if req.Selector != nil && req.Selector.Kind != "" && !isSelectorKind(req.Selector.Kind) {
+		fields = append(fields, FieldError{Field: "selector.kind", Problem: "invalid"})
+	}
+	return fields
+}
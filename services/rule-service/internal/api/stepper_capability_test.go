@@ -0,0 +1,40 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCapabilityRequestValidation(t *testing.T) {
+	h := &Handler{Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterStepperRoutes(r)
+
+	usl, lsl := 2.0, 10.0
+	body, _ := json.Marshal(map[string]any{
+		"unitId":        "unit-1",
+		"parameterId":   buildParameterID("etchers_data", "gas_ar_flow"),
+		"connectionRef": "conn-1",
+		"specLimits":    map[string]any{"usl": usl, "lsl": lsl},
+	})
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/rules/capability", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.Code)
+	}
+	var parsed validationErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(parsed.FieldErrors) != 1 || parsed.FieldErrors[0].Field != "specLimits" || parsed.FieldErrors[0].Problem != "invalid" {
+		t.Fatalf("expected specLimits error, got %+v", parsed.FieldErrors)
+	}
+}
@@ -2,6 +2,9 @@ package api
 
 import "net/http"
 
+// defaultStepChangeWindow matches the scheduler default for windowSize.
+const defaultStepChangeWindow = 10
+
 func (h *Handler) handleRuleCatalog(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, buildCatalog())
 }
@@ -53,6 +56,30 @@ func buildCatalog() catalogResponse {
 						Type:     "number",
 						Required: false,
 					},
+					{
+						Key:      "specLimits.uslColumn",
+						Label:    "USL Column",
+						Type:     "column",
+						Required: false,
+					},
+					{
+						Key:      "specLimits.lslColumn",
+						Label:    "LSL Column",
+						Type:     "column",
+						Required: false,
+					},
+					{
+						Key:      "controlLimits.uclColumn",
+						Label:    "UCL Column",
+						Type:     "column",
+						Required: false,
+					},
+					{
+						Key:      "controlLimits.lclColumn",
+						Label:    "LCL Column",
+						Type:     "column",
+						Required: false,
+					},
 					{
 						Key:      "epsilon",
 						Label:    "Tolerance",
@@ -245,6 +272,30 @@ func buildCatalog() catalogResponse {
 						Type:     "number",
 						Required: false,
 					},
+					{
+						Key:      "specLimits.usl",
+						Label:    "USL",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "specLimits.lsl",
+						Label:    "LSL",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "specLimits.uslColumn",
+						Label:    "USL Column",
+						Type:     "column",
+						Required: false,
+					},
+					{
+						Key:      "specLimits.lslColumn",
+						Label:    "LSL Column",
+						Type:     "column",
+						Required: false,
+					},
 					{
 						Key:      "requireSpecLimits",
 						Label:    "Require Spec Limits",
@@ -255,6 +306,536 @@ func buildCatalog() catalogResponse {
 				}},
 				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"windowN": 5, "regressionTimeBasis": "timestamp", "slopeThreshold": 0.5}}},
 			},
+			{
+				Type:                "RUN_RULES",
+				Title:               "Run Rules (Nelson / Western Electric)",
+				Description:         "Compute μ and σ from baseline (or frozen values); flag Nelson rule patterns such as 2 of 3 beyond 2σ or 9 on one side",
+				Phase:               2,
+				Category:            "run_rules",
+				RequiresBaseline:    true,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 20, MinBaselineSubgroups: 0, MinEvalSamples: 15},
+				RequiredInputs:      []string{"baselineSelector"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "baseline.selector",
+						Label:    "Baseline",
+						Type:     "baselineSelector",
+						Required: true,
+						Default:  map[string]any{"kind": "lastN", "value": 100},
+						HelpText: "Choose stable baseline period",
+					},
+					{
+						Key:         "rules",
+						Label:       "Rules",
+						Type:        "multiEnum",
+						Required:    false,
+						Default:     []int{1, 2, 3, 4, 5, 6, 7, 8},
+						EnumOptions: []string{"1", "2", "3", "4", "5", "6", "7", "8"},
+						HelpText:    "1: beyond 3σ, 2: run on one side, 3: 6 trending, 4: 14 alternating, 5: 2 of 3 beyond 2σ, 6: 4 of 5 beyond 1σ, 7: 15 within 1σ, 8: 8 outside 1σ",
+					},
+					{
+						Key:      "sameSideRun",
+						Label:    "Same-side run length",
+						Type:     "number",
+						Required: false,
+						Default:  9,
+						HelpText: "9 (Nelson) or 8 (Western Electric)",
+					},
+					{
+						Key:      "mu",
+						Label:    "Frozen μ",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "sigma",
+						Label:    "Frozen σ",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "minBaselineN",
+						Label:    "Min Baseline N",
+						Type:     "number",
+						Required: false,
+						Default:  20,
+					},
+				}},
+				Examples: []catalogExample{{Name: "Western Electric", Config: map[string]any{"rules": []int{1, 2, 5, 6}, "sameSideRun": 8}}},
+			},
+			{
+				Type:                "EWMA",
+				Title:               "EWMA Control Chart",
+				Description:         "Exponentially weighted moving average with time-varying limits μ±L·σ·√(λ/(2-λ)·(1-(1-λ)^2i)); catches small sustained shifts",
+				Phase:               2,
+				Category:            "ewma",
+				RequiresBaseline:    true,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 20, MinBaselineSubgroups: 0, MinEvalSamples: 1},
+				RequiredInputs:      []string{"baselineSelector"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "baseline.selector",
+						Label:    "Baseline",
+						Type:     "baselineSelector",
+						Required: true,
+						Default:  map[string]any{"kind": "lastN", "value": 100},
+						HelpText: "Choose stable baseline period",
+					},
+					{
+						Key:      "lambda",
+						Label:    "λ (smoothing)",
+						Type:     "number",
+						Required: false,
+						Default:  0.2,
+						HelpText: "Smaller values detect smaller shifts; 0.05-0.25 is typical",
+					},
+					{
+						Key:      "l",
+						Label:    "L (limit width)",
+						Type:     "number",
+						Required: false,
+						Default:  3,
+					},
+					{
+						Key:      "mu",
+						Label:    "Frozen μ",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "sigma",
+						Label:    "Frozen σ",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "minBaselineN",
+						Label:    "Min Baseline N",
+						Type:     "number",
+						Required: false,
+						Default:  20,
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"lambda": 0.2, "l": 3}}},
+			},
+			{
+				Type:                "CUSUM",
+				Title:               "CUSUM (Tabular)",
+				Description:         "Upper/lower cumulative sums C⁺=max(0,x-(μ0+kσ)+C⁺), C⁻=max(0,(μ0-kσ)-x+C⁻); alarms when either exceeds hσ, reports the change point and resets",
+				Phase:               2,
+				Category:            "cusum",
+				RequiresBaseline:    true,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 20, MinBaselineSubgroups: 0, MinEvalSamples: 1},
+				RequiredInputs:      []string{"baselineSelector"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "baseline.selector",
+						Label:    "Baseline",
+						Type:     "baselineSelector",
+						Required: true,
+						Default:  map[string]any{"kind": "lastN", "value": 100},
+						HelpText: "Used for μ0 and σ unless both are fixed",
+					},
+					{
+						Key:      "k",
+						Label:    "k (allowance, σ)",
+						Type:     "number",
+						Required: false,
+						Default:  0.5,
+						HelpText: "Half the shift to detect, in σ units",
+					},
+					{
+						Key:      "h",
+						Label:    "h (decision interval, σ)",
+						Type:     "number",
+						Required: false,
+						Default:  5,
+					},
+					{
+						Key:      "target",
+						Label:    "Target μ0",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "sigma",
+						Label:    "Frozen σ",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "minBaselineN",
+						Label:    "Min Baseline N",
+						Type:     "number",
+						Required: false,
+						Default:  20,
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"k": 0.5, "h": 5}}},
+			},
+			attributeCatalogType("P_CHART", "p Chart (Fraction Defective)", "Fraction defective d/n against binomial limits p̄±3√(p̄(1-p̄)/n); n from a sample size column", true),
+			attributeCatalogType("NP_CHART", "np Chart (Number Defective)", "Number defective against binomial limits np̄±3√(np̄(1-p̄)); constant sample size", true),
+			attributeCatalogType("C_CHART", "c Chart (Defect Count)", "Defects per inspection unit against Poisson limits c̄±3√c̄", false),
+			attributeCatalogType("U_CHART", "u Chart (Defects per Unit)", "Defects per unit c/n against Poisson limits ū±3√(ū/n); optional sample size column", false),
+			xbarCatalogType("XBAR_R", "X-bar / R Chart", "Subgroup means against X̿±A2·R̄ and subgroup ranges against D3/D4 limits; flags location and dispersion together"),
+			xbarCatalogType("XBAR_S", "X-bar / S Chart", "Subgroup means against X̿±A3·S̄ and subgroup standard deviations against B3/B4 limits; flags location and dispersion together"),
+			{
+				Type:                "I_MR",
+				Title:               "Individuals / Moving Range (I-MR)",
+				Description:         "Individual values against X̄±3·MR̄/d2 and moving ranges against D4·MR̄; for one measurement per run",
+				Phase:               2,
+				Category:            "control_chart",
+				RequiresBaseline:    true,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 20, MinBaselineSubgroups: 0, MinEvalSamples: 1},
+				RequiredInputs:      []string{"baselineSelector"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "baseline.selector",
+						Label:    "Baseline",
+						Type:     "baselineSelector",
+						Required: true,
+						Default:  map[string]any{"kind": "lastN", "value": 100},
+						HelpText: "Choose stable baseline period",
+					},
+					{
+						Key:      "minBaselineN",
+						Label:    "Min Baseline N",
+						Type:     "number",
+						Required: false,
+						Default:  20,
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"minBaselineN": 20}}},
+			},
+			{
+				Type:                "CAPABILITY",
+				Title:               "Process Capability (Cpk)",
+				Description:         "Rolling Cpk over the last N samples against spec limits; alerts when capability drops below a minimum",
+				Phase:               2,
+				Category:            "capability",
+				RequiresBaseline:    false,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 0, MinEvalSamples: 50},
+				RequiredInputs:      []string{"specLimits"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "specLimits.usl",
+						Label:    "USL",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "specLimits.lsl",
+						Label:    "LSL",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "minCpk",
+						Label:    "Minimum Cpk",
+						Type:     "number",
+						Required: false,
+						Default:  1.33,
+					},
+					{
+						Key:      "window",
+						Label:    "Window (samples)",
+						Type:     "number",
+						Required: false,
+						Default:  50,
+						HelpText: "Cpk is computed over this many most recent samples",
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"specLimits": map[string]any{"usl": 10, "lsl": 2}, "minCpk": 1.33, "window": 50}}},
+			},
+			{
+				Type:                "FLATLINE",
+				Title:               "Flatline / Stuck Sensor",
+				Description:         "Flags a value that has not moved beyond epsilon for N consecutive samples or T seconds",
+				Phase:               2,
+				Category:            "flatline",
+				RequiresBaseline:    false,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 0, MinEvalSamples: 2},
+				RequiredInputs:      []string{},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "epsilon",
+						Label:    "Epsilon",
+						Type:     "number",
+						Required: false,
+						Default:  0,
+						HelpText: "Largest spread still treated as unchanged",
+					},
+					{
+						Key:      "minSamples",
+						Label:    "Min Samples",
+						Type:     "number",
+						Required: false,
+						Default:  20,
+					},
+					{
+						Key:      "minDurationSeconds",
+						Label:    "Min Duration (s)",
+						Type:     "number",
+						Required: false,
+						HelpText: "Needs a timestamp column; set evalWindow to cover it",
+					},
+					{
+						Key:      "evalWindow",
+						Label:    "Eval Window",
+						Type:     "number",
+						Required: false,
+						Default:  100,
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"epsilon": 0, "minSamples": 20}}},
+			},
+			{
+				Type:                "STEP_CHANGE",
+				Title:               "Step Change",
+				Description:         "Compares the means of two adjacent windows and flags sudden level shifts",
+				Phase:               2,
+				Category:            "step_change",
+				RequiresBaseline:    false,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 0, MinEvalSamples: 2 * defaultStepChangeWindow},
+				RequiredInputs:      []string{},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "windowSize",
+						Label:    "Window Size",
+						Type:     "number",
+						Required: false,
+						Default:  defaultStepChangeWindow,
+						HelpText: "Samples on each side of the split; evaluation needs 2 × windowSize samples",
+					},
+					{
+						Key:      "sigmaMultiplier",
+						Label:    "Shift (σ)",
+						Type:     "number",
+						Required: false,
+						Default:  3,
+						HelpText: "Shift in pooled standard deviations of both windows",
+					},
+					{
+						Key:      "minShift",
+						Label:    "Min Shift",
+						Type:     "number",
+						Required: false,
+						HelpText: "Absolute shift in parameter units",
+					},
+				}},
+				Examples: []catalogExample{{Name: "Default", Config: map[string]any{"windowSize": 10, "sigmaMultiplier": 3}}},
+			},
+			{
+				Type:                "RATE_OF_CHANGE",
+				Title:               "Rate of Change",
+				Description:         "Derivative between consecutive samples, per second or per run, checked against min/max bounds",
+				Phase:               2,
+				Category:            "rate_of_change",
+				RequiresBaseline:    false,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 0, MinEvalSamples: 2},
+				RequiredInputs:      []string{"bounds"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "basis",
+						Label:    "Basis",
+						Type:     "enum",
+						Required: false,
+						Default:  "second",
+						EnumOptions: []string{"second", "run"},
+						HelpText: "Per second of timestamp or per run of ordering column",
+					},
+					{
+						Key:      "maxRate",
+						Label:    "Max Rate",
+						Type:     "number",
+						Required: false,
+					},
+					{
+						Key:      "minRate",
+						Label:    "Min Rate",
+						Type:     "number",
+						Required: false,
+						HelpText: "Use a negative value to bound drops",
+					},
+					{
+						Key:      "smoothingWindow",
+						Label:    "Smoothing Window",
+						Type:     "number",
+						Required: false,
+						Default:  1,
+						HelpText: "Average the rate over this many consecutive pairs",
+					},
+				}},
+				Examples: []catalogExample{{Name: "Ramp limit", Config: map[string]any{"basis": "second", "maxRate": 0.5, "minRate": -0.5, "smoothingWindow": 3}}},
+			},
+			{
+				Type:                "TEXT_MATCH",
+				Title:               "Text Match",
+				Description:         "Alerts when new rows of a text column match a keyword or regex, or when any value appears",
+				Phase:               2,
+				Category:            "text",
+				RequiresBaseline:    false,
+				SupportsSubgrouping: false,
+				MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 0, MinEvalSamples: 1},
+				RequiredInputs:      []string{"patterns"},
+				ConfigSchema: configSchema{Fields: []configField{
+					{
+						Key:      "patterns",
+						Label:    "Patterns",
+						Type:     "textPatterns",
+						Required: false,
+						HelpText: "Each pattern has a kind (keyword or regex), a severity (high or medium) and optional case sensitivity",
+					},
+					{
+						Key:      "anyValue",
+						Label:    "Alert On Any Value",
+						Type:     "boolean",
+						Required: false,
+						Default:  false,
+						HelpText: "Raise an alert whenever a non-empty value appears",
+					},
+					{
+						Key:         "anyValueSeverity",
+						Label:       "Any Value Severity",
+						Type:        "enum",
+						Required:    false,
+						Default:     "high",
+						EnumOptions: []string{"high", "medium"},
+						VisibleWhen: &visibleWhen{Field: "anyValue", Is: true},
+					},
+					{
+						Key:      "evalWindow",
+						Label:    "Initial Rows",
+						Type:     "number",
+						Required: false,
+						Default:  50,
+						HelpText: "Rows scanned on the first poll; later polls only scan new rows",
+					},
+				}},
+				Examples: []catalogExample{{Name: "Abort keywords", Config: map[string]any{"patterns": []map[string]any{{"pattern": "abort", "kind": "keyword", "severity": "high"}, {"pattern": "^W\\d+", "kind": "regex", "severity": "medium"}}}}},
+			},
 		},
 	}
 }
+
+func xbarCatalogType(ruleType, title, description string) catalogType {
+	return catalogType{
+		Type:                ruleType,
+		Title:               title,
+		Description:         description,
+		Phase:               2,
+		Category:            "control_chart",
+		RequiresBaseline:    true,
+		SupportsSubgrouping: true,
+		MinData:             minDataSpec{MinBaselineSamples: 0, MinBaselineSubgroups: 10, MinEvalSamples: 1},
+		RequiredInputs:      []string{"baselineSelector", "subgrouping"},
+		ConfigSchema: configSchema{Fields: []configField{
+			{
+				Key:      "baseline.selector",
+				Label:    "Baseline",
+				Type:     "baselineSelector",
+				Required: true,
+				Default:  map[string]any{"kind": "lastN", "value": 100},
+			},
+			{
+				Key:      "subgrouping.subgroupSize",
+				Label:    "Subgroup Size",
+				Type:     "number",
+				Required: true,
+				Default:  5,
+				HelpText: "Supported sizes: 2-10",
+			},
+			{
+				Key:         "subgrouping.kind",
+				Label:       "Subgrouping",
+				Type:        "enum",
+				Required:    true,
+				Default:     "column",
+				EnumOptions: []string{"column", "consecutive"},
+			},
+			{
+				Key:         "subgrouping.column",
+				Label:       "Subgroup Column",
+				Type:        "column",
+				Required:    false,
+				VisibleWhen: &visibleWhen{Field: "subgrouping.kind", Is: "column"},
+			},
+			{
+				Key:      "minBaselineSubgroups",
+				Label:    "Min Baseline Subgroups",
+				Type:     "number",
+				Required: false,
+				Default:  10,
+			},
+		}},
+		Examples: []catalogExample{{Name: "Default", Config: map[string]any{"subgroupSize": 5, "minBaselineSubgroups": 10}}},
+	}
+}
+
+func attributeCatalogType(ruleType, title, description string, sampleSizeRequired bool) catalogType {
+	return catalogType{
+		Type:                ruleType,
+		Title:               title,
+		Description:         description,
+		Phase:               2,
+		Category:            "attribute",
+		RequiresBaseline:    true,
+		SupportsSubgrouping: false,
+		MinData:             minDataSpec{MinBaselineSamples: 20, MinBaselineSubgroups: 0, MinEvalSamples: 1},
+		RequiredInputs:      []string{"baselineSelector"},
+		ConfigSchema: configSchema{Fields: []configField{
+			{
+				Key:      "baseline.selector",
+				Label:    "Baseline",
+				Type:     "baselineSelector",
+				Required: true,
+				Default:  map[string]any{"kind": "lastN", "value": 100},
+				HelpText: "Choose stable baseline period",
+			},
+			{
+				Key:      "sampleSizeColumn",
+				Label:    "Sample Size Column",
+				Type:     "column",
+				Required: false,
+				HelpText: "Units inspected per row",
+			},
+			{
+				Key:      "sampleSize",
+				Label:    "Sample Size",
+				Type:     "number",
+				Required: false,
+				HelpText: "Constant units inspected per row when there is no sample size column",
+			},
+			{
+				Key:      "sigmaMultiplier",
+				Label:    "Limit Width (σ)",
+				Type:     "number",
+				Required: false,
+				Default:  3,
+			},
+			{
+				Key:      "minBaselineN",
+				Label:    "Min Baseline N",
+				Type:     "number",
+				Required: false,
+				Default:  20,
+			},
+		}},
+		Examples: []catalogExample{attributeCatalogExample(sampleSizeRequired)},
+	}
+}
+
+func attributeCatalogExample(sampleSizeRequired bool) catalogExample {
+	if sampleSizeRequired {
+		return catalogExample{Name: "Wafers inspected", Config: map[string]any{"sampleSizeColumn": "wafers_inspected"}}
+	}
+	return catalogExample{Name: "Default", Config: map[string]any{"sigmaMultiplier": 3}}
+}
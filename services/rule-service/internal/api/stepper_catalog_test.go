@@ -30,6 +30,21 @@ func TestRuleCatalogContainsPhase1Types(t *testing.T) {
 		"RANGE_CHART_R":        true,
 		"TREND_6_POINTS":       true,
 		"TPA":                  true,
+		"RUN_RULES":            true,
+		"EWMA":                 true,
+		"CUSUM":                true,
+		"P_CHART":              true,
+		"NP_CHART":             true,
+		"C_CHART":              true,
+		"U_CHART":              true,
+		"XBAR_R":               true,
+		"XBAR_S":               true,
+		"I_MR":                 true,
+		"CAPABILITY":           true,
+		"FLATLINE":             true,
+		"STEP_CHANGE":          true,
+		"RATE_OF_CHANGE":       true,
+		"TEXT_MATCH":           true,
 	}
 	for _, entry := range payload.Types {
 		delete(want, entry.Type)
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 
@@ -33,7 +34,13 @@ func (h *Handler) handleRuleHealth(w http.ResponseWriter, r *http.Request) {
 		colTypes[col.Name] = col.Type
 	}
 	items := []ruleHealthItem{}
+	if rowFilter, err := decodeRowFilter(unit.RowFilter); err != nil || !rowFilterMatchesSchema(rowFilter, colTypes) {
+		items = append(items, ruleHealthItem{Severity: "error", Code: "ROW_FILTER_INVALID", Message: "machine unit rowFilter references unknown columns"})
+	}
 	for _, rule := range rulesList {
+		if rule.Status == "INVALID" {
+			items = append(items, ruleHealthItem{Severity: "error", Code: "RULE_INVALID", Message: stepperRuleErrorMessage(rule.LastError), RuleID: rule.ID, ParameterID: rule.ParameterID})
+		}
 		_, col := parseParameterID(rule.ParameterID)
 		if col == "" {
 			items = append(items, ruleHealthItem{Severity: "error", Code: "PARAMETER_NOT_FOUND", Message: "parameterId invalid", RuleID: rule.ID, ParameterID: rule.ParameterID})
@@ -44,6 +51,12 @@ func (h *Handler) handleRuleHealth(w http.ResponseWriter, r *http.Request) {
 			items = append(items, ruleHealthItem{Severity: "error", Code: "COLUMN_NOT_FOUND", Message: "value column not found", RuleID: rule.ID, ParameterID: rule.ParameterID})
 			continue
 		}
+		if rule.RuleType == "TEXT_MATCH" {
+			if !isTextType(typeName) {
+				items = append(items, ruleHealthItem{Severity: "error", Code: "COLUMN_NOT_TEXT", Message: "value column must be text", RuleID: rule.ID, ParameterID: rule.ParameterID})
+			}
+			continue
+		}
 		if !isNumericType(typeName) {
 			items = append(items, ruleHealthItem{Severity: "error", Code: "COLUMN_NOT_NUMERIC", Message: "value column must be numeric", RuleID: rule.ID, ParameterID: rule.ParameterID})
 		}
@@ -59,3 +72,13 @@ func (h *Handler) handleRuleHealth(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, ruleHealthResponse{UnitID: unitID, WarningsCount: warnings, ErrorsCount: errors, Items: items})
 }
+
+func stepperRuleErrorMessage(lastError json.RawMessage) string {
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(lastError, &payload); err != nil || payload.Error == "" {
+		return "rule rejected by scheduler"
+	}
+	return payload.Error
+}
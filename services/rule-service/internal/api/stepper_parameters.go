@@ -6,8 +6,13 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/pkg/expression"
+	"predixaai-backend/services/rule-service/internal/storage"
 )
 
+const derivedDataType = "derived"
+
 func (h *Handler) handleUnitParameters(w http.ResponseWriter, r *http.Request) {
 	unitID := chi.URLParam(r, "unitId")
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
@@ -35,28 +40,25 @@ func (h *Handler) handleUnitParameters(w http.ResponseWriter, r *http.Request) {
 			timestampCandidates = append(timestampCandidates, col.Name)
 		}
 	}
-	defaultTimestamp := pickTimestampColumn(timestampCandidates)
+	orderingColumn := strings.TrimSpace(unit.OrderingColumn)
+	defaultTimestamp := ""
+	if orderingColumn == "" {
+		defaultTimestamp = pickTimestampColumn(timestampCandidates)
+	}
 	unitTimestamp := strings.TrimSpace(unit.TimestampColumn)
 	if unitTimestamp != "" {
 		if _, ok := columns[unitTimestamp]; ok {
 			defaultTimestamp = unitTimestamp
 		}
 	}
-	params := make([]parameterResponse, 0, len(unit.SelectedColumns))
+	params := make([]parameterResponse, 0, len(unit.SelectedColumns)+len(unit.DerivedParameters))
 	for _, col := range unit.SelectedColumns {
 		typeName := columns[col]
 		notes := []string{}
 		if typeName == "" {
 			notes = append(notes, "column not found in schema")
 		}
-		if unitTimestamp != "" {
-			colType, ok := columns[unitTimestamp]
-			if !ok {
-				notes = append(notes, "timestampColumn not found in schema")
-			} else if !isTimeType(colType) {
-				notes = append(notes, "timestampColumn must be time/date")
-			}
-		}
+		notes = append(notes, sourceColumnNotes(columns, unitTimestamp, orderingColumn)...)
 		parameterID := buildParameterID(unit.SelectedTable, col)
 		params = append(params, parameterResponse{
 			ParameterID:              parameterID,
@@ -65,16 +67,82 @@ func (h *Handler) handleUnitParameters(w http.ResponseWriter, r *http.Request) {
 			ValueColumn:              col,
 			DataType:                 typeName,
 			TimestampColumn:          defaultTimestamp,
+			OrderingColumn:           orderingColumn,
 			SubgroupCandidateColumns: subgroupCandidates(schema.Columns, col, defaultTimestamp),
-			SupportsTrend:            isNumericType(typeName) && defaultTimestamp != "",
+			SupportsTrend:            isNumericType(typeName) && (defaultTimestamp != "" || orderingColumn != ""),
 			SupportsShewhart:         isNumericType(typeName),
 			SupportsRangeChart:       isNumericType(typeName),
+			SupportsAttributeChart:   isIntegerType(typeName),
+			SupportsTextMatch:        isTextType(typeName),
+			SampleSizeCandidateColumns: sampleSizeCandidates(schema.Columns, col, defaultTimestamp, orderingColumn),
+			SuggestedLimitColumns:    suggestLimitColumns(col, columns),
+			Notes:                    notes,
+		})
+	}
+	for _, derived := range unit.DerivedParameters {
+		notes := []string{}
+		if expr, err := expression.Parse(derived.Expression); err != nil {
+			notes = append(notes, "expression is invalid")
+		} else {
+			for _, col := range expr.Columns() {
+				colType, ok := columns[col]
+				if !ok {
+					notes = append(notes, "expression column "+col+" not found in schema")
+				} else if !isNumericType(colType) {
+					notes = append(notes, "expression column "+col+" must be numeric")
+				}
+			}
+		}
+		notes = append(notes, sourceColumnNotes(columns, unitTimestamp, orderingColumn)...)
+		params = append(params, parameterResponse{
+			ParameterID:              buildParameterID(unit.SelectedTable, derived.Name),
+			UnitName:                 unit.UnitName,
+			Table:                    unit.SelectedTable,
+			ValueColumn:              derived.Name,
+			DataType:                 derivedDataType,
+			Expression:               derived.Expression,
+			TimestampColumn:          defaultTimestamp,
+			OrderingColumn:           orderingColumn,
+			SubgroupCandidateColumns: subgroupCandidates(schema.Columns, derived.Name, defaultTimestamp),
+			SupportsTrend:            defaultTimestamp != "" || orderingColumn != "",
+			SupportsShewhart:         true,
+			SupportsRangeChart:       true,
 			Notes:                    notes,
 		})
 	}
 	writeJSON(w, http.StatusOK, unitParametersResponse{UnitID: unitID, Parameters: params})
 }
 
+func sourceColumnNotes(columns map[string]string, timestampColumn, orderingColumn string) []string {
+	notes := []string{}
+	if timestampColumn != "" {
+		colType, ok := columns[timestampColumn]
+		if !ok {
+			notes = append(notes, "timestampColumn not found in schema")
+		} else if !isTimeType(colType) {
+			notes = append(notes, "timestampColumn must be time/date")
+		}
+	}
+	if orderingColumn != "" {
+		colType, ok := columns[orderingColumn]
+		if !ok {
+			notes = append(notes, "orderingColumn not found in schema")
+		} else if !isIntegerType(colType) {
+			notes = append(notes, "orderingColumn must be integer")
+		}
+	}
+	return notes
+}
+
+func findDerivedParameter(params []storage.DerivedParameter, name string) (storage.DerivedParameter, bool) {
+	for _, param := range params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+	return storage.DerivedParameter{}, false
+}
+
 func buildParameterID(table, column string) string {
 	return table + "." + column
 }
@@ -111,11 +179,51 @@ func subgroupCandidates(columns []columnInfo, valueColumn, timestampColumn strin
 	return values
 }
 
+func sampleSizeCandidates(columns []columnInfo, valueColumn, timestampColumn, orderingColumn string) []string {
+	values := []string{}
+	for _, col := range columns {
+		if col.Name == valueColumn || col.Name == timestampColumn || col.Name == orderingColumn {
+			continue
+		}
+		if isIntegerType(col.Type) {
+			values = append(values, col.Name)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func suggestLimitColumns(valueColumn string, columns map[string]string) *limitColumnSuggestion {
+	suggestion := limitColumnSuggestion{}
+	if colType, ok := columns[valueColumn+"_upper_limit"]; ok && isNumericType(colType) {
+		suggestion.UpperColumn = valueColumn + "_upper_limit"
+	}
+	if colType, ok := columns[valueColumn+"_lower_limit"]; ok && isNumericType(colType) {
+		suggestion.LowerColumn = valueColumn + "_lower_limit"
+	}
+	if suggestion.UpperColumn == "" && suggestion.LowerColumn == "" {
+		return nil
+	}
+	return &suggestion
+}
+
 func isNumericType(t string) bool {
 	value := strings.ToLower(t)
 	return strings.Contains(value, "int") || strings.Contains(value, "decimal") || strings.Contains(value, "numeric") || strings.Contains(value, "float") || strings.Contains(value, "double") || strings.Contains(value, "real")
 }
 
+func isTextType(t string) bool {
+	value := strings.ToLower(t)
+	return strings.Contains(value, "char") || strings.Contains(value, "text") || strings.Contains(value, "string")
+}
+
+func isIntegerType(t string) bool {
+	value := strings.ToLower(t)
+	return strings.Contains(value, "int") || value == "serial" || value == "bigserial"
+}
+
 func isTimeType(t string) bool {
 	value := strings.ToLower(t)
 	return strings.Contains(value, "time") || strings.Contains(value, "date")
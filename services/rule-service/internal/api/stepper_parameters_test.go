@@ -19,6 +19,7 @@ func TestUnitParametersEligibility(t *testing.T) {
 		{Name: "rf_power", Type: "float"},
 		{Name: "ts", Type: "timestamp"},
 		{Name: "batch_id", Type: "text"},
+		{Name: "wafers_inspected", Type: "integer"},
 	}}
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/describe" {
@@ -52,4 +53,34 @@ func TestUnitParametersEligibility(t *testing.T) {
 	if !payload.Parameters[0].SupportsRangeChart {
 		t.Fatalf("expected range chart eligibility")
 	}
+	if payload.Parameters[0].SupportsAttributeChart {
+		t.Fatalf("expected float column to be ineligible for attribute charts")
+	}
+	if payload.Parameters[0].SupportsTextMatch {
+		t.Fatalf("expected float column to be ineligible for text match")
+	}
+	if len(payload.Parameters[0].SampleSizeCandidateColumns) != 1 || payload.Parameters[0].SampleSizeCandidateColumns[0] != "wafers_inspected" {
+		t.Fatalf("unexpected sample size candidates %+v", payload.Parameters[0].SampleSizeCandidateColumns)
+	}
+}
+
+func TestSuggestLimitColumns(t *testing.T) {
+	columns := map[string]string{
+		"si_etch_rate":             "double precision",
+		"si_etch_rate_upper_limit": "double precision",
+		"si_etch_rate_lower_limit": "double precision",
+		"trench_width":             "double precision",
+		"trench_width_lower_limit": "text",
+		"particle_count":           "integer",
+	}
+	suggestion := suggestLimitColumns("si_etch_rate", columns)
+	if suggestion == nil || suggestion.UpperColumn != "si_etch_rate_upper_limit" || suggestion.LowerColumn != "si_etch_rate_lower_limit" {
+		t.Fatalf("unexpected suggestion %+v", suggestion)
+	}
+	if suggestLimitColumns("trench_width", columns) != nil {
+		t.Fatalf("expected no suggestion for non-numeric limit column")
+	}
+	if suggestLimitColumns("particle_count", columns) != nil {
+		t.Fatalf("expected no suggestion without limit columns")
+	}
 }
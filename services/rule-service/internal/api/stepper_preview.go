@@ -6,28 +6,38 @@ import (
 	"errors"
 	"net/http"
 	"strings"
+
+	"predixaai-backend/pkg/expression"
+	"predixaai-backend/services/rule-service/internal/rules"
 )
 
 type schedulerPreviewRequest struct {
-	ConnectionRef    string          `json:"connectionRef"`
-	Table            string          `json:"table"`
-	TimestampColumn  string          `json:"timestampColumn"`
-	ValueColumn      string          `json:"valueColumn"`
-	RuleType         string          `json:"ruleType"`
-	Config           json.RawMessage `json:"config"`
-	BaselineSelector *selectorSpec   `json:"baselineSelector,omitempty"`
-	EvalSelector     *selectorSpec   `json:"evalSelector,omitempty"`
-	Subgrouping      *subgroupSpec   `json:"subgrouping,omitempty"`
+	ConnectionRef    string           `json:"connectionRef"`
+	Table            string           `json:"table"`
+	TimestampColumn  string           `json:"timestampColumn"`
+	OrderingColumn   string           `json:"orderingColumn,omitempty"`
+	Where            *rules.WhereSpec `json:"where,omitempty"`
+	ValueColumn      string           `json:"valueColumn"`
+	Expression       string           `json:"expression,omitempty"`
+	RuleType         string           `json:"ruleType"`
+	Config           json.RawMessage  `json:"config"`
+	BaselineSelector *selectorSpec    `json:"baselineSelector,omitempty"`
+	EvalSelector     *selectorSpec    `json:"evalSelector,omitempty"`
+	Subgrouping      *subgroupSpec    `json:"subgrouping,omitempty"`
 }
 
 type schedulerBaselineRequest struct {
-	ConnectionRef    string        `json:"connectionRef"`
-	Table            string        `json:"table"`
-	TimestampColumn  string        `json:"timestampColumn"`
-	ValueColumn      string        `json:"valueColumn"`
-	RuleType         string        `json:"ruleType"`
-	BaselineSelector selectorSpec  `json:"baselineSelector"`
-	Subgrouping      *subgroupSpec `json:"subgrouping,omitempty"`
+	ConnectionRef    string           `json:"connectionRef"`
+	Table            string           `json:"table"`
+	TimestampColumn  string           `json:"timestampColumn"`
+	OrderingColumn   string           `json:"orderingColumn,omitempty"`
+	Where            *rules.WhereSpec `json:"where,omitempty"`
+	ValueColumn      string           `json:"valueColumn"`
+	Expression       string           `json:"expression,omitempty"`
+	RuleType         string           `json:"ruleType"`
+	Config           json.RawMessage  `json:"config,omitempty"`
+	BaselineSelector selectorSpec     `json:"baselineSelector"`
+	Subgrouping      *subgroupSpec    `json:"subgrouping,omitempty"`
 }
 
 func (h *Handler) handleRuleBaselineCheck(w http.ResponseWriter, r *http.Request) {
@@ -48,15 +58,7 @@ func (h *Handler) handleRuleBaselineCheck(w http.ResponseWriter, r *http.Request
 	}
 	paramInfo, err := h.resolveParameter(ctx, req.UnitID, req.ParameterID)
 	if err != nil {
-		if errors.Is(err, errInvalidParameter) {
-			writeStepperValidationError(w, "PARAMETER_NOT_FOUND", "parameterId invalid", []FieldError{{Field: "parameterId", Problem: "not_found"}})
-			return
-		}
-		if errors.Is(err, errInvalidTimestamp) {
-			writeStepperValidationError(w, "TIMESTAMP_COLUMN_INVALID", "timestampColumn invalid", []FieldError{{Field: "timestampColumn", Problem: "invalid"}})
-			return
-		}
-		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "parameter resolution failed"})
+		writeParameterResolutionError(w, err)
 		return
 	}
 	client := schedulerClient{BaseURL: h.SchedulerURL, Client: defaultHTTPClient(h.Timeout)}
@@ -65,8 +67,12 @@ func (h *Handler) handleRuleBaselineCheck(w http.ResponseWriter, r *http.Request
 		ConnectionRef:    req.ConnectionRef,
 		Table:            paramInfo.Table,
 		TimestampColumn:  paramInfo.TimestampColumn,
+		OrderingColumn:   paramInfo.OrderingColumn,
+		Where:            paramInfo.Where,
 		ValueColumn:      paramInfo.ValueColumn,
+		Expression:       paramInfo.Expression,
 		RuleType:         req.RuleType,
+		Config:           req.Config,
 		BaselineSelector: req.BaselineSelector,
 		Subgrouping:      req.Subgrouping,
 	}
@@ -95,15 +101,7 @@ func (h *Handler) handleRulePreview(w http.ResponseWriter, r *http.Request) {
 	}
 	paramInfo, err := h.resolveParameter(ctx, req.UnitID, req.ParameterID)
 	if err != nil {
-		if errors.Is(err, errInvalidParameter) {
-			writeStepperValidationError(w, "PARAMETER_NOT_FOUND", "parameterId invalid", []FieldError{{Field: "parameterId", Problem: "not_found"}})
-			return
-		}
-		if errors.Is(err, errInvalidTimestamp) {
-			writeStepperValidationError(w, "TIMESTAMP_COLUMN_INVALID", "timestampColumn invalid", []FieldError{{Field: "timestampColumn", Problem: "invalid"}})
-			return
-		}
-		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "parameter resolution failed"})
+		writeParameterResolutionError(w, err)
 		return
 	}
 	client := schedulerClient{BaseURL: h.SchedulerURL, Client: defaultHTTPClient(h.Timeout)}
@@ -112,7 +110,10 @@ func (h *Handler) handleRulePreview(w http.ResponseWriter, r *http.Request) {
 		ConnectionRef:    req.ConnectionRef,
 		Table:            paramInfo.Table,
 		TimestampColumn:  paramInfo.TimestampColumn,
+		OrderingColumn:   paramInfo.OrderingColumn,
+		Where:            paramInfo.Where,
 		ValueColumn:      paramInfo.ValueColumn,
+		Expression:       paramInfo.Expression,
 		RuleType:         req.RuleType,
 		Config:           req.Config,
 		BaselineSelector: req.BaselineSelector,
@@ -130,6 +131,9 @@ type parameterInfo struct {
 	Table           string
 	ValueColumn     string
 	TimestampColumn string
+	OrderingColumn  string
+	Where           *rules.WhereSpec
+	Expression      string
 }
 
 func (h *Handler) resolveParameter(ctx context.Context, unitID, parameterID string) (parameterInfo, error) {
@@ -151,7 +155,8 @@ func (h *Handler) resolveParameter(ctx context.Context, unitID, parameterID stri
 			break
 		}
 	}
-	if !found {
+	derived, isDerived := findDerivedParameter(unit.DerivedParameters, paramCol)
+	if !found && !isDerived {
 		return parameterInfo{}, errInvalidParameter
 	}
 	connector := dbConnectorClient{BaseURL: h.DBConnectorURL, Client: defaultHTTPClient(h.Timeout)}
@@ -167,6 +172,28 @@ func (h *Handler) resolveParameter(ctx context.Context, unitID, parameterID stri
 			timestampCandidates = append(timestampCandidates, col.Name)
 		}
 	}
+	if !found {
+		expr, err := expression.Parse(derived.Expression)
+		if err != nil {
+			return parameterInfo{}, errInvalidParameter
+		}
+		for _, col := range expr.Columns() {
+			if colType, ok := columns[col]; !ok || !isNumericType(colType) {
+				return parameterInfo{}, errInvalidParameter
+			}
+		}
+	}
+	rowFilter, err := decodeRowFilter(unit.RowFilter)
+	if err != nil || !rowFilterMatchesSchema(rowFilter, columns) {
+		return parameterInfo{}, errInvalidRowFilter
+	}
+	orderingColumn := strings.TrimSpace(unit.OrderingColumn)
+	if orderingColumn != "" {
+		colType, ok := columns[orderingColumn]
+		if !ok || !isIntegerType(colType) {
+			return parameterInfo{}, errInvalidOrdering
+		}
+	}
 	unitTimestamp := strings.TrimSpace(unit.TimestampColumn)
 	timestampColumn := ""
 	if unitTimestamp != "" {
@@ -175,14 +202,31 @@ func (h *Handler) resolveParameter(ctx context.Context, unitID, parameterID stri
 		} else {
 			return parameterInfo{}, errInvalidTimestamp
 		}
-	} else {
+	} else if orderingColumn == "" {
 		timestampColumn = pickTimestampColumn(timestampCandidates)
 	}
-	if strings.TrimSpace(timestampColumn) == "" {
+	if strings.TrimSpace(timestampColumn) == "" && orderingColumn == "" {
 		return parameterInfo{}, errInvalidTimestamp
 	}
-	return parameterInfo{Table: unit.SelectedTable, ValueColumn: paramCol, TimestampColumn: timestampColumn}, nil
+	return parameterInfo{Table: unit.SelectedTable, ValueColumn: paramCol, TimestampColumn: timestampColumn, OrderingColumn: orderingColumn, Where: rowFilter, Expression: derived.Expression}, nil
+}
+
+func writeParameterResolutionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidParameter):
+		writeStepperValidationError(w, "PARAMETER_NOT_FOUND", "parameterId invalid", []FieldError{{Field: "parameterId", Problem: "not_found"}})
+	case errors.Is(err, errInvalidTimestamp):
+		writeStepperValidationError(w, "TIMESTAMP_COLUMN_INVALID", "timestampColumn invalid", []FieldError{{Field: "timestampColumn", Problem: "invalid"}})
+	case errors.Is(err, errInvalidOrdering):
+		writeStepperValidationError(w, "ORDERING_COLUMN_INVALID", "orderingColumn invalid", []FieldError{{Field: "orderingColumn", Problem: "invalid"}})
+	case errors.Is(err, errInvalidRowFilter):
+		writeStepperValidationError(w, "ROW_FILTER_INVALID", "machine unit rowFilter invalid", []FieldError{{Field: "rowFilter", Problem: "invalid"}})
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": "parameter resolution failed"})
+	}
 }
 
 var errInvalidParameter = errors.New("invalid parameter")
 var errInvalidTimestamp = errors.New("invalid timestamp column")
+var errInvalidOrdering = errors.New("invalid ordering column")
+var errInvalidRowFilter = errors.New("invalid row filter")
@@ -7,6 +7,8 @@ func (h *Handler) RegisterStepperRoutes(r chi.Router) {
 		r.Get("/rules/catalog", h.handleRuleCatalog)
 		r.Post("/rules/baseline/check", h.handleRuleBaselineCheck)
 		r.Post("/rules/preview", h.handleRulePreview)
+		r.Post("/rules/capability", h.handleRuleCapability)
+		r.Post("/rules/backtest", h.handleRuleBacktest)
 		r.Route("/rules", func(r chi.Router) {
 			r.Post("/", h.handleStepperRuleCreate)
 			r.Put("/{ruleId}", h.handleStepperRuleUpdate)
@@ -14,8 +16,16 @@ func (h *Handler) RegisterStepperRoutes(r chi.Router) {
 			r.Delete("/{ruleId}", h.handleStepperRuleDelete)
 			r.Post("/{ruleId}/enable", h.handleStepperRuleEnable)
 			r.Post("/{ruleId}/disable", h.handleStepperRuleDisable)
+			r.Post("/{ruleId}/shadow", h.handleStepperRuleShadow)
+			r.Get("/{ruleId}/alerts", h.handleStepperRuleAlerts)
+			r.Get("/{ruleId}/shadow-alerts", h.handleStepperRuleShadowAlerts)
+			r.Get("/{ruleId}/runs", h.handleStepperRuleRuns)
+			r.Get("/{ruleId}/baselines", h.handleStepperBaselines)
+			r.Post("/{ruleId}/baselines/recompute", h.handleStepperBaselineRecompute)
+			r.Post("/{ruleId}/baselines/{baselineId}/approve", h.handleStepperBaselineApprove)
 		})
 		r.Get("/machine-units/{unitId}/parameters", h.handleUnitParameters)
 		r.Get("/machine-units/{unitId}/rule-health", h.handleRuleHealth)
+		r.Get("/machine-units/{unitId}/shadow-comparison", h.handleShadowComparison)
 	})
 }
@@ -18,7 +18,7 @@ func (h *Handler) handleStepperRuleCreate(w http.ResponseWriter, r *http.Request
 		writeJSON(w, http.StatusBadRequest, map[string]any{"ok": false, "message": err.Error()})
 		return
 	}
-	if fieldErrors := validateStepperRuleRequest(req); len(fieldErrors) > 0 {
+	if fieldErrors := append(validateStepperRuleRequest(req), validateBaselineStats(req.BaselineStats)...); len(fieldErrors) > 0 {
 		writeStepperValidationError(w, "INVALID_REQUEST", "invalid rule request", fieldErrors)
 		return
 	}
@@ -33,11 +33,40 @@ func (h *Handler) handleStepperRuleCreate(w http.ResponseWriter, r *http.Request
 		writeStepperValidationError(w, "PARAMETER_NOT_FOUND", "parameterId not found", []FieldError{{Field: "parameterId", Problem: "not_found"}})
 		return
 	}
-	rec, err := h.Repo.CreateStepperRule(ctx, toStepperRecord(req, true))
+	rule := toStepperRecord(req, true)
+	var baseline *storage.Baseline
+	if req.BaselineStats != nil {
+		// Freeze the statistics recomputed here, not the ones the client sent;
+		// they must still describe the same data the client reviewed.
+		paramInfo, err := h.resolveParameter(ctx, req.UnitID, req.ParameterID)
+		if err != nil {
+			writeParameterResolutionError(w, err)
+			return
+		}
+		check, err := h.checkStepperBaseline(ctx, unit.ConnectionRef, paramInfo, rule.RuleType, rule.Config)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, map[string]any{"ok": false, "message": "baseline check failed"})
+			return
+		}
+		if check.Stats == nil || degenerateBaselineStats(*check.Stats) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"ok": false, "message": "baseline not available", "status": check.Status, "available": check.Available, "required": check.Required})
+			return
+		}
+		if check.Stats.DataHash != req.BaselineStats.DataHash {
+			writeJSON(w, http.StatusConflict, map[string]any{"ok": false, "message": "baseline data changed since the check", "stats": check.Stats})
+			return
+		}
+		stats := *check.Stats
+		_, stats.ParameterName = parseParameterID(req.ParameterID)
+		rec := toBaselineRecord("", stats, storage.BaselineStatusActive, "baseline_check")
+		baseline = &rec
+	}
+	rec, err := h.Repo.CreateStepperRule(ctx, rule, baseline)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to create rule"})
 		return
 	}
+	_ = h.Bus.Publish("ui_rule.created", map[string]any{"rule_id": rec.ID})
 	writeJSON(w, http.StatusOK, toStepperResponse(rec))
 }
 
@@ -54,7 +83,8 @@ func (h *Handler) handleStepperRuleUpdate(w http.ResponseWriter, r *http.Request
 	}
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
 	defer cancel()
-	if _, err := h.Repo.GetStepperRule(ctx, ruleID); err != nil {
+	existing, err := h.Repo.GetStepperRule(ctx, ruleID)
+	if err != nil {
 		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
 		return
 	}
@@ -69,11 +99,19 @@ func (h *Handler) handleStepperRuleUpdate(w http.ResponseWriter, r *http.Request
 	}
 	rec := toStepperRecord(req, true)
 	rec.ID = ruleID
+	// Fields the PUT omits keep their stored mode.
+	if req.Enabled == nil {
+		rec.Enabled = existing.Enabled
+	}
+	if req.Shadow == nil {
+		rec.Shadow = existing.Shadow
+	}
 	updated, err := h.Repo.UpdateStepperRule(ctx, rec)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to update rule"})
 		return
 	}
+	_ = h.Bus.Publish("ui_rule.updated", map[string]any{"rule_id": ruleID})
 	writeJSON(w, http.StatusOK, toStepperResponse(updated))
 }
 
@@ -85,6 +123,7 @@ func (h *Handler) handleStepperRuleDelete(w http.ResponseWriter, r *http.Request
 		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
 		return
 	}
+	_ = h.Bus.Publish("ui_rule.deleted", map[string]any{"rule_id": ruleID})
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
@@ -92,10 +131,11 @@ func (h *Handler) handleStepperRuleEnable(w http.ResponseWriter, r *http.Request
 	ruleID := chi.URLParam(r, "ruleId")
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
 	defer cancel()
-	if err := h.Repo.SetStepperRuleEnabled(ctx, ruleID, true); err != nil {
+	if err := h.Repo.SetStepperRuleMode(ctx, ruleID, true, false, "DRAFT"); err != nil {
 		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
 		return
 	}
+	_ = h.Bus.Publish("ui_rule.enabled", map[string]any{"rule_id": ruleID})
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
@@ -103,10 +143,11 @@ func (h *Handler) handleStepperRuleDisable(w http.ResponseWriter, r *http.Reques
 	ruleID := chi.URLParam(r, "ruleId")
 	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
 	defer cancel()
-	if err := h.Repo.SetStepperRuleEnabled(ctx, ruleID, false); err != nil {
+	if err := h.Repo.SetStepperRuleMode(ctx, ruleID, false, false, "DISABLED"); err != nil {
 		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
 		return
 	}
+	_ = h.Bus.Publish("ui_rule.disabled", map[string]any{"rule_id": ruleID})
 	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
 }
 
@@ -126,11 +167,56 @@ func (h *Handler) handleStepperRuleList(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, http.StatusOK, map[string]any{"rules": responses})
 }
 
+func (h *Handler) handleStepperRuleAlerts(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetStepperRule(ctx, ruleID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	alerts, err := h.Repo.ListStepperRuleAlerts(ctx, ruleID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch alerts"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"alerts": toStepperAlertResponses(alerts)})
+}
+
+func toStepperAlertResponses(alerts []storage.AlertRecord) []stepperAlertResponse {
+	responses := make([]stepperAlertResponse, 0, len(alerts))
+	for _, alert := range alerts {
+		responses = append(responses, stepperAlertResponse{
+			ID:              alert.ID,
+			RuleID:          alert.UIRuleID,
+			Timestamp:       alert.TSUTC.UTC().Format(time.RFC3339),
+			ParameterName:   alert.ParameterName,
+			ObservedValue:   alert.ObservedValue,
+			LimitExpression: alert.LimitExpr,
+			DetectorType:    alert.DetectorType,
+			Severity:        alert.Severity,
+			Treated:         alert.Treated,
+			State:           alert.State,
+			OpenedAt:        formatOptionalTime(alert.OpenedAt),
+			LastSeenAt:      formatOptionalTime(alert.LastSeenAt),
+			AcknowledgedAt:  formatOptionalTime(alert.AcknowledgedAt),
+			ResolvedAt:      formatOptionalTime(alert.ResolvedAt),
+			Occurrences:     alert.Occurrences,
+			Metadata:        alert.Metadata,
+		})
+	}
+	return responses
+}
+
 func toStepperRecord(req stepperRuleRequest, includeEnabled bool) storage.StepperRule {
 	enabled := true
 	if includeEnabled && req.Enabled != nil {
 		enabled = *req.Enabled
 	}
+	shadow := false
+	if req.Shadow != nil {
+		shadow = *req.Shadow
+	}
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
 		name = req.RuleType
@@ -146,6 +232,7 @@ func toStepperRecord(req stepperRuleRequest, includeEnabled bool) storage.Steppe
 		ParameterID: req.ParameterID,
 		Config:      config,
 		Enabled:     enabled,
+		Shadow:      shadow,
 	}
 }
 
@@ -157,12 +244,22 @@ func toStepperResponse(rec storage.StepperRule) stepperRuleResponse {
 		RuleType:    rec.RuleType,
 		ParameterID: rec.ParameterID,
 		Enabled:     rec.Enabled,
+		Shadow:      rec.Shadow,
 		Config:      rec.Config,
+		Status:      rec.Status,
+		LastError:   rec.LastError,
+		LastValidatedAt: formatOptionalTime(rec.LastValidatedAt),
 		CreatedAt:   rec.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:   rec.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
 
+func formatOptionalTime(ts *time.Time) string {
+	if ts == nil {
+		return ""
+	}
+	return ts.UTC().Format(time.RFC3339)
+}
 
 func parameterInUnit(table string, columns []string, parameterID string) bool {
 	paramTable, paramCol := parseParameterID(parameterID)
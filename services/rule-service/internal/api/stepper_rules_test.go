@@ -44,3 +44,48 @@ func TestStepperRuleCreateValidation(t *testing.T) {
 		t.Fatalf("expected field errors")
 	}
 }
+
+func TestStepperRuleLifecycleStatus(t *testing.T) {
+	fixture := setupMachineUnitFixture(t)
+	defer fixture.cleanup()
+
+	h := &Handler{Repo: fixture.repo, Timeout: 2 * time.Second}
+	r := chi.NewRouter()
+	h.RegisterStepperRoutes(r)
+
+	payload := map[string]any{
+		"unitId":      fixture.unitID,
+		"ruleType":    "SPEC_LIMIT_VIOLATION",
+		"parameterId": buildParameterID("etchers_data", "gas_ar_flow"),
+		"config":      map[string]any{"specLimits": map[string]any{"usl": 10}},
+	}
+	body, _ := json.Marshal(payload)
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	var created stepperRuleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if created.Status != "DRAFT" {
+		t.Fatalf("expected DRAFT status, got %s", created.Status)
+	}
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/rules/"+created.ID+"/disable", nil)
+	r.ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.Code)
+	}
+	rec, err := fixture.repo.GetStepperRule(req.Context(), created.ID)
+	if err != nil {
+		t.Fatalf("get rule failed: %v", err)
+	}
+	if rec.Enabled || rec.Status != "DISABLED" {
+		t.Fatalf("expected disabled rule, got enabled=%v status=%s", rec.Enabled, rec.Status)
+	}
+}
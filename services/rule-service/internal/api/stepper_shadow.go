@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+const (
+	defaultShadowComparisonDays = 7
+	maxShadowComparisonDays     = 90
+)
+
+type alertVolumeResponse struct {
+	Rules        int     `json:"rules,omitempty"`
+	Alerts       int     `json:"alerts"`
+	Occurrences  int     `json:"occurrences"`
+	AlertsPerDay float64 `json:"alertsPerDay"`
+}
+
+type ruleAlertVolumeResponse struct {
+	RuleID      string              `json:"ruleId"`
+	Name        string              `json:"name"`
+	RuleType    string              `json:"ruleType"`
+	ParameterID string              `json:"parameterId"`
+	Mode        string              `json:"mode"`
+	Live        alertVolumeResponse `json:"live"`
+	Shadow      alertVolumeResponse `json:"shadow"`
+}
+
+type parameterAlertVolumeResponse struct {
+	ParameterID string              `json:"parameterId"`
+	Live        alertVolumeResponse `json:"live"`
+	Shadow      alertVolumeResponse `json:"shadow"`
+}
+
+type shadowComparisonResponse struct {
+	UnitID     string                         `json:"unitId"`
+	Days       int                            `json:"days"`
+	Since      string                         `json:"since"`
+	Live       alertVolumeResponse            `json:"live"`
+	Shadow     alertVolumeResponse            `json:"shadow"`
+	Parameters []parameterAlertVolumeResponse `json:"parameters"`
+	Rules      []ruleAlertVolumeResponse      `json:"rules"`
+}
+
+func (h *Handler) handleStepperRuleShadow(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if err := h.Repo.SetStepperRuleMode(ctx, ruleID, true, true, "DRAFT"); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	_ = h.Bus.Publish("ui_rule.shadowed", map[string]any{"rule_id": ruleID})
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *Handler) handleStepperRuleShadowAlerts(w http.ResponseWriter, r *http.Request) {
+	ruleID := chi.URLParam(r, "ruleId")
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetStepperRule(ctx, ruleID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "rule not found"})
+		return
+	}
+	alerts, err := h.Repo.ListStepperRuleShadowAlerts(ctx, ruleID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to fetch shadow alerts"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"alerts": toStepperAlertResponses(alerts)})
+}
+
+func (h *Handler) handleShadowComparison(w http.ResponseWriter, r *http.Request) {
+	unitID := chi.URLParam(r, "unitId")
+	days := defaultShadowComparisonDays
+	if raw := strings.TrimSpace(r.URL.Query().Get("days")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 || parsed > maxShadowComparisonDays {
+			writeStepperValidationError(w, "INVALID_REQUEST", "invalid days", []FieldError{{Field: "days", Problem: "invalid", Hint: "days must be between 1 and 90"}})
+			return
+		}
+		days = parsed
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), h.Timeout)
+	defer cancel()
+	if _, err := h.Repo.GetMachineUnit(ctx, unitID); err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]any{"ok": false, "message": "machine unit not found"})
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+	volumes, err := h.Repo.ListRuleAlertVolumes(ctx, unitID, since)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"ok": false, "message": "failed to compare alert volume"})
+		return
+	}
+	writeJSON(w, http.StatusOK, buildShadowComparison(unitID, days, since, volumes))
+}
+
+func buildShadowComparison(unitID string, days int, since time.Time, volumes []storage.RuleAlertVolume) shadowComparisonResponse {
+	resp := shadowComparisonResponse{
+		UnitID:     unitID,
+		Days:       days,
+		Since:      since.Format(time.RFC3339),
+		Parameters: []parameterAlertVolumeResponse{},
+		Rules:      make([]ruleAlertVolumeResponse, 0, len(volumes)),
+	}
+	paramIndex := map[string]int{}
+	for _, volume := range volumes {
+		mode := ruleMode(volume.Enabled, volume.Shadow)
+		live := alertVolumeResponse{Alerts: volume.LiveAlerts, Occurrences: volume.LiveOccurrences, AlertsPerDay: float64(volume.LiveAlerts) / float64(days)}
+		shadow := alertVolumeResponse{Alerts: volume.ShadowAlerts, Occurrences: volume.ShadowOccurrences, AlertsPerDay: float64(volume.ShadowAlerts) / float64(days)}
+		resp.Rules = append(resp.Rules, ruleAlertVolumeResponse{
+			RuleID:      volume.RuleID,
+			Name:        volume.Name,
+			RuleType:    volume.RuleType,
+			ParameterID: volume.ParameterID,
+			Mode:        mode,
+			Live:        live,
+			Shadow:      shadow,
+		})
+		idx, ok := paramIndex[volume.ParameterID]
+		if !ok {
+			idx = len(resp.Parameters)
+			paramIndex[volume.ParameterID] = idx
+			resp.Parameters = append(resp.Parameters, parameterAlertVolumeResponse{ParameterID: volume.ParameterID})
+		}
+		param := &resp.Parameters[idx]
+		addAlertVolume(&param.Live, live, mode == "live")
+		addAlertVolume(&param.Shadow, shadow, mode == "shadow")
+		addAlertVolume(&resp.Live, live, mode == "live")
+		addAlertVolume(&resp.Shadow, shadow, mode == "shadow")
+	}
+	for i := range resp.Parameters {
+		resp.Parameters[i].Live.AlertsPerDay = float64(resp.Parameters[i].Live.Alerts) / float64(days)
+		resp.Parameters[i].Shadow.AlertsPerDay = float64(resp.Parameters[i].Shadow.Alerts) / float64(days)
+	}
+	resp.Live.AlertsPerDay = float64(resp.Live.Alerts) / float64(days)
+	resp.Shadow.AlertsPerDay = float64(resp.Shadow.Alerts) / float64(days)
+	return resp
+}
+
+func addAlertVolume(total *alertVolumeResponse, volume alertVolumeResponse, active bool) {
+	if active {
+		total.Rules++
+	}
+	total.Alerts += volume.Alerts
+	total.Occurrences += volume.Occurrences
+}
+
+func ruleMode(enabled, shadow bool) string {
+	switch {
+	case !enabled:
+		return "disabled"
+	case shadow:
+		return "shadow"
+	default:
+		return "live"
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"predixaai-backend/services/rule-service/internal/storage"
+)
+
+func TestShadowComparisonInvalidDays(t *testing.T) {
+	h := &Handler{Timeout: time.Second}
+	r := chi.NewRouter()
+	h.RegisterStepperRoutes(r)
+
+	for _, days := range []string{"0", "abc", "365"} {
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/api/machine-units/unit-1/shadow-comparison?days="+days, nil))
+		if resp.Code != http.StatusBadRequest {
+			t.Fatalf("days=%s: expected 400, got %d", days, resp.Code)
+		}
+	}
+}
+
+func TestBuildShadowComparison(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resp := buildShadowComparison("unit-1", 7, since, []storage.RuleAlertVolume{
+		{RuleID: "r1", ParameterID: "p1", Enabled: true, LiveAlerts: 14, LiveOccurrences: 30},
+		{RuleID: "r2", ParameterID: "p1", Enabled: true, Shadow: true, ShadowAlerts: 7, ShadowOccurrences: 9},
+		{RuleID: "r3", ParameterID: "p2", Enabled: false, LiveAlerts: 3},
+	})
+	if resp.Live.Rules != 1 || resp.Live.Alerts != 17 || resp.Shadow.Rules != 1 || resp.Shadow.Alerts != 7 {
+		t.Fatalf("unexpected totals %+v / %+v", resp.Live, resp.Shadow)
+	}
+	if resp.Shadow.AlertsPerDay != 1 || resp.Live.AlertsPerDay != 17.0/7 {
+		t.Fatalf("unexpected rates %v / %v", resp.Live.AlertsPerDay, resp.Shadow.AlertsPerDay)
+	}
+	if len(resp.Parameters) != 2 || resp.Parameters[0].Live.Alerts != 14 || resp.Parameters[0].Shadow.Alerts != 7 {
+		t.Fatalf("unexpected per-parameter volume %+v", resp.Parameters)
+	}
+	if resp.Rules[1].Mode != "shadow" || resp.Rules[2].Mode != "disabled" {
+		t.Fatalf("unexpected modes %+v", resp.Rules)
+	}
+}
@@ -71,6 +71,8 @@ type selectorSpec struct {
 	Value int    `json:"value,omitempty"`
 	Start string `json:"start,omitempty"`
 	End   string `json:"end,omitempty"`
+	From  *int64 `json:"from,omitempty"`
+	To    *int64 `json:"to,omitempty"`
 }
 
 type subgroupSpec struct {
@@ -85,14 +87,25 @@ type parameterResponse struct {
 	Table                     string   `json:"table"`
 	ValueColumn               string   `json:"valueColumn"`
 	DataType                  string   `json:"dataType"`
+	Expression                string   `json:"expression,omitempty"`
 	TimestampColumn           string   `json:"timestampColumn"`
+	OrderingColumn            string   `json:"orderingColumn,omitempty"`
 	SubgroupCandidateColumns  []string `json:"subgroupCandidateColumns"`
 	SupportsTrend             bool     `json:"supportsTrend"`
 	SupportsShewhart          bool     `json:"supportsShewhart"`
 	SupportsRangeChart        bool     `json:"supportsRangeChart"`
+	SupportsAttributeChart    bool     `json:"supportsAttributeChart"`
+	SupportsTextMatch         bool     `json:"supportsTextMatch"`
+	SampleSizeCandidateColumns []string `json:"sampleSizeCandidateColumns,omitempty"`
+	SuggestedLimitColumns     *limitColumnSuggestion `json:"suggestedLimitColumns,omitempty"`
 	Notes                     []string `json:"notes"`
 }
 
+type limitColumnSuggestion struct {
+	UpperColumn string `json:"upperColumn,omitempty"`
+	LowerColumn string `json:"lowerColumn,omitempty"`
+}
+
 type unitParametersResponse struct {
 	UnitID     string              `json:"unitId"`
 	Parameters []parameterResponse `json:"parameters"`
@@ -103,6 +116,7 @@ type baselineCheckRequest struct {
 	ParameterID     string      `json:"parameterId"`
 	RuleType        string      `json:"ruleType"`
 	ConnectionRef   string      `json:"connectionRef"`
+	Config          json.RawMessage `json:"config,omitempty"`
 	BaselineSelector selectorSpec `json:"baselineSelector"`
 	Subgrouping     *subgroupSpec `json:"subgrouping"`
 }
@@ -112,12 +126,14 @@ type baselineCheckResponse struct {
 	Available   map[string]int   `json:"available"`
 	Required    map[string]int   `json:"required"`
 	Continuity continuitySummary `json:"continuity"`
+	Stats       *baselineStats   `json:"stats,omitempty"`
 	Messages    []string         `json:"messages"`
 }
 
 type continuitySummary struct {
 	GapsDetected       bool    `json:"gapsDetected"`
 	LargestGapSeconds  float64 `json:"largestGapSeconds"`
+	LargestGapRuns     int64   `json:"largestGapRuns,omitempty"`
 }
 
 type previewRequest struct {
@@ -146,7 +162,9 @@ type stepperRuleRequest struct {
 	RuleType    string          `json:"ruleType"`
 	ParameterID string          `json:"parameterId"`
 	Enabled     *bool           `json:"enabled"`
+	Shadow      *bool           `json:"shadow"`
 	Config      json.RawMessage `json:"config"`
+	BaselineStats *baselineStats `json:"baselineStats,omitempty"`
 }
 
 type stepperRuleResponse struct {
@@ -156,11 +174,47 @@ type stepperRuleResponse struct {
 	RuleType   string          `json:"ruleType"`
 	ParameterID string         `json:"parameterId"`
 	Enabled    bool            `json:"enabled"`
+	Shadow     bool            `json:"shadow"`
 	Config     json.RawMessage `json:"config"`
+	Status     string          `json:"status"`
+	LastError  json.RawMessage `json:"lastError,omitempty"`
+	LastValidatedAt string     `json:"lastValidatedAt,omitempty"`
 	CreatedAt  string          `json:"createdAt"`
 	UpdatedAt  string          `json:"updatedAt"`
 }
 
+type stepperAlertResponse struct {
+	ID              int64           `json:"id"`
+	RuleID          string          `json:"ruleId"`
+	Timestamp       string          `json:"timestamp"`
+	ParameterName   string          `json:"parameterName"`
+	ObservedValue   string          `json:"observedValue"`
+	LimitExpression string          `json:"limitExpression"`
+	DetectorType    string          `json:"detectorType"`
+	Severity        string          `json:"severity"`
+	Treated         bool            `json:"treated"`
+	State           string          `json:"state"`
+	OpenedAt        string          `json:"openedAt,omitempty"`
+	LastSeenAt      string          `json:"lastSeenAt,omitempty"`
+	AcknowledgedAt  string          `json:"acknowledgedAt,omitempty"`
+	ResolvedAt      string          `json:"resolvedAt,omitempty"`
+	Occurrences     int             `json:"occurrences"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+}
+
+type ruleRunResponse struct {
+	ID            int64  `json:"id"`
+	RuleID        string `json:"ruleId"`
+	ParameterName string `json:"parameterName"`
+	DetectorType  string `json:"detectorType"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	StartedAt     string `json:"startedAt"`
+	FinishedAt    string `json:"finishedAt"`
+	DurationMS    int64  `json:"durationMs"`
+	SampleCount   int    `json:"sampleCount"`
+}
+
 type ruleHealthResponse struct {
 	UnitID        string              `json:"unitId"`
 	WarningsCount int                 `json:"warningsCount"`
@@ -39,7 +39,7 @@ func validateBaselineCheck(req baselineCheckRequest) []FieldError {
 	if strings.TrimSpace(req.BaselineSelector.Kind) == "" {
 		fields = append(fields, FieldError{Field: "baselineSelector.kind", Problem: "missing"})
 	}
-	if req.BaselineSelector.Kind != "" && req.BaselineSelector.Kind != "lastN" && req.BaselineSelector.Kind != "timeRange" {
+	if req.BaselineSelector.Kind != "" && !isSelectorKind(req.BaselineSelector.Kind) {
 		fields = append(fields, FieldError{Field: "baselineSelector.kind", Problem: "invalid"})
 	}
 	return fields
@@ -62,15 +62,19 @@ func validatePreviewRequest(req previewRequest) []FieldError {
 	if len(req.Config) == 0 {
 		fields = append(fields, FieldError{Field: "config", Problem: "missing"})
 	}
-	if req.BaselineSelector != nil && req.BaselineSelector.Kind != "" && req.BaselineSelector.Kind != "lastN" && req.BaselineSelector.Kind != "timeRange" {
+	if req.BaselineSelector != nil && req.BaselineSelector.Kind != "" && !isSelectorKind(req.BaselineSelector.Kind) {
 		fields = append(fields, FieldError{Field: "baselineSelector.kind", Problem: "invalid"})
 	}
-	if req.EvalSelector != nil && req.EvalSelector.Kind != "" && req.EvalSelector.Kind != "lastN" && req.EvalSelector.Kind != "timeRange" {
+	if req.EvalSelector != nil && req.EvalSelector.Kind != "" && !isSelectorKind(req.EvalSelector.Kind) {
 		fields = append(fields, FieldError{Field: "evalSelector.kind", Problem: "invalid"})
 	}
 	return fields
 }
 
+func isSelectorKind(kind string) bool {
+	return kind == "lastN" || kind == "timeRange" || kind == "runRange"
+}
+
 func writeStepperValidationError(w http.ResponseWriter, code, message string, fields []FieldError) {
 	writeJSON(w, http.StatusBadRequest, validationErrorResponse{Code: code, Message: message, FieldErrors: fields})
 }
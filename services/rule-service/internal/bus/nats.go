@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/nats-io/nats.go"
+	"predixaai-backend/pkg/events"
 )
 
 type Publisher struct {
@@ -26,9 +27,29 @@ func (p *Publisher) Close() {
 }
 
 func (p *Publisher) Publish(subject string, payload any) error {
+	if p == nil || p.Conn == nil {
+		return nil
+	}
 	data, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 	return p.Conn.Publish(subject, data)
 }
+
+// AlertMessage is the alert event published on alert.* subjects. The
+// scheduler publishes alert.created and alert.resolved; rule-service publishes
+// manual acknowledge and resolve transitions. Both use the pkg/events schema.
+type AlertMessage = events.AlertMessage
+
+const SchemaVersion = events.SchemaVersion
+
+func (p *Publisher) SubscribeAlerts(handler func(AlertMessage)) (*nats.Subscription, error) {
+	return p.Conn.Subscribe("alert.*", func(msg *nats.Msg) {
+		var evt AlertMessage
+		if err := json.Unmarshal(msg.Data, &evt); err != nil {
+			return
+		}
+		handler(evt)
+	})
+}
@@ -8,6 +8,9 @@ type RuleSpec struct {
 	Parameters          []ParameterSpec `json:"parameters"`
 	PollIntervalSeconds int             `json:"pollIntervalSeconds"`
 	CooldownSeconds     *int            `json:"cooldownSeconds"`
+	AutoResolveAfter    *int            `json:"autoResolveAfter,omitempty"`
+	Composite           *CompositeSpec  `json:"composite,omitempty"`
+	FreezeBaseline      bool            `json:"freezeBaseline,omitempty"`
 	Enabled             bool            `json:"enabled"`
 
 	// Legacy fields (threshold rules)
@@ -20,6 +23,8 @@ type RuleSpec struct {
 type SourceSpec struct {
 	Table           string     `json:"table"`
 	TimestampColumn string     `json:"timestampColumn"`
+	OrderingColumn  string     `json:"orderingColumn,omitempty"`
+	KeyColumn       string     `json:"keyColumn,omitempty"`
 	Where           *WhereSpec `json:"where"`
 
 	// Legacy field
@@ -29,19 +34,31 @@ type SourceSpec struct {
 type ParameterSpec struct {
 	ParameterName string       `json:"parameterName"`
 	ValueColumn   string       `json:"valueColumn"`
+	Expression    string       `json:"expression,omitempty"`
 	Detector      DetectorSpec `json:"detector"`
 }
 
 type DetectorSpec struct {
-	Type        string           `json:"type"`
-	Threshold   *ThresholdSpec   `json:"threshold,omitempty"`
-	RobustZ     *RobustZSpec     `json:"robustZ,omitempty"`
-	MissingData *MissingDataSpec `json:"missingData,omitempty"`
-	SpecLimit   *SpecLimitSpec   `json:"specLimit,omitempty"`
-	Shewhart    *ShewhartSpec    `json:"shewhart,omitempty"`
-	RangeChart  *RangeChartSpec  `json:"rangeChart,omitempty"`
-	Trend       *TrendSpec       `json:"trend,omitempty"`
-	TPA         *TPASpec         `json:"tpa,omitempty"`
+	Type         string            `json:"type"`
+	Threshold    *ThresholdSpec    `json:"threshold,omitempty"`
+	RobustZ      *RobustZSpec      `json:"robustZ,omitempty"`
+	MissingData  *MissingDataSpec  `json:"missingData,omitempty"`
+	SpecLimit    *SpecLimitSpec    `json:"specLimit,omitempty"`
+	Shewhart     *ShewhartSpec     `json:"shewhart,omitempty"`
+	RangeChart   *RangeChartSpec   `json:"rangeChart,omitempty"`
+	Trend        *TrendSpec        `json:"trend,omitempty"`
+	TPA          *TPASpec          `json:"tpa,omitempty"`
+	RunRules     *RunRulesSpec     `json:"runRules,omitempty"`
+	EWMA         *EWMASpec         `json:"ewma,omitempty"`
+	CUSUM        *CUSUMSpec        `json:"cusum,omitempty"`
+	Attribute    *AttributeSpec    `json:"attribute,omitempty"`
+	XbarChart    *XbarChartSpec    `json:"xbarChart,omitempty"`
+	IMR          *IMRSpec          `json:"imr,omitempty"`
+	Capability   *CapabilitySpec   `json:"capability,omitempty"`
+	Flatline     *FlatlineSpec     `json:"flatline,omitempty"`
+	StepChange   *StepChangeSpec   `json:"stepChange,omitempty"`
+	RateOfChange *RateOfChangeSpec `json:"rateOfChange,omitempty"`
+	TextMatch    *TextMatchSpec    `json:"textMatch,omitempty"`
 }
 
 type ThresholdSpec struct {
@@ -57,10 +74,12 @@ type RobustZSpec struct {
 	ZWarn                 float64 `json:"zWarn"`
 	ZCrit                 float64 `json:"zCrit"`
 	MinSamples            int     `json:"minSamples"`
+	BaselineRuns          int     `json:"baselineRuns,omitempty"`
 }
 
 type MissingDataSpec struct {
-	MaxGapSeconds int `json:"maxGapSeconds"`
+	MaxGapSeconds int  `json:"maxGapSeconds"`
+	MaxGapRuns    *int `json:"maxGapRuns,omitempty"`
 }
 
 type SpecLimitSpec struct {
@@ -71,13 +90,17 @@ type SpecLimitSpec struct {
 }
 
 type SpecLimitBounds struct {
-	USL *float64 `json:"usl,omitempty"`
-	LSL *float64 `json:"lsl,omitempty"`
+	USL       *float64 `json:"usl,omitempty"`
+	LSL       *float64 `json:"lsl,omitempty"`
+	USLColumn string   `json:"uslColumn,omitempty"`
+	LSLColumn string   `json:"lslColumn,omitempty"`
 }
 
 type ControlLimitBounds struct {
-	UCL *float64 `json:"ucl,omitempty"`
-	LCL *float64 `json:"lcl,omitempty"`
+	UCL       *float64 `json:"ucl,omitempty"`
+	LCL       *float64 `json:"lcl,omitempty"`
+	UCLColumn string   `json:"uclColumn,omitempty"`
+	LCLColumn string   `json:"lclColumn,omitempty"`
 }
 
 type ShewhartSpec struct {
@@ -87,9 +110,58 @@ type ShewhartSpec struct {
 	PopulationSigma bool        `json:"populationSigma"`
 }
 
+type RunRulesSpec struct {
+	Baseline        BaselineSpec `json:"baseline"`
+	Rules           []int        `json:"rules,omitempty"`
+	Mu              *float64     `json:"mu,omitempty"`
+	Sigma           *float64     `json:"sigma,omitempty"`
+	MinBaselineN    int          `json:"minBaselineN"`
+	PopulationSigma bool         `json:"populationSigma"`
+	SameSideRun     int          `json:"sameSideRun,omitempty"`
+	EvalWindow      int          `json:"evalWindow,omitempty"`
+}
+
+type EWMASpec struct {
+	Baseline        BaselineSpec `json:"baseline"`
+	Lambda          float64      `json:"lambda"`
+	L               float64      `json:"l"`
+	Mu              *float64     `json:"mu,omitempty"`
+	Sigma           *float64     `json:"sigma,omitempty"`
+	MinBaselineN    int          `json:"minBaselineN"`
+	PopulationSigma bool         `json:"populationSigma"`
+	EvalWindow      int          `json:"evalWindow,omitempty"`
+}
+
+type CUSUMSpec struct {
+	Baseline        BaselineSpec `json:"baseline"`
+	K               float64      `json:"k"`
+	H               float64      `json:"h"`
+	Target          *float64     `json:"target,omitempty"`
+	Sigma           *float64     `json:"sigma,omitempty"`
+	MinBaselineN    int          `json:"minBaselineN"`
+	PopulationSigma bool         `json:"populationSigma"`
+	EvalWindow      int          `json:"evalWindow,omitempty"`
+}
+
+type AttributeSpec struct {
+	Chart            string       `json:"chart"`
+	Baseline         BaselineSpec `json:"baseline"`
+	SampleSizeColumn string       `json:"sampleSizeColumn,omitempty"`
+	SampleSize       int          `json:"sampleSize,omitempty"`
+	SigmaMultiplier  float64      `json:"sigmaMultiplier"`
+	MinBaselineN     int          `json:"minBaselineN"`
+	EvalWindow       int          `json:"evalWindow,omitempty"`
+}
+
 type BaselineSpec struct {
 	LastN     *int           `json:"lastN,omitempty"`
 	TimeRange *TimeRangeSpec `json:"timeRange,omitempty"`
+	RunRange  *RunRangeSpec  `json:"runRange,omitempty"`
+}
+
+type RunRangeSpec struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
 }
 
 type TimeRangeSpec struct {
@@ -104,6 +176,75 @@ type RangeChartSpec struct {
 	MinBaselineSubgroups int            `json:"minBaselineSubgroups"`
 }
 
+type XbarChartSpec struct {
+	SubgroupSize         int             `json:"subgroupSize"`
+	Subgrouping          SubgroupingSpec `json:"subgrouping"`
+	Baseline             BaselineSpec    `json:"baseline"`
+	MinBaselineSubgroups int             `json:"minBaselineSubgroups"`
+	EvalWindow           int             `json:"evalWindow,omitempty"`
+}
+
+type IMRSpec struct {
+	Baseline     BaselineSpec `json:"baseline"`
+	MinBaselineN int          `json:"minBaselineN"`
+	EvalWindow   int          `json:"evalWindow,omitempty"`
+}
+
+type CapabilitySpec struct {
+	SpecLimits      *SpecLimitBounds `json:"specLimits,omitempty"`
+	MinCpk          float64          `json:"minCpk"`
+	Window          int              `json:"window"`
+	ConfidenceLevel float64          `json:"confidenceLevel,omitempty"`
+}
+
+type FlatlineSpec struct {
+	Epsilon            float64 `json:"epsilon"`
+	MinSamples         int     `json:"minSamples,omitempty"`
+	MinDurationSeconds int     `json:"minDurationSeconds,omitempty"`
+	EvalWindow         int     `json:"evalWindow,omitempty"`
+}
+
+type StepChangeSpec struct {
+	WindowSize      int      `json:"windowSize"`
+	SigmaMultiplier float64  `json:"sigmaMultiplier,omitempty"`
+	MinShift        *float64 `json:"minShift,omitempty"`
+}
+
+type RateOfChangeSpec struct {
+	Basis           string   `json:"basis,omitempty"`
+	MinRate         *float64 `json:"minRate,omitempty"`
+	MaxRate         *float64 `json:"maxRate,omitempty"`
+	SmoothingWindow int      `json:"smoothingWindow,omitempty"`
+}
+
+type TextMatchSpec struct {
+	Patterns         []TextPattern `json:"patterns,omitempty"`
+	AnyValue         bool          `json:"anyValue,omitempty"`
+	AnyValueSeverity string        `json:"anyValueSeverity,omitempty"`
+	EvalWindow       int           `json:"evalWindow,omitempty"`
+}
+
+type TextPattern struct {
+	Pattern       string `json:"pattern"`
+	Kind          string `json:"kind,omitempty"`
+	Severity      string `json:"severity,omitempty"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+}
+
+type CompositeSpec struct {
+	Name      string             `json:"name,omitempty"`
+	Severity  string             `json:"severity,omitempty"`
+	Condition CompositeCondition `json:"condition"`
+}
+
+type CompositeCondition struct {
+	Op         string               `json:"op,omitempty"`
+	Count      int                  `json:"count,omitempty"`
+	Conditions []CompositeCondition `json:"conditions,omitempty"`
+	Parameter  string               `json:"parameter,omitempty"`
+	Detector   string               `json:"detector,omitempty"`
+}
+
 type SubgroupingSpec struct {
 	Mode   string `json:"mode"`
 	Column string `json:"column,omitempty"`
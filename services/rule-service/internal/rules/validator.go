@@ -3,6 +3,9 @@ package rules
 import (
 	"fmt"
 	"regexp"
+	"strings"
+
+	"predixaai-backend/pkg/expression"
 )
 
 var identRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
@@ -12,12 +15,21 @@ func ValidateRuleSpec(spec RuleSpec, minPoll, maxPoll int) *ParseError {
 	if spec.Source.Table == "" || !identRegex.MatchString(spec.Source.Table) {
 		details = append(details, ErrorDetail{Field: "source.table", Problem: "invalid", Hint: "Use alphanumeric identifiers"})
 	}
-	if spec.Source.TimestampColumn == "" || !identRegex.MatchString(spec.Source.TimestampColumn) {
+	if (spec.Source.TimestampColumn != "" || spec.Source.OrderingColumn == "") && !identRegex.MatchString(spec.Source.TimestampColumn) {
 		details = append(details, ErrorDetail{Field: "source.timestampColumn", Problem: "invalid", Hint: "Use alphanumeric identifiers"})
 	}
+	if spec.Source.OrderingColumn != "" && !identRegex.MatchString(spec.Source.OrderingColumn) {
+		details = append(details, ErrorDetail{Field: "source.orderingColumn", Problem: "invalid", Hint: "Use alphanumeric identifiers"})
+	}
+	if spec.Source.KeyColumn != "" && !identRegex.MatchString(spec.Source.KeyColumn) {
+		details = append(details, ErrorDetail{Field: "source.keyColumn", Problem: "invalid", Hint: "Use alphanumeric identifiers"})
+	}
 	if spec.PollIntervalSeconds < minPoll || spec.PollIntervalSeconds > maxPoll {
 		details = append(details, ErrorDetail{Field: "pollIntervalSeconds", Problem: "out of range", Hint: fmt.Sprintf("min %d, max %d", minPoll, maxPoll)})
 	}
+	if spec.AutoResolveAfter != nil && *spec.AutoResolveAfter < 1 {
+		details = append(details, ErrorDetail{Field: "autoResolveAfter", Problem: "invalid", Hint: "Must be >= 1"})
+	}
 	if spec.Aggregation != "" && spec.Aggregation != "latest" {
 		if spec.WindowSeconds == nil || *spec.WindowSeconds <= 0 {
 			details = append(details, ErrorDetail{Field: "windowSeconds", Problem: "required", Hint: "Provide a window for aggregate rules"})
@@ -36,10 +48,24 @@ func ValidateRuleSpec(spec RuleSpec, minPoll, maxPoll int) *ParseError {
 		if param.ParameterName != "" && !identRegex.MatchString(param.ParameterName) {
 			details = append(details, ErrorDetail{Field: fmt.Sprintf("parameters[%d].parameterName", i), Problem: "invalid", Hint: "Use alphanumeric identifiers"})
 		}
+		if param.Expression != "" {
+			if _, err := expression.Parse(param.Expression); err != nil {
+				details = append(details, ErrorDetail{Field: fmt.Sprintf("parameters[%d].expression", i), Problem: "invalid", Hint: err.Error()})
+			}
+			if spec.Aggregation != "" && spec.Aggregation != "latest" {
+				details = append(details, ErrorDetail{Field: "aggregation", Problem: "unsupported", Hint: "Derived parameters only support latest values"})
+			}
+			if param.Detector.Type == "text_match" {
+				details = append(details, ErrorDetail{Field: fmt.Sprintf("parameters[%d].expression", i), Problem: "unsupported", Hint: "text_match reads the raw column value"})
+			}
+		}
 		if err := validateDetector(param.Detector, spec.PollIntervalSeconds, i); err != nil {
 			details = append(details, *err)
 		}
 	}
+	if spec.Composite != nil {
+		details = append(details, validateComposite(*spec.Composite, params)...)
+	}
 	if spec.Source.Where != nil {
 		for i, clause := range spec.Source.Where.Clauses {
 			if !identRegex.MatchString(clause.Column) {
@@ -54,6 +80,38 @@ func ValidateRuleSpec(spec RuleSpec, minPoll, maxPoll int) *ParseError {
 	return nil
 }
 
+func ValidateWhereSpec(where *WhereSpec, field string) []ErrorDetail {
+	details := []ErrorDetail{}
+	if where == nil {
+		return details
+	}
+	if where.Type != "" && !strings.EqualFold(where.Type, "and") && !strings.EqualFold(where.Type, "or") {
+		details = append(details, ErrorDetail{Field: field + ".type", Problem: "invalid", Hint: "Use and or or"})
+	}
+	if len(where.Clauses) == 0 {
+		details = append(details, ErrorDetail{Field: field + ".clauses", Problem: "missing", Hint: "Provide at least one clause"})
+	}
+	for i, clause := range where.Clauses {
+		if !identRegex.MatchString(clause.Column) {
+			details = append(details, ErrorDetail{Field: fmt.Sprintf("%s.clauses[%d].column", field, i), Problem: "invalid", Hint: "Use alphanumeric identifiers"})
+		}
+		op := strings.ToLower(strings.TrimSpace(clause.Op))
+		switch op {
+		case "=", "==", "!=", "<>", ">", ">=", "<", "<=", "like":
+			if clause.Value == nil {
+				details = append(details, ErrorDetail{Field: fmt.Sprintf("%s.clauses[%d].value", field, i), Problem: "missing", Hint: "Provide a value"})
+			}
+		case "in":
+			if values, ok := clause.Value.([]interface{}); !ok || len(values) == 0 {
+				details = append(details, ErrorDetail{Field: fmt.Sprintf("%s.clauses[%d].value", field, i), Problem: "invalid", Hint: "in requires a non-empty array"})
+			}
+		default:
+			details = append(details, ErrorDetail{Field: fmt.Sprintf("%s.clauses[%d].op", field, i), Problem: "unsupported", Hint: "Use =, !=, >, >=, <, <=, like, or in"})
+		}
+	}
+	return details
+}
+
 func normalizeParameters(spec RuleSpec) []ParameterSpec {
 	if len(spec.Parameters) > 0 {
 		return spec.Parameters
@@ -93,11 +151,18 @@ func validateDetector(detector DetectorSpec, pollInterval int, index int) *Error
 		if detector.RobustZ.MinSamples < 20 {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.robustZ.minSamples", index), Problem: "too small", Hint: "minSamples >= 20"}
 		}
+		if detector.RobustZ.BaselineRuns != 0 && detector.RobustZ.BaselineRuns < detector.RobustZ.MinSamples {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.robustZ.baselineRuns", index), Problem: "too small", Hint: "baselineRuns >= minSamples"}
+		}
 	case "missing_data":
 		if detector.MissingData == nil {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.missingData", index), Problem: "missing", Hint: "Provide missingData settings"}
 		}
-		if detector.MissingData.MaxGapSeconds < pollInterval {
+		if detector.MissingData.MaxGapRuns != nil {
+			if *detector.MissingData.MaxGapRuns < 0 {
+				return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.missingData.maxGapRuns", index), Problem: "invalid", Hint: "maxGapRuns must be >= 0"}
+			}
+		} else if detector.MissingData.MaxGapSeconds < pollInterval {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.missingData.maxGapSeconds", index), Problem: "too small", Hint: "maxGapSeconds >= pollIntervalSeconds"}
 		}
 	case "spec_limit":
@@ -111,11 +176,14 @@ func validateDetector(detector DetectorSpec, pollInterval int, index int) *Error
 		if mode != "spec" && mode != "control" && mode != "both" {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.specLimit.mode", index), Problem: "invalid", Hint: "Use spec, control, or both"}
 		}
-		if (mode == "spec" || mode == "both") && (detector.SpecLimit.SpecLimits == nil || (detector.SpecLimit.SpecLimits.USL == nil && detector.SpecLimit.SpecLimits.LSL == nil)) {
-			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.specLimit.specLimits", index), Problem: "missing", Hint: "Provide USL/LSL"}
+		if (mode == "spec" || mode == "both") && !hasSpecLimits(detector.SpecLimit.SpecLimits) {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.specLimit.specLimits", index), Problem: "missing", Hint: "Provide USL/LSL or uslColumn/lslColumn"}
+		}
+		if (mode == "control" || mode == "both") && !hasControlLimits(detector.SpecLimit.ControlLimits) {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.specLimit.controlLimits", index), Problem: "missing", Hint: "Provide UCL/LCL or uclColumn/lclColumn"}
 		}
-		if (mode == "control" || mode == "both") && (detector.SpecLimit.ControlLimits == nil || (detector.SpecLimit.ControlLimits.UCL == nil && detector.SpecLimit.ControlLimits.LCL == nil)) {
-			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.specLimit.controlLimits", index), Problem: "missing", Hint: "Provide UCL/LCL"}
+		if err := validateLimitColumns(fmt.Sprintf("parameters[%d].detector.specLimit", index), detector.SpecLimit.SpecLimits, detector.SpecLimit.ControlLimits); err != nil {
+			return err
 		}
 	case "shewhart":
 		if detector.Shewhart == nil {
@@ -174,21 +242,137 @@ func validateDetector(detector DetectorSpec, pollInterval int, index int) *Error
 		if detector.TPA.RequireSpecLimits && detector.TPA.SpecLimits == nil {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.tpa.specLimits", index), Problem: "missing", Hint: "Provide spec limits"}
 		}
+		if err := validateLimitColumns(fmt.Sprintf("parameters[%d].detector.tpa", index), detector.TPA.SpecLimits, nil); err != nil {
+			return err
+		}
 		if detector.TPA.SlopeThreshold == nil && detector.TPA.TimeToSpecThreshold == nil {
 			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.tpa", index), Problem: "invalid", Hint: "Provide slopeThreshold or timeToSpecThreshold"}
 		}
+	case "run_rules":
+		if detector.RunRules == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.runRules", index), Problem: "missing", Hint: "Provide runRules settings"}
+		}
+		if err := validateRunRules(*detector.RunRules, fmt.Sprintf("parameters[%d].detector.runRules", index)); err != nil {
+			return err
+		}
+	case "ewma":
+		if detector.EWMA == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.ewma", index), Problem: "missing", Hint: "Provide ewma settings"}
+		}
+		if err := validateEWMA(*detector.EWMA, fmt.Sprintf("parameters[%d].detector.ewma", index)); err != nil {
+			return err
+		}
+	case "cusum":
+		if detector.CUSUM == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.cusum", index), Problem: "missing", Hint: "Provide cusum settings"}
+		}
+		if err := validateCUSUM(*detector.CUSUM, fmt.Sprintf("parameters[%d].detector.cusum", index)); err != nil {
+			return err
+		}
+	case "attribute":
+		if detector.Attribute == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.attribute", index), Problem: "missing", Hint: "Provide attribute chart settings"}
+		}
+		if err := validateAttribute(*detector.Attribute, fmt.Sprintf("parameters[%d].detector.attribute", index)); err != nil {
+			return err
+		}
+	case "xbar_r", "xbar_s":
+		if detector.XbarChart == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.xbarChart", index), Problem: "missing", Hint: "Provide xbarChart settings"}
+		}
+		if err := validateXbarChart(*detector.XbarChart, fmt.Sprintf("parameters[%d].detector.xbarChart", index)); err != nil {
+			return err
+		}
+	case "i_mr":
+		if detector.IMR == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.imr", index), Problem: "missing", Hint: "Provide imr settings"}
+		}
+		if err := validateBaseline(detector.IMR.Baseline, fmt.Sprintf("parameters[%d].detector.imr.baseline", index)); err != nil {
+			return err
+		}
+		if detector.IMR.MinBaselineN < 0 || detector.IMR.EvalWindow < 0 {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.imr", index), Problem: "invalid", Hint: "minBaselineN and evalWindow must be >= 0"}
+		}
+	case "capability":
+		if detector.Capability == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.capability", index), Problem: "missing", Hint: "Provide capability settings"}
+		}
+		if err := validateCapability(*detector.Capability, fmt.Sprintf("parameters[%d].detector.capability", index)); err != nil {
+			return err
+		}
+	case "flatline":
+		if detector.Flatline == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.flatline", index), Problem: "missing", Hint: "Provide flatline settings"}
+		}
+		if err := validateFlatline(*detector.Flatline, fmt.Sprintf("parameters[%d].detector.flatline", index)); err != nil {
+			return err
+		}
+	case "step_change":
+		if detector.StepChange == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.stepChange", index), Problem: "missing", Hint: "Provide stepChange settings"}
+		}
+		if err := validateStepChange(*detector.StepChange, fmt.Sprintf("parameters[%d].detector.stepChange", index)); err != nil {
+			return err
+		}
+	case "rate_of_change":
+		if detector.RateOfChange == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.rateOfChange", index), Problem: "missing", Hint: "Provide rateOfChange settings"}
+		}
+		if err := validateRateOfChange(*detector.RateOfChange, fmt.Sprintf("parameters[%d].detector.rateOfChange", index)); err != nil {
+			return err
+		}
+	case "text_match":
+		if detector.TextMatch == nil {
+			return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.textMatch", index), Problem: "missing", Hint: "Provide textMatch settings"}
+		}
+		if err := validateTextMatch(*detector.TextMatch, fmt.Sprintf("parameters[%d].detector.textMatch", index)); err != nil {
+			return err
+		}
 	default:
-		return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.type", index), Problem: "unsupported", Hint: "Use threshold, robust_zscore, missing_data, spec_limit, shewhart, range_chart, trend, or tpa"}
+		return &ErrorDetail{Field: fmt.Sprintf("parameters[%d].detector.type", index), Problem: "unsupported", Hint: "Use threshold, robust_zscore, missing_data, spec_limit, shewhart, range_chart, trend, tpa, run_rules, ewma, cusum, attribute, xbar_r, xbar_s, i_mr, capability, flatline, step_change, rate_of_change, or text_match"}
+	}
+	return nil
+}
+
+func hasSpecLimits(bounds *SpecLimitBounds) bool {
+	return bounds != nil && (bounds.USL != nil || bounds.LSL != nil || bounds.USLColumn != "" || bounds.LSLColumn != "")
+}
+
+func hasControlLimits(bounds *ControlLimitBounds) bool {
+	return bounds != nil && (bounds.UCL != nil || bounds.LCL != nil || bounds.UCLColumn != "" || bounds.LCLColumn != "")
+}
+
+func validateLimitColumns(field string, spec *SpecLimitBounds, control *ControlLimitBounds) *ErrorDetail {
+	names := []string{}
+	columns := []string{}
+	if spec != nil {
+		names = append(names, field+".specLimits.uslColumn", field+".specLimits.lslColumn")
+		columns = append(columns, spec.USLColumn, spec.LSLColumn)
+	}
+	if control != nil {
+		names = append(names, field+".controlLimits.uclColumn", field+".controlLimits.lclColumn")
+		columns = append(columns, control.UCLColumn, control.LCLColumn)
+	}
+	for i, column := range columns {
+		if column != "" && !identRegex.MatchString(column) {
+			return &ErrorDetail{Field: names[i], Problem: "invalid", Hint: "Use alphanumeric identifiers"}
+		}
 	}
 	return nil
 }
 
 func validateBaseline(baseline BaselineSpec, field string) *ErrorDetail {
-	if baseline.LastN != nil && baseline.TimeRange != nil {
-		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "Use lastN or timeRange"}
+	selected := 0
+	for _, set := range []bool{baseline.LastN != nil, baseline.TimeRange != nil, baseline.RunRange != nil} {
+		if set {
+			selected++
+		}
 	}
-	if baseline.LastN == nil && baseline.TimeRange == nil {
-		return &ErrorDetail{Field: field, Problem: "missing", Hint: "Provide lastN or timeRange"}
+	if selected > 1 {
+		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "Use lastN, timeRange, or runRange"}
+	}
+	if selected == 0 {
+		return &ErrorDetail{Field: field, Problem: "missing", Hint: "Provide lastN, timeRange, or runRange"}
 	}
 	if baseline.LastN != nil && *baseline.LastN <= 0 {
 		return &ErrorDetail{Field: field + ".lastN", Problem: "invalid", Hint: "lastN must be > 0"}
@@ -198,6 +382,248 @@ func validateBaseline(baseline BaselineSpec, field string) *ErrorDetail {
 			return &ErrorDetail{Field: field + ".timeRange", Problem: "invalid", Hint: "Provide start and end"}
 		}
 	}
+	if baseline.RunRange != nil && baseline.RunRange.To < baseline.RunRange.From {
+		return &ErrorDetail{Field: field + ".runRange", Problem: "invalid", Hint: "to must be >= from"}
+	}
+	return nil
+}
+
+func validateRunRules(spec RunRulesSpec, field string) *ErrorDetail {
+	seen := map[int]struct{}{}
+	for _, rule := range spec.Rules {
+		if rule < 1 || rule > 8 {
+			return &ErrorDetail{Field: field + ".rules", Problem: "invalid", Hint: "Rules are numbered 1-8"}
+		}
+		if _, ok := seen[rule]; ok {
+			return &ErrorDetail{Field: field + ".rules", Problem: "duplicate", Hint: fmt.Sprintf("Rule %d listed twice", rule)}
+		}
+		seen[rule] = struct{}{}
+	}
+	if err := validateBaselineStats(spec.Baseline, spec.Mu, spec.Sigma, spec.MinBaselineN, field); err != nil {
+		return err
+	}
+	if spec.SameSideRun != 0 && (spec.SameSideRun < 7 || spec.SameSideRun > 9) {
+		return &ErrorDetail{Field: field + ".sameSideRun", Problem: "invalid", Hint: "Use 7, 8 (Western Electric) or 9 (Nelson)"}
+	}
+	if spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateEWMA(spec EWMASpec, field string) *ErrorDetail {
+	if spec.Lambda < 0 || spec.Lambda > 1 {
+		return &ErrorDetail{Field: field + ".lambda", Problem: "invalid", Hint: "lambda must be in (0, 1]"}
+	}
+	if spec.L < 0 {
+		return &ErrorDetail{Field: field + ".l", Problem: "invalid", Hint: "L must be > 0"}
+	}
+	if err := validateBaselineStats(spec.Baseline, spec.Mu, spec.Sigma, spec.MinBaselineN, field); err != nil {
+		return err
+	}
+	if spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateCUSUM(spec CUSUMSpec, field string) *ErrorDetail {
+	if spec.K < 0 {
+		return &ErrorDetail{Field: field + ".k", Problem: "invalid", Hint: "k must be >= 0"}
+	}
+	if spec.H < 0 {
+		return &ErrorDetail{Field: field + ".h", Problem: "invalid", Hint: "h must be > 0"}
+	}
+	if spec.Sigma != nil && *spec.Sigma <= 0 {
+		return &ErrorDetail{Field: field + ".sigma", Problem: "invalid", Hint: "sigma must be > 0"}
+	}
+	if spec.Target == nil || spec.Sigma == nil {
+		if err := validateBaseline(spec.Baseline, field+".baseline"); err != nil {
+			return err
+		}
+	}
+	if spec.MinBaselineN < 0 {
+		return &ErrorDetail{Field: field + ".minBaselineN", Problem: "invalid", Hint: "minBaselineN must be >= 0"}
+	}
+	if spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateAttribute(spec AttributeSpec, field string) *ErrorDetail {
+	switch spec.Chart {
+	case "p", "np", "c", "u":
+	default:
+		return &ErrorDetail{Field: field + ".chart", Problem: "invalid", Hint: "Use p, np, c, or u"}
+	}
+	if spec.SampleSizeColumn != "" && !identRegex.MatchString(spec.SampleSizeColumn) {
+		return &ErrorDetail{Field: field + ".sampleSizeColumn", Problem: "invalid", Hint: "Use alphanumeric identifiers"}
+	}
+	if spec.SampleSize < 0 {
+		return &ErrorDetail{Field: field + ".sampleSize", Problem: "invalid", Hint: "sampleSize must be > 0"}
+	}
+	if (spec.Chart == "p" || spec.Chart == "np") && spec.SampleSizeColumn == "" && spec.SampleSize == 0 {
+		return &ErrorDetail{Field: field + ".sampleSizeColumn", Problem: "missing", Hint: "p and np charts need sampleSizeColumn or sampleSize"}
+	}
+	if spec.SigmaMultiplier < 0 {
+		return &ErrorDetail{Field: field + ".sigmaMultiplier", Problem: "invalid", Hint: "sigmaMultiplier must be > 0"}
+	}
+	if err := validateBaseline(spec.Baseline, field+".baseline"); err != nil {
+		return err
+	}
+	if spec.MinBaselineN < 0 {
+		return &ErrorDetail{Field: field + ".minBaselineN", Problem: "invalid", Hint: "minBaselineN must be >= 0"}
+	}
+	if spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateXbarChart(spec XbarChartSpec, field string) *ErrorDetail {
+	if !isSupportedRangeChartSize(spec.SubgroupSize) {
+		return &ErrorDetail{Field: field + ".subgroupSize", Problem: "invalid", Hint: "Supported subgroupSize: 2-10"}
+	}
+	if err := validateBaseline(spec.Baseline, field+".baseline"); err != nil {
+		return err
+	}
+	mode := spec.Subgrouping.Mode
+	if mode == "" {
+		mode = "consecutive"
+	}
+	if mode != "consecutive" && mode != "column" {
+		return &ErrorDetail{Field: field + ".subgrouping.mode", Problem: "invalid", Hint: "Use consecutive or column"}
+	}
+	if mode == "column" && spec.Subgrouping.Column == "" {
+		return &ErrorDetail{Field: field + ".subgrouping.column", Problem: "missing", Hint: "Provide column name"}
+	}
+	if spec.MinBaselineSubgroups < 0 || spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "minBaselineSubgroups and evalWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateCapability(spec CapabilitySpec, field string) *ErrorDetail {
+	if !hasSpecLimits(spec.SpecLimits) {
+		return &ErrorDetail{Field: field + ".specLimits", Problem: "missing", Hint: "Provide USL/LSL or uslColumn/lslColumn"}
+	}
+	if spec.SpecLimits.USL != nil && spec.SpecLimits.LSL != nil && *spec.SpecLimits.USL <= *spec.SpecLimits.LSL {
+		return &ErrorDetail{Field: field + ".specLimits", Problem: "invalid", Hint: "usl must be greater than lsl"}
+	}
+	if err := validateLimitColumns(field, spec.SpecLimits, nil); err != nil {
+		return err
+	}
+	if spec.MinCpk < 0 {
+		return &ErrorDetail{Field: field + ".minCpk", Problem: "invalid", Hint: "minCpk must be >= 0"}
+	}
+	if spec.Window != 0 && spec.Window < 10 {
+		return &ErrorDetail{Field: field + ".window", Problem: "invalid", Hint: "window must be >= 10"}
+	}
+	if spec.ConfidenceLevel != 0 && (spec.ConfidenceLevel <= 0 || spec.ConfidenceLevel >= 1) {
+		return &ErrorDetail{Field: field + ".confidenceLevel", Problem: "invalid", Hint: "confidenceLevel must be between 0 and 1"}
+	}
+	return nil
+}
+
+func validateFlatline(spec FlatlineSpec, field string) *ErrorDetail {
+	if spec.Epsilon < 0 {
+		return &ErrorDetail{Field: field + ".epsilon", Problem: "invalid", Hint: "epsilon must be >= 0"}
+	}
+	if spec.MinSamples < 0 || spec.MinDurationSeconds < 0 {
+		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "minSamples and minDurationSeconds must be >= 0"}
+	}
+	if spec.MinSamples == 0 && spec.MinDurationSeconds == 0 {
+		return &ErrorDetail{Field: field, Problem: "missing", Hint: "Provide minSamples or minDurationSeconds"}
+	}
+	if spec.MinSamples == 1 {
+		return &ErrorDetail{Field: field + ".minSamples", Problem: "invalid", Hint: "minSamples must be >= 2"}
+	}
+	if spec.EvalWindow < 0 || (spec.EvalWindow > 0 && spec.EvalWindow < spec.MinSamples) {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= minSamples"}
+	}
+	return nil
+}
+
+func validateStepChange(spec StepChangeSpec, field string) *ErrorDetail {
+	if spec.WindowSize != 0 && spec.WindowSize < 2 {
+		return &ErrorDetail{Field: field + ".windowSize", Problem: "invalid", Hint: "windowSize must be >= 2"}
+	}
+	if spec.SigmaMultiplier < 0 {
+		return &ErrorDetail{Field: field + ".sigmaMultiplier", Problem: "invalid", Hint: "sigmaMultiplier must be >= 0"}
+	}
+	if spec.MinShift != nil && *spec.MinShift <= 0 {
+		return &ErrorDetail{Field: field + ".minShift", Problem: "invalid", Hint: "minShift must be > 0"}
+	}
+	return nil
+}
+
+func validateRateOfChange(spec RateOfChangeSpec, field string) *ErrorDetail {
+	if spec.Basis != "" && spec.Basis != "second" && spec.Basis != "run" {
+		return &ErrorDetail{Field: field + ".basis", Problem: "invalid", Hint: "Use second or run"}
+	}
+	if spec.MinRate == nil && spec.MaxRate == nil {
+		return &ErrorDetail{Field: field, Problem: "missing", Hint: "Provide minRate or maxRate"}
+	}
+	if spec.MinRate != nil && spec.MaxRate != nil && *spec.MinRate >= *spec.MaxRate {
+		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "minRate must be less than maxRate"}
+	}
+	if spec.SmoothingWindow < 0 {
+		return &ErrorDetail{Field: field + ".smoothingWindow", Problem: "invalid", Hint: "smoothingWindow must be >= 0"}
+	}
+	return nil
+}
+
+func validateTextMatch(spec TextMatchSpec, field string) *ErrorDetail {
+	if len(spec.Patterns) == 0 && !spec.AnyValue {
+		return &ErrorDetail{Field: field, Problem: "missing", Hint: "Provide patterns or set anyValue"}
+	}
+	if !isTextSeverity(spec.AnyValueSeverity) {
+		return &ErrorDetail{Field: field + ".anyValueSeverity", Problem: "invalid", Hint: "Use high or medium"}
+	}
+	if spec.EvalWindow < 0 {
+		return &ErrorDetail{Field: field + ".evalWindow", Problem: "invalid", Hint: "evalWindow must be >= 0"}
+	}
+	for i, pattern := range spec.Patterns {
+		patternField := fmt.Sprintf("%s.patterns[%d]", field, i)
+		if pattern.Pattern == "" {
+			return &ErrorDetail{Field: patternField + ".pattern", Problem: "missing", Hint: "Provide a keyword or regular expression"}
+		}
+		switch pattern.Kind {
+		case "", "keyword":
+		case "regex":
+			if _, err := regexp.Compile(pattern.Pattern); err != nil {
+				return &ErrorDetail{Field: patternField + ".pattern", Problem: "invalid", Hint: err.Error()}
+			}
+		default:
+			return &ErrorDetail{Field: patternField + ".kind", Problem: "invalid", Hint: "Use keyword or regex"}
+		}
+		if !isTextSeverity(pattern.Severity) {
+			return &ErrorDetail{Field: patternField + ".severity", Problem: "invalid", Hint: "Use high or medium"}
+		}
+	}
+	return nil
+}
+
+func isTextSeverity(severity string) bool {
+	return severity == "" || severity == "high" || severity == "medium"
+}
+
+func validateBaselineStats(baseline BaselineSpec, mu, sigma *float64, minBaselineN int, field string) *ErrorDetail {
+	if (mu == nil) != (sigma == nil) {
+		return &ErrorDetail{Field: field, Problem: "invalid", Hint: "Provide both mu and sigma, or neither"}
+	}
+	if sigma != nil && *sigma <= 0 {
+		return &ErrorDetail{Field: field + ".sigma", Problem: "invalid", Hint: "sigma must be > 0"}
+	}
+	if mu == nil {
+		if err := validateBaseline(baseline, field+".baseline"); err != nil {
+			return err
+		}
+	}
+	if minBaselineN < 0 {
+		return &ErrorDetail{Field: field + ".minBaselineN", Problem: "invalid", Hint: "minBaselineN must be >= 0"}
+	}
 	return nil
 }
 
@@ -209,3 +635,53 @@ func isSupportedRangeChartSize(size int) bool {
 		return false
 	}
 }
+
+func validateComposite(spec CompositeSpec, params []ParameterSpec) []ErrorDetail {
+	details := []ErrorDetail{}
+	if spec.Name != "" && !identRegex.MatchString(spec.Name) {
+		details = append(details, ErrorDetail{Field: "composite.name", Problem: "invalid", Hint: "Use alphanumeric identifiers"})
+	}
+	if spec.Severity != "" && spec.Severity != "medium" && spec.Severity != "high" {
+		details = append(details, ErrorDetail{Field: "composite.severity", Problem: "invalid", Hint: "Use medium or high"})
+	}
+	return append(details, validateCompositeCondition(spec.Condition, params, "composite.condition")...)
+}
+
+func validateCompositeCondition(cond CompositeCondition, params []ParameterSpec, field string) []ErrorDetail {
+	details := []ErrorDetail{}
+	op := strings.ToLower(strings.TrimSpace(cond.Op))
+	switch op {
+	case "", "parameter":
+		found := false
+		for _, param := range params {
+			if param.ParameterName == cond.Parameter && (cond.Detector == "" || param.Detector.Type == cond.Detector) {
+				found = true
+				break
+			}
+		}
+		if strings.TrimSpace(cond.Parameter) == "" {
+			details = append(details, ErrorDetail{Field: field + ".parameter", Problem: "missing", Hint: "Reference a parameterName"})
+		} else if !found {
+			details = append(details, ErrorDetail{Field: field + ".parameter", Problem: "unknown", Hint: "Must match a parameterName (and detector type) in parameters"})
+		}
+		return details
+	case "and", "or":
+		if len(cond.Conditions) < 2 {
+			details = append(details, ErrorDetail{Field: field + ".conditions", Problem: "invalid", Hint: "Provide at least two conditions"})
+		}
+	case "not":
+		if len(cond.Conditions) != 1 {
+			details = append(details, ErrorDetail{Field: field + ".conditions", Problem: "invalid", Hint: "not takes exactly one condition"})
+		}
+	case "atleast":
+		if cond.Count < 1 || cond.Count > len(cond.Conditions) {
+			details = append(details, ErrorDetail{Field: field + ".count", Problem: "invalid", Hint: "count must be between 1 and the number of conditions"})
+		}
+	default:
+		return append(details, ErrorDetail{Field: field + ".op", Problem: "unsupported", Hint: "Use and, or, not, atLeast or a parameter leaf"})
+	}
+	for i, child := range cond.Conditions {
+		details = append(details, validateCompositeCondition(child, params, fmt.Sprintf("%s.conditions[%d]", field, i))...)
+	}
+	return details
+}
@@ -106,6 +106,33 @@ func TestValidateRuleSpecShewhartInvalidBaseline(t *testing.T) {
 	}
 }
 
+func TestValidateRuleSpecOrderingColumn(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "lots", OrderingColumn: "run_order"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "thickness",
+			ValueColumn:   "thickness",
+			Detector: DetectorSpec{
+				Type:     "shewhart",
+				Shewhart: &ShewhartSpec{Baseline: BaselineSpec{RunRange: &RunRangeSpec{From: 1, To: 100}}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.Shewhart.Baseline.RunRange = &RunRangeSpec{From: 100, To: 1}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected runRange validation error")
+	}
+	spec.Source.OrderingColumn = ""
+	spec.Parameters[0].Detector.Shewhart.Baseline.RunRange = &RunRangeSpec{From: 1, To: 100}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected timestampColumn required without orderingColumn")
+	}
+}
+
 func TestValidateRuleSpecTPAInvalid(t *testing.T) {
 	spec := RuleSpec{
 		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
@@ -151,3 +178,316 @@ func TestValidateRuleSpecRangeChartInvalidSize(t *testing.T) {
 func intPtr(value int) *int {
 	return &value
 }
+
+func TestValidateWhereSpec(t *testing.T) {
+	valid := &WhereSpec{Type: "and", Clauses: []ClauseSpec{{Column: "unit", Op: "=", Value: "A"}, {Column: "recipe", Op: "in", Value: []interface{}{"r1", "r2"}}}}
+	if details := ValidateWhereSpec(valid, "rowFilter"); len(details) != 0 {
+		t.Fatalf("unexpected details: %+v", details)
+	}
+	invalid := &WhereSpec{Type: "xor", Clauses: []ClauseSpec{{Column: "unit;drop", Op: "=", Value: "A"}, {Column: "recipe", Op: "in", Value: "r1"}, {Column: "step", Op: "~", Value: 1}}}
+	if details := ValidateWhereSpec(invalid, "rowFilter"); len(details) != 4 {
+		t.Fatalf("expected 4 details, got %+v", details)
+	}
+	if details := ValidateWhereSpec(&WhereSpec{}, "rowFilter"); len(details) != 1 {
+		t.Fatalf("expected missing clauses detail")
+	}
+}
+
+func TestValidateRuleSpecRunRules(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "temp",
+			ValueColumn:   "temp",
+			Detector: DetectorSpec{
+				Type:     "run_rules",
+				RunRules: &RunRulesSpec{Rules: []int{1, 2, 5}, Baseline: BaselineSpec{LastN: intPtr(100)}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.RunRules.Rules = []int{1, 9}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected invalid rule number")
+	}
+	mu := 10.0
+	spec.Parameters[0].Detector.RunRules = &RunRulesSpec{Mu: &mu}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected mu without sigma to fail")
+	}
+}
+
+func TestValidateRuleSpecEWMA(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "etch_rate",
+			ValueColumn:   "etch_rate",
+			Detector: DetectorSpec{
+				Type: "ewma",
+				EWMA: &EWMASpec{Lambda: 0.2, L: 2.7, Baseline: BaselineSpec{LastN: intPtr(100)}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.EWMA.Lambda = 1.5
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected lambda validation error")
+	}
+}
+
+func TestValidateRuleSpecCUSUM(t *testing.T) {
+	target := 50.0
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "etch_rate",
+			ValueColumn:   "etch_rate",
+			Detector: DetectorSpec{
+				Type:  "cusum",
+				CUSUM: &CUSUMSpec{K: 0.5, H: 4, Target: &target, Baseline: BaselineSpec{LastN: intPtr(100)}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.CUSUM.Baseline = BaselineSpec{}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected baseline required without frozen sigma")
+	}
+	spec.Parameters[0].Detector.CUSUM.H = -1
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected h validation error")
+	}
+}
+
+func TestValidateRuleSpecAttribute(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "particle_count",
+			ValueColumn:   "particle_count",
+			Detector: DetectorSpec{
+				Type:      "attribute",
+				Attribute: &AttributeSpec{Chart: "c", Baseline: BaselineSpec{LastN: intPtr(50)}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.Attribute.Chart = "p"
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected p chart to require a sample size")
+	}
+	spec.Parameters[0].Detector.Attribute.SampleSizeColumn = "wafers_inspected"
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.Attribute.Chart = "x"
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected invalid chart")
+	}
+}
+
+func TestValidateRuleSpecXbarAndIMR(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "thickness",
+			ValueColumn:   "thickness",
+			Detector: DetectorSpec{
+				Type: "xbar_s",
+				XbarChart: &XbarChartSpec{
+					SubgroupSize: 5,
+					Subgrouping:  SubgroupingSpec{Mode: "column", Column: "lot_id"},
+					Baseline:     BaselineSpec{LastN: intPtr(100)},
+				},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.XbarChart.SubgroupSize = 1
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected subgroup size validation error")
+	}
+	spec.Parameters[0].Detector = DetectorSpec{Type: "i_mr", IMR: &IMRSpec{}}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected i_mr baseline required")
+	}
+	spec.Parameters[0].Detector.IMR.Baseline = BaselineSpec{LastN: intPtr(50)}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRuleSpecCapability(t *testing.T) {
+	usl, lsl := 10.0, 2.0
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "thickness",
+			ValueColumn:   "thickness",
+			Detector: DetectorSpec{
+				Type:       "capability",
+				Capability: &CapabilitySpec{SpecLimits: &SpecLimitBounds{USL: &usl, LSL: &lsl}, MinCpk: 1.33, Window: 50},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.Capability.Window = 5
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected window validation error")
+	}
+	spec.Parameters[0].Detector.Capability = &CapabilitySpec{SpecLimits: &SpecLimitBounds{USL: &lsl, LSL: &usl}}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected usl > lsl validation error")
+	}
+	spec.Parameters[0].Detector.Capability = &CapabilitySpec{}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected spec limits required")
+	}
+}
+
+func TestValidateRuleSpecComposite(t *testing.T) {
+	threshold := 19.0
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{
+			{ParameterName: "rf_power", ValueColumn: "rf_power", Detector: DetectorSpec{Type: "threshold", Threshold: &ThresholdSpec{Op: ">", Value: threshold}}},
+			{ParameterName: "chamber_pressure", ValueColumn: "chamber_pressure", Detector: DetectorSpec{Type: "robust_zscore", RobustZ: &RobustZSpec{BaselineWindowSeconds: 3600, EvalWindowSeconds: 60, ZWarn: 3, ZCrit: 5, MinSamples: 30}}},
+		},
+		PollIntervalSeconds: 10,
+		Composite: &CompositeSpec{Condition: CompositeCondition{Op: "and", Conditions: []CompositeCondition{
+			{Parameter: "rf_power"},
+			{Parameter: "chamber_pressure", Detector: "robust_zscore"},
+		}}},
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Composite.Condition.Conditions[1].Parameter = "unknown"
+	err := ValidateRuleSpec(spec, 5, 3600)
+	if err == nil || err.Details[0].Field != "composite.condition.conditions[1].parameter" {
+		t.Fatalf("expected unknown parameter error, got %v", err)
+	}
+	spec.Composite.Condition = CompositeCondition{Op: "atLeast", Count: 3, Conditions: []CompositeCondition{{Parameter: "rf_power"}, {Parameter: "chamber_pressure"}}}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil || err.Details[0].Field != "composite.condition.count" {
+		t.Fatalf("expected count validation error, got %v", err)
+	}
+}
+
+func TestValidateRuleSpecDerivedExpression(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{
+			{ParameterName: "power_ratio", ValueColumn: "power_ratio", Expression: "rf_power / max(gas_ar_flow, 1)", Detector: DetectorSpec{Type: "threshold", Threshold: &ThresholdSpec{Op: ">", Value: 5}}},
+		},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Expression = "rf_power; drop table"
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil || err.Details[0].Field != "parameters[0].expression" {
+		t.Fatalf("expected expression validation error, got %v", err)
+	}
+}
+
+func TestValidateRuleSpecFlatlineAndStepChange(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{
+			{ParameterName: "chamber_pressure", ValueColumn: "chamber_pressure", Detector: DetectorSpec{Type: "flatline", Flatline: &FlatlineSpec{Epsilon: 0.01, MinSamples: 20}}},
+			{ParameterName: "rf_power", ValueColumn: "rf_power", Detector: DetectorSpec{Type: "step_change", StepChange: &StepChangeSpec{WindowSize: 10, SigmaMultiplier: 3}}},
+		},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.Flatline = &FlatlineSpec{Epsilon: 0.01}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil || err.Details[0].Field != "parameters[0].detector.flatline" {
+		t.Fatalf("expected missing flatline limit error, got %v", err)
+	}
+	spec.Parameters[0].Detector.Flatline = &FlatlineSpec{MinDurationSeconds: 600}
+	spec.Parameters[1].Detector.StepChange.WindowSize = 1
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil || err.Details[0].Field != "parameters[1].detector.stepChange.windowSize" {
+		t.Fatalf("expected window size error, got %v", err)
+	}
+}
+
+func TestValidateRuleSpecRateOfChange(t *testing.T) {
+	minRate, maxRate := -0.5, 0.5
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "telemetry", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "chamber_temp",
+			ValueColumn:   "chamber_temp",
+			Detector: DetectorSpec{
+				Type:         "rate_of_change",
+				RateOfChange: &RateOfChangeSpec{Basis: "second", MinRate: &minRate, MaxRate: &maxRate, SmoothingWindow: 3},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.RateOfChange.Basis = "minute"
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected basis validation error")
+	}
+	spec.Parameters[0].Detector.RateOfChange = &RateOfChangeSpec{MinRate: &maxRate, MaxRate: &minRate}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected minRate < maxRate validation error")
+	}
+}
+
+func TestValidateRuleSpecTextMatch(t *testing.T) {
+	spec := RuleSpec{
+		Source: SourceSpec{Table: "etchers_data", TimestampColumn: "ts"},
+		Parameters: []ParameterSpec{{
+			ParameterName: "warning_abort",
+			ValueColumn:   "warning_abort",
+			Detector: DetectorSpec{
+				Type: "text_match",
+				TextMatch: &TextMatchSpec{Patterns: []TextPattern{
+					{Pattern: "abort", Severity: "high"},
+					{Pattern: `^W\d+`, Kind: "regex", Severity: "medium"},
+				}},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	if err := ValidateRuleSpec(spec, 5, 3600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Detector.TextMatch.Patterns[1].Pattern = "("
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected regex validation error")
+	}
+	spec.Parameters[0].Detector.TextMatch = &TextMatchSpec{AnyValue: true, AnyValueSeverity: "low"}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected severity validation error")
+	}
+	spec.Parameters[0].Detector.TextMatch = &TextMatchSpec{}
+	if err := ValidateRuleSpec(spec, 5, 3600); err == nil {
+		t.Fatalf("expected missing patterns error")
+	}
+}
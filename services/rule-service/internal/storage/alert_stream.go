@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// streamAlertSelect resolves the units of an alert: the unit of a stepper
+// rule, and every unit listing a legacy rule in machine_units.rule_ids.
+const streamAlertSelect = `
+	SELECT a.id, COALESCE(a.rule_id::text,''), COALESCE(a.ui_rule_id::text,''), COALESCE(u.unit_id,''),
+		ARRAY(SELECT m.unit_id FROM machine_units m WHERE m.unit_id = u.unit_id OR m.rule_ids ? a.rule_id::text ORDER BY m.unit_id), a.ts_utc, a.parameter_name, a.observed_value, a.limit_expression, a.detector_type, a.severity, a.treated, a.metadata, a.state, a.opened_at, a.last_seen_at, a.acknowledged_at, a.resolved_at, a.occurrences
+	FROM alerts a LEFT JOIN ui_rules u ON u.id = a.ui_rule_id`
+
+// AlertFilter narrows streamed alerts. Empty fields match everything and
+// MinSeverity "high" keeps only high-severity alerts.
+type AlertFilter struct {
+	UnitID      string
+	RuleID      string
+	MinSeverity string
+}
+
+func (r *Repository) GetAlert(ctx context.Context, id int64) (AlertRecord, error) {
+	rec, err := scanStreamAlert(r.Store.Pool.QueryRow(ctx, streamAlertSelect+` WHERE a.id=$1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return AlertRecord{}, ErrNotFound
+	}
+	return rec, err
+}
+
+// ListAlertsAfter returns live alerts with an id above afterID in id order.
+func (r *Repository) ListAlertsAfter(ctx context.Context, afterID int64, filter AlertFilter, limit int) ([]AlertRecord, error) {
+	rows, err := r.Store.Pool.Query(ctx, streamAlertSelect+`
+		WHERE a.id > $1
+			AND ($2 = '' OR u.unit_id = $2 OR EXISTS (SELECT 1 FROM machine_units m WHERE m.unit_id = $2 AND m.rule_ids ? a.rule_id::text))
+			AND ($3 = '' OR a.rule_id::text = $3 OR a.ui_rule_id::text = $3)
+			AND ($4 <> 'high' OR a.severity = 'high')
+		ORDER BY a.id LIMIT $5`, afterID, filter.UnitID, filter.RuleID, filter.MinSeverity, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []AlertRecord{}
+	for rows.Next() {
+		rec, err := scanStreamAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, rows.Err()
+}
+
+func scanStreamAlert(row scanner) (AlertRecord, error) {
+	var rec AlertRecord
+	err := row.Scan(&rec.ID, &rec.RuleID, &rec.UIRuleID, &rec.UnitID, &rec.UnitIDs, &rec.TSUTC, &rec.ParameterName, &rec.ObservedValue, &rec.LimitExpr, &rec.DetectorType, &rec.Severity, &rec.Treated, &rec.Metadata, &rec.State, &rec.OpenedAt, &rec.LastSeenAt, &rec.AcknowledgedAt, &rec.ResolvedAt, &rec.Occurrences)
+	if rec.UnitID == "" && len(rec.UnitIDs) == 1 {
+		rec.UnitID = rec.UnitIDs[0]
+	}
+	return rec, err
+}
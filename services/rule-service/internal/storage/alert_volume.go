@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type RuleAlertVolume struct {
+	RuleID            string
+	Name              string
+	RuleType          string
+	ParameterID       string
+	Enabled           bool
+	Shadow            bool
+	LiveAlerts        int
+	LiveOccurrences   int
+	ShadowAlerts      int
+	ShadowOccurrences int
+}
+
+// ListRuleAlertVolumes counts live and shadow alerts since the given time for
+// the unit's stepper rules and the legacy rules linked through rule_ids.
+func (r *Repository) ListRuleAlertVolumes(ctx context.Context, unitID string, since time.Time) ([]RuleAlertVolume, error) {
+	rows, err := r.Store.Pool.Query(ctx, `
+		SELECT id, name, rule_type, parameter_id, enabled, shadow, live_alerts, live_occurrences, shadow_alerts, shadow_occurrences
+		FROM (
+			SELECT r.id::text AS id, r.name, r.rule_type, r.parameter_id, r.enabled, r.shadow, r.created_at,
+				COALESCE(l.alerts, 0) AS live_alerts, COALESCE(l.occurrences, 0) AS live_occurrences,
+				COALESCE(s.alerts, 0) AS shadow_alerts, COALESCE(s.occurrences, 0) AS shadow_occurrences
+			FROM ui_rules r
+			LEFT JOIN (
+				SELECT ui_rule_id, count(*) AS alerts, sum(occurrences) AS occurrences
+				FROM alerts WHERE ts_utc >= $2 GROUP BY ui_rule_id) l ON l.ui_rule_id = r.id
+			LEFT JOIN (
+				SELECT ui_rule_id, count(*) AS alerts, sum(occurrences) AS occurrences
+				FROM shadow_alerts WHERE ts_utc >= $2 GROUP BY ui_rule_id) s ON s.ui_rule_id = r.id
+			WHERE r.unit_id=$1
+			UNION ALL
+			SELECT r.id::text, r.name, 'LEGACY', r.parameter_name, r.enabled, r.shadow, r.created_at,
+				COALESCE(l.alerts, 0), COALESCE(l.occurrences, 0), COALESCE(s.alerts, 0), COALESCE(s.occurrences, 0)
+			FROM rules r
+			JOIN machine_units m ON m.unit_id=$1 AND m.rule_ids ? r.id::text
+			LEFT JOIN (
+				SELECT rule_id, count(*) AS alerts, sum(occurrences) AS occurrences
+				FROM alerts WHERE ts_utc >= $2 GROUP BY rule_id) l ON l.rule_id = r.id
+			LEFT JOIN (
+				SELECT rule_id, count(*) AS alerts, sum(occurrences) AS occurrences
+				FROM shadow_alerts WHERE ts_utc >= $2 GROUP BY rule_id) s ON s.rule_id = r.id
+		) v
+		ORDER BY parameter_id, created_at`, unitID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []RuleAlertVolume{}
+	for rows.Next() {
+		var rec RuleAlertVolume
+		if err := rows.Scan(&rec.RuleID, &rec.Name, &rec.RuleType, &rec.ParameterID, &rec.Enabled, &rec.Shadow, &rec.LiveAlerts, &rec.LiveOccurrences, &rec.ShadowAlerts, &rec.ShadowOccurrences); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, nil
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	BaselineStatusActive     = "ACTIVE"
+	BaselineStatusPending    = "PENDING"
+	BaselineStatusSuperseded = "SUPERSEDED"
+)
+
+const baselineColumns = `id, COALESCE(ui_rule_id::text,''), parameter_name, detector_type, status, source, mu, sigma, rbar, median, mad, n, subgroup_count, window_start, window_end, order_start, order_end, data_hash, created_at, approved_at`
+
+var ErrBaselineNotPending = errors.New("baseline is not pending")
+
+func (r *Repository) ListStepperBaselines(ctx context.Context, ruleID string) ([]Baseline, error) {
+	rows, err := r.Store.Pool.Query(ctx, `SELECT `+baselineColumns+` FROM baselines WHERE ui_rule_id=$1 ORDER BY created_at DESC, id DESC`, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []Baseline{}
+	for rows.Next() {
+		rec, err := scanBaseline(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, rows.Err()
+}
+
+func (r *Repository) CreateStepperBaseline(ctx context.Context, rec Baseline) (Baseline, error) {
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return Baseline{}, err
+	}
+	defer tx.Rollback(ctx)
+	created, err := insertBaseline(ctx, tx, rec)
+	if err != nil {
+		return Baseline{}, err
+	}
+	return created, tx.Commit(ctx)
+}
+
+func insertBaseline(ctx context.Context, tx pgx.Tx, rec Baseline) (Baseline, error) {
+	if rec.Status == BaselineStatusActive {
+		if err := supersedeActiveBaseline(ctx, tx, rec); err != nil {
+			return Baseline{}, err
+		}
+	}
+	row := tx.QueryRow(ctx, `
+		INSERT INTO baselines (ui_rule_id, parameter_name, detector_type, status, source, mu, sigma, rbar, median, mad, n, subgroup_count, window_start, window_end, order_start, order_end, data_hash, created_at, approved_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,now(),CASE WHEN $4='ACTIVE' THEN now() END)
+		RETURNING `+baselineColumns,
+		rec.UIRuleID, rec.ParameterName, rec.DetectorType, rec.Status, rec.Source, rec.Mu, rec.Sigma, rec.RBar, rec.Median, rec.MAD, rec.N, rec.SubgroupCount, rec.WindowStart, rec.WindowEnd, rec.OrderStart, rec.OrderEnd, rec.DataHash,
+	)
+	return scanBaseline(row)
+}
+
+func (r *Repository) ApproveStepperBaseline(ctx context.Context, ruleID string, id int64) (Baseline, error) {
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return Baseline{}, err
+	}
+	defer tx.Rollback(ctx)
+	rec, err := scanBaseline(tx.QueryRow(ctx, `SELECT `+baselineColumns+` FROM baselines WHERE id=$1 AND ui_rule_id=$2 FOR UPDATE`, id, ruleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Baseline{}, ErrNotFound
+		}
+		return Baseline{}, err
+	}
+	if rec.Status != BaselineStatusPending {
+		return Baseline{}, ErrBaselineNotPending
+	}
+	if err := supersedeActiveBaseline(ctx, tx, rec); err != nil {
+		return Baseline{}, err
+	}
+	approved, err := scanBaseline(tx.QueryRow(ctx, `UPDATE baselines SET status='ACTIVE', approved_at=now() WHERE id=$1 RETURNING `+baselineColumns, id))
+	if err != nil {
+		return Baseline{}, err
+	}
+	return approved, tx.Commit(ctx)
+}
+
+func supersedeActiveBaseline(ctx context.Context, tx pgx.Tx, rec Baseline) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE baselines SET status='SUPERSEDED'
+		WHERE ui_rule_id=$1 AND parameter_name=$2 AND detector_type=$3 AND status='ACTIVE'`,
+		rec.UIRuleID, rec.ParameterName, rec.DetectorType)
+	return err
+}
+
+func scanBaseline(row scanner) (Baseline, error) {
+	var rec Baseline
+	err := row.Scan(&rec.ID, &rec.UIRuleID, &rec.ParameterName, &rec.DetectorType, &rec.Status, &rec.Source, &rec.Mu, &rec.Sigma, &rec.RBar, &rec.Median, &rec.MAD, &rec.N, &rec.SubgroupCount, &rec.WindowStart, &rec.WindowEnd, &rec.OrderStart, &rec.OrderEnd, &rec.DataHash, &rec.CreatedAt, &rec.ApprovedAt)
+	return rec, err
+}
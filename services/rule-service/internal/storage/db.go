@@ -33,3 +33,5 @@ func (s *Store) Close() {
 }
 
 var ErrNotFound = errors.New("not found")
+
+var ErrAlertState = errors.New("invalid alert state transition")
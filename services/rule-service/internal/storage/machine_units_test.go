@@ -68,7 +68,10 @@ func ensureMachineUnitSchema(t *testing.T, repo *Repository) {
 		connection_ref uuid NOT NULL REFERENCES db_connections(id),
 		selected_table text NOT NULL,
 		timestamp_column text NOT NULL DEFAULT '',
+		ordering_column text NOT NULL DEFAULT '',
+		row_filter jsonb,
 		selected_columns jsonb NOT NULL DEFAULT '[]'::jsonb,
+		derived_parameters jsonb NOT NULL DEFAULT '[]'::jsonb,
 		live_parameters jsonb NOT NULL DEFAULT '[]'::jsonb,
 		rule_ids jsonb NOT NULL DEFAULT '[]'::jsonb,
 		created_at timestamptz NOT NULL DEFAULT now(),
@@ -25,6 +25,7 @@ type RuleRecord struct {
 	ParameterName   string
 	RuleJSON        []byte
 	Enabled         bool
+	Shadow          bool
 	Status          string
 	LastError       []byte
 	LastValidatedAt *time.Time
@@ -35,6 +36,9 @@ type RuleRecord struct {
 type AlertRecord struct {
 	ID             int64
 	RuleID         string
+	UIRuleID       string
+	UnitID         string
+	UnitIDs        []string
 	TSUTC          time.Time
 	ParameterName  string
 	ObservedValue  string
@@ -47,31 +51,85 @@ type AlertRecord struct {
 	Hit            bool
 	Treated        bool
 	Metadata       []byte
+	State          string
+	OpenedAt       *time.Time
+	LastSeenAt     *time.Time
+	AcknowledgedAt *time.Time
+	ResolvedAt     *time.Time
+	Occurrences    int
+}
+
+type RuleRunRecord struct {
+	ID            int64
+	RuleID        string
+	ParameterName string
+	DetectorType  string
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	DurationMS    int64
+	Status        string
+	Error         *string
+	SampleCount   int
 }
 
 type MachineUnit struct {
+	UnitID            string
+	UnitName          string
+	ConnectionRef     string
+	SelectedTable     string
+	TimestampColumn   string
+	OrderingColumn    string
+	RowFilter         json.RawMessage
+	SelectedColumns   []string
+	DerivedParameters []DerivedParameter
+	LiveParameters    json.RawMessage
+	RuleIDs           []string
+	PosX              float64
+	PosY              float64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type DerivedParameter struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+type StepperRule struct {
+	ID              string
 	UnitID          string
-	UnitName        string
-	ConnectionRef   string
-	SelectedTable   string
-	TimestampColumn string
-	SelectedColumns []string
-	LiveParameters  json.RawMessage
-	RuleIDs         []string
-	PosX            float64
-	PosY            float64
+	Name            string
+	RuleType        string
+	ParameterID     string
+	Config          json.RawMessage
+	Enabled         bool
+	Shadow          bool
+	Status          string
+	LastError       json.RawMessage
+	LastValidatedAt *time.Time
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
 
-type StepperRule struct {
-	ID          string
-	UnitID      string
-	Name        string
-	RuleType    string
-	ParameterID string
-	Config      json.RawMessage
-	Enabled     bool
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+type Baseline struct {
+	ID            int64
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	Status        string
+	Source        string
+	Mu            *float64
+	Sigma         *float64
+	RBar          *float64
+	Median        *float64
+	MAD           *float64
+	N             int
+	SubgroupCount int
+	WindowStart   *time.Time
+	WindowEnd     *time.Time
+	OrderStart    *int64
+	OrderEnd      *int64
+	DataHash      string
+	CreatedAt     time.Time
+	ApprovedAt    *time.Time
 }
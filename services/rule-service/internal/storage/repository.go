@@ -32,10 +32,10 @@ func (r *Repository) CreateConnection(ctx context.Context, conn DBConnection) (s
 
 func (r *Repository) GetRule(ctx context.Context, id string) (RuleRecord, error) {
 	row := r.Store.Pool.QueryRow(ctx, `
-		SELECT id, name, description, connection_ref, parameter_name, rule_json, enabled, status, last_error, last_validated_at, created_at, updated_at
+		SELECT id, name, description, connection_ref, parameter_name, rule_json, enabled, shadow, status, last_error, last_validated_at, created_at, updated_at
 		FROM rules WHERE id=$1`, id)
 	var rec RuleRecord
-	if err := row.Scan(&rec.ID, &rec.Name, &rec.Description, &rec.ConnectionRef, &rec.ParameterName, &rec.RuleJSON, &rec.Enabled, &rec.Status, &rec.LastError, &rec.LastValidatedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+	if err := row.Scan(&rec.ID, &rec.Name, &rec.Description, &rec.ConnectionRef, &rec.ParameterName, &rec.RuleJSON, &rec.Enabled, &rec.Shadow, &rec.Status, &rec.LastError, &rec.LastValidatedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
 		return RuleRecord{}, ErrNotFound
 	}
 	return rec, nil
@@ -43,7 +43,7 @@ func (r *Repository) GetRule(ctx context.Context, id string) (RuleRecord, error)
 
 func (r *Repository) ListRules(ctx context.Context) ([]RuleRecord, error) {
 	rows, err := r.Store.Pool.Query(ctx, `
-		SELECT id, name, description, connection_ref, parameter_name, rule_json, enabled, status, last_error, last_validated_at, created_at, updated_at
+		SELECT id, name, description, connection_ref, parameter_name, rule_json, enabled, shadow, status, last_error, last_validated_at, created_at, updated_at
 		FROM rules ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
@@ -52,7 +52,7 @@ func (r *Repository) ListRules(ctx context.Context) ([]RuleRecord, error) {
 	results := []RuleRecord{}
 	for rows.Next() {
 		var rec RuleRecord
-		if err := rows.Scan(&rec.ID, &rec.Name, &rec.Description, &rec.ConnectionRef, &rec.ParameterName, &rec.RuleJSON, &rec.Enabled, &rec.Status, &rec.LastError, &rec.LastValidatedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+		if err := rows.Scan(&rec.ID, &rec.Name, &rec.Description, &rec.ConnectionRef, &rec.ParameterName, &rec.RuleJSON, &rec.Enabled, &rec.Shadow, &rec.Status, &rec.LastError, &rec.LastValidatedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
 			return nil, err
 		}
 		results = append(results, rec)
@@ -73,25 +73,49 @@ func (r *Repository) CreateRule(ctx context.Context, rec RuleRecord) (string, er
 	return id, nil
 }
 
+// UpdateRule saves the rule. A change to the spec beyond its name,
+// description or enabled flag supersedes the rule's frozen baselines.
 func (r *Repository) UpdateRule(ctx context.Context, rec RuleRecord) error {
-	_, err := r.Store.Pool.Exec(ctx, `
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `
+		UPDATE baselines SET status='SUPERSEDED'
+		WHERE rule_id=$1 AND status IN ('ACTIVE','PENDING')
+		  AND EXISTS (SELECT 1 FROM rules WHERE id=$1 AND (rule_json - 'name' - 'description' - 'enabled') IS DISTINCT FROM ($2::jsonb - 'name' - 'description' - 'enabled'))`,
+		rec.ID, rec.RuleJSON); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
 		UPDATE rules
 		SET name=$1, description=$2, parameter_name=$3, rule_json=$4, enabled=$5, status=$6, last_error=$7, last_validated_at=$8, updated_at=now()
 		WHERE id=$9`,
 		rec.Name, rec.Description, rec.ParameterName, rec.RuleJSON, rec.Enabled, rec.Status, rec.LastError, rec.LastValidatedAt, rec.ID,
-	)
-	return err
+	); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-func (r *Repository) SetRuleEnabled(ctx context.Context, id string, enabled bool, status string) error {
-	_, err := r.Store.Pool.Exec(ctx, `UPDATE rules SET enabled=$1, status=$2, updated_at=now() WHERE id=$3`, enabled, status, id)
+func (r *Repository) SetRuleMode(ctx context.Context, id string, enabled, shadow bool, status string) error {
+	_, err := r.Store.Pool.Exec(ctx, `UPDATE rules SET enabled=$1, shadow=$2, status=$3, updated_at=now() WHERE id=$4`, enabled, shadow, status, id)
 	return err
 }
 
 func (r *Repository) ListAlerts(ctx context.Context, ruleID string) ([]AlertRecord, error) {
+	return r.listRuleAlerts(ctx, "alerts", ruleID)
+}
+
+func (r *Repository) ListShadowAlerts(ctx context.Context, ruleID string) ([]AlertRecord, error) {
+	return r.listRuleAlerts(ctx, "shadow_alerts", ruleID)
+}
+
+func (r *Repository) listRuleAlerts(ctx context.Context, table, ruleID string) ([]AlertRecord, error) {
 	rows, err := r.Store.Pool.Query(ctx, `
-		SELECT id, rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata
-		FROM alerts WHERE rule_id=$1 ORDER BY ts_utc DESC`, ruleID)
+		SELECT id, rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata, state, opened_at, last_seen_at, acknowledged_at, resolved_at, occurrences
+		FROM `+table+` WHERE rule_id=$1 ORDER BY ts_utc DESC`, ruleID)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +123,7 @@ func (r *Repository) ListAlerts(ctx context.Context, ruleID string) ([]AlertReco
 	results := []AlertRecord{}
 	for rows.Next() {
 		var rec AlertRecord
-		if err := rows.Scan(&rec.ID, &rec.RuleID, &rec.TSUTC, &rec.ParameterName, &rec.ObservedValue, &rec.LimitExpr, &rec.DetectorType, &rec.Severity, &rec.AnomalyScore, &rec.BaselineMedian, &rec.BaselineMAD, &rec.Hit, &rec.Treated, &rec.Metadata); err != nil {
+		if err := rows.Scan(&rec.ID, &rec.RuleID, &rec.TSUTC, &rec.ParameterName, &rec.ObservedValue, &rec.LimitExpr, &rec.DetectorType, &rec.Severity, &rec.AnomalyScore, &rec.BaselineMedian, &rec.BaselineMAD, &rec.Hit, &rec.Treated, &rec.Metadata, &rec.State, &rec.OpenedAt, &rec.LastSeenAt, &rec.AcknowledgedAt, &rec.ResolvedAt, &rec.Occurrences); err != nil {
 			return nil, err
 		}
 		results = append(results, rec)
@@ -107,15 +131,33 @@ func (r *Repository) ListAlerts(ctx context.Context, ruleID string) ([]AlertReco
 	return results, nil
 }
 
-func (r *Repository) UpdateAlertTreated(ctx context.Context, alertID int64, treated bool) error {
-	_, err := r.Store.Pool.Exec(ctx, `UPDATE alerts SET treated=$1 WHERE id=$2`, treated, alertID)
-	return err
+func (r *Repository) AcknowledgeAlert(ctx context.Context, alertID int64) error {
+	return r.transitionAlert(ctx, alertID, `UPDATE alerts SET state='ACKNOWLEDGED', acknowledged_at=now(), treated=true WHERE id=$1 AND state='OPEN'`)
+}
+
+func (r *Repository) ResolveAlert(ctx context.Context, alertID int64) error {
+	return r.transitionAlert(ctx, alertID, `UPDATE alerts SET state='RESOLVED', resolved_at=now(), treated=true WHERE id=$1 AND state IN ('OPEN','ACKNOWLEDGED')`)
+}
+
+func (r *Repository) transitionAlert(ctx context.Context, alertID int64, query string) error {
+	tag, err := r.Store.Pool.Exec(ctx, query, alertID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+	var state string
+	if err := r.Store.Pool.QueryRow(ctx, `SELECT state FROM alerts WHERE id=$1`, alertID).Scan(&state); err != nil {
+		return ErrNotFound
+	}
+	return ErrAlertState
 }
 
 func (r *Repository) CreateAlert(ctx context.Context, alert AlertRecord) error {
 	_, err := r.Store.Pool.Exec(ctx, `
-		INSERT INTO alerts (rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		INSERT INTO alerts (rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata, opened_at, last_seen_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$2,$2)`,
 		alert.RuleID, alert.TSUTC, alert.ParameterName, alert.ObservedValue, alert.LimitExpr, alert.DetectorType, alert.Severity, alert.AnomalyScore, alert.BaselineMedian, alert.BaselineMAD, alert.Hit, alert.Treated, alert.Metadata)
 	return err
 }
@@ -152,6 +194,8 @@ func (r *Repository) ListRuleIDs(ctx context.Context, ids []string) (map[string]
 	return result, nil
 }
 
+const machineUnitColumns = `unit_id, unit_name, connection_ref, selected_table, timestamp_column, ordering_column, row_filter, selected_columns, derived_parameters, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`
+
 func (r *Repository) CreateMachineUnit(ctx context.Context, unit MachineUnit) (MachineUnit, error) {
 	selectedColumnsJSON, err := json.Marshal(unit.SelectedColumns)
 	if err != nil {
@@ -161,19 +205,23 @@ func (r *Repository) CreateMachineUnit(ctx context.Context, unit MachineUnit) (M
 	if err != nil {
 		return MachineUnit{}, err
 	}
+	derivedParamsJSON, err := json.Marshal(derivedParametersOrEmpty(unit.DerivedParameters))
+	if err != nil {
+		return MachineUnit{}, err
+	}
 	liveParamsJSON := normalizeRawJSON(unit.LiveParameters)
 	row := r.Store.Pool.QueryRow(ctx, `
-		INSERT INTO machine_units (unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,now(),now())
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`,
-		unit.UnitID, unit.UnitName, unit.ConnectionRef, unit.SelectedTable, unit.TimestampColumn, selectedColumnsJSON, liveParamsJSON, ruleIDsJSON, unit.PosX, unit.PosY,
+		INSERT INTO machine_units (unit_id, unit_name, connection_ref, selected_table, timestamp_column, ordering_column, row_filter, selected_columns, derived_parameters, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,now(),now())
+		RETURNING `+machineUnitColumns,
+		unit.UnitID, unit.UnitName, unit.ConnectionRef, unit.SelectedTable, unit.TimestampColumn, unit.OrderingColumn, nullableJSON(unit.RowFilter), selectedColumnsJSON, derivedParamsJSON, liveParamsJSON, ruleIDsJSON, unit.PosX, unit.PosY,
 	)
 	return scanMachineUnit(row)
 }
 
 func (r *Repository) ListMachineUnits(ctx context.Context) ([]MachineUnit, error) {
 	rows, err := r.Store.Pool.Query(ctx, `
-		SELECT unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at
+		SELECT `+machineUnitColumns+`
 		FROM machine_units ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
@@ -192,7 +240,7 @@ func (r *Repository) ListMachineUnits(ctx context.Context) ([]MachineUnit, error
 
 func (r *Repository) GetMachineUnit(ctx context.Context, unitID string) (MachineUnit, error) {
 	row := r.Store.Pool.QueryRow(ctx, `
-		SELECT unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at
+		SELECT `+machineUnitColumns+`
 		FROM machine_units WHERE unit_id=$1`, unitID)
 	unit, err := scanMachineUnit(row)
 	if err != nil {
@@ -213,13 +261,17 @@ func (r *Repository) UpdateMachineUnit(ctx context.Context, unit MachineUnit) (M
 	if err != nil {
 		return MachineUnit{}, err
 	}
+	derivedParamsJSON, err := json.Marshal(derivedParametersOrEmpty(unit.DerivedParameters))
+	if err != nil {
+		return MachineUnit{}, err
+	}
 	liveParamsJSON := normalizeRawJSON(unit.LiveParameters)
 	row := r.Store.Pool.QueryRow(ctx, `
 		UPDATE machine_units
-		SET unit_name=$1, connection_ref=$2, selected_table=$3, timestamp_column=$4, selected_columns=$5, live_parameters=$6, rule_ids=$7, pos_x=$8, pos_y=$9, updated_at=now()
-		WHERE unit_id=$10
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`,
-		unit.UnitName, unit.ConnectionRef, unit.SelectedTable, unit.TimestampColumn, selectedColumnsJSON, liveParamsJSON, ruleIDsJSON, unit.PosX, unit.PosY, unit.UnitID,
+		SET unit_name=$1, connection_ref=$2, selected_table=$3, timestamp_column=$4, ordering_column=$5, row_filter=$6, selected_columns=$7, derived_parameters=$8, live_parameters=$9, rule_ids=$10, pos_x=$11, pos_y=$12, updated_at=now()
+		WHERE unit_id=$13
+		RETURNING `+machineUnitColumns,
+		unit.UnitName, unit.ConnectionRef, unit.SelectedTable, unit.TimestampColumn, unit.OrderingColumn, nullableJSON(unit.RowFilter), selectedColumnsJSON, derivedParamsJSON, liveParamsJSON, ruleIDsJSON, unit.PosX, unit.PosY, unit.UnitID,
 	)
 	updated, err := scanMachineUnit(row)
 	if err != nil {
@@ -228,15 +280,25 @@ func (r *Repository) UpdateMachineUnit(ctx context.Context, unit MachineUnit) (M
 	return updated, nil
 }
 
+// DeleteMachineUnit deletes the unit together with the notification
+// subscriptions filtered on it.
 func (r *Repository) DeleteMachineUnit(ctx context.Context, unitID string) error {
-	cmd, err := r.Store.Pool.Exec(ctx, `DELETE FROM machine_units WHERE unit_id=$1`, unitID)
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	cmd, err := tx.Exec(ctx, `DELETE FROM machine_units WHERE unit_id=$1`, unitID)
 	if err != nil {
 		return err
 	}
 	if cmd.RowsAffected() == 0 {
 		return ErrNotFound
 	}
-	return nil
+	if _, err := tx.Exec(ctx, `DELETE FROM notification_subscriptions WHERE unit_id=$1`, unitID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *Repository) UpdateRules(ctx context.Context, unitID string, add []string, remove []string) (MachineUnit, error) {
@@ -283,9 +345,9 @@ func (r *Repository) UpdateTable(ctx context.Context, unitID string, table strin
 
 	updatedRow := tx.QueryRow(ctx, `
 		UPDATE machine_units
-		SET selected_table=$1, timestamp_column=$2, selected_columns=$3, updated_at=now()
+		SET selected_table=$1, timestamp_column=$2, ordering_column='', row_filter=NULL, derived_parameters='[]'::jsonb, selected_columns=$3, updated_at=now()
 		WHERE unit_id=$4
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`,
+		RETURNING `+machineUnitColumns,
 		table, "", columnsJSON, unitID,
 	)
 	updated, err := scanMachineUnit(updatedRow)
@@ -302,7 +364,7 @@ func (r *Repository) UpdateConnection(ctx context.Context, unitID string, connec
 	row := r.Store.Pool.QueryRow(ctx, `
 		UPDATE machine_units SET connection_ref=$1, updated_at=now()
 		WHERE unit_id=$2
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`,
+		RETURNING `+machineUnitColumns,
 		connectionRef, unitID,
 	)
 	updated, err := scanMachineUnit(row)
@@ -316,7 +378,7 @@ func (r *Repository) UpdatePosition(ctx context.Context, unitID string, x float6
 	row := r.Store.Pool.QueryRow(ctx, `
 		UPDATE machine_units SET pos_x=$1, pos_y=$2, updated_at=now()
 		WHERE unit_id=$3
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`,
+		RETURNING `+machineUnitColumns,
 		x, y, unitID,
 	)
 	updated, err := scanMachineUnit(row)
@@ -351,7 +413,7 @@ func (r *Repository) updateMachineUnitJSONArrays(ctx context.Context, unitID str
 		return MachineUnit{}, err
 	}
 	updateQuery := `UPDATE machine_units SET ` + column + `=$1, updated_at=now() WHERE unit_id=$2
-		RETURNING unit_id, unit_name, connection_ref, selected_table, timestamp_column, selected_columns, live_parameters, rule_ids, pos_x, pos_y, created_at, updated_at`
+		RETURNING ` + machineUnitColumns
 	row := tx.QueryRow(ctx, updateQuery, updatedJSON, unitID)
 	unit, err := scanMachineUnit(row)
 	if err != nil {
@@ -394,9 +456,10 @@ func applyAddRemove(current []string, add []string, remove []string) []string {
 func scanMachineUnit(row pgx.Row) (MachineUnit, error) {
 	var unit MachineUnit
 	var selectedColumnsRaw []byte
+	var derivedParamsRaw []byte
 	var liveParamsRaw []byte
 	var ruleIDsRaw []byte
-	if err := row.Scan(&unit.UnitID, &unit.UnitName, &unit.ConnectionRef, &unit.SelectedTable, &unit.TimestampColumn, &selectedColumnsRaw, &liveParamsRaw, &ruleIDsRaw, &unit.PosX, &unit.PosY, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
+	if err := row.Scan(&unit.UnitID, &unit.UnitName, &unit.ConnectionRef, &unit.SelectedTable, &unit.TimestampColumn, &unit.OrderingColumn, &unit.RowFilter, &selectedColumnsRaw, &derivedParamsRaw, &liveParamsRaw, &ruleIDsRaw, &unit.PosX, &unit.PosY, &unit.CreatedAt, &unit.UpdatedAt); err != nil {
 		if err == pgx.ErrNoRows {
 			return MachineUnit{}, ErrNotFound
 		}
@@ -411,11 +474,24 @@ func scanMachineUnit(row pgx.Row) (MachineUnit, error) {
 		return MachineUnit{}, err
 	}
 	unit.SelectedColumns = selectedColumns
+	unit.DerivedParameters = []DerivedParameter{}
+	if len(derivedParamsRaw) > 0 {
+		if err := json.Unmarshal(derivedParamsRaw, &unit.DerivedParameters); err != nil {
+			return MachineUnit{}, err
+		}
+	}
 	unit.RuleIDs = ruleIDs
 	unit.LiveParameters = normalizeRawJSON(liveParamsRaw)
 	return unit, nil
 }
 
+func derivedParametersOrEmpty(params []DerivedParameter) []DerivedParameter {
+	if params == nil {
+		return []DerivedParameter{}
+	}
+	return params
+}
+
 func decodeStringArray(raw []byte) ([]string, error) {
 	if len(raw) == 0 {
 		return []string{}, nil
@@ -434,6 +510,13 @@ func normalizeRawJSON(raw []byte) []byte {
 	return raw
 }
 
+func nullableJSON(raw json.RawMessage) []byte {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
 func nowPtr() *time.Time {
 	now := time.Now().UTC()
 	return &now
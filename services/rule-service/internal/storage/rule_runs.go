@@ -0,0 +1,34 @@
+package storage
+
+import "context"
+
+func (r *Repository) ListRuleRuns(ctx context.Context, ruleID string, limit, offset int) ([]RuleRunRecord, int, error) {
+	return r.listRuns(ctx, "rule_id", ruleID, limit, offset)
+}
+
+func (r *Repository) ListStepperRuleRuns(ctx context.Context, ruleID string, limit, offset int) ([]RuleRunRecord, int, error) {
+	return r.listRuns(ctx, "ui_rule_id", ruleID, limit, offset)
+}
+
+func (r *Repository) listRuns(ctx context.Context, column, ruleID string, limit, offset int) ([]RuleRunRecord, int, error) {
+	var total int
+	if err := r.Store.Pool.QueryRow(ctx, `SELECT count(*) FROM rule_runs WHERE `+column+`=$1`, ruleID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.Store.Pool.Query(ctx, `
+		SELECT id, `+column+`, parameter_name, detector_type, started_at, finished_at, duration_ms, status, error, sample_count
+		FROM rule_runs WHERE `+column+`=$1 ORDER BY started_at DESC, id DESC LIMIT $2 OFFSET $3`, ruleID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	results := []RuleRunRecord{}
+	for rows.Next() {
+		var rec RuleRunRecord
+		if err := rows.Scan(&rec.ID, &rec.RuleID, &rec.ParameterName, &rec.DetectorType, &rec.StartedAt, &rec.FinishedAt, &rec.DurationMS, &rec.Status, &rec.Error, &rec.SampleCount); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, rec)
+	}
+	return results, total, rows.Err()
+}
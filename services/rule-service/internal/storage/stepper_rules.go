@@ -8,51 +8,110 @@ import (
 	"github.com/google/uuid"
 )
 
+const stepperRuleColumns = `id, unit_id, name, rule_type, parameter_id, config, enabled, shadow, status, last_error, last_validated_at, created_at, updated_at`
+
 type scanner interface {
 	Scan(dest ...any) error
 }
 
-func (r *Repository) CreateStepperRule(ctx context.Context, rec StepperRule) (StepperRule, error) {
+// CreateStepperRule inserts the rule and, when given, its initial ACTIVE
+// baseline in one transaction.
+func (r *Repository) CreateStepperRule(ctx context.Context, rec StepperRule, baseline *Baseline) (StepperRule, error) {
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return StepperRule{}, err
+	}
+	defer tx.Rollback(ctx)
 	id := uuid.NewString()
-	row := r.Store.Pool.QueryRow(ctx, `
-		INSERT INTO ui_rules (id, unit_id, name, rule_type, parameter_id, config, enabled, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,now(),now())
-		RETURNING id, unit_id, name, rule_type, parameter_id, config, enabled, created_at, updated_at`,
-		id, rec.UnitID, rec.Name, rec.RuleType, rec.ParameterID, rec.Config, rec.Enabled,
+	row := tx.QueryRow(ctx, `
+		INSERT INTO ui_rules (id, unit_id, name, rule_type, parameter_id, config, enabled, shadow, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,'DRAFT',now(),now())
+		RETURNING `+stepperRuleColumns,
+		id, rec.UnitID, rec.Name, rec.RuleType, rec.ParameterID, rec.Config, rec.Enabled, rec.Shadow,
 	)
-	return scanStepperRule(row)
+	created, err := scanStepperRule(row)
+	if err != nil {
+		return StepperRule{}, err
+	}
+	if baseline != nil {
+		initial := *baseline
+		initial.UIRuleID = created.ID
+		if _, err := insertBaseline(ctx, tx, initial); err != nil {
+			return StepperRule{}, err
+		}
+	}
+	return created, tx.Commit(ctx)
 }
 
+// UpdateStepperRule saves the rule. Changing its type, parameter or config
+// supersedes its active and pending baselines, which describe the old config;
+// the scheduler freezes a new one on the next clean evaluation.
 func (r *Repository) UpdateStepperRule(ctx context.Context, rec StepperRule) (StepperRule, error) {
-	row := r.Store.Pool.QueryRow(ctx, `
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return StepperRule{}, err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `
+		UPDATE baselines SET status='SUPERSEDED'
+		WHERE ui_rule_id=$1 AND status IN ('ACTIVE','PENDING')
+		  AND EXISTS (SELECT 1 FROM ui_rules WHERE id=$1 AND (rule_type, parameter_id, config) IS DISTINCT FROM ($2::text, $3::text, $4::jsonb))`,
+		rec.ID, rec.RuleType, rec.ParameterID, rec.Config); err != nil {
+		return StepperRule{}, err
+	}
+	row := tx.QueryRow(ctx, `
 		UPDATE ui_rules
-		SET name=$1, rule_type=$2, parameter_id=$3, config=$4, enabled=$5, updated_at=now()
-		WHERE id=$6
-		RETURNING id, unit_id, name, rule_type, parameter_id, config, enabled, created_at, updated_at`,
-		rec.Name, rec.RuleType, rec.ParameterID, rec.Config, rec.Enabled, rec.ID,
+		SET name=$1, rule_type=$2, parameter_id=$3, config=$4, enabled=$5, shadow=$6, status='DRAFT', last_error=NULL, last_validated_at=NULL, updated_at=now()
+		WHERE id=$7
+		RETURNING `+stepperRuleColumns,
+		rec.Name, rec.RuleType, rec.ParameterID, rec.Config, rec.Enabled, rec.Shadow, rec.ID,
 	)
-	return scanStepperRule(row)
+	updated, err := scanStepperRule(row)
+	if err != nil {
+		return StepperRule{}, err
+	}
+	return updated, tx.Commit(ctx)
 }
 
-func (r *Repository) SetStepperRuleEnabled(ctx context.Context, id string, enabled bool) error {
-	_, err := r.Store.Pool.Exec(ctx, `UPDATE ui_rules SET enabled=$1, updated_at=now() WHERE id=$2`, enabled, id)
-	return err
+func (r *Repository) SetStepperRuleMode(ctx context.Context, id string, enabled, shadow bool, status string) error {
+	tag, err := r.Store.Pool.Exec(ctx, `UPDATE ui_rules SET enabled=$1, shadow=$2, status=$3, updated_at=now() WHERE id=$4`, enabled, shadow, status, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
 }
 
+// DeleteStepperRule deletes the rule and its notification subscriptions and
+// resolves its open alerts. The alert rows are kept; their ui_rule_id is
+// cleared by the foreign key.
 func (r *Repository) DeleteStepperRule(ctx context.Context, id string) error {
-	_, err := r.Store.Pool.Exec(ctx, `DELETE FROM ui_rules WHERE id=$1`, id)
-	return err
+	tx, err := r.Store.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE alerts SET state='RESOLVED', resolved_at=now() WHERE ui_rule_id=$1 AND state IN ('OPEN','ACKNOWLEDGED')`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM notification_subscriptions WHERE rule_id=$1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM ui_rules WHERE id=$1`, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func (r *Repository) GetStepperRule(ctx context.Context, id string) (StepperRule, error) {
-	row := r.Store.Pool.QueryRow(ctx, `
-		SELECT id, unit_id, name, rule_type, parameter_id, config, enabled, created_at, updated_at
-		FROM ui_rules WHERE id=$1`, id)
+	row := r.Store.Pool.QueryRow(ctx, `SELECT `+stepperRuleColumns+` FROM ui_rules WHERE id=$1`, id)
 	return scanStepperRule(row)
 }
 
 func (r *Repository) ListStepperRules(ctx context.Context, unitID string) ([]StepperRule, error) {
-	query := `SELECT id, unit_id, name, rule_type, parameter_id, config, enabled, created_at, updated_at FROM ui_rules`
+	query := `SELECT ` + stepperRuleColumns + ` FROM ui_rules`
 	args := []any{}
 	if unitID != "" {
 		query += " WHERE unit_id=$1"
@@ -78,10 +137,14 @@ func (r *Repository) ListStepperRules(ctx context.Context, unitID string) ([]Ste
 func scanStepperRule(row scanner) (StepperRule, error) {
 	var rec StepperRule
 	var cfg json.RawMessage
-	if err := row.Scan(&rec.ID, &rec.UnitID, &rec.Name, &rec.RuleType, &rec.ParameterID, &cfg, &rec.Enabled, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
+	var lastError []byte
+	if err := row.Scan(&rec.ID, &rec.UnitID, &rec.Name, &rec.RuleType, &rec.ParameterID, &cfg, &rec.Enabled, &rec.Shadow, &rec.Status, &lastError, &rec.LastValidatedAt, &rec.CreatedAt, &rec.UpdatedAt); err != nil {
 		return StepperRule{}, ErrNotFound
 	}
 	rec.Config = cfg
+	if len(lastError) > 0 {
+		rec.LastError = lastError
+	}
 	if rec.CreatedAt.IsZero() {
 		rec.CreatedAt = time.Now().UTC()
 	}
@@ -90,3 +153,30 @@ func scanStepperRule(row scanner) (StepperRule, error) {
 	}
 	return rec, nil
 }
+
+func (r *Repository) ListStepperRuleAlerts(ctx context.Context, ruleID string) ([]AlertRecord, error) {
+	return r.listStepperRuleAlerts(ctx, "alerts", ruleID)
+}
+
+func (r *Repository) ListStepperRuleShadowAlerts(ctx context.Context, ruleID string) ([]AlertRecord, error) {
+	return r.listStepperRuleAlerts(ctx, "shadow_alerts", ruleID)
+}
+
+func (r *Repository) listStepperRuleAlerts(ctx context.Context, table, ruleID string) ([]AlertRecord, error) {
+	rows, err := r.Store.Pool.Query(ctx, `
+		SELECT id, ui_rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata, state, opened_at, last_seen_at, acknowledged_at, resolved_at, occurrences
+		FROM `+table+` WHERE ui_rule_id=$1 ORDER BY ts_utc DESC`, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []AlertRecord{}
+	for rows.Next() {
+		var rec AlertRecord
+		if err := rows.Scan(&rec.ID, &rec.UIRuleID, &rec.TSUTC, &rec.ParameterName, &rec.ObservedValue, &rec.LimitExpr, &rec.DetectorType, &rec.Severity, &rec.AnomalyScore, &rec.BaselineMedian, &rec.BaselineMAD, &rec.Hit, &rec.Treated, &rec.Metadata, &rec.State, &rec.OpenedAt, &rec.LastSeenAt, &rec.AcknowledgedAt, &rec.ResolvedAt, &rec.Occurrences); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, nil
+}
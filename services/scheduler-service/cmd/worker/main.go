@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"os/signal"
 	"strconv"
@@ -13,8 +14,10 @@ import (
 	"syscall"
 	"time"
 
+	"predixaai-backend/pkg/crypto"
 	"predixaai-backend/services/scheduler-service/internal/bus"
 	"predixaai-backend/services/scheduler-service/internal/mcp"
+	"predixaai-backend/services/scheduler-service/internal/notify"
 	"predixaai-backend/services/scheduler-service/internal/scheduler"
 	"predixaai-backend/services/scheduler-service/internal/security"
 	"predixaai-backend/services/scheduler-service/internal/storage"
@@ -32,6 +35,21 @@ func main() {
 	mcpConfigPath := getenv("MCP_CONFIG_PATH", "")
 	allowlistTables := splitCSV(getenv("ALLOWLIST_TABLES", ""))
 	limits := security.DefaultLimits()
+	key := getenv("ENCRYPTION_KEY", "")
+	if len(key) != 32 {
+		logger.Error("ENCRYPTION_KEY must be 32 bytes")
+		os.Exit(1)
+	}
+	enc, err := crypto.NewAesGcmEncryptor([]byte(key))
+	if err != nil {
+		logger.Error("failed to init encryptor", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	webhookAllow, err := parsePrefixes(splitCSV(getenv("NOTIFY_WEBHOOK_ALLOW_CIDRS", "")))
+	if err != nil {
+		logger.Error("invalid NOTIFY_WEBHOOK_ALLOW_CIDRS", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	store, err := storage.NewStore(ctx, dsn)
 	if err != nil {
@@ -47,6 +65,7 @@ func main() {
 		os.Exit(1)
 	}
 	defer subscriber.Close()
+	publisher := &bus.Publisher{Conn: subscriber.Conn}
 
 	adapterRegistry, err := buildAdapterRegistry(mcpConfigPath)
 	if err != nil {
@@ -58,6 +77,18 @@ func main() {
 	reg := scheduler.NewRegistry(repo, limits, workers, jobTimeout)
 	defer reg.Stop()
 
+	dispatcher := notify.NewDispatcher(repo)
+	dispatcher.MaxAttempts = getenvInt("NOTIFY_MAX_ATTEMPTS", dispatcher.MaxAttempts)
+	dispatcher.Client = notify.NewWebhookClient(webhookAllow)
+	dispatcher.Secrets = enc
+	dispatcher.Logger = logger
+	notifyCtx, stopNotify := context.WithCancel(ctx)
+	defer stopNotify()
+	go dispatcher.Run(notifyCtx)
+	reg.SetNotifier(dispatcher)
+	reg.SetPublisher(publisher)
+	reg.SetLogger(logger)
+
 	if err := reconcile(ctx, repo, reg, adapterRegistry, allowlist, limits); err != nil {
 		logger.Error("reconcile error", slog.String("error", err.Error()))
 	}
@@ -72,20 +103,28 @@ func main() {
 }
 
 func subscribeEvents(sub *bus.Subscriber, repo *storage.Repository, reg *scheduler.Registry, registry *mcp.AdapterRegistry, allowlist security.Allowlist, limits security.Limits, logger *slog.Logger) {
-	subscribe := func(subject string) {
+	type processFunc func(context.Context, *storage.Repository, *scheduler.Registry, *mcp.AdapterRegistry, security.Allowlist, security.Limits, string) error
+	subscribe := func(subject string, process processFunc) {
 		_, _ = sub.Subscribe(subject, func(evt bus.Event) {
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer cancel()
-			if err := processRule(ctx, repo, reg, registry, allowlist, limits, evt.RuleID); err != nil {
+			if err := process(ctx, repo, reg, registry, allowlist, limits, evt.RuleID); err != nil {
 				logger.Error("rule event processing failed", slog.String("subject", subject), slog.String("error", err.Error()))
 			}
 		})
 	}
-	subscribe("rule.created")
-	subscribe("rule.updated")
-	subscribe("rule.enabled")
-	subscribe("rule.disabled")
-	subscribe("rule.deleted")
+	subscribe("rule.created", processRule)
+	subscribe("rule.updated", processRule)
+	subscribe("rule.enabled", processRule)
+	subscribe("rule.shadowed", processRule)
+	subscribe("rule.disabled", processRule)
+	subscribe("rule.deleted", processRule)
+	subscribe("ui_rule.created", processStepperRule)
+	subscribe("ui_rule.updated", processStepperRule)
+	subscribe("ui_rule.enabled", processStepperRule)
+	subscribe("ui_rule.shadowed", processStepperRule)
+	subscribe("ui_rule.disabled", processStepperRule)
+	subscribe("ui_rule.deleted", processStepperRule)
 }
 
 func startAdminServer(port string, repo *storage.Repository, reg *scheduler.Registry, registry *mcp.AdapterRegistry, allowlist security.Allowlist, limits security.Limits, logger *slog.Logger) {
@@ -140,7 +179,7 @@ func reconcile(ctx context.Context, repo *storage.Repository, reg *scheduler.Reg
 	for _, rec := range rulesList {
 		_ = processRule(ctx, repo, reg, registry, allowlist, limits, rec.ID)
 	}
-	return nil
+	return reconcileStepperRules(ctx, repo, reg, registry, allowlist, limits)
 }
 
 func processRule(ctx context.Context, repo *storage.Repository, reg *scheduler.Registry, registry *mcp.AdapterRegistry, allowlist security.Allowlist, limits security.Limits, ruleID string) error {
@@ -154,41 +193,49 @@ func processRule(ctx context.Context, repo *storage.Repository, reg *scheduler.R
 	}
 	var spec scheduler.RuleSpec
 	if err := json.Unmarshal(rec.RuleJSON, &spec); err != nil {
-		_ = repo.UpdateRuleStatus(ctx, ruleID, "INVALID", []byte(`{"error":"invalid rule json"}`))
+		setRuleStatus(ctx, repo, reg, ruleID, "INVALID", []byte(`{"error":"invalid rule json"}`))
 		return err
 	}
 	connType, err := repo.GetConnectionType(ctx, spec.ConnectionRef)
 	if err != nil {
 		errJSON, _ := json.Marshal(map[string]any{"error": "connection not found"})
-		_ = repo.UpdateRuleStatus(ctx, ruleID, "INVALID", errJSON)
+		setRuleStatus(ctx, repo, reg, ruleID, "INVALID", errJSON)
 		reg.Unschedule(ruleID)
 		return err
 	}
 	if registry == nil {
 		err := errors.New("adapter registry not configured")
 		errJSON, _ := json.Marshal(map[string]any{"error": err.Error()})
-		_ = repo.UpdateRuleStatus(ctx, ruleID, "INVALID", errJSON)
+		setRuleStatus(ctx, repo, reg, ruleID, "INVALID", errJSON)
 		reg.Unschedule(ruleID)
 		return err
 	}
 	adapter, err := registry.AdapterFor(connType)
 	if err != nil {
 		errJSON, _ := json.Marshal(map[string]any{"error": err.Error()})
-		_ = repo.UpdateRuleStatus(ctx, ruleID, "INVALID", errJSON)
+		setRuleStatus(ctx, repo, reg, ruleID, "INVALID", errJSON)
 		reg.Unschedule(ruleID)
 		return err
 	}
 	if err := validation.RuntimeValidateRule(ctx, adapter, spec, allowlist, limits); err != nil {
 		errJSON, _ := json.Marshal(map[string]any{"error": err.Error()})
-		_ = repo.UpdateRuleStatus(ctx, ruleID, "INVALID", errJSON)
+		setRuleStatus(ctx, repo, reg, ruleID, "INVALID", errJSON)
 		reg.Unschedule(ruleID)
 		return err
 	}
-	_ = repo.UpdateRuleStatus(ctx, ruleID, "ACTIVE", nil)
-	reg.Schedule(ruleID, spec, adapter)
+	setRuleStatus(ctx, repo, reg, ruleID, "ACTIVE", nil)
+	reg.Schedule(ruleID, spec, adapter, rec.Shadow)
 	return nil
 }
 
+func setRuleStatus(ctx context.Context, repo *storage.Repository, reg *scheduler.Registry, ruleID, status string, lastError []byte) {
+	previous, err := repo.UpdateRuleStatus(ctx, ruleID, status, lastError)
+	if err != nil {
+		return
+	}
+	reg.PublishRuleStatus(ruleID, false, previous, status, lastError)
+}
+
 func getenv(key, fallback string) string {
 	val := os.Getenv(key)
 	if val == "" {
@@ -221,6 +268,18 @@ func splitCSV(value string) []string {
 	return results
 }
 
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
 func buildAdapterRegistry(configPath string) (*mcp.AdapterRegistry, error) {
 	if configPath != "" {
 		cfg, err := mcp.LoadConfig(configPath)
@@ -56,6 +56,52 @@ func registerStepperHandlers(mux *http.ServeMux, repo *storage.Repository, regis
 		}
 		writeAdminJSON(w, http.StatusOK, resp)
 	})
+
+	mux.HandleFunc("/api/rules/capability", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req scheduler.StepperCapabilityRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+		adapter, err := adapterForConnection(r, repo, registry, req.ConnectionRef)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp, err := scheduler.StepperCapability(r.Context(), adapter, allowlist, limits, req)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("/api/rules/backtest", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req scheduler.StepperBacktestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeAdminError(w, http.StatusBadRequest, "invalid payload")
+			return
+		}
+		adapter, err := adapterForConnection(r, repo, registry, req.ConnectionRef)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp, err := scheduler.StepperBacktest(r.Context(), adapter, allowlist, limits, req)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, resp)
+	})
 }
 
 func adapterForConnection(r *http.Request, repo *storage.Repository, registry *mcp.AdapterRegistry, connectionRef string) (mcp.DbMcpAdapter, error) {
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+	"predixaai-backend/services/scheduler-service/internal/scheduler"
+	"predixaai-backend/services/scheduler-service/internal/security"
+	"predixaai-backend/services/scheduler-service/internal/storage"
+	"predixaai-backend/services/scheduler-service/internal/validation"
+)
+
+func reconcileStepperRules(ctx context.Context, repo *storage.Repository, reg *scheduler.Registry, registry *mcp.AdapterRegistry, allowlist security.Allowlist, limits security.Limits) error {
+	rulesList, err := repo.ListEnabledStepperRules(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rec := range rulesList {
+		_ = processStepperRule(ctx, repo, reg, registry, allowlist, limits, rec.ID)
+	}
+	return nil
+}
+
+func processStepperRule(ctx context.Context, repo *storage.Repository, reg *scheduler.Registry, registry *mcp.AdapterRegistry, allowlist security.Allowlist, limits security.Limits, ruleID string) error {
+	rec, err := repo.GetStepperRule(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			reg.Unschedule(ruleID)
+			return nil
+		}
+		return err
+	}
+	if !rec.Enabled {
+		reg.Unschedule(ruleID)
+		return nil
+	}
+	markInvalid := func(err error) error {
+		errJSON, _ := json.Marshal(map[string]any{"error": err.Error()})
+		setStepperRuleStatus(ctx, repo, reg, ruleID, "INVALID", errJSON)
+		reg.Unschedule(ruleID)
+		return err
+	}
+	connType, err := repo.GetConnectionType(ctx, rec.ConnectionRef)
+	if err != nil {
+		_ = markInvalid(errors.New("connection not found"))
+		return err
+	}
+	if registry == nil {
+		return markInvalid(errors.New("adapter registry not configured"))
+	}
+	adapter, err := registry.AdapterFor(connType)
+	if err != nil {
+		return markInvalid(err)
+	}
+	timestampColumn := rec.TimestampColumn
+	if timestampColumn == "" && rec.OrderingColumn == "" {
+		timestampColumn, err = scheduler.ResolveStepperTimestampColumn(ctx, adapter, rec.ConnectionRef, rec.Table)
+		if err != nil {
+			return markInvalid(err)
+		}
+	}
+	spec, err := scheduler.BuildStepperRuleSpec(scheduler.StepperRuleDefinition{
+		RuleID:            rec.ID,
+		Name:              rec.Name,
+		RuleType:          rec.RuleType,
+		ParameterID:       rec.ParameterID,
+		Config:            rec.Config,
+		ConnectionRef:     rec.ConnectionRef,
+		Table:             rec.Table,
+		TimestampColumn:   timestampColumn,
+		OrderingColumn:    rec.OrderingColumn,
+		RowFilter:         rec.RowFilter,
+		DerivedParameters: rec.DerivedParameters,
+	})
+	if err != nil {
+		return markInvalid(err)
+	}
+	if err := validation.RuntimeValidateRule(ctx, adapter, spec, allowlist, limits); err != nil {
+		return markInvalid(err)
+	}
+	setStepperRuleStatus(ctx, repo, reg, ruleID, "ACTIVE", nil)
+	reg.ScheduleStepper(ruleID, spec, adapter, rec.Shadow)
+	return nil
+}
+
+func setStepperRuleStatus(ctx context.Context, repo *storage.Repository, reg *scheduler.Registry, ruleID, status string, lastError []byte) {
+	previous, err := repo.UpdateStepperRuleStatus(ctx, ruleID, status, lastError)
+	if err != nil {
+		return
+	}
+	reg.PublishRuleStatus(ruleID, true, previous, status, lastError)
+}
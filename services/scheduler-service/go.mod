@@ -6,6 +6,9 @@ require (
 	github.com/jackc/pgx/v5 v5.6.0
 	github.com/nats-io/nats.go v1.37.0
 	gopkg.in/yaml.v3 v3.0.1
+	predixaai-backend/pkg/crypto v0.0.0
+	predixaai-backend/pkg/events v0.0.0
+	predixaai-backend/pkg/expression v0.0.0
 )
 
 require (
@@ -20,3 +23,9 @@ require (
 	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
+
+replace (
+	predixaai-backend/pkg/crypto => ../../pkg/crypto
+	predixaai-backend/pkg/events => ../../pkg/events
+	predixaai-backend/pkg/expression => ../../pkg/expression
+)
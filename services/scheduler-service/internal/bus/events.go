@@ -0,0 +1,92 @@
+package bus
+
+import (
+	"encoding/json"
+	"time"
+
+	"predixaai-backend/pkg/events"
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+// The payloads are defined in pkg/events so rule-service decodes exactly what
+// the scheduler publishes.
+const SchemaVersion = events.SchemaVersion
+
+const (
+	SubjectAlertCreated      = events.SubjectAlertCreated
+	SubjectAlertUpdated      = events.SubjectAlertUpdated
+	SubjectAlertResolved     = events.SubjectAlertResolved
+	SubjectRuleStatusChanged = events.SubjectRuleStatusChanged
+)
+
+type (
+	AlertMessage      = events.AlertMessage
+	RuleStatusMessage = events.RuleStatusMessage
+)
+
+func NewAlertCreated(alertID int64, alert storage.AlertRecord, now time.Time) AlertMessage {
+	return newAlertMessage(SubjectAlertCreated, alertID, alert, now)
+}
+
+// NewAlertUpdated announces a repeat occurrence of an open alert; ts and the
+// observed value are those of the latest occurrence.
+func NewAlertUpdated(alertID int64, alert storage.AlertRecord, now time.Time) AlertMessage {
+	return newAlertMessage(SubjectAlertUpdated, alertID, alert, now)
+}
+
+func newAlertMessage(event string, alertID int64, alert storage.AlertRecord, now time.Time) AlertMessage {
+	ts := alert.TSUTC
+	msg := AlertMessage{
+		SchemaVersion:   SchemaVersion,
+		Event:           event,
+		EmittedAt:       now.UTC(),
+		AlertID:         alertID,
+		RuleID:          alert.RuleID,
+		UIRuleID:        alert.UIRuleID,
+		ParameterName:   alert.ParameterName,
+		DetectorType:    alert.DetectorType,
+		Severity:        alert.Severity,
+		ObservedValue:   alert.ObservedValue,
+		LimitExpression: alert.LimitExpr,
+		TS:              &ts,
+	}
+	if json.Valid(alert.Metadata) {
+		msg.Metadata = alert.Metadata
+	}
+	return msg
+}
+
+func NewAlertResolved(resolved storage.ResolvedAlert, now time.Time) AlertMessage {
+	resolvedAt := resolved.ResolvedAt.UTC()
+	return AlertMessage{
+		SchemaVersion: SchemaVersion,
+		Event:         SubjectAlertResolved,
+		EmittedAt:     now.UTC(),
+		AlertID:       resolved.ID,
+		RuleID:        resolved.RuleID,
+		UIRuleID:      resolved.UIRuleID,
+		ParameterName: resolved.ParameterName,
+		DetectorType:  resolved.DetectorType,
+		Severity:      resolved.Severity,
+		ResolvedAt:    &resolvedAt,
+	}
+}
+
+func NewRuleStatusChanged(ruleID string, stepper bool, previous, status string, lastError []byte, now time.Time) RuleStatusMessage {
+	msg := RuleStatusMessage{
+		SchemaVersion:  SchemaVersion,
+		Event:          SubjectRuleStatusChanged,
+		EmittedAt:      now.UTC(),
+		PreviousStatus: previous,
+		Status:         status,
+	}
+	if stepper {
+		msg.UIRuleID = ruleID
+	} else {
+		msg.RuleID = ruleID
+	}
+	if json.Valid(lastError) {
+		msg.LastError = lastError
+	}
+	return msg
+}
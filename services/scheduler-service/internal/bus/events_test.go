@@ -0,0 +1,139 @@
+package bus
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+func TestAlertCreatedSchema(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 5, 0, time.UTC)
+	msg := NewAlertCreated(42, storage.AlertRecord{
+		UIRuleID:      "rule-1",
+		TSUTC:         now.Add(-5 * time.Second),
+		ParameterName: "rf_power",
+		ObservedValue: "812.5",
+		LimitExpr:     "x > 800",
+		DetectorType:  "THRESHOLD",
+		Severity:      "high",
+		Metadata:      []byte(`{"table":"etch"}`),
+	}, now)
+	got := encode(t, msg)
+	want := map[string]any{
+		"schemaVersion":   float64(SchemaVersion),
+		"event":           "alert.created",
+		"emittedAt":       "2026-03-04T10:00:05Z",
+		"alertId":         float64(42),
+		"uiRuleId":        "rule-1",
+		"parameterName":   "rf_power",
+		"detectorType":    "THRESHOLD",
+		"severity":        "high",
+		"observedValue":   "812.5",
+		"limitExpression": "x > 800",
+		"ts":              "2026-03-04T10:00:00Z",
+		"metadata":        map[string]any{"table": "etch"},
+	}
+	assertFields(t, got, want)
+}
+
+func TestAlertUpdatedSchema(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 1, 0, 0, time.UTC)
+	got := encode(t, NewAlertUpdated(42, storage.AlertRecord{
+		UIRuleID:      "rule-1",
+		TSUTC:         now.Add(-time.Second),
+		ParameterName: "rf_power",
+		ObservedValue: "815",
+		DetectorType:  "THRESHOLD",
+		Severity:      "high",
+	}, now))
+	want := map[string]any{
+		"schemaVersion": float64(SchemaVersion),
+		"event":         "alert.updated",
+		"emittedAt":     "2026-03-04T10:01:00Z",
+		"alertId":       float64(42),
+		"uiRuleId":      "rule-1",
+		"parameterName": "rf_power",
+		"detectorType":  "THRESHOLD",
+		"severity":      "high",
+		"observedValue": "815",
+		"ts":            "2026-03-04T10:00:59Z",
+	}
+	assertFields(t, got, want)
+}
+
+func TestAlertResolvedSchema(t *testing.T) {
+	now := time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)
+	msg := NewAlertResolved(storage.ResolvedAlert{
+		ID:            42,
+		RuleID:        "rule-2",
+		ParameterName: "pressure",
+		DetectorType:  "SHEWHART_3SIGMA",
+		Severity:      "medium",
+		ResolvedAt:    now.Add(-time.Second),
+	}, now)
+	got := encode(t, msg)
+	want := map[string]any{
+		"schemaVersion": float64(SchemaVersion),
+		"event":         "alert.resolved",
+		"emittedAt":     "2026-03-04T11:00:00Z",
+		"alertId":       float64(42),
+		"ruleId":        "rule-2",
+		"parameterName": "pressure",
+		"detectorType":  "SHEWHART_3SIGMA",
+		"severity":      "medium",
+		"resolvedAt":    "2026-03-04T10:59:59Z",
+	}
+	assertFields(t, got, want)
+}
+
+func TestRuleStatusChangedSchema(t *testing.T) {
+	now := time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC)
+	got := encode(t, NewRuleStatusChanged("rule-3", true, "ACTIVE", "INVALID", []byte(`{"error":"connection not found"}`), now))
+	want := map[string]any{
+		"schemaVersion":  float64(SchemaVersion),
+		"event":          "rule.status_changed",
+		"emittedAt":      "2026-03-04T12:00:00Z",
+		"uiRuleId":       "rule-3",
+		"previousStatus": "ACTIVE",
+		"status":         "INVALID",
+		"lastError":      map[string]any{"error": "connection not found"},
+	}
+	assertFields(t, got, want)
+
+	got = encode(t, NewRuleStatusChanged("rule-4", false, "DRAFT", "ACTIVE", nil, now))
+	if got["ruleId"] != "rule-4" {
+		t.Fatalf("expected ruleId, got %v", got)
+	}
+	if _, ok := got["lastError"]; ok {
+		t.Fatalf("expected lastError to be omitted, got %v", got)
+	}
+}
+
+func encode(t *testing.T, msg any) map[string]any {
+	t.Helper()
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+func assertFields(t *testing.T, got, want map[string]any) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("expected fields %v, got %v", want, got)
+	}
+	for key, value := range want {
+		gotJSON, _ := json.Marshal(got[key])
+		wantJSON, _ := json.Marshal(value)
+		if string(gotJSON) != string(wantJSON) {
+			t.Fatalf("field %s: expected %s, got %s", key, wantJSON, gotJSON)
+		}
+	}
+}
@@ -36,3 +36,18 @@ func (s *Subscriber) Subscribe(subject string, handler func(Event)) (*nats.Subsc
 		handler(evt)
 	})
 }
+
+type Publisher struct {
+	Conn *nats.Conn
+}
+
+func (p *Publisher) Publish(subject string, payload any) error {
+	if p == nil || p.Conn == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.Conn.Publish(subject, data)
+}
@@ -57,6 +57,14 @@ type FetchRecentRowsRequest struct {
 	Where           *WhereSpec `json:"where"`
 	Since           string     `json:"since"`
 	Limit           int        `json:"limit"`
+	OrderColumn     string     `json:"orderColumn,omitempty"`
+	MinOrder        *int64     `json:"minOrder,omitempty"`
+	MaxOrder        *int64     `json:"maxOrder,omitempty"`
+	Until           string     `json:"until,omitempty"`
+	Ascending       bool       `json:"ascending,omitempty"`
+	KeyColumn       string     `json:"keyColumn,omitempty"`
+	AfterTS         string     `json:"afterTs,omitempty"`
+	AfterKey        *int64     `json:"afterKey,omitempty"`
 }
 
 type FetchRecentRowsResult struct {
@@ -8,6 +8,7 @@ type MockAdapter struct {
 	LatestResult LatestValueResult
 	AggResult    AggregateResult
 	RecentRows   FetchRecentRowsResult
+	Requests     []FetchRecentRowsRequest
 	Err          error
 }
 
@@ -35,5 +36,6 @@ func (m *MockAdapter) QueryAggregate(ctx context.Context, req AggregateRequest)
 }
 
 func (m *MockAdapter) FetchRecentRows(ctx context.Context, req FetchRecentRowsRequest) (FetchRecentRowsResult, error) {
+	m.Requests = append(m.Requests, req)
 	return m.RecentRows, m.Err
 }
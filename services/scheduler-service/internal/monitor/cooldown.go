@@ -3,5 +3,9 @@ package monitor
 import "time"
 
 func WithinCooldown(last time.Time, cooldownSeconds int) bool {
-	return time.Since(last) < time.Duration(cooldownSeconds)*time.Second
+	return WithinCooldownAt(last, time.Now(), cooldownSeconds)
+}
+
+func WithinCooldownAt(last, now time.Time, cooldownSeconds int) bool {
+	return now.Sub(last) < time.Duration(cooldownSeconds)*time.Second
 }
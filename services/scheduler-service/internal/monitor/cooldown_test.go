@@ -11,3 +11,13 @@ func TestWithinCooldown(t *testing.T) {
 		t.Fatalf("expected within cooldown")
 	}
 }
+
+func TestWithinCooldownAt(t *testing.T) {
+	last := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !WithinCooldownAt(last, last.Add(9*time.Second), 10) {
+		t.Fatalf("expected within cooldown")
+	}
+	if WithinCooldownAt(last, last.Add(10*time.Second), 10) {
+		t.Fatalf("expected cooldown to have elapsed")
+	}
+}
@@ -0,0 +1,333 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"predixaai-backend/pkg/crypto"
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+const (
+	EventAlertCreated = "alert.created"
+
+	defaultMaxAttempts  = 6
+	defaultBaseBackoff  = 5 * time.Second
+	defaultMaxBackoff   = 10 * time.Minute
+	defaultPollInterval = 2 * time.Second
+	defaultBatchSize    = 50
+	defaultSendTimeout  = 10 * time.Second
+)
+
+// AlertEvent is the payload delivered to channels and the data webhook
+// templates are rendered with.
+type AlertEvent struct {
+	Event           string          `json:"event"`
+	AlertID         int64           `json:"alertId"`
+	RuleID          string          `json:"ruleId,omitempty"`
+	UIRuleID        string          `json:"uiRuleId,omitempty"`
+	UnitID          string          `json:"unitId,omitempty"`
+	UnitName        string          `json:"unitName,omitempty"`
+	ParameterName   string          `json:"parameterName"`
+	DetectorType    string          `json:"detectorType"`
+	Severity        string          `json:"severity"`
+	ObservedValue   string          `json:"observedValue"`
+	LimitExpression string          `json:"limitExpression"`
+	TS              time.Time       `json:"ts"`
+	Metadata        json.RawMessage `json:"metadata,omitempty"`
+}
+
+func NewAlertEvent(event string, alertID int64, alert storage.AlertRecord) AlertEvent {
+	return AlertEvent{
+		Event:           event,
+		AlertID:         alertID,
+		RuleID:          alert.RuleID,
+		UIRuleID:        alert.UIRuleID,
+		ParameterName:   alert.ParameterName,
+		DetectorType:    alert.DetectorType,
+		Severity:        alert.Severity,
+		ObservedValue:   alert.ObservedValue,
+		LimitExpression: alert.LimitExpr,
+		TS:              alert.TSUTC,
+		Metadata:        alert.Metadata,
+	}
+}
+
+func (e AlertEvent) ruleID() string {
+	if e.UIRuleID != "" {
+		return e.UIRuleID
+	}
+	return e.RuleID
+}
+
+// Store is the part of the repository the dispatcher needs.
+type Store interface {
+	GetRuleUnit(ctx context.Context, ruleID string) (string, string, error)
+	MatchNotificationChannels(ctx context.Context, ruleID, severity string) ([]storage.NotificationChannelRecord, error)
+	CreateNotificationDelivery(ctx context.Context, rec storage.NotificationDeliveryRecord) error
+	ClaimNotificationDeliveries(ctx context.Context, limit int, lease time.Duration) ([]storage.NotificationDeliveryRecord, error)
+	RecordNotificationAttempt(ctx context.Context, attempt storage.NotificationAttempt) error
+}
+
+// Dispatcher queues alert events for every subscribed channel in
+// notification_deliveries and delivers them in the background, retrying
+// failures with exponential backoff until MaxAttempts is reached. Deliveries
+// to digest email channels are held until the digest is due and then sent
+// together. Channel secrets are stored encrypted by rule-service and are
+// decrypted with Secrets just before sending.
+type Dispatcher struct {
+	Store        Store
+	Client       *http.Client
+	Secrets      crypto.Encryptor
+	Logger       *slog.Logger
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	BatchSize    int
+	Now          func() time.Time
+	wake         chan struct{}
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		Store:        store,
+		Client:       NewWebhookClient(nil),
+		Logger:       slog.Default(),
+		MaxAttempts:  defaultMaxAttempts,
+		BaseBackoff:  defaultBaseBackoff,
+		MaxBackoff:   defaultMaxBackoff,
+		PollInterval: defaultPollInterval,
+		BatchSize:    defaultBatchSize,
+		Now:          time.Now,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Enqueue records a pending delivery of event for each matching channel and
+// wakes the delivery loop.
+func (d *Dispatcher) Enqueue(ctx context.Context, event AlertEvent) error {
+	channels, err := d.Store.MatchNotificationChannels(ctx, event.ruleID(), event.Severity)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+	if event.UnitID == "" {
+		if unitID, unitName, err := d.Store.GetRuleUnit(ctx, event.ruleID()); err == nil {
+			event.UnitID, event.UnitName = unitID, unitName
+		}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var alertID *int64
+	if event.AlertID > 0 {
+		alertID = &event.AlertID
+	}
+	for _, channel := range channels {
+		rec := storage.NotificationDeliveryRecord{ChannelID: channel.ID, AlertID: alertID, Event: event.Event, Payload: payload}
+		if digest := channelDigest(channel); digest != "" {
+			rec.NextAttemptAt = DigestDue(digest, d.Now())
+		}
+		if err := d.Store.CreateNotificationDelivery(ctx, rec); err != nil {
+			return err
+		}
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers due notifications until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		_ = d.DeliverDue(ctx)
+		select {
+		case <-ticker.C:
+		case <-d.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeliverDue claims one batch of due deliveries and attempts them. Due
+// deliveries of a digest channel go out as a single message. A failure to
+// record one attempt is logged and does not stop the rest of the batch; the
+// delivery is claimed again once its lease expires.
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	deliveries, err := d.Store.ClaimNotificationDeliveries(ctx, d.BatchSize, d.lease())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, batch := range groupDeliveries(deliveries) {
+		for _, attempt := range d.attempt(ctx, batch) {
+			if err := d.Store.RecordNotificationAttempt(ctx, attempt); err != nil {
+				d.Logger.Error("failed to record notification attempt", slog.Int64("deliveryId", attempt.DeliveryID), slog.String("status", attempt.Status), slog.String("error", err.Error()))
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, batch []storage.NotificationDeliveryRecord) []storage.NotificationAttempt {
+	now := d.Now().UTC()
+	code, err := d.send(ctx, batch, now)
+	attempts := make([]storage.NotificationAttempt, 0, len(batch))
+	for _, delivery := range batch {
+		attempt := storage.NotificationAttempt{DeliveryID: delivery.ID, Attempts: delivery.Attempts + 1, NextAttemptAt: now}
+		if code > 0 {
+			attempt.ResponseCode = &code
+		}
+		switch {
+		case err == nil:
+			attempt.Status = storage.DeliveryStatusDelivered
+		case errors.Is(err, errPermanent) || attempt.Attempts >= d.MaxAttempts:
+			attempt.Status = storage.DeliveryStatusFailed
+			attempt.Error = err.Error()
+		default:
+			attempt.Status = storage.DeliveryStatusPending
+			attempt.Error = err.Error()
+			attempt.NextAttemptAt = now.Add(d.Backoff(attempt.Attempts))
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts
+}
+
+// groupDeliveries keeps claim order and puts the deliveries of each digest
+// channel into one batch.
+func groupDeliveries(deliveries []storage.NotificationDeliveryRecord) [][]storage.NotificationDeliveryRecord {
+	batches := [][]storage.NotificationDeliveryRecord{}
+	digests := map[string]int{}
+	for _, delivery := range deliveries {
+		if channelDigest(delivery.Channel) == "" {
+			batches = append(batches, []storage.NotificationDeliveryRecord{delivery})
+			continue
+		}
+		idx, ok := digests[delivery.ChannelID]
+		if !ok {
+			idx = len(batches)
+			digests[delivery.ChannelID] = idx
+			batches = append(batches, nil)
+		}
+		batches[idx] = append(batches[idx], delivery)
+	}
+	return batches
+}
+
+func channelDigest(channel storage.NotificationChannelRecord) string {
+	if channel.Type != ChannelTypeEmail {
+		return ""
+	}
+	var cfg EmailConfig
+	if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+		return ""
+	}
+	return cfg.Digest
+}
+
+func (d *Dispatcher) send(ctx context.Context, batch []storage.NotificationDeliveryRecord, now time.Time) (int, error) {
+	channel := batch[0].Channel
+	if !channel.Enabled {
+		return 0, fmt.Errorf("%w: channel disabled", errPermanent)
+	}
+	events := make([]AlertEvent, 0, len(batch))
+	for _, delivery := range batch {
+		var event AlertEvent
+		if err := json.Unmarshal(delivery.Payload, &event); err != nil {
+			return 0, fmt.Errorf("%w: invalid payload: %v", errPermanent, err)
+		}
+		events = append(events, event)
+	}
+	event, delivery := events[0], batch[0]
+	var err error
+	switch channel.Type {
+	case ChannelTypeWebhook:
+		var cfg WebhookConfig
+		if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+			return 0, fmt.Errorf("%w: invalid webhook config: %v", errPermanent, err)
+		}
+		if cfg.Secret, err = d.decrypt(cfg.Secret); err != nil {
+			return 0, err
+		}
+		body, err := RenderWebhookBody(cfg, event)
+		if err != nil {
+			return 0, err
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, defaultSendTimeout)
+		defer cancel()
+		return SendWebhook(sendCtx, d.Client, cfg, delivery.Event, body, now)
+	case ChannelTypeEmail:
+		var cfg EmailConfig
+		if err := json.Unmarshal(channel.Config, &cfg); err != nil {
+			return 0, fmt.Errorf("%w: invalid email config: %v", errPermanent, err)
+		}
+		if cfg.Password, err = d.decrypt(cfg.Password); err != nil {
+			return 0, err
+		}
+		var msg []byte
+		if cfg.Digest != "" {
+			msg, err = BuildDigestEmail(cfg, events, now)
+		} else {
+			msg, err = BuildAlertEmail(cfg, event, now)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", errPermanent, err)
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, defaultSendTimeout)
+		defer cancel()
+		return 0, SendEmail(sendCtx, cfg, msg)
+	default:
+		return 0, fmt.Errorf("%w: unsupported channel type %q", errPermanent, channel.Type)
+	}
+}
+
+// decrypt returns the plaintext of a stored channel secret. A secret that
+// cannot be decrypted will not decrypt on retry either.
+func (d *Dispatcher) decrypt(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	if d.Secrets == nil {
+		return "", fmt.Errorf("%w: no key to decrypt the channel secret", errPermanent)
+	}
+	plain, err := d.Secrets.Decrypt(secret)
+	if err != nil {
+		return "", fmt.Errorf("%w: cannot decrypt the channel secret: %v", errPermanent, err)
+	}
+	return plain, nil
+}
+
+// Backoff returns the wait before the attempt after the given one: the base
+// delay doubled per failed attempt, capped at MaxBackoff.
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts && delay < d.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+	return delay
+}
+
+// lease keeps claimed deliveries from being picked up again while a batch is
+// in flight.
+func (d *Dispatcher) lease() time.Duration {
+	return time.Duration(d.BatchSize+1) * defaultSendTimeout
+}
@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"predixaai-backend/pkg/crypto"
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+type memoryStore struct {
+	channels     []storage.NotificationChannelRecord
+	deliveries   []storage.NotificationDeliveryRecord
+	attempts     []storage.NotificationAttempt
+	unitName     string
+	failAttempts int
+}
+
+func (m *memoryStore) GetRuleUnit(ctx context.Context, ruleID string) (string, string, error) {
+	if m.unitName == "" {
+		return "", "", storage.ErrNotFound
+	}
+	return "machine-1", m.unitName, nil
+}
+
+func (m *memoryStore) MatchNotificationChannels(ctx context.Context, ruleID, severity string) ([]storage.NotificationChannelRecord, error) {
+	return m.channels, nil
+}
+
+func (m *memoryStore) CreateNotificationDelivery(ctx context.Context, rec storage.NotificationDeliveryRecord) error {
+	rec.ID = int64(len(m.deliveries) + 1)
+	for _, channel := range m.channels {
+		if channel.ID == rec.ChannelID {
+			rec.Channel = channel
+		}
+	}
+	m.deliveries = append(m.deliveries, rec)
+	return nil
+}
+
+func (m *memoryStore) ClaimNotificationDeliveries(ctx context.Context, limit int, lease time.Duration) ([]storage.NotificationDeliveryRecord, error) {
+	pending := []storage.NotificationDeliveryRecord{}
+	for _, delivery := range m.deliveries {
+		if m.status(delivery.ID) == storage.DeliveryStatusPending {
+			pending = append(pending, delivery)
+		}
+	}
+	return pending, nil
+}
+
+func (m *memoryStore) RecordNotificationAttempt(ctx context.Context, attempt storage.NotificationAttempt) error {
+	if m.failAttempts > 0 {
+		m.failAttempts--
+		return errors.New("connection reset")
+	}
+	m.attempts = append(m.attempts, attempt)
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == attempt.DeliveryID {
+			m.deliveries[i].Attempts = attempt.Attempts
+		}
+	}
+	return nil
+}
+
+func (m *memoryStore) status(id int64) string {
+	status := storage.DeliveryStatusPending
+	for _, attempt := range m.attempts {
+		if attempt.DeliveryID == id {
+			status = attempt.Status
+		}
+	}
+	return status
+}
+
+func webhookChannel(t *testing.T, url string) storage.NotificationChannelRecord {
+	cfg, err := json.Marshal(WebhookConfig{URL: url, Template: `{"alert": {{.AlertID}}, "severity": {{json .Severity}}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage.NotificationChannelRecord{ID: "c1", Name: "ops", Type: ChannelTypeWebhook, Config: cfg, Enabled: true}
+}
+
+func TestDispatcherRetriesWithBackoff(t *testing.T) {
+	var calls int32
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	store := &memoryStore{channels: []storage.NotificationChannelRecord{webhookChannel(t, receiver.URL)}}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDispatcher(store)
+	d.Client = receiver.Client()
+	d.Now = func() time.Time { return now }
+	if err := d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 42, UIRuleID: "r1", Severity: "high"}); err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := d.DeliverDue(context.Background()); err != nil {
+			t.Fatalf("deliver failed: %v", err)
+		}
+	}
+	if len(store.attempts) != 3 {
+		t.Fatalf("expected three attempts, got %+v", store.attempts)
+	}
+	first, second, last := store.attempts[0], store.attempts[1], store.attempts[2]
+	if first.Status != storage.DeliveryStatusPending || first.NextAttemptAt != now.Add(5*time.Second) || *first.ResponseCode != http.StatusBadGateway {
+		t.Fatalf("unexpected first attempt %+v", first)
+	}
+	if second.NextAttemptAt != now.Add(10*time.Second) {
+		t.Fatalf("expected the backoff to double, got %+v", second)
+	}
+	if last.Status != storage.DeliveryStatusDelivered || last.Attempts != 3 || last.Error != "" {
+		t.Fatalf("expected delivery on the third attempt, got %+v", last)
+	}
+	if string(body) != `{"alert": 42, "severity": "high"}` {
+		t.Fatalf("unexpected body %s", body)
+	}
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+	channel := webhookChannel(t, receiver.URL)
+	store := &memoryStore{channels: []storage.NotificationChannelRecord{channel}}
+	d := NewDispatcher(store)
+	d.Client = receiver.Client()
+	d.MaxAttempts = 2
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 1})
+	_ = d.DeliverDue(context.Background())
+	_ = d.DeliverDue(context.Background())
+	_ = d.DeliverDue(context.Background())
+	if len(store.attempts) != 2 || store.attempts[1].Status != storage.DeliveryStatusFailed {
+		t.Fatalf("expected to give up after two attempts, got %+v", store.attempts)
+	}
+	store.channels[0].Type = "pager"
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 2})
+	_ = d.DeliverDue(context.Background())
+	if last := store.attempts[len(store.attempts)-1]; last.Status != storage.DeliveryStatusFailed || last.Attempts != 1 {
+		t.Fatalf("expected an unsupported channel to fail without retries, got %+v", last)
+	}
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	d := NewDispatcher(&memoryStore{})
+	if got := d.Backoff(1); got != 5*time.Second {
+		t.Fatalf("expected the base delay first, got %v", got)
+	}
+	if got := d.Backoff(4); got != 40*time.Second {
+		t.Fatalf("expected 40s after four attempts, got %v", got)
+	}
+	if got := d.Backoff(20); got != d.MaxBackoff {
+		t.Fatalf("expected the cap, got %v", got)
+	}
+}
+
+func TestDeliverDueContinuesAfterRecordFailure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	store := &memoryStore{channels: []storage.NotificationChannelRecord{webhookChannel(t, receiver.URL)}, failAttempts: 1}
+	d := NewDispatcher(store)
+	d.Client = receiver.Client()
+	d.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 1})
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 2})
+	if err := d.DeliverDue(context.Background()); err == nil {
+		t.Fatalf("expected the record failure to be reported")
+	}
+	if len(store.attempts) != 1 || store.attempts[0].DeliveryID != 2 {
+		t.Fatalf("expected the second delivery to be recorded, got %+v", store.attempts)
+	}
+}
+
+func TestDispatcherDecryptsWebhookSecret(t *testing.T) {
+	var signature, timestamp string
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature, timestamp = r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	enc, _ := crypto.NewAesGcmEncryptor([]byte("0123456789abcdef0123456789abcdef"))
+	secret, _ := enc.Encrypt("s3cret")
+	cfg, _ := json.Marshal(WebhookConfig{URL: receiver.URL, Secret: secret})
+	store := &memoryStore{channels: []storage.NotificationChannelRecord{{ID: "c1", Type: ChannelTypeWebhook, Config: cfg, Enabled: true}}}
+	d := NewDispatcher(store)
+	d.Client = receiver.Client()
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 1})
+	_ = d.DeliverDue(context.Background())
+	if last := store.attempts[len(store.attempts)-1]; last.Status != storage.DeliveryStatusFailed {
+		t.Fatalf("expected a permanent failure without a key, got %+v", last)
+	}
+	d.Secrets = enc
+	_ = d.Enqueue(context.Background(), AlertEvent{Event: EventAlertCreated, AlertID: 2})
+	_ = d.DeliverDue(context.Background())
+	ts, _ := strconv.ParseInt(timestamp, 10, 64)
+	if signature != "sha256="+Sign("s3cret", ts, body) {
+		t.Fatalf("expected the request to be signed with the decrypted secret, got %q", signature)
+	}
+}
@@ -0,0 +1,357 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	ChannelTypeEmail = "email"
+
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+
+	emailTLSStartTLS = "starttls"
+	emailTLSImplicit = "tls"
+	emailTLSNone     = "none"
+
+	maxEmailViolations = 20
+	unassignedUnit     = "Unassigned rules"
+)
+
+// EmailConfig is the config stored for email channels by rule-service. TLS
+// is "starttls" (default), "tls" or "none".
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	TLS      string   `json:"tls,omitempty"`
+	Digest   string   `json:"digest,omitempty"`
+}
+
+type emailAlert struct {
+	AlertEvent
+	Explain        string
+	Violations     []string
+	MoreViolations int
+}
+
+type emailUnit struct {
+	Name   string
+	Alerts []emailAlert
+}
+
+type emailDigest struct {
+	Label string
+	Total int
+	Units []emailUnit
+}
+
+type alertMetadata struct {
+	Explain    string          `json:"explain"`
+	Violations []violationInfo `json:"violations"`
+}
+
+type violationInfo struct {
+	Timestamp  *time.Time `json:"timestamp"`
+	Order      *int64     `json:"order"`
+	Value      float64    `json:"value"`
+	Text       string     `json:"text"`
+	Reason     string     `json:"reason"`
+	LimitName  string     `json:"limitName"`
+	LimitValue float64    `json:"limitValue"`
+}
+
+var alertTextTemplate = template.Must(template.New("alert_email").Parse(`{{define "alert"}}{{.Severity}} {{.DetectorType}} alert on {{.ParameterName}}{{if .UnitName}} ({{.UnitName}}){{end}}
+Time: {{.TS.UTC.Format "2006-01-02 15:04:05 MST"}}
+Observed: {{.ObservedValue}}
+Limit: {{.LimitExpression}}
+{{- if .Explain}}
+Explain: {{.Explain}}{{end}}
+{{- if .Violations}}
+Violations:
+{{- range .Violations}}
+  - {{.}}{{end}}
+{{- if .MoreViolations}}
+  ... and {{.MoreViolations}} more{{end}}{{end}}
+{{end}}{{template "alert" .}}`))
+
+var alertHTMLTemplate = htmltemplate.Must(htmltemplate.New("alert_email").Parse(`{{define "alert"}}<div style="margin-bottom:16px">
+<h3 style="margin:0">{{.Severity}} {{.DetectorType}} alert on {{.ParameterName}}{{if .UnitName}} ({{.UnitName}}){{end}}</h3>
+<table cellpadding="2">
+<tr><td><b>Time</b></td><td>{{.TS.UTC.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><td><b>Observed</b></td><td>{{.ObservedValue}}</td></tr>
+<tr><td><b>Limit</b></td><td><code>{{.LimitExpression}}</code></td></tr>
+{{- if .Explain}}
+<tr><td><b>Explain</b></td><td>{{.Explain}}</td></tr>{{end}}
+</table>
+{{- if .Violations}}
+<ul>{{range .Violations}}<li>{{.}}</li>{{end}}{{if .MoreViolations}}<li>... and {{.MoreViolations}} more</li>{{end}}</ul>{{end}}
+</div>{{end}}<html><body>{{template "alert" .}}</body></html>`))
+
+var digestTextTemplate = template.Must(template.Must(alertTextTemplate.Clone()).New("digest").Parse(`{{.Label}} digest: {{.Total}} alerts
+{{range .Units}}
+== {{.Name}} ==
+{{range .Alerts}}
+{{template "alert" .}}{{end}}{{end}}`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.Must(alertHTMLTemplate.Clone()).New("digest").Parse(`<html><body>
+<p>{{.Label}} digest: {{.Total}} alerts</p>
+{{range .Units}}<h2>{{.Name}}</h2>
+{{range .Alerts}}{{template "alert" .}}
+{{end}}{{end}}</body></html>`))
+
+// DigestDue returns when a digest collecting alerts at now is sent: the start
+// of the next UTC hour or day. Without a digest alerts are sent right away.
+func DigestDue(digest string, now time.Time) time.Time {
+	now = now.UTC()
+	switch digest {
+	case DigestHourly:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	case DigestDaily:
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	default:
+		return now
+	}
+}
+
+// BuildAlertEmail renders a single alert as a multipart plain-text and HTML
+// message.
+func BuildAlertEmail(cfg EmailConfig, event AlertEvent, now time.Time) ([]byte, error) {
+	alert := newEmailAlert(event)
+	subject := fmt.Sprintf("[Predixa] %s %s alert on %s", strings.ToUpper(event.Severity), event.DetectorType, event.ParameterName)
+	if event.UnitName != "" {
+		subject += " (" + event.UnitName + ")"
+	}
+	var text, html bytes.Buffer
+	if err := alertTextTemplate.Execute(&text, alert); err != nil {
+		return nil, err
+	}
+	if err := alertHTMLTemplate.Execute(&html, alert); err != nil {
+		return nil, err
+	}
+	return buildEmailMessage(cfg, subject, text.String(), html.String(), now)
+}
+
+// BuildDigestEmail renders events as one message grouped by machine unit.
+func BuildDigestEmail(cfg EmailConfig, events []AlertEvent, now time.Time) ([]byte, error) {
+	digest := emailDigest{Label: "Hourly", Total: len(events)}
+	if cfg.Digest == DigestDaily {
+		digest.Label = "Daily"
+	}
+	byUnit := map[string]int{}
+	for _, event := range events {
+		name := event.UnitName
+		if name == "" {
+			name = event.UnitID
+		}
+		if name == "" {
+			name = unassignedUnit
+		}
+		idx, ok := byUnit[name]
+		if !ok {
+			idx = len(digest.Units)
+			byUnit[name] = idx
+			digest.Units = append(digest.Units, emailUnit{Name: name})
+		}
+		digest.Units[idx].Alerts = append(digest.Units[idx].Alerts, newEmailAlert(event))
+	}
+	sort.Slice(digest.Units, func(i, j int) bool {
+		if (digest.Units[i].Name == unassignedUnit) != (digest.Units[j].Name == unassignedUnit) {
+			return digest.Units[j].Name == unassignedUnit
+		}
+		return digest.Units[i].Name < digest.Units[j].Name
+	})
+	for _, unit := range digest.Units {
+		sort.SliceStable(unit.Alerts, func(i, j int) bool { return unit.Alerts[i].TS.Before(unit.Alerts[j].TS) })
+	}
+	subject := fmt.Sprintf("[Predixa] %s digest: %d alerts on %d machine units", digest.Label, len(events), len(digest.Units))
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.ExecuteTemplate(&text, "digest", digest); err != nil {
+		return nil, err
+	}
+	if err := digestHTMLTemplate.ExecuteTemplate(&html, "digest", digest); err != nil {
+		return nil, err
+	}
+	return buildEmailMessage(cfg, subject, text.String(), html.String(), now)
+}
+
+func newEmailAlert(event AlertEvent) emailAlert {
+	alert := emailAlert{AlertEvent: event}
+	var meta alertMetadata
+	if len(event.Metadata) > 0 {
+		_ = json.Unmarshal(event.Metadata, &meta)
+	}
+	alert.Explain = meta.Explain
+	for i, violation := range meta.Violations {
+		if i == maxEmailViolations {
+			alert.MoreViolations = len(meta.Violations) - maxEmailViolations
+			break
+		}
+		alert.Violations = append(alert.Violations, violation.summary())
+	}
+	return alert
+}
+
+func (v violationInfo) summary() string {
+	parts := []string{}
+	if v.Timestamp != nil {
+		parts = append(parts, v.Timestamp.UTC().Format(time.RFC3339))
+	} else if v.Order != nil {
+		parts = append(parts, "run "+strconv.FormatInt(*v.Order, 10))
+	}
+	if v.Text != "" {
+		parts = append(parts, strconv.Quote(v.Text))
+	} else {
+		parts = append(parts, fmt.Sprintf("value %g", v.Value))
+	}
+	reason := v.Reason
+	if v.LimitName != "" && v.Text == "" {
+		reason += fmt.Sprintf(" (%s %g)", v.LimitName, v.LimitValue)
+	} else if v.LimitName != "" {
+		reason += " (" + v.LimitName + ")"
+	}
+	return strings.Join(append(parts, reason), " - ")
+}
+
+func buildEmailMessage(cfg EmailConfig, subject, text, html string, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}, "Content-Transfer-Encoding": {"quoted-printable"}})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// SendEmail delivers msg over SMTP. SMTP 5xx replies and configuration
+// problems are permanent; everything else is retried.
+func SendEmail(ctx context.Context, cfg EmailConfig, msg []byte) error {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("%w: invalid from address", errPermanent)
+	}
+	recipients := make([]string, 0, len(cfg.To))
+	for _, to := range cfg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("%w: invalid recipient %q", errPermanent, to)
+		}
+		recipients = append(recipients, addr.Address)
+	}
+	if cfg.Username != "" && cfg.TLS == emailTLSNone && !isLocalSMTPHost(cfg.Host) {
+		return fmt.Errorf("%w: refusing to send SMTP credentials without TLS", errPermanent)
+	}
+	err = sendSMTP(ctx, cfg, from.Address, recipients, msg)
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	return err
+}
+
+// isLocalSMTPHost mirrors the hosts net/smtp accepts PLAIN auth for without
+// TLS.
+func isLocalSMTPHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func sendSMTP(ctx context.Context, cfg EmailConfig, from string, recipients []string, msg []byte) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if cfg.TLS == emailTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if cfg.TLS == "" || cfg.TLS == emailTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%w: server does not support STARTTLS", errPermanent)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, to := range recipients {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,267 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+// smtpStandIn is a minimal in-process SMTP server that records every message
+// it accepts. Recipients listed in reject get a 550 reply.
+type smtpStandIn struct {
+	listener net.Listener
+	reject   map[string]bool
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	server := &smtpStandIn{listener: listener, reject: map[string]bool{}}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+	return server
+}
+
+func (s *smtpStandIn) config() EmailConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return EmailConfig{Host: host, Port: portNum, From: "Predixa <alerts@example.com>", To: []string{"lead@example.com"}, TLS: "none"}
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 standin ESMTP")
+	var msg smtpMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250 standin")
+		case "MAIL":
+			msg = smtpMessage{from: strings.TrimPrefix(line, "MAIL FROM:")}
+			reply("250 OK")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>")
+			if s.reject[to] {
+				reply("550 no such user")
+				continue
+			}
+			msg.to = append(msg.to, to)
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			msg.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func (s *smtpStandIn) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+// parts returns the decoded plain-text and HTML bodies of a message.
+func parts(t *testing.T, data string) (string, string, *mail.Message) {
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid message: %v", err)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("invalid content type: %v", err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	bodies := map[string]string{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid part: %v", err)
+		}
+		raw, _ := io.ReadAll(part)
+		mediaType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[mediaType] = string(raw)
+	}
+	return bodies["text/plain"], bodies["text/html"], msg
+}
+
+func testAlertEvent(id int64, unit string, parameter string) AlertEvent {
+	metadata, _ := json.Marshal(map[string]any{
+		"explain": "value above 100 for 3 samples",
+		"violations": []map[string]any{
+			{"timestamp": "2024-01-01T10:00:00Z", "value": 104.5, "reason": "above_max", "limitName": "max", "limitValue": 100},
+			{"order": 17, "value": 2, "reason": "<script>", "limitName": "min", "limitValue": 3},
+		},
+	})
+	return AlertEvent{
+		Event:           EventAlertCreated,
+		AlertID:         id,
+		UIRuleID:        "r1",
+		UnitName:        unit,
+		ParameterName:   parameter,
+		DetectorType:    "threshold",
+		Severity:        "high",
+		ObservedValue:   "104.5",
+		LimitExpression: "value <= 100",
+		TS:              time.Date(2024, 1, 1, 10, 0, int(id), 0, time.UTC),
+		Metadata:        metadata,
+	}
+}
+
+func TestSendAlertEmail(t *testing.T) {
+	server := newSMTPStandIn(t)
+	cfg := server.config()
+	msg, err := BuildAlertEmail(cfg, testAlertEvent(1, "etcher-1", "rf_power"), time.Now())
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if err := SendEmail(context.Background(), cfg, msg); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	received := server.received()
+	if len(received) != 1 || received[0].from != "<alerts@example.com>" || len(received[0].to) != 1 || received[0].to[0] != "lead@example.com" {
+		t.Fatalf("unexpected envelope %+v", received)
+	}
+	text, html, header := parts(t, received[0].data)
+	if subject := header.Header.Get("Subject"); subject != "[Predixa] HIGH threshold alert on rf_power (etcher-1)" {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	for _, want := range []string{"Observed: 104.5", "Limit: value <= 100", "Explain: value above 100 for 3 samples", "2024-01-01T10:00:00Z - value 104.5 - above_max (max 100)", "run 17 - value 2"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected %q in text body:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "<code>value &lt;= 100</code>") || strings.Contains(html, "<script>") {
+		t.Fatalf("expected escaped HTML body:\n%s", html)
+	}
+}
+
+func TestSendEmailRejectedRecipientIsPermanent(t *testing.T) {
+	server := newSMTPStandIn(t)
+	server.reject["lead@example.com"] = true
+	cfg := server.config()
+	if err := SendEmail(context.Background(), cfg, []byte("Subject: x\r\n\r\nx")); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected a permanent error, got %v", err)
+	}
+	cfg.TLS = ""
+	if err := SendEmail(context.Background(), cfg, []byte("Subject: x\r\n\r\nx")); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected missing STARTTLS to be permanent, got %v", err)
+	}
+	cfg.TLS, cfg.Host, cfg.Username, cfg.Password = emailTLSNone, "smtp.example.com", "alerts", "secret"
+	if err := SendEmail(context.Background(), cfg, []byte("Subject: x\r\n\r\nx")); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected credentials without TLS to be permanent, got %v", err)
+	}
+}
+
+func TestDispatcherSendsDigestPerChannel(t *testing.T) {
+	server := newSMTPStandIn(t)
+	cfg := server.config()
+	cfg.Digest = DigestHourly
+	raw, _ := json.Marshal(cfg)
+	now := time.Date(2024, 1, 1, 10, 20, 0, 0, time.UTC)
+	store := &memoryStore{channels: []storage.NotificationChannelRecord{{ID: "mail", Type: ChannelTypeEmail, Config: raw, Enabled: true}}}
+	d := NewDispatcher(store)
+	d.Now = func() time.Time { return now }
+	events := []AlertEvent{testAlertEvent(1, "etcher-2", "rf_power"), testAlertEvent(2, "etcher-1", "chamber_pressure"), testAlertEvent(3, "etcher-2", "gas_flow")}
+	for _, event := range events {
+		if err := d.Enqueue(context.Background(), event); err != nil {
+			t.Fatalf("enqueue failed: %v", err)
+		}
+	}
+	for _, delivery := range store.deliveries {
+		if !delivery.NextAttemptAt.Equal(time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)) {
+			t.Fatalf("expected the digest to be due at the next hour, got %v", delivery.NextAttemptAt)
+		}
+	}
+	if err := d.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("deliver failed: %v", err)
+	}
+	received := server.received()
+	if len(received) != 1 {
+		t.Fatalf("expected one digest email, got %d", len(received))
+	}
+	if len(store.attempts) != 3 || store.attempts[2].Status != storage.DeliveryStatusDelivered {
+		t.Fatalf("expected every queued alert to be marked delivered, got %+v", store.attempts)
+	}
+	text, _, header := parts(t, received[0].data)
+	if subject := header.Header.Get("Subject"); subject != "[Predixa] Hourly digest: 3 alerts on 2 machine units" {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	first, second := strings.Index(text, "== etcher-1 =="), strings.Index(text, "== etcher-2 ==")
+	if first < 0 || second < first || !strings.Contains(text[second:], "rf_power") || !strings.Contains(text[second:], "gas_flow") {
+		t.Fatalf("expected alerts grouped by machine unit:\n%s", text)
+	}
+}
+
+func TestDigestDue(t *testing.T) {
+	now := time.Date(2024, 3, 31, 23, 59, 0, 0, time.UTC)
+	if got := DigestDue(DigestHourly, now); !got.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected hourly due %v", got)
+	}
+	if got := DigestDue(DigestDaily, now.Add(-12*time.Hour)); !got.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected daily due %v", got)
+	}
+}
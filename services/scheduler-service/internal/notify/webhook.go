@@ -0,0 +1,149 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"text/template"
+	"time"
+)
+
+const (
+	ChannelTypeWebhook = "webhook"
+
+	SignatureHeader = "X-Predixa-Signature"
+	TimestampHeader = "X-Predixa-Timestamp"
+	EventHeader     = "X-Predixa-Event"
+)
+
+// WebhookConfig is the config stored for webhook channels by rule-service.
+type WebhookConfig struct {
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Secret   string            `json:"secret,omitempty"`
+}
+
+// errPermanent marks delivery failures that retrying cannot fix.
+var errPermanent = errors.New("permanent delivery failure")
+
+// RenderWebhookBody renders the channel template against the event, or
+// encodes the event as JSON when no template is set.
+func RenderWebhookBody(cfg WebhookConfig, event AlertEvent) ([]byte, error) {
+	if cfg.Template == "" {
+		return json.Marshal(event)
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid template: %v", errPermanent, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, event); err != nil {
+		return nil, fmt.Errorf("%w: template failed: %v", errPermanent, err)
+	}
+	if !json.Valid(out.Bytes()) {
+		return nil, fmt.Errorf("%w: template did not render valid JSON", errPermanent)
+	}
+	return out.Bytes(), nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which netip does not
+// count as private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewWebhookClient returns the client webhooks are sent with. It refuses to
+// connect to loopback, private, link-local and other internal addresses,
+// including ones a public host name resolves to or redirects to, unless the
+// address falls in one of allow.
+func NewWebhookClient(allow []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: defaultSendTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return checkWebhookAddress(address, allow)
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: defaultSendTimeout, Transport: transport}
+}
+
+func checkWebhookAddress(address string, allow []netip.Prefix) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook address %q", errPermanent, address)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("%w: invalid webhook address %q", errPermanent, address)
+	}
+	addr = addr.Unmap()
+	for _, prefix := range allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() || sharedAddressSpace.Contains(addr) {
+		return fmt.Errorf("%w: webhook address %s is not allowed", errPermanent, addr)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" under secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendWebhook posts body to the webhook and returns the response status.
+// Custom headers are applied first so they cannot replace the signature.
+func SendWebhook(ctx context.Context, client *http.Client, cfg WebhookConfig, event string, body []byte, now time.Time) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	for name, value := range cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if cfg.Secret != "" {
+		timestamp := now.Unix()
+		req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(SignatureHeader, "sha256="+Sign(cfg.Secret, timestamp, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	err = fmt.Errorf("webhook responded %d", resp.StatusCode)
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		err = fmt.Errorf("%w: %v", errPermanent, err)
+	}
+	return resp.StatusCode, err
+}
+
+func templateJSON(value any) (string, error) {
+	raw, err := json.Marshal(value)
+	return string(raw), err
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRenderWebhookBody(t *testing.T) {
+	event := AlertEvent{Event: EventAlertCreated, AlertID: 7, ParameterName: "temp \"A\"", Severity: "high", ObservedValue: "101"}
+	body, err := RenderWebhookBody(WebhookConfig{Template: `{"text": {{json (printf "%s on %s" .Severity .ParameterName)}}, "id": {{.AlertID}}}`}, event)
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(body, &out); err != nil || out["text"] != `high on temp "A"` || out["id"] != 7.0 {
+		t.Fatalf("unexpected body %s", body)
+	}
+	raw, err := RenderWebhookBody(WebhookConfig{}, event)
+	if err != nil || !json.Valid(raw) {
+		t.Fatalf("expected the event as JSON without a template, got %s (%v)", raw, err)
+	}
+	if _, err := RenderWebhookBody(WebhookConfig{Template: `{"text": {{.ParameterName}}}`}, event); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected invalid JSON output to fail permanently, got %v", err)
+	}
+}
+
+func TestSendWebhookSignsRequest(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+	now := time.Unix(1700000000, 0)
+	cfg := WebhookConfig{URL: receiver.URL, Secret: "s3cret", Headers: map[string]string{"Authorization": "Bearer abc", SignatureHeader: "forged"}}
+	code, err := SendWebhook(context.Background(), receiver.Client(), cfg, EventAlertCreated, []byte(`{"ok":true}`), now)
+	if err != nil || code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d (%v)", code, err)
+	}
+	if got.Header.Get("Authorization") != "Bearer abc" || got.Header.Get(EventHeader) != EventAlertCreated || got.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers %v", got.Header)
+	}
+	if got.Header.Get(TimestampHeader) != strconv.FormatInt(now.Unix(), 10) || got.Header.Get(SignatureHeader) != "sha256="+Sign("s3cret", now.Unix(), gotBody) {
+		t.Fatalf("unexpected signature headers %v", got.Header)
+	}
+}
+
+func TestSendWebhookClassifiesFailures(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+	send := func() error {
+		_, err := SendWebhook(context.Background(), receiver.Client(), WebhookConfig{URL: receiver.URL}, EventAlertCreated, []byte(`{}`), time.Now())
+		return err
+	}
+	if err := send(); err == nil || errors.Is(err, errPermanent) {
+		t.Fatalf("expected a retryable error for 503, got %v", err)
+	}
+	status = http.StatusTooManyRequests
+	if err := send(); err == nil || errors.Is(err, errPermanent) {
+		t.Fatalf("expected a retryable error for 429, got %v", err)
+	}
+	status = http.StatusNotFound
+	if err := send(); !errors.Is(err, errPermanent) {
+		t.Fatalf("expected a permanent error for 404, got %v", err)
+	}
+}
+
+func TestWebhookClientBlocksInternalAddresses(t *testing.T) {
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "169.254.169.254:80", "[::1]:443", "[::ffff:192.168.1.1]:80", "100.64.0.1:80", "0.0.0.0:80"} {
+		if err := checkWebhookAddress(address, nil); !errors.Is(err, errPermanent) {
+			t.Fatalf("expected %s to be blocked, got %v", address, err)
+		}
+	}
+	if err := checkWebhookAddress("93.184.216.34:443", nil); err != nil {
+		t.Fatalf("expected a public address to pass, got %v", err)
+	}
+	if err := checkWebhookAddress("10.1.2.3:443", []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16")}); err != nil {
+		t.Fatalf("expected an allowed range to pass, got %v", err)
+	}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+	_, err := SendWebhook(context.Background(), NewWebhookClient(nil), WebhookConfig{URL: receiver.URL}, EventAlertCreated, []byte(`{}`), time.Now())
+	if !errors.Is(err, errPermanent) {
+		t.Fatalf("expected a loopback webhook to fail permanently, got %v", err)
+	}
+}
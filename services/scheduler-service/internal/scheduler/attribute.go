@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const defaultAttributeEvalWindow = 1
+
+func EvaluateAttributeChart(baseline []Sample, eval []Sample, spec AttributeSpec, latestOnly bool) DetectorResult {
+	width := spec.SigmaMultiplier
+	if width == 0 {
+		width = 3
+	}
+	minBaseline := spec.MinBaselineN
+	if minBaseline == 0 {
+		minBaseline = defaultBaselineMinN
+	}
+	var defects, units float64
+	n := 0
+	for _, sample := range baseline {
+		size, ok := attributeSampleSize(sample, spec)
+		if !ok {
+			continue
+		}
+		defects += sample.Value
+		units += size
+		n++
+	}
+	if n < minBaseline {
+		return insufficientData("baseline too small")
+	}
+	if len(eval) == 0 {
+		return insufficientData("not enough samples")
+	}
+	center := defects / units
+	if spec.Chart == "c" {
+		center = defects / float64(n)
+	}
+	if (spec.Chart == "p" || spec.Chart == "np") && center > 1 {
+		return invalidConfig("defectives exceed sample size")
+	}
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(eval[len(eval)-1].Value),
+		LimitExpr: fmt.Sprintf("%s chart center=%.4g ±%.1fσ", spec.Chart, center, width),
+		Metadata: map[string]any{
+			"chart":           spec.Chart,
+			"center":          center,
+			"sigmaMultiplier": width,
+			"baselineCount":   n,
+		},
+	}
+	series := make([]map[string]any, 0, len(eval))
+	var ucl, lcl float64
+	for i, sample := range eval {
+		size, ok := attributeSampleSize(sample, spec)
+		if !ok {
+			continue
+		}
+		value, centerLine, spread := attributeLimits(spec.Chart, sample.Value, size, center)
+		ucl, lcl = centerLine+width*spread, math.Max(0, centerLine-width*spread)
+		if spec.Chart == "p" {
+			ucl = math.Min(1, ucl)
+		}
+		point := map[string]any{"index": i, "value": value, "n": size, "center": centerLine, "ucl": ucl, "lcl": lcl}
+		if !sample.TS.IsZero() {
+			point["timestamp"] = sample.TS.UTC().Format(time.RFC3339)
+		}
+		if sample.Order != nil {
+			point["order"] = *sample.Order
+		}
+		series = append(series, point)
+		if latestOnly && i != len(eval)-1 {
+			continue
+		}
+		idx := i
+		if value > ucl {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: value, Reason: "above_ucl", LimitName: "UCL", LimitValue: ucl, Delta: value - ucl})
+		}
+		if value < lcl {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: value, Reason: "below_lcl", LimitName: "LCL", LimitValue: lcl, Delta: value - lcl})
+		}
+	}
+	if len(series) == 0 {
+		return insufficientData("sample size missing")
+	}
+	result.Metadata["ucl"] = ucl
+	result.Metadata["lcl"] = lcl
+	result.Metadata["series"] = series
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Metadata["limitBreached"] = result.Violations[len(result.Violations)-1].LimitName
+	}
+	return result
+}
+
+// attributeLimits returns the plotted statistic, its center line and one sigma
+// for a point with the given count and sample size.
+func attributeLimits(chart string, count, size, center float64) (float64, float64, float64) {
+	switch chart {
+	case "p":
+		return count / size, center, math.Sqrt(center * (1 - center) / size)
+	case "np":
+		return count, size * center, math.Sqrt(size * center * (1 - center))
+	case "u":
+		return count / size, center, math.Sqrt(center / size)
+	default:
+		return count, center, math.Sqrt(center)
+	}
+}
+
+func attributeSampleSize(sample Sample, spec AttributeSpec) (float64, bool) {
+	if sample.Value < 0 {
+		return 0, false
+	}
+	if spec.SampleSizeColumn != "" {
+		size, ok := sample.Columns[spec.SampleSizeColumn]
+		if !ok || size <= 0 {
+			return 0, false
+		}
+		return size, true
+	}
+	if spec.SampleSize > 0 {
+		return float64(spec.SampleSize), true
+	}
+	return 1, spec.Chart == "c" || spec.Chart == "u"
+}
+
+func attributeEvalWindow(spec AttributeSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return defaultAttributeEvalWindow
+}
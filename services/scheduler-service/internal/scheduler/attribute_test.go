@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvaluateAttributeChartC(t *testing.T) {
+	baseline := make([]Sample, 0, 20)
+	for i := 0; i < 20; i++ {
+		baseline = append(baseline, Sample{Value: float64(3 + i%3)})
+	}
+	result := EvaluateAttributeChart(baseline, runRulesSamples(4, 16), AttributeSpec{Chart: "c"}, true)
+	if !result.Hit || result.Violations[0].LimitName != "UCL" {
+		t.Fatalf("expected count above Poisson UCL, got %+v", result)
+	}
+	if ucl := result.Metadata["ucl"].(float64); math.Abs(ucl-(3.95+3*math.Sqrt(3.95))) > 1e-9 {
+		t.Fatalf("unexpected ucl %v", ucl)
+	}
+	if ok := EvaluateAttributeChart(baseline, runRulesSamples(16, 4), AttributeSpec{Chart: "c"}, true); ok.Hit {
+		t.Fatalf("expected scheduled run to only check the latest point")
+	}
+}
+
+func TestEvaluateAttributeChartPVaryingSampleSize(t *testing.T) {
+	spec := AttributeSpec{Chart: "p", SampleSizeColumn: "inspected"}
+	withSize := func(defects, size float64) Sample {
+		return Sample{Value: defects, Columns: map[string]float64{"inspected": size}}
+	}
+	baseline := make([]Sample, 0, 20)
+	for i := 0; i < 20; i++ {
+		baseline = append(baseline, withSize(5, 100))
+	}
+	result := EvaluateAttributeChart(baseline, []Sample{withSize(6, 100), withSize(6, 20)}, spec, false)
+	if !result.Hit || len(result.Violations) != 1 || *result.Violations[0].Index != 1 {
+		t.Fatalf("expected only the small sample to breach, got %+v", result.Violations)
+	}
+	if result.Metadata["center"] != 0.05 {
+		t.Fatalf("expected p-bar 0.05, got %v", result.Metadata["center"])
+	}
+	if missing := EvaluateAttributeChart(baseline, runRulesSamples(3), spec, true); missing.Status != statusInsufficient {
+		t.Fatalf("expected insufficient data without sample size, got %+v", missing)
+	}
+}
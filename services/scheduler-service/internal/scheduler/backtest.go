@@ -0,0 +1,583 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+	"predixaai-backend/services/scheduler-service/internal/monitor"
+	"predixaai-backend/services/scheduler-service/internal/security"
+)
+
+type StepperBacktestRequest struct {
+	ConnectionRef   string          `json:"connectionRef"`
+	Table           string          `json:"table"`
+	TimestampColumn string          `json:"timestampColumn"`
+	OrderingColumn  string          `json:"orderingColumn,omitempty"`
+	Where           *WhereSpec      `json:"where,omitempty"`
+	ValueColumn     string          `json:"valueColumn"`
+	Expression      string          `json:"expression,omitempty"`
+	RuleType        string          `json:"ruleType"`
+	Config          json.RawMessage `json:"config"`
+	Range           selectorSpec    `json:"range"`
+	Incidents       []selectorSpec  `json:"incidents,omitempty"`
+}
+
+type BacktestAlert struct {
+	OpenedAt      string `json:"openedAt,omitempty"`
+	OpenedOrder   *int64 `json:"openedOrder,omitempty"`
+	LastSeenAt    string `json:"lastSeenAt,omitempty"`
+	ResolvedAt    string `json:"resolvedAt,omitempty"`
+	ResolvedOrder *int64 `json:"resolvedOrder,omitempty"`
+	Severity      string `json:"severity"`
+	Observed      string `json:"observed"`
+	LimitExpr     string `json:"limitExpr"`
+	Reason        string `json:"reason,omitempty"`
+	Occurrences   int    `json:"occurrences"`
+	FalseAlarm    bool   `json:"falseAlarm"`
+}
+
+type StepperBacktestResponse struct {
+	Status            string                 `json:"status"`
+	Window            map[string]string      `json:"window"`
+	Baseline          map[string]interface{} `json:"baseline"`
+	Settings          map[string]int         `json:"settings"`
+	Polls             int                    `json:"polls"`
+	Evaluations       int                    `json:"evaluations"`
+	Insufficient      int                    `json:"insufficient"`
+	Alerts            []BacktestAlert        `json:"alerts"`
+	CountsBySeverity  map[string]int         `json:"countsBySeverity"`
+	Suppressed        int                    `json:"suppressedByCooldown"`
+	FalseAlarms       int                    `json:"falseAlarms"`
+	FalseAlarmRate    float64                `json:"falseAlarmRate"`
+	Incidents         int                    `json:"incidents"`
+	IncidentsDetected int                    `json:"incidentsDetected"`
+	Messages          []string               `json:"messages"`
+}
+
+type backtestPoll struct {
+	Index  int
+	At     time.Time
+	Repeat int
+}
+
+type backtestIncident struct {
+	start    *time.Time
+	end      *time.Time
+	from     *int64
+	to       *int64
+	detected bool
+}
+
+// StepperBacktest replays a rule over a historical range. Each poll sees only
+// the samples recorded up to that point and feeds the same open/update/resolve
+// and cooldown flow the worker applies to live alerts.
+func StepperBacktest(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist security.Allowlist, limits security.Limits, req StepperBacktestRequest) (StepperBacktestResponse, error) {
+	spec, err := buildRuleSpec(req.ConnectionRef, req.Table, req.TimestampColumn, req.ValueColumn, req.RuleType, req.Config)
+	if err != nil {
+		return StepperBacktestResponse{}, err
+	}
+	spec.Source.OrderingColumn = req.OrderingColumn
+	spec.Source.Where = req.Where
+	spec.Parameters[0].Expression = req.Expression
+	var cfg stepperRuleConfig
+	if len(req.Config) > 0 {
+		if err := json.Unmarshal(req.Config, &cfg); err != nil {
+			return StepperBacktestResponse{}, errors.New("invalid rule config")
+		}
+	}
+	detector := &spec.Parameters[0].Detector
+	if cfg.Subgrouping != nil {
+		applyStepperSubgrouping(detector, *cfg.Subgrouping)
+	}
+	if detector.RangeChart != nil && detector.RangeChart.SubgroupSize == 0 {
+		detector.RangeChart.SubgroupSize = 5
+	}
+	if detector.XbarChart != nil && detector.XbarChart.SubgroupSize == 0 {
+		detector.XbarChart.SubgroupSize = 5
+	}
+	pollInterval := defaultStepperPollSeconds
+	if cfg.PollIntervalSeconds > 0 {
+		pollInterval = cfg.PollIntervalSeconds
+	}
+	cooldown := 0
+	if cfg.CooldownSeconds != nil {
+		cooldown = *cfg.CooldownSeconds
+	}
+	resolveAfter := autoResolveAfter(RuleSpec{AutoResolveAfter: cfg.AutoResolveAfter})
+	resp := StepperBacktestResponse{
+		Window:           map[string]string{},
+		Baseline:         map[string]interface{}{},
+		Settings:         map[string]int{"pollIntervalSeconds": pollInterval, "cooldownSeconds": cooldown, "autoResolveAfter": resolveAfter},
+		Alerts:           []BacktestAlert{},
+		CountsBySeverity: map[string]int{},
+		Incidents:        len(req.Incidents),
+		Messages:         []string{},
+	}
+	if err := validateStepperMetadata(ctx, adapter, allowlist, spec, cfg.Subgrouping); err != nil {
+		resp.Status = statusInvalidConfig
+		resp.Messages = append(resp.Messages, err.Error())
+		return resp, nil
+	}
+	incidents, err := parseBacktestIncidents(req.Incidents)
+	if err != nil {
+		return StepperBacktestResponse{}, err
+	}
+	samples, err := fetchForSelector(ctx, adapter, spec, req.Range, cfg.Subgrouping, limits)
+	if err != nil {
+		return StepperBacktestResponse{}, err
+	}
+	if limits.MaxSampleRows > 0 && len(samples) >= limits.MaxSampleRows {
+		resp.Messages = append(resp.Messages, fmt.Sprintf("range limited to the latest %d samples", len(samples)))
+	}
+	baseline, start := []Sample{}, 0
+	if backtestUsesBaseline(req.RuleType) {
+		selector := selectorSpec{Kind: "lastN", Value: defaultBaselineLastN}
+		if cfg.Baseline != nil && cfg.Baseline.Selector != nil {
+			selector = *cfg.Baseline.Selector
+		}
+		if selector.Kind == "lastN" {
+			n := defaultBaselineLastN
+			if selector.Value > 0 {
+				n = selector.Value
+			}
+			start = min(n, len(samples))
+			baseline = samples[:start]
+			resp.Baseline["source"] = "range"
+		} else {
+			baseline, err = fetchForSelector(ctx, adapter, spec, selector, cfg.Subgrouping, limits)
+			if err != nil {
+				return StepperBacktestResponse{}, err
+			}
+			resp.Baseline["source"] = "selector"
+		}
+	}
+	baselineWindow := DetectorResult{}
+	applyWindowAndBaseline(&baselineWindow, baseline, nil, nil, false)
+	resp.Baseline["start"] = formatTime(baselineWindow.WindowStart)
+	resp.Baseline["end"] = formatTime(baselineWindow.WindowEnd)
+	resp.Baseline["count"] = len(baseline)
+	evalWindow := DetectorResult{}
+	applyWindowAndBaseline(&evalWindow, samples[start:], nil, nil, false)
+	resp.Window["start"] = formatTime(evalWindow.WindowStart)
+	resp.Window["end"] = formatTime(evalWindow.WindowEnd)
+	if evalWindow.OrderStart != nil {
+		resp.Window["startOrder"] = strconv.FormatInt(*evalWindow.OrderStart, 10)
+	}
+	if evalWindow.OrderEnd != nil {
+		resp.Window["endOrder"] = strconv.FormatInt(*evalWindow.OrderEnd, 10)
+	}
+	evaluate, err := backtestEvaluator(req.RuleType, spec, baseline, start, cfg.Subgrouping)
+	if err != nil {
+		return StepperBacktestResponse{}, err
+	}
+	if cooldown > 0 && spec.Source.TimestampColumn == "" {
+		resp.Messages = append(resp.Messages, "cooldown ignored: source has no timestamp column")
+	}
+	replay := backtestReplay{cooldown: cooldown, resolveAfter: resolveAfter, open: -1}
+	step := time.Duration(pollInterval) * time.Second
+	inControl := 0
+	lastError := ""
+	for _, poll := range backtestPolls(samples, start, spec.Source.OrderingColumn != "", pollInterval) {
+		result := evaluate(samples[:poll.Index+1])
+		sample := samples[poll.Index]
+		incident := matchBacktestIncident(incidents, sample)
+		if result.Status == statusInsufficient || result.Status == statusInvalidConfig {
+			resp.Insufficient++
+			if message, ok := result.Metadata["error"].(string); ok {
+				lastError = message
+			}
+		} else {
+			resp.Evaluations++
+			if incident < 0 {
+				inControl++
+			}
+		}
+		for i := 0; i < poll.Repeat; i++ {
+			resp.Polls++
+			if i > 0 && incrementalRuleType(req.RuleType) {
+				continue
+			}
+			if !replay.observe(result, sample, poll.At.Add(time.Duration(i)*step)) {
+				continue
+			}
+			alert := &replay.alerts[len(replay.alerts)-1]
+			alert.FalseAlarm = incident < 0
+			if incident >= 0 {
+				incidents[incident].detected = true
+			}
+		}
+	}
+	resp.Alerts = replay.alerts
+	resp.Suppressed = replay.suppressed
+	for _, alert := range resp.Alerts {
+		resp.CountsBySeverity[alert.Severity]++
+		if alert.FalseAlarm {
+			resp.FalseAlarms++
+		}
+	}
+	if inControl > 0 {
+		resp.FalseAlarmRate = float64(resp.FalseAlarms) / float64(inControl)
+	}
+	for _, incident := range incidents {
+		if incident.detected {
+			resp.IncidentsDetected++
+		}
+	}
+	resp.Status = statusOK
+	if resp.Evaluations == 0 {
+		resp.Status = statusInsufficient
+		if lastError != "" {
+			resp.Messages = append(resp.Messages, lastError)
+		} else {
+			resp.Messages = append(resp.Messages, "no samples to replay after the baseline")
+		}
+	}
+	return resp, nil
+}
+
+// backtestEvaluator returns a function evaluating the latest poll given every
+// sample seen so far. Shewhart and range charts freeze their limits from the
+// baseline, matching a rule that was activated at the start of the range.
+func backtestEvaluator(ruleType string, spec RuleSpec, baseline []Sample, start int, subgroup *subgroupSpec) (func([]Sample) DetectorResult, error) {
+	detector := spec.Parameters[0].Detector
+	constant := func(result DetectorResult) func([]Sample) DetectorResult {
+		return func([]Sample) DetectorResult { return result }
+	}
+	switch ruleType {
+	case "SPEC_LIMIT_VIOLATION":
+		return func(history []Sample) DetectorResult {
+			return EvaluateSpecLimit(history[len(history)-1], *detector.SpecLimit)
+		}, nil
+	case "SHEWHART_3SIGMA", "SHEWHART_2SIGMA":
+		shewhart := *detector.Shewhart
+		frozen := EvaluateShewhart(baseline, shewhart, shewhart.SigmaMultiplier)
+		mu, okMu := frozen.Metadata["mu"].(float64)
+		sigma, okSigma := frozen.Metadata["sigma"].(float64)
+		if !okMu || !okSigma {
+			return constant(frozen), nil
+		}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateShewhartFrozen(points[len(points)-1], mu, sigma, shewhart.SigmaMultiplier)
+			})
+		}, nil
+	case "RUN_RULES":
+		runRules := *detector.RunRules
+		window := runRulesEvalWindow(runRules)
+		seen := start
+		return func(history []Sample) DetectorResult {
+			fresh := len(history) - seen
+			if fresh <= 0 {
+				fresh = 1
+			}
+			result := EvaluateRunRules(baseline, lastSamples(history, window+fresh-1), runRules, fresh)
+			if result.Status == statusOK || result.Status == statusViolation {
+				seen = len(history)
+			}
+			return result
+		}, nil
+	case "EWMA":
+		ewma := *detector.EWMA
+		state := &EWMAState{}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			eval, warmUp := history[seen:], state.Count == 0
+			if warmUp {
+				eval = lastSamples(history, ewmaEvalWindow(ewma))
+			}
+			seen = len(history)
+			return foldEWMA(baseline, eval, ewma, state, warmUp)
+		}, nil
+	case "CUSUM":
+		cusum := *detector.CUSUM
+		state := &CUSUMState{}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			eval := history[seen:]
+			seen = len(history)
+			return EvaluateCUSUM(baseline, eval, cusum, state)
+		}, nil
+	case "P_CHART", "NP_CHART", "C_CHART", "U_CHART":
+		attribute := *detector.Attribute
+		return func(history []Sample) DetectorResult {
+			return EvaluateAttributeChart(baseline, lastSamples(history, attributeEvalWindow(attribute)), attribute, true)
+		}, nil
+	case "RANGE_CHART_R":
+		rangeChart := *detector.RangeChart
+		frozen := EvaluateRangeChart(backtestGroups(baseline, subgroup, rangeChart.SubgroupSize), rangeChart)
+		rbar, ok := frozen.Metadata["rbar"].(float64)
+		if !ok {
+			return constant(frozen), nil
+		}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			batch := newIncrementalBatch(history, len(history)-seen, nil)
+			results := []DetectorResult{}
+			for _, group := range backtestGroups(history, subgroup, rangeChart.SubgroupSize) {
+				if batch.isNew(group[len(group)-1]) {
+					results = append(results, EvaluateRangeChartFrozen(group, rbar, rangeChart.SubgroupSize))
+				}
+			}
+			if len(results) == 0 {
+				return insufficientData("no valid subgroups")
+			}
+			seen = len(history)
+			return mergeNewResults(results, batch.fresh)
+		}, nil
+	case "XBAR_R", "XBAR_S":
+		xbar := *detector.XbarChart
+		baselineGroups := backtestGroups(baseline, subgroup, xbar.SubgroupSize)
+		return func(history []Sample) DetectorResult {
+			groups := backtestGroups(history, subgroup, xbar.SubgroupSize)
+			if window := xbarEvalWindow(xbar); len(groups) > window {
+				groups = groups[len(groups)-window:]
+			}
+			return EvaluateXbarChart(baselineGroups, groups, xbar, xbarDispersion(detector.Type), true)
+		}, nil
+	case "I_MR":
+		imr := *detector.IMR
+		return func(history []Sample) DetectorResult {
+			return EvaluateIMR(baseline, lastSamples(history, imrEvalWindow(imr)+1), imr, true)
+		}, nil
+	case "CAPABILITY":
+		capability := *detector.Capability
+		return func(history []Sample) DetectorResult {
+			return EvaluateCapability(lastSamples(history, capabilityWindow(capability)), capability, true)
+		}, nil
+	case "FLATLINE":
+		flatline := *detector.Flatline
+		window := flatlineEvalWindow(flatline)
+		if flatlineSpan(flatline) > 0 {
+			window = 0
+		}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateFlatline(lastSamples(points, window), flatline, true)
+			})
+		}, nil
+	case "STEP_CHANGE":
+		stepChange := *detector.StepChange
+		window := 2 * stepChangeWindow(stepChange)
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateStepChange(lastSamples(points, window), stepChange, 1)
+			})
+		}, nil
+	case "RATE_OF_CHANGE":
+		rate := *detector.RateOfChange
+		window := rateSmoothingWindow(rate) + 1
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateRateOfChange(lastSamples(points, window), rate, true)
+			})
+		}, nil
+	case "TEXT_MATCH":
+		textMatch := *detector.TextMatch
+		seen := start
+		return func(history []Sample) DetectorResult {
+			eval := history[seen:]
+			seen = len(history)
+			return EvaluateTextMatch(eval, textMatch)
+		}, nil
+	case "TREND_6_POINTS":
+		trend := *detector.Trend
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateTrend6(lastSamples(points, trend.WindowSize), trend)
+			})
+		}, nil
+	case "TPA":
+		tpa := *detector.TPA
+		if tpa.RegressionTimeBasis == "" && spec.Source.TimestampColumn == "" {
+			tpa.RegressionTimeBasis = "index"
+		}
+		window := tpa.WindowN
+		if window == 0 {
+			window = 3
+		}
+		seen := start
+		return func(history []Sample) DetectorResult {
+			return backtestNewPoints(&seen, history, func(points []Sample) DetectorResult {
+				return EvaluateTPA(lastSamples(points, window), tpa)
+			})
+		}, nil
+	default:
+		return nil, errors.New("unsupported rule type")
+	}
+}
+
+// backtestNewPoints evaluates the samples that arrived since the last
+// completed poll, the way the scheduler's watermark does, and moves seen past
+// them once the evaluation completes.
+func backtestNewPoints(seen *int, history []Sample, evaluate func([]Sample) DetectorResult) DetectorResult {
+	result := evaluateNewPoints(history, len(history)-*seen, evaluate)
+	if result.Status == statusOK || result.Status == statusViolation {
+		*seen = len(history)
+	}
+	return result
+}
+
+// incrementalRuleType reports whether scheduled runs of ruleType only look at
+// samples they have not evaluated yet, so polls without new data are no-ops.
+func incrementalRuleType(ruleType string) bool {
+	switch ruleType {
+	case "SPEC_LIMIT_VIOLATION":
+		return false
+	default:
+		return true
+	}
+}
+
+// backtestPolls lists the polls a live rule would have made over samples.
+// Ordered sources are evaluated once per new run; timestamped sources are
+// polled every interval, and intervals without new data repeat the last poll.
+func backtestPolls(samples []Sample, start int, ordered bool, interval int) []backtestPoll {
+	polls := []backtestPoll{}
+	if start >= len(samples) {
+		return polls
+	}
+	if ordered || interval <= 0 || samples[start].TS.IsZero() {
+		for i := start; i < len(samples); i++ {
+			polls = append(polls, backtestPoll{Index: i, At: samples[i].TS, Repeat: 1})
+		}
+		return polls
+	}
+	step := time.Duration(interval) * time.Second
+	at := samples[start].TS
+	i := start
+	for {
+		for i+1 < len(samples) && !samples[i+1].TS.After(at) {
+			i++
+		}
+		polls = append(polls, backtestPoll{Index: i, At: at, Repeat: 1})
+		if i+1 >= len(samples) {
+			return polls
+		}
+		gap := samples[i+1].TS.Sub(at)
+		next := int((gap + step - 1) / step)
+		polls[len(polls)-1].Repeat = next
+		at = at.Add(time.Duration(next) * step)
+	}
+}
+
+type backtestReplay struct {
+	cooldown     int
+	resolveAfter int
+	alerts       []BacktestAlert
+	open         int
+	okStreak     int
+	lastSeen     time.Time
+	suppressed   int
+}
+
+// observe applies one poll result and reports whether it opened a new alert.
+func (b *backtestReplay) observe(result DetectorResult, sample Sample, at time.Time) bool {
+	if !result.Hit {
+		if result.Status == statusOK && b.open >= 0 {
+			b.okStreak++
+			if b.okStreak >= b.resolveAfter {
+				b.alerts[b.open].ResolvedAt = formatTime(timePtr(at))
+				b.alerts[b.open].ResolvedOrder = sample.Order
+				b.open = -1
+				b.okStreak = 0
+			}
+		}
+		return false
+	}
+	b.okStreak = 0
+	if b.open >= 0 {
+		alert := &b.alerts[b.open]
+		alert.Occurrences++
+		alert.Severity = result.Severity
+		alert.Observed = result.Observed
+		alert.LastSeenAt = formatTime(timePtr(at))
+		b.lastSeen = at
+		return false
+	}
+	if b.cooldown > 0 && !b.lastSeen.IsZero() && !at.IsZero() && monitor.WithinCooldownAt(b.lastSeen, at, b.cooldown) {
+		b.suppressed++
+		return false
+	}
+	alert := BacktestAlert{
+		OpenedAt:    formatTime(timePtr(at)),
+		OpenedOrder: sample.Order,
+		LastSeenAt:  formatTime(timePtr(at)),
+		Severity:    result.Severity,
+		Observed:    result.Observed,
+		LimitExpr:   result.LimitExpr,
+		Occurrences: 1,
+	}
+	if len(result.Violations) > 0 {
+		alert.Reason = result.Violations[len(result.Violations)-1].Reason
+	}
+	b.alerts = append(b.alerts, alert)
+	b.open = len(b.alerts) - 1
+	b.lastSeen = at
+	return true
+}
+
+func parseBacktestIncidents(selectors []selectorSpec) ([]backtestIncident, error) {
+	incidents := make([]backtestIncident, 0, len(selectors))
+	for _, selector := range selectors {
+		switch selector.Kind {
+		case "timeRange":
+			start, end, err := parseTimeRange(TimeRangeSpec{Start: selector.Start, End: selector.End})
+			if err != nil {
+				return nil, err
+			}
+			incidents = append(incidents, backtestIncident{start: &start, end: &end})
+		case "runRange":
+			if selector.From == nil || selector.To == nil || *selector.To < *selector.From {
+				return nil, errors.New("invalid incident runRange")
+			}
+			incidents = append(incidents, backtestIncident{from: selector.From, to: selector.To})
+		default:
+			return nil, errors.New("invalid incident kind")
+		}
+	}
+	return incidents, nil
+}
+
+func matchBacktestIncident(incidents []backtestIncident, sample Sample) int {
+	for i, incident := range incidents {
+		if incident.start != nil && !sample.TS.IsZero() && !sample.TS.Before(*incident.start) && !sample.TS.After(*incident.end) {
+			return i
+		}
+		if incident.from != nil && sample.Order != nil && *sample.Order >= *incident.from && *sample.Order <= *incident.to {
+			return i
+		}
+	}
+	return -1
+}
+
+func backtestUsesBaseline(ruleType string) bool {
+	switch ruleType {
+	case "SHEWHART_3SIGMA", "SHEWHART_2SIGMA", "RUN_RULES", "EWMA", "CUSUM", "RANGE_CHART_R", "XBAR_R", "XBAR_S", "I_MR":
+		return true
+	}
+	return isAttributeRuleType(ruleType)
+}
+
+func backtestGroups(samples []Sample, subgroup *subgroupSpec, size int) [][]Sample {
+	if subgroup != nil && subgroup.Kind == "column" {
+		return groupBySubgroup(samples, size)
+	}
+	return groupConsecutive(samples, size)
+}
+
+func lastSamples(samples []Sample, n int) []Sample {
+	if n > 0 && len(samples) > n {
+		return samples[len(samples)-n:]
+	}
+	return samples
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+	"predixaai-backend/services/scheduler-service/internal/security"
+)
+
+func TestBacktestPollsRepeatIdleIntervals(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{{TS: t0}, {TS: t0.Add(30 * time.Second)}, {TS: t0.Add(200 * time.Second)}}
+	polls := backtestPolls(samples, 0, false, 60)
+	if len(polls) != 3 {
+		t.Fatalf("expected 3 polls, got %+v", polls)
+	}
+	if polls[1].Index != 1 || !polls[1].At.Equal(t0.Add(60*time.Second)) || polls[1].Repeat != 3 {
+		t.Fatalf("unexpected idle poll %+v", polls[1])
+	}
+	if polls[2].Index != 2 || !polls[2].At.Equal(t0.Add(240*time.Second)) {
+		t.Fatalf("unexpected last poll %+v", polls[2])
+	}
+	if ordered := backtestPolls(samples, 1, true, 60); len(ordered) != 2 || ordered[0].Index != 1 {
+		t.Fatalf("expected one poll per run, got %+v", ordered)
+	}
+}
+
+func TestBacktestReplayCooldownAndResolve(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hit := DetectorResult{Hit: true, Status: statusViolation, Severity: "high"}
+	ok := DetectorResult{Status: statusOK}
+	replay := backtestReplay{cooldown: 120, resolveAfter: 2, open: -1}
+	steps := []struct {
+		result  DetectorResult
+		offset  time.Duration
+		created bool
+	}{
+		{hit, 0, true},
+		{hit, 60 * time.Second, false},
+		{ok, 90 * time.Second, false},
+		{ok, 120 * time.Second, false},
+		{hit, 150 * time.Second, false},
+		{hit, 200 * time.Second, true},
+	}
+	for i, step := range steps {
+		if created := replay.observe(step.result, Sample{}, t0.Add(step.offset)); created != step.created {
+			t.Fatalf("step %d: expected created=%v", i, step.created)
+		}
+	}
+	if len(replay.alerts) != 2 || replay.suppressed != 1 {
+		t.Fatalf("expected 2 alerts and 1 suppressed, got %d/%d", len(replay.alerts), replay.suppressed)
+	}
+	if replay.alerts[0].Occurrences != 2 || replay.alerts[0].ResolvedAt == "" {
+		t.Fatalf("expected first alert updated then resolved, got %+v", replay.alerts[0])
+	}
+}
+
+func TestStepperBacktestShewhart(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	values := alternatingValues(20)
+	values = append(values, 10, 10, 20, 20, 10, 10, 10, 10, 20, 10)
+	rows := []mcp.Row{}
+	for i := len(values) - 1; i >= 0; i-- {
+		rows = append(rows, mcp.Row{"value": values[i], "ts": t0.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)})
+	}
+	adapter := &mcp.MockAdapter{
+		Tables: []string{"telemetry"},
+		Columns: map[string][]mcp.Column{
+			"telemetry": {{Name: "value", Type: "float"}, {Name: "ts", Type: "timestamp"}},
+		},
+		RecentRows: mcp.FetchRecentRowsResult{Rows: rows},
+	}
+	config, _ := json.Marshal(map[string]any{"pollIntervalSeconds": 60, "baseline": map[string]any{"selector": map[string]any{"kind": "lastN", "value": 20}}})
+	resp, err := StepperBacktest(context.Background(), adapter, security.Allowlist{Tables: []string{"telemetry"}}, security.DefaultLimits(), StepperBacktestRequest{
+		ConnectionRef:   "conn",
+		Table:           "telemetry",
+		TimestampColumn: "ts",
+		ValueColumn:     "value",
+		RuleType:        "SHEWHART_3SIGMA",
+		Config:          config,
+		Range:           selectorSpec{Kind: "lastN", Value: 30},
+		Incidents: []selectorSpec{{
+			Kind:  "timeRange",
+			Start: t0.Add(22 * time.Minute).Format(time.RFC3339),
+			End:   t0.Add(23 * time.Minute).Format(time.RFC3339),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("backtest failed: %v", err)
+	}
+	if resp.Status != statusOK || resp.Evaluations != 10 || resp.Polls != 10 {
+		t.Fatalf("unexpected replay summary %+v", resp)
+	}
+	if len(resp.Alerts) != 2 || resp.Alerts[0].Occurrences != 2 || resp.Alerts[0].ResolvedAt == "" {
+		t.Fatalf("unexpected alerts %+v", resp.Alerts)
+	}
+	if resp.Alerts[0].FalseAlarm || !resp.Alerts[1].FalseAlarm || resp.FalseAlarms != 1 || resp.IncidentsDetected != 1 {
+		t.Fatalf("unexpected false alarm accounting %+v", resp)
+	}
+	if resp.CountsBySeverity["high"] != 2 || resp.FalseAlarmRate != 1.0/8 {
+		t.Fatalf("unexpected counts %v rate %v", resp.CountsBySeverity, resp.FalseAlarmRate)
+	}
+}
@@ -7,23 +7,58 @@ import (
 
 const defaultBaselineLastN = 50
 
-func buildBaselineWindow(now time.Time, baseline BaselineSpec, maxRows int) (time.Time, *time.Time, *time.Time, int, error) {
-	if baseline.LastN == nil && baseline.TimeRange == nil {
+// robustZWindow is the robust_zscore baseline: the last baselineWindowSeconds
+// of a timestamped source, or the last baselineRuns runs of an ordered source
+// without a timestamp column.
+func robustZWindow(source SourceSpec, spec RobustZSpec, now time.Time, maxRows int) sampleWindow {
+	if source.TimestampColumn != "" {
+		return sampleWindow{Since: now.Add(-time.Duration(spec.BaselineWindowSeconds) * time.Second), Limit: maxRows}
+	}
+	runs := spec.BaselineRuns
+	if runs <= 0 {
+		runs = max(defaultBaselineLastN, spec.MinSamples)
+	}
+	return sampleWindow{Limit: clampLimit(runs, maxRows)}
+}
+
+func buildBaselineWindow(now time.Time, baseline BaselineSpec, maxRows int) (sampleWindow, *time.Time, *time.Time, error) {
+	if baseline.LastN == nil && baseline.TimeRange == nil && baseline.RunRange == nil {
 		lastN := defaultBaselineLastN
-		return now.Add(-time.Hour * 24 * 365), nil, nil, clampLimit(lastN, maxRows), nil
+		return sampleWindow{Since: now.Add(-sampleLookback), Limit: clampLimit(lastN, maxRows)}, nil, nil, nil
+	}
+	if baseline.RunRange != nil {
+		if baseline.RunRange.To < baseline.RunRange.From {
+			return sampleWindow{}, nil, nil, errors.New("runRange to must be >= from")
+		}
+		from := baseline.RunRange.From
+		to := baseline.RunRange.To
+		return sampleWindow{Limit: maxRows, MinOrder: &from, MaxOrder: &to}, nil, nil, nil
 	}
 	if baseline.TimeRange != nil {
 		start, end, err := parseTimeRange(*baseline.TimeRange)
 		if err != nil {
-			return time.Time{}, nil, nil, 0, err
+			return sampleWindow{}, nil, nil, err
 		}
-		since := start
-		return since, &start, &end, maxRows, nil
+		return sampleWindow{Since: start, Limit: maxRows}, &start, &end, nil
 	}
 	if baseline.LastN == nil || *baseline.LastN <= 0 {
-		return time.Time{}, nil, nil, 0, errors.New("lastN must be > 0")
+		return sampleWindow{}, nil, nil, errors.New("lastN must be > 0")
+	}
+	return sampleWindow{Since: now.Add(-sampleLookback), Limit: clampLimit(*baseline.LastN, maxRows)}, nil, nil, nil
+}
+
+func baselineStats(baseline []Sample, mu, sigma *float64, minBaselineN int, population bool) (float64, float64, string, bool) {
+	if mu != nil && sigma != nil {
+		return *mu, *sigma, "frozen", true
+	}
+	if minBaselineN == 0 {
+		minBaselineN = defaultBaselineMinN
+	}
+	values := extractValues(baseline)
+	if len(values) < minBaselineN {
+		return 0, 0, "", false
 	}
-	return now.Add(-time.Hour * 24 * 365), nil, nil, clampLimit(*baseline.LastN, maxRows), nil
+	return Mean(values), StdDev(values, population), "baseline", true
 }
 
 func parseTimeRange(spec TimeRangeSpec) (time.Time, time.Time, error) {
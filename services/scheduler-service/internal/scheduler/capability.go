@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultCapabilityWindow     = 50
+	defaultCapabilityMinCpk     = 1.33
+	defaultCapabilityConfidence = 0.95
+	normalityAlpha              = 0.05
+)
+
+type CapabilityIndex struct {
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+type NormalityCheck struct {
+	Method    string  `json:"method"`
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"pValue"`
+	Normal    bool    `json:"normal"`
+	Evaluated bool    `json:"evaluated"`
+}
+
+type CapabilityResult struct {
+	N               int              `json:"n"`
+	Mean            float64          `json:"mean"`
+	SigmaWithin     float64          `json:"sigmaWithin"`
+	SigmaOverall    float64          `json:"sigmaOverall"`
+	USL             *float64         `json:"usl,omitempty"`
+	LSL             *float64         `json:"lsl,omitempty"`
+	ConfidenceLevel float64          `json:"confidenceLevel"`
+	Cp              *CapabilityIndex `json:"cp,omitempty"`
+	Cpk             *CapabilityIndex `json:"cpk,omitempty"`
+	Pp              *CapabilityIndex `json:"pp,omitempty"`
+	Ppk             *CapabilityIndex `json:"ppk,omitempty"`
+	Normality       NormalityCheck   `json:"normality"`
+}
+
+// ComputeCapability estimates short-term (Cp, Cpk) indices from the average
+// moving range and long-term (Pp, Ppk) indices from the overall standard
+// deviation. Cp and Pp need both limits; one-sided limits yield Cpk and Ppk only.
+func ComputeCapability(values []float64, limits SpecLimitBounds, confidence float64) (CapabilityResult, error) {
+	if limits.USL == nil && limits.LSL == nil {
+		return CapabilityResult{}, errors.New("spec limits required")
+	}
+	if limits.USL != nil && limits.LSL != nil && *limits.USL <= *limits.LSL {
+		return CapabilityResult{}, errors.New("usl must be greater than lsl")
+	}
+	n := len(values)
+	if n < 2 {
+		return CapabilityResult{}, errors.New("not enough samples")
+	}
+	if confidence <= 0 || confidence >= 1 {
+		confidence = defaultCapabilityConfidence
+	}
+	ranges := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		ranges = append(ranges, math.Abs(values[i]-values[i-1]))
+	}
+	mean := Mean(values)
+	result := CapabilityResult{
+		N:               n,
+		Mean:            mean,
+		SigmaWithin:     Mean(ranges) / imrD2,
+		SigmaOverall:    StdDev(values, false),
+		USL:             limits.USL,
+		LSL:             limits.LSL,
+		ConfidenceLevel: confidence,
+	}
+	if result.SigmaWithin <= 0 || result.SigmaOverall <= 0 {
+		return result, errors.New("sigma is zero")
+	}
+	result.Cp, result.Cpk = capabilityIndices(mean, result.SigmaWithin, limits, n, confidence)
+	result.Pp, result.Ppk = capabilityIndices(mean, result.SigmaOverall, limits, n, confidence)
+	result.Normality = NormalityCheck{Method: "anderson_darling"}
+	if stat, p, ok := AndersonDarling(values); ok {
+		result.Normality = NormalityCheck{Method: "anderson_darling", Statistic: stat, PValue: p, Normal: p >= normalityAlpha, Evaluated: true}
+	}
+	return result, nil
+}
+
+// capabilityIndices returns the potential and actual index for sigma. The
+// potential index uses a chi-square interval; the actual index uses Bissell's
+// normal approximation.
+func capabilityIndices(mean, sigma float64, limits SpecLimitBounds, n int, confidence float64) (*CapabilityIndex, *CapabilityIndex) {
+	alpha := 1 - confidence
+	dof := float64(n - 1)
+	z := NormalQuantile(1 - alpha/2)
+	var potential *CapabilityIndex
+	if limits.USL != nil && limits.LSL != nil {
+		value := (*limits.USL - *limits.LSL) / (6 * sigma)
+		potential = &CapabilityIndex{
+			Value: value,
+			Lower: value * math.Sqrt(ChiSquareQuantile(alpha/2, dof)/dof),
+			Upper: value * math.Sqrt(ChiSquareQuantile(1-alpha/2, dof)/dof),
+		}
+	}
+	value := math.Inf(1)
+	if limits.USL != nil {
+		value = math.Min(value, (*limits.USL-mean)/(3*sigma))
+	}
+	if limits.LSL != nil {
+		value = math.Min(value, (mean-*limits.LSL)/(3*sigma))
+	}
+	spread := z * math.Sqrt(1/(9*float64(n))+value*value/(2*dof))
+	return potential, &CapabilityIndex{Value: value, Lower: value - spread, Upper: value + spread}
+}
+
+// EvaluateCapability computes Cpk over a rolling window ending at each eval
+// sample and flags windows whose Cpk drops below the configured minimum.
+func EvaluateCapability(samples []Sample, spec CapabilitySpec, latestOnly bool) DetectorResult {
+	if spec.SpecLimits == nil {
+		return invalidConfig("spec limits required")
+	}
+	window := capabilityWindow(spec)
+	minCpk := spec.MinCpk
+	if minCpk == 0 {
+		minCpk = defaultCapabilityMinCpk
+	}
+	if len(samples) < window {
+		return insufficientData("not enough samples")
+	}
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		LimitExpr: fmt.Sprintf("cpk >= %.2f over %d samples", minCpk, window),
+		Metadata: map[string]any{
+			"minCpk": minCpk,
+			"window": window,
+		},
+	}
+	series := make([]map[string]any, 0, len(samples)-window+1)
+	var latest CapabilityResult
+	for end := window - 1; end < len(samples); end++ {
+		sample := samples[end]
+		limits := resolveSpecLimits(spec.SpecLimits, sample)
+		capability, err := ComputeCapability(extractValues(samples[end-window+1:end+1]), *limits, spec.ConfidenceLevel)
+		if err != nil {
+			if limits.USL == nil && limits.LSL == nil {
+				return invalidConfig(err.Error())
+			}
+			return insufficientData(err.Error())
+		}
+		latest = capability
+		point := map[string]any{"index": end, "cpk": capability.Cpk.Value, "ppk": capability.Ppk.Value}
+		if !sample.TS.IsZero() {
+			point["timestamp"] = sample.TS.UTC().Format(time.RFC3339)
+		}
+		if sample.Order != nil {
+			point["order"] = *sample.Order
+		}
+		series = append(series, point)
+		if latestOnly && end != len(samples)-1 {
+			continue
+		}
+		if capability.Cpk.Value < minCpk {
+			idx := end
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: capability.Cpk.Value, Reason: "cpk_below_min", LimitName: "Cpk", LimitValue: minCpk, Delta: capability.Cpk.Value - minCpk})
+		}
+	}
+	result.Observed = fmt.Sprint(latest.Cpk.Value)
+	result.Metadata["capability"] = latest
+	result.Metadata["series"] = series
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Metadata["limitBreached"] = "Cpk"
+	}
+	return result
+}
+
+func capabilityWindow(spec CapabilitySpec) int {
+	if spec.Window > 0 {
+		return spec.Window
+	}
+	return defaultCapabilityWindow
+}
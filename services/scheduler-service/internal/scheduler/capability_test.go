@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func alternatingValues(n int) []float64 {
+	values := []float64{}
+	for i := 0; i < n; i++ {
+		values = append(values, 9+float64(i%2)*2)
+	}
+	return values
+}
+
+func TestComputeCapabilityIndices(t *testing.T) {
+	usl, lsl := 16.0, 4.0
+	result, err := ComputeCapability(alternatingValues(20), SpecLimitBounds{USL: &usl, LSL: &lsl}, 0.95)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if math.Abs(result.Cp.Value-12/(6*2/imrD2)) > 1e-9 || math.Abs(result.Cpk.Value-result.Cp.Value) > 1e-9 {
+		t.Fatalf("unexpected cp/cpk %+v %+v", result.Cp, result.Cpk)
+	}
+	if math.Abs(result.Pp.Value-12/(6*StdDev(alternatingValues(20), false))) > 1e-9 {
+		t.Fatalf("unexpected pp %+v", result.Pp)
+	}
+	for _, index := range []*CapabilityIndex{result.Cp, result.Cpk, result.Pp, result.Ppk} {
+		if index.Lower >= index.Value || index.Upper <= index.Value {
+			t.Fatalf("expected interval around value, got %+v", index)
+		}
+	}
+	oneSided, err := ComputeCapability(alternatingValues(20), SpecLimitBounds{USL: &usl}, 0.95)
+	if err != nil || oneSided.Cp != nil || oneSided.Pp != nil || oneSided.Cpk == nil {
+		t.Fatalf("expected cpk only for one-sided limits, got %+v %v", oneSided, err)
+	}
+	if _, err := ComputeCapability(alternatingValues(20), SpecLimitBounds{}, 0.95); err == nil {
+		t.Fatalf("expected missing limits error")
+	}
+}
+
+func TestAndersonDarlingNormality(t *testing.T) {
+	normal, skewed := []float64{}, []float64{}
+	for i := 0; i < 50; i++ {
+		p := (float64(i) + 0.5) / 50
+		normal = append(normal, NormalQuantile(p))
+		skewed = append(skewed, -math.Log(1-p))
+	}
+	if _, p, ok := AndersonDarling(normal); !ok || p < normalityAlpha {
+		t.Fatalf("expected normal sample to pass, p=%v", p)
+	}
+	if _, p, ok := AndersonDarling(skewed); !ok || p >= normalityAlpha {
+		t.Fatalf("expected exponential sample to fail, p=%v", p)
+	}
+}
+
+func TestEvaluateCapabilityRollingCpk(t *testing.T) {
+	usl, lsl := 16.0, 4.0
+	spec := CapabilitySpec{SpecLimits: &SpecLimitBounds{USL: &usl, LSL: &lsl}, Window: 20}
+	result := EvaluateCapability(runRulesSamples(alternatingValues(22)...), spec, false)
+	if !result.Hit || len(result.Violations) != 3 || result.Violations[0].Reason != "cpk_below_min" {
+		t.Fatalf("expected a violation per window, got %+v", result.Violations)
+	}
+	spec.MinCpk = 1
+	if result := EvaluateCapability(runRulesSamples(alternatingValues(22)...), spec, true); result.Hit {
+		t.Fatalf("expected cpk above 1, got %+v", result.Violations)
+	}
+	if result := EvaluateCapability(runRulesSamples(alternatingValues(10)...), spec, true); result.Status != statusInsufficient {
+		t.Fatalf("expected insufficient data, got %s", result.Status)
+	}
+	if result := EvaluateCapability(runRulesSamples(alternatingValues(22)...), CapabilitySpec{Window: 20}, true); result.Status != statusInvalidConfig {
+		t.Fatalf("expected invalid config, got %s", result.Status)
+	}
+}
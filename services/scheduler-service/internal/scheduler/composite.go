@@ -0,0 +1,249 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const compositeDetectorType = "composite"
+
+type parameterOutcome struct {
+	Param  ParameterSpec
+	Result DetectorResult
+	Err    error
+}
+
+// compositeTruth is the three-valued outcome of a condition: a parameter
+// that failed or lacked data makes every branch depending on it unknown.
+type compositeTruth int
+
+const (
+	compositeFalse compositeTruth = iota
+	compositeTrue
+	compositeUnknown
+)
+
+// compositeAnchor is the point every contributing result must end on: the
+// run being evaluated for ordered sources, else the newest timestamp any
+// parameter reached.
+type compositeAnchor struct {
+	order *int64
+	ts    *time.Time
+}
+
+// EvaluateComposite combines the parameter results of one run through the
+// condition tree. A parameter only counts when its result ends on the
+// anchor, so every contributor describes the same run or row; a condition
+// that depends on a failed parameter yields INSUFFICIENT_DATA.
+func EvaluateComposite(spec CompositeSpec, outcomes []parameterOutcome, runOrder *int64) DetectorResult {
+	anchor := compositeAnchor{order: runOrder}
+	if runOrder == nil {
+		for _, outcome := range outcomes {
+			if outcome.Err == nil && outcome.Result.WindowEnd != nil && (anchor.ts == nil || outcome.Result.WindowEnd.After(*anchor.ts)) {
+				anchor.ts = outcome.Result.WindowEnd
+			}
+		}
+	}
+	truth, contributors := evaluateCompositeCondition(spec.Condition, outcomes, anchor)
+	switch truth {
+	case compositeFalse:
+		return DetectorResult{Status: statusOK}
+	case compositeUnknown:
+		result := DetectorResult{Status: statusInsufficient, Metadata: map[string]any{"error": "composite condition depends on a parameter without a result"}}
+		for _, outcome := range outcomes {
+			if !outcomeKnown(outcome) {
+				result.Metadata["error"] = fmt.Sprintf("parameter %s: %s", outcome.Param.ParameterName, runError(outcome.Result, outcome.Err))
+				break
+			}
+		}
+		return result
+	}
+	sort.Ints(contributors)
+	severity := spec.Severity
+	observed := make([]string, 0, len(contributors))
+	details := make([]map[string]any, 0, len(contributors))
+	for _, idx := range contributors {
+		outcome := outcomes[idx]
+		if spec.Severity == "" && (severity == "" || outcome.Result.Severity == "high") {
+			severity = outcome.Result.Severity
+		}
+		observed = append(observed, fmt.Sprintf("%s=%s", outcome.Param.ParameterName, outcome.Result.Observed))
+		detail := map[string]any{
+			"parameterName":   outcome.Param.ParameterName,
+			"valueColumn":     outcome.Param.ValueColumn,
+			"detector":        outcome.Param.Detector.Type,
+			"observed":        outcome.Result.Observed,
+			"limitExpression": outcome.Result.LimitExpr,
+			"severity":        outcome.Result.Severity,
+			"explain":         buildExplain(outcome.Result, outcome.Param),
+		}
+		if outcome.Result.OrderEnd != nil {
+			detail["order"] = *outcome.Result.OrderEnd
+		}
+		if len(outcome.Result.Violations) > 0 {
+			detail["violations"] = outcome.Result.Violations
+		}
+		details = append(details, detail)
+	}
+	if severity == "" {
+		severity = "high"
+	}
+	return DetectorResult{
+		Hit:       true,
+		Status:    statusViolation,
+		Severity:  severity,
+		Observed:  strings.Join(observed, ", "),
+		LimitExpr: describeCompositeCondition(spec.Condition),
+		Metadata:  map[string]any{"contributors": details},
+	}
+}
+
+func evaluateCompositeCondition(cond CompositeCondition, outcomes []parameterOutcome, anchor compositeAnchor) (compositeTruth, []int) {
+	switch strings.ToLower(cond.Op) {
+	case "and":
+		if len(cond.Conditions) == 0 {
+			return compositeFalse, nil
+		}
+		truth := compositeTrue
+		contributors := []int{}
+		for _, child := range cond.Conditions {
+			childTruth, indexes := evaluateCompositeCondition(child, outcomes, anchor)
+			switch childTruth {
+			case compositeFalse:
+				return compositeFalse, nil
+			case compositeUnknown:
+				truth = compositeUnknown
+			}
+			contributors = append(contributors, indexes...)
+		}
+		if truth != compositeTrue {
+			return truth, nil
+		}
+		return compositeTrue, dedupeIndexes(contributors)
+	case "or", "atleast":
+		required := 1
+		if strings.EqualFold(cond.Op, "atLeast") {
+			required = cond.Count
+		}
+		if required < 1 {
+			return compositeFalse, nil
+		}
+		hits, unknown := 0, 0
+		contributors := []int{}
+		for _, child := range cond.Conditions {
+			switch childTruth, indexes := evaluateCompositeCondition(child, outcomes, anchor); childTruth {
+			case compositeTrue:
+				hits++
+				contributors = append(contributors, indexes...)
+			case compositeUnknown:
+				unknown++
+			}
+		}
+		switch {
+		case hits >= required:
+			return compositeTrue, dedupeIndexes(contributors)
+		case hits+unknown >= required:
+			return compositeUnknown, nil
+		default:
+			return compositeFalse, nil
+		}
+	case "not":
+		if len(cond.Conditions) != 1 {
+			return compositeFalse, nil
+		}
+		switch truth, _ := evaluateCompositeCondition(cond.Conditions[0], outcomes, anchor); truth {
+		case compositeTrue:
+			return compositeFalse, nil
+		case compositeFalse:
+			return compositeTrue, nil
+		default:
+			return compositeUnknown, nil
+		}
+	case "", "parameter":
+		truth := compositeFalse
+		contributors := []int{}
+		for i, outcome := range outcomes {
+			if outcome.Param.ParameterName != cond.Parameter || (cond.Detector != "" && outcome.Param.Detector.Type != cond.Detector) {
+				continue
+			}
+			if !outcomeKnown(outcome) {
+				truth = compositeUnknown
+				continue
+			}
+			if outcome.Result.Hit && outcomeAligned(outcome, anchor) {
+				contributors = append(contributors, i)
+			}
+		}
+		if len(contributors) > 0 {
+			return compositeTrue, contributors
+		}
+		return truth, nil
+	default:
+		return compositeFalse, nil
+	}
+}
+
+// outcomeKnown reports whether the parameter produced a usable verdict.
+func outcomeKnown(outcome parameterOutcome) bool {
+	status := runStatus(outcome.Result, outcome.Err)
+	return status == statusOK || status == statusViolation
+}
+
+// outcomeAligned reports whether a result ends on the composite's anchor.
+// missing_data judges the current time rather than a row, so it always is.
+func outcomeAligned(outcome parameterOutcome, anchor compositeAnchor) bool {
+	if outcome.Param.Detector.Type == "missing_data" {
+		return true
+	}
+	if anchor.order != nil {
+		return outcome.Result.OrderEnd != nil && *outcome.Result.OrderEnd == *anchor.order
+	}
+	return anchor.ts != nil && outcome.Result.WindowEnd != nil && outcome.Result.WindowEnd.Equal(*anchor.ts)
+}
+
+func describeCompositeCondition(cond CompositeCondition) string {
+	parts := make([]string, 0, len(cond.Conditions))
+	for _, child := range cond.Conditions {
+		part := describeCompositeCondition(child)
+		if len(child.Conditions) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	switch strings.ToLower(cond.Op) {
+	case "and":
+		return strings.Join(parts, " AND ")
+	case "or":
+		return strings.Join(parts, " OR ")
+	case "atleast":
+		return fmt.Sprintf("%d of [%s]", cond.Count, strings.Join(parts, ", "))
+	case "not":
+		return "NOT " + strings.Join(parts, "")
+	default:
+		if cond.Detector != "" {
+			return fmt.Sprintf("%s %s", cond.Parameter, cond.Detector)
+		}
+		return cond.Parameter
+	}
+}
+
+func compositeName(spec CompositeSpec) string {
+	if strings.TrimSpace(spec.Name) != "" {
+		return spec.Name
+	}
+	return compositeDetectorType
+}
+
+func dedupeIndexes(indexes []int) []int {
+	seen := map[int]bool{}
+	result := make([]int, 0, len(indexes))
+	for _, idx := range indexes {
+		if !seen[idx] {
+			seen[idx] = true
+			result = append(result, idx)
+		}
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateCompositeAndWithinRun(t *testing.T) {
+	run := int64(42)
+	stale := int64(41)
+	outcomes := []parameterOutcome{
+		{Param: ParameterSpec{ParameterName: "rf_power", Detector: DetectorSpec{Type: "threshold"}}, Result: DetectorResult{Hit: true, Severity: "medium", Observed: "19.4", OrderEnd: &run}},
+		{Param: ParameterSpec{ParameterName: "chamber_pressure", Detector: DetectorSpec{Type: "robust_zscore"}}, Result: DetectorResult{Hit: true, Severity: "high", Observed: "3.2", OrderEnd: &run}},
+	}
+	spec := CompositeSpec{Name: "rf_pressure", Condition: CompositeCondition{Op: "and", Conditions: []CompositeCondition{
+		{Parameter: "rf_power"},
+		{Parameter: "chamber_pressure", Detector: "robust_zscore"},
+	}}}
+	result := EvaluateComposite(spec, outcomes, &run)
+	if !result.Hit || result.Severity != "high" || result.Observed != "rf_power=19.4, chamber_pressure=3.2" {
+		t.Fatalf("unexpected composite result %+v", result)
+	}
+	if result.LimitExpr != "rf_power AND chamber_pressure robust_zscore" {
+		t.Fatalf("unexpected limit expression %q", result.LimitExpr)
+	}
+	if contributors := result.Metadata["contributors"].([]map[string]any); len(contributors) != 2 {
+		t.Fatalf("expected 2 contributors, got %v", contributors)
+	}
+	outcomes[1].Result.OrderEnd = &stale
+	if result := EvaluateComposite(spec, outcomes, &run); result.Hit || result.Status != statusOK {
+		t.Fatalf("expected hit from an earlier run to be ignored, got %+v", result)
+	}
+}
+
+func TestEvaluateCompositeAtLeast(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	outcomes := []parameterOutcome{
+		{Param: ParameterSpec{ParameterName: "a"}, Result: DetectorResult{Hit: true, Severity: "medium", WindowEnd: &now}},
+		{Param: ParameterSpec{ParameterName: "b"}, Result: DetectorResult{Status: statusOK, WindowEnd: &now}},
+		{Param: ParameterSpec{ParameterName: "c"}, Result: DetectorResult{Hit: true, Severity: "medium", WindowEnd: &now}},
+	}
+	spec := CompositeSpec{Severity: "high", Condition: CompositeCondition{Op: "atLeast", Count: 2, Conditions: []CompositeCondition{
+		{Parameter: "a"}, {Parameter: "b"}, {Parameter: "c"},
+	}}}
+	result := EvaluateComposite(spec, outcomes, nil)
+	if !result.Hit || result.Severity != "high" || result.LimitExpr != "2 of [a, b, c]" {
+		t.Fatalf("unexpected composite result %+v", result)
+	}
+	outcomes[2] = parameterOutcome{Param: ParameterSpec{ParameterName: "c"}, Err: errors.New("query failed")}
+	if result := EvaluateComposite(spec, outcomes, nil); result.Hit || result.Status != statusInsufficient {
+		t.Fatalf("expected insufficient when a parameter failed, got %+v", result)
+	}
+	not := CompositeSpec{Condition: CompositeCondition{Op: "and", Conditions: []CompositeCondition{
+		{Parameter: "a"},
+		{Op: "not", Conditions: []CompositeCondition{{Parameter: "b"}}},
+	}}}
+	if result := EvaluateComposite(not, outcomes, nil); !result.Hit || result.Severity != "medium" {
+		t.Fatalf("expected a AND NOT b to hit, got %+v", result)
+	}
+}
+
+func TestEvaluateCompositeUnknownParameters(t *testing.T) {
+	run := int64(7)
+	outcomes := []parameterOutcome{
+		{Param: ParameterSpec{ParameterName: "a"}, Result: DetectorResult{Hit: true, Severity: "high", OrderEnd: &run}},
+		{Param: ParameterSpec{ParameterName: "b"}, Err: errors.New("query failed")},
+	}
+	not := CompositeSpec{Condition: CompositeCondition{Op: "not", Conditions: []CompositeCondition{{Parameter: "b"}}}}
+	if result := EvaluateComposite(not, outcomes, &run); result.Hit || result.Status != statusInsufficient {
+		t.Fatalf("expected NOT of a failed parameter to be insufficient, got %+v", result)
+	}
+	and := CompositeSpec{Condition: CompositeCondition{Op: "and", Conditions: []CompositeCondition{{Parameter: "a"}, {Parameter: "b"}}}}
+	if result := EvaluateComposite(and, outcomes, &run); result.Hit || result.Status != statusInsufficient {
+		t.Fatalf("expected a AND failed b to be insufficient, got %+v", result)
+	}
+	or := CompositeSpec{Condition: CompositeCondition{Op: "or", Conditions: []CompositeCondition{{Parameter: "a"}, {Parameter: "b"}}}}
+	if result := EvaluateComposite(or, outcomes, &run); !result.Hit {
+		t.Fatalf("expected a OR failed b to hit on a, got %+v", result)
+	}
+	outcomes[0].Result.OrderEnd = nil
+	if result := EvaluateComposite(or, outcomes, &run); result.Hit || result.Status != statusInsufficient {
+		t.Fatalf("expected a hit without a run order to be ignored, got %+v", result)
+	}
+}
@@ -14,3 +14,25 @@ var rangeChartConstants = map[int]struct{
 	9: {D3: 0.184, D4: 1.816},
 	10:{D3: 0.223, D4: 1.777},
 }
+
+var xbarChartConstants = map[int]struct{
+	A2 float64
+	A3 float64
+	B3 float64
+	B4 float64
+}{
+	2: {A2: 1.880, A3: 2.659, B3: 0, B4: 3.267},
+	3: {A2: 1.023, A3: 1.954, B3: 0, B4: 2.568},
+	4: {A2: 0.729, A3: 1.628, B3: 0, B4: 2.266},
+	5: {A2: 0.577, A3: 1.427, B3: 0, B4: 2.089},
+	6: {A2: 0.483, A3: 1.287, B3: 0.030, B4: 1.970},
+	7: {A2: 0.419, A3: 1.182, B3: 0.118, B4: 1.882},
+	8: {A2: 0.373, A3: 1.099, B3: 0.185, B4: 1.815},
+	9: {A2: 0.337, A3: 1.032, B3: 0.239, B4: 1.761},
+	10:{A2: 0.308, A3: 0.975, B3: 0.284, B4: 1.716},
+}
+
+const (
+	imrD2 = 1.128
+	imrD4 = 3.267
+)
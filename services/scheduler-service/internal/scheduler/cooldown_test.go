@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"predixaai-backend/services/scheduler-service/internal/monitor"
+	"predixaai-backend/services/scheduler-service/internal/security"
 )
 
 func TestWithinCooldown(t *testing.T) {
@@ -13,3 +14,27 @@ func TestWithinCooldown(t *testing.T) {
 		t.Fatalf("expected within cooldown")
 	}
 }
+
+func TestAutoResolveAfter(t *testing.T) {
+	if got := autoResolveAfter(RuleSpec{}); got != defaultAutoResolveAfter {
+		t.Fatalf("expected default %d, got %d", defaultAutoResolveAfter, got)
+	}
+	value := 5
+	if got := autoResolveAfter(RuleSpec{AutoResolveAfter: &value}); got != 5 {
+		t.Fatalf("expected 5, got %d", got)
+	}
+}
+
+func TestListJobsReportsShadow(t *testing.T) {
+	reg := NewRegistry(nil, security.DefaultLimits(), 0, time.Second)
+	defer reg.Stop()
+	reg.ScheduleStepper("r1", RuleSpec{PollIntervalSeconds: 60}, nil, true)
+	reg.ScheduleStepper("r2", RuleSpec{PollIntervalSeconds: 60}, nil, false)
+	shadow := map[string]bool{}
+	for _, job := range reg.ListJobs() {
+		shadow[job.RuleID] = job.Shadow
+	}
+	if !shadow["r1"] || shadow["r2"] {
+		t.Fatalf("unexpected shadow flags %v", shadow)
+	}
+}
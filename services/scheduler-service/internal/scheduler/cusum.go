@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultCUSUMK          = 0.5
+	defaultCUSUMH          = 5.0
+	defaultCUSUMEvalWindow = 100
+)
+
+// CUSUMState carries the tabular sums between polls so that a scheduled rule
+// only folds in samples it has not seen yet. The baseline target is computed
+// once per config and reused.
+type CUSUMState struct {
+	ConfigHash   string      `json:"configHash"`
+	Target       *float64    `json:"target,omitempty"`
+	Sigma        *float64    `json:"sigma,omitempty"`
+	TargetSource string      `json:"targetSource,omitempty"`
+	Upper        float64     `json:"upper"`
+	Lower        float64     `json:"lower"`
+	UpperRun     int         `json:"upperRun"`
+	LowerRun     int         `json:"lowerRun"`
+	UpperStart   *cusumPoint `json:"upperStart,omitempty"`
+	LowerStart   *cusumPoint `json:"lowerStart,omitempty"`
+	Watermark
+}
+
+type cusumPoint struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Order     *int64     `json:"order,omitempty"`
+}
+
+func EvaluateCUSUM(baseline []Sample, eval []Sample, spec CUSUMSpec, state *CUSUMState) DetectorResult {
+	k := spec.K
+	if k == 0 {
+		k = defaultCUSUMK
+	}
+	h := spec.H
+	if h == 0 {
+		h = defaultCUSUMH
+	}
+	if state == nil {
+		state = &CUSUMState{}
+	}
+	target, sigma, source, ok := cusumTarget(baseline, spec, *state)
+	if !ok {
+		return insufficientData("baseline too small")
+	}
+	if sigma <= 0 {
+		return insufficientData("baseline sigma is zero")
+	}
+	if len(eval) == 0 {
+		return insufficientData("not enough samples")
+	}
+	state.Target, state.Sigma, state.TargetSource = &target, &sigma, source
+	slack, decision := k*sigma, h*sigma
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(eval[len(eval)-1].Value),
+		LimitExpr: fmt.Sprintf("cusum k=%.2fσ h=%.2fσ", k, h),
+		Metadata: map[string]any{
+			"target":         target,
+			"sigma":          sigma,
+			"k":              k,
+			"h":              h,
+			"baselineSource": source,
+		},
+	}
+	upperIdx, lowerIdx := -1, -1
+	series := make([]map[string]any, 0, len(eval))
+	for i, sample := range eval {
+		state.Upper = math.Max(0, state.Upper+sample.Value-target-slack)
+		state.Lower = math.Max(0, state.Lower+target-slack-sample.Value)
+		if state.Upper > 0 {
+			if state.UpperRun == 0 {
+				upperIdx = i
+				state.UpperStart = &cusumPoint{Timestamp: timePtr(sample.TS), Order: sample.Order}
+			}
+			state.UpperRun++
+		} else {
+			upperIdx, state.UpperRun, state.UpperStart = -1, 0, nil
+		}
+		if state.Lower > 0 {
+			if state.LowerRun == 0 {
+				lowerIdx = i
+				state.LowerStart = &cusumPoint{Timestamp: timePtr(sample.TS), Order: sample.Order}
+			}
+			state.LowerRun++
+		} else {
+			lowerIdx, state.LowerRun, state.LowerStart = -1, 0, nil
+		}
+		point := map[string]any{"index": i, "value": sample.Value, "upper": state.Upper, "lower": state.Lower}
+		if !sample.TS.IsZero() {
+			point["timestamp"] = sample.TS.UTC().Format(time.RFC3339)
+		}
+		if sample.Order != nil {
+			point["order"] = *sample.Order
+		}
+		series = append(series, point)
+		alarmed := false
+		if state.Upper > decision {
+			addViolation(&result, cusumViolation(sample, i, "upper", state.Upper, decision, upperIdx))
+			setCUSUMChangePoint(&result, "upper", upperIdx, state.UpperStart, target+slack+state.Upper/float64(state.UpperRun))
+			alarmed = true
+		}
+		if state.Lower > decision {
+			addViolation(&result, cusumViolation(sample, i, "lower", state.Lower, decision, lowerIdx))
+			setCUSUMChangePoint(&result, "lower", lowerIdx, state.LowerStart, target-slack-state.Lower/float64(state.LowerRun))
+			alarmed = true
+		}
+		if alarmed {
+			*state = CUSUMState{ConfigHash: state.ConfigHash, Target: state.Target, Sigma: state.Sigma, TargetSource: state.TargetSource, Watermark: state.Watermark}
+			upperIdx, lowerIdx = -1, -1
+		}
+		state.Watermark = state.Watermark.advancedBy([]Sample{sample})
+	}
+	result.Metadata["upper"] = state.Upper
+	result.Metadata["lower"] = state.Lower
+	result.Metadata["series"] = series
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Metadata["limitBreached"] = result.Violations[len(result.Violations)-1].LimitName
+	}
+	return result
+}
+
+func cusumTarget(baseline []Sample, spec CUSUMSpec, state CUSUMState) (float64, float64, string, bool) {
+	if spec.Target != nil && spec.Sigma != nil {
+		return *spec.Target, *spec.Sigma, "frozen", true
+	}
+	if state.Target != nil && state.Sigma != nil {
+		return *state.Target, *state.Sigma, state.TargetSource, true
+	}
+	mu, sigma, source, ok := baselineStats(baseline, nil, nil, spec.MinBaselineN, spec.PopulationSigma)
+	if !ok {
+		return 0, 0, "", false
+	}
+	if spec.Target != nil {
+		mu = *spec.Target
+	}
+	if spec.Sigma != nil {
+		sigma = *spec.Sigma
+	}
+	return mu, sigma, source, true
+}
+
+func cusumViolation(sample Sample, index int, side string, sum, decision float64, changePoint int) Violation {
+	idx := index
+	violation := Violation{
+		Timestamp:  timePtr(sample.TS),
+		Index:      &idx,
+		Order:      sample.Order,
+		Value:      sum,
+		Reason:     "cusum_" + side,
+		LimitName:  "H",
+		LimitValue: decision,
+		Delta:      sum - decision,
+	}
+	if changePoint >= 0 {
+		violation.ChangePoint = &changePoint
+	}
+	return violation
+}
+
+func setCUSUMChangePoint(result *DetectorResult, side string, index int, start *cusumPoint, estimatedMean float64) {
+	result.Metadata["side"] = side
+	result.Metadata["estimatedMean"] = estimatedMean
+	delete(result.Metadata, "changePointIndex")
+	delete(result.Metadata, "changePointTimestamp")
+	delete(result.Metadata, "changePointOrder")
+	if index >= 0 {
+		result.Metadata["changePointIndex"] = index
+	}
+	if start == nil {
+		return
+	}
+	if start.Timestamp != nil {
+		result.Metadata["changePointTimestamp"] = start.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if start.Order != nil {
+		result.Metadata["changePointOrder"] = *start.Order
+	}
+}
+
+// newCUSUMSamples drops samples already folded into the persisted sums.
+func newCUSUMSamples(samples []Sample, state CUSUMState) []Sample {
+	return samplesAfter(samples, state.Watermark)
+}
+
+func cusumEvalWindow(spec CUSUMSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return defaultCUSUMEvalWindow
+}
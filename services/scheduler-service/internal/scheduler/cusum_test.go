@@ -0,0 +1,59 @@
+package scheduler
+
+import "testing"
+
+func TestEvaluateCUSUMUpperShift(t *testing.T) {
+	target, sigma := 10.0, 1.0
+	spec := CUSUMSpec{K: 0.5, H: 4, Target: &target, Sigma: &sigma}
+	result := EvaluateCUSUM(nil, runRulesSamples(10, 10, 10, 11.5, 11.5, 11.5, 11.5, 11.5, 10), spec, nil)
+	if !result.Hit || len(result.Violations) != 1 {
+		t.Fatalf("expected single cusum alarm, got %+v", result)
+	}
+	violation := result.Violations[0]
+	if violation.Reason != "cusum_upper" || *violation.Index != 7 || violation.ChangePoint == nil || *violation.ChangePoint != 3 {
+		t.Fatalf("unexpected violation %+v", violation)
+	}
+	if result.Metadata["side"] != "upper" || result.Metadata["upper"] != 0.0 {
+		t.Fatalf("expected upper alarm and reset, got %+v", result.Metadata)
+	}
+}
+
+func TestEvaluateCUSUMCarriesState(t *testing.T) {
+	target, sigma := 10.0, 1.0
+	spec := CUSUMSpec{K: 0.5, H: 4, Target: &target, Sigma: &sigma}
+	samples := runRulesSamples(8.5, 8.5, 8.5, 8.5, 8.5, 8.5)
+	for i := range samples {
+		order := int64(i + 1)
+		samples[i].Order = &order
+	}
+	state := CUSUMState{}
+	if first := EvaluateCUSUM(nil, samples[:3], spec, &state); first.Hit || state.Lower != 3 {
+		t.Fatalf("expected accumulating lower sum, got %+v", state)
+	}
+	if fresh := newCUSUMSamples(samples, state); len(fresh) != 3 {
+		t.Fatalf("expected only unseen samples, got %d", len(fresh))
+	}
+	second := EvaluateCUSUM(nil, newCUSUMSamples(samples, state), spec, &state)
+	if !second.Hit || second.Violations[0].Reason != "cusum_lower" {
+		t.Fatalf("expected lower alarm, got %+v", second)
+	}
+	if second.Violations[0].ChangePoint != nil || second.Metadata["changePointOrder"] != int64(1) {
+		t.Fatalf("expected change point from earlier poll, got %+v", second.Metadata)
+	}
+	if state.Lower != 1 || state.LowerRun != 1 || *state.LastOrder != 6 {
+		t.Fatalf("expected sums to restart after alarm, got %+v", state)
+	}
+}
+
+func TestEvaluateCUSUMCachesBaselineTarget(t *testing.T) {
+	state := CUSUMState{}
+	baseline := runRulesSamples(9, 11, 9, 11, 9, 11, 9, 11, 9, 11)
+	first := EvaluateCUSUM(baseline, runRulesSamples(10), CUSUMSpec{K: 0.5, H: 4, MinBaselineN: 10}, &state)
+	if first.Status != statusOK || state.Target == nil || *state.Target != 10 || state.Sigma == nil {
+		t.Fatalf("expected target cached from baseline, got %+v", state)
+	}
+	second := EvaluateCUSUM(nil, runRulesSamples(10), CUSUMSpec{K: 0.5, H: 4, MinBaselineN: 10}, &state)
+	if second.Status != statusOK || second.Metadata["target"] != 10.0 {
+		t.Fatalf("expected cached target without baseline, got %+v", second)
+	}
+}
@@ -24,17 +24,26 @@ type DetectorResult struct {
 	WindowEnd      *time.Time
 	BaselineStart  *time.Time
 	BaselineEnd    *time.Time
+	OrderStart     *int64
+	OrderEnd       *int64
 	Violations     []Violation
+	SampleCount    int
 }
 
 type Violation struct {
-	Timestamp  *time.Time `json:"timestamp,omitempty"`
-	Index      *int       `json:"index,omitempty"`
-	Value      float64    `json:"value"`
-	Reason     string     `json:"reason"`
-	LimitName  string     `json:"limitName"`
-	LimitValue float64    `json:"limitValue"`
-	Delta      float64    `json:"delta"`
+	Timestamp   *time.Time `json:"timestamp,omitempty"`
+	Index       *int       `json:"index,omitempty"`
+	Order       *int64     `json:"order,omitempty"`
+	Value       float64    `json:"value"`
+	Text        string     `json:"text,omitempty"`
+	Reason      string     `json:"reason"`
+	LimitName   string     `json:"limitName"`
+	LimitValue  float64    `json:"limitValue"`
+	Delta       float64    `json:"delta"`
+	Rule        int        `json:"rule,omitempty"`
+	Indices     []int      `json:"indices,omitempty"`
+	ChangePoint *int       `json:"changePoint,omitempty"`
+	Severity    string     `json:"severity,omitempty"`
 }
 
 func EvaluateThresholdDetector(threshold ThresholdSpec, value any) (DetectorResult, error) {
@@ -51,7 +60,10 @@ func EvaluateThresholdDetector(threshold ThresholdSpec, value any) (DetectorResu
 
 func EvaluateRobustZ(samples []float64, latest float64, zWarn, zCrit float64) DetectorResult {
 	median := Median(samples)
-	mad := MAD(samples, median)
+	return EvaluateRobustZFrozen(latest, median, MAD(samples, median), zWarn, zCrit)
+}
+
+func EvaluateRobustZFrozen(latest, median, mad float64, zWarn, zCrit float64) DetectorResult {
 	result := DetectorResult{
 		Hit:            false,
 		Status:         statusOK,
@@ -103,11 +115,51 @@ func EvaluateMissingData(latestTS time.Time, maxGapSeconds int, now time.Time) D
 	return result
 }
 
+func EvaluateMissingRuns(orders []int64, maxGapRuns int) DetectorResult {
+	if len(orders) == 0 {
+		return insufficientData("no runs found")
+	}
+	sorted := append([]int64(nil), orders...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	latest := sorted[len(sorted)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(latest),
+		LimitExpr: fmt.Sprintf("missing_runs > %d", maxGapRuns),
+		Metadata:  map[string]any{"largestGapRuns": int64(0), "runsChecked": len(sorted)},
+	}
+	largest := int64(0)
+	for i := 1; i < len(sorted); i++ {
+		gap := sorted[i] - sorted[i-1] - 1
+		if gap > largest {
+			largest = gap
+		}
+		if gap > int64(maxGapRuns) {
+			order := sorted[i]
+			result.Hit = true
+			result.Status = statusViolation
+			addViolation(&result, Violation{
+				Order:      &order,
+				Value:      float64(gap),
+				Reason:     "run_gap",
+				LimitName:  "maxGapRuns",
+				LimitValue: float64(maxGapRuns),
+				Delta:      float64(gap - int64(maxGapRuns)),
+			})
+		}
+	}
+	result.Metadata["largestGapRuns"] = largest
+	return result
+}
+
 const (
 	statusOK              = "OK"
 	statusViolation       = "VIOLATION"
 	statusInsufficient    = "INSUFFICIENT_DATA"
 	statusInvalidConfig   = "INVALID_CONFIG"
+	statusError           = "ERROR"
 )
 
 func statusFromHit(hit bool) string {
@@ -1,6 +1,7 @@
 package scheduler
 
 import (
+	"errors"
 	"math"
 	"testing"
 	"time"
@@ -39,3 +40,38 @@ func TestEvaluateMissingData(t *testing.T) {
 		t.Fatalf("expected missing data alert")
 	}
 }
+
+func TestEvaluateMissingRuns(t *testing.T) {
+	result := EvaluateMissingRuns([]int64{5, 1, 2, 3, 9}, 2)
+	if !result.Hit || len(result.Violations) != 1 {
+		t.Fatalf("expected one run gap violation, got %+v", result)
+	}
+	if result.Violations[0].Order == nil || *result.Violations[0].Order != 9 {
+		t.Fatalf("expected violation at run 9")
+	}
+	if result.Metadata["largestGapRuns"] != int64(3) {
+		t.Fatalf("expected largest gap 3, got %v", result.Metadata["largestGapRuns"])
+	}
+	if EvaluateMissingRuns([]int64{1, 2, 4}, 1).Hit {
+		t.Fatalf("expected gap within tolerance")
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	if status := runStatus(DetectorResult{}, errors.New("query failed")); status != statusError {
+		t.Fatalf("expected ERROR, got %s", status)
+	}
+	if status := runStatus(DetectorResult{Hit: true}, nil); status != statusViolation {
+		t.Fatalf("expected VIOLATION, got %s", status)
+	}
+	result := insufficientData("not enough samples")
+	if status := runStatus(result, nil); status != statusInsufficient {
+		t.Fatalf("expected INSUFFICIENT_DATA, got %s", status)
+	}
+	if msg := runError(result, nil); msg != "not enough samples" {
+		t.Fatalf("unexpected run error %q", msg)
+	}
+	if msg := runError(DetectorResult{Status: statusOK, Metadata: map[string]any{"error": "ignored"}}, nil); msg != "" {
+		t.Fatalf("expected empty run error, got %q", msg)
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	defaultEWMALambda     = 0.2
+	defaultEWMAWidth      = 3.0
+	defaultEWMAEvalWindow = 50
+)
+
+// EWMAState carries the smoothed statistic between polls so that a scheduled
+// rule continues it instead of restarting from μ. Count is the number of
+// points folded so far and drives the time-varying limits.
+type EWMAState struct {
+	ConfigHash     string   `json:"configHash"`
+	EWMA           float64  `json:"ewma"`
+	Count          int      `json:"count"`
+	Mu             *float64 `json:"mu,omitempty"`
+	Sigma          *float64 `json:"sigma,omitempty"`
+	BaselineSource string   `json:"baselineSource,omitempty"`
+	Watermark
+}
+
+func EvaluateEWMA(baseline []Sample, eval []Sample, spec EWMASpec, latestOnly bool) DetectorResult {
+	return foldEWMA(baseline, eval, spec, nil, latestOnly)
+}
+
+// foldEWMA folds eval into state. With latestOnly only the last point is
+// checked against the limits.
+func foldEWMA(baseline []Sample, eval []Sample, spec EWMASpec, state *EWMAState, latestOnly bool) DetectorResult {
+	lambda := spec.Lambda
+	if lambda == 0 {
+		lambda = defaultEWMALambda
+	}
+	width := spec.L
+	if width == 0 {
+		width = defaultEWMAWidth
+	}
+	if state == nil {
+		state = &EWMAState{}
+	}
+	mu, sigma, source, ok := ewmaTarget(baseline, spec, *state)
+	if !ok {
+		return insufficientData("baseline too small")
+	}
+	if sigma <= 0 {
+		return insufficientData("baseline sigma is zero")
+	}
+	if len(eval) == 0 {
+		return insufficientData("not enough samples")
+	}
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(eval[len(eval)-1].Value),
+		LimitExpr: fmt.Sprintf("ewma λ=%.2f L=%.1f", lambda, width),
+		Metadata: map[string]any{
+			"mu":             mu,
+			"sigma":          sigma,
+			"lambda":         lambda,
+			"l":              width,
+			"baselineSource": source,
+		},
+	}
+	state.Mu, state.Sigma, state.BaselineSource = &mu, &sigma, source
+	series := make([]map[string]any, 0, len(eval))
+	ewma := mu
+	if state.Count > 0 {
+		ewma = state.EWMA
+	}
+	ucl, lcl := mu, mu
+	for i, sample := range eval {
+		ewma = lambda*sample.Value + (1-lambda)*ewma
+		spread := width * sigma * math.Sqrt(lambda/(2-lambda)*(1-math.Pow(1-lambda, 2*float64(state.Count+i+1))))
+		ucl, lcl = mu+spread, mu-spread
+		point := map[string]any{"index": i, "value": sample.Value, "ewma": ewma, "ucl": ucl, "lcl": lcl}
+		if !sample.TS.IsZero() {
+			point["timestamp"] = sample.TS.UTC().Format(time.RFC3339)
+		}
+		if sample.Order != nil {
+			point["order"] = *sample.Order
+		}
+		series = append(series, point)
+		if latestOnly && i != len(eval)-1 {
+			continue
+		}
+		idx := i
+		if ewma > ucl {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: ewma, Reason: "ewma_above_ucl", LimitName: "UCL", LimitValue: ucl, Delta: ewma - ucl})
+		}
+		if ewma < lcl {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: ewma, Reason: "ewma_below_lcl", LimitName: "LCL", LimitValue: lcl, Delta: ewma - lcl})
+		}
+	}
+	state.EWMA = ewma
+	state.Count += len(eval)
+	state.Watermark = state.Watermark.advancedBy(eval)
+	result.Metadata["ewma"] = ewma
+	result.Metadata["ucl"] = ucl
+	result.Metadata["lcl"] = lcl
+	result.Metadata["series"] = series
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Metadata["limitBreached"] = result.Violations[len(result.Violations)-1].LimitName
+	}
+	return result
+}
+
+func ewmaTarget(baseline []Sample, spec EWMASpec, state EWMAState) (float64, float64, string, bool) {
+	if (spec.Mu == nil || spec.Sigma == nil) && state.Mu != nil && state.Sigma != nil {
+		return *state.Mu, *state.Sigma, state.BaselineSource, true
+	}
+	return baselineStats(baseline, spec.Mu, spec.Sigma, spec.MinBaselineN, spec.PopulationSigma)
+}
+
+// configHash fingerprints the config that persisted detector state was built
+// from, so state is discarded once that config changes.
+func configHash(v any) string {
+	raw, _ := json.Marshal(v)
+	sum := sha1.Sum(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func ewmaEvalWindow(spec EWMASpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return defaultEWMAEvalWindow
+}
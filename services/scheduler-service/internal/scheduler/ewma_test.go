@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEvaluateEWMASmallShift(t *testing.T) {
+	mu, sigma := 10.0, 1.0
+	spec := EWMASpec{Lambda: 0.2, L: 3, Mu: &mu, Sigma: &sigma}
+	values := make([]float64, 0, 20)
+	for i := 0; i < 20; i++ {
+		values = append(values, 11.2)
+	}
+	result := EvaluateEWMA(nil, runRulesSamples(values...), spec, true)
+	if !result.Hit || result.Violations[0].LimitName != "UCL" {
+		t.Fatalf("expected sustained shift to breach UCL, got %+v", result)
+	}
+	if series, ok := result.Metadata["series"].([]map[string]any); !ok || len(series) != 20 {
+		t.Fatalf("expected ewma series in metadata")
+	}
+	if shewhart := EvaluateRunRules(nil, runRulesSamples(values...), RunRulesSpec{Rules: []int{1}, Mu: &mu, Sigma: &sigma}, 1); shewhart.Hit {
+		t.Fatalf("expected 1.2σ shift to stay inside 3σ limits")
+	}
+}
+
+func TestEvaluateEWMAInControl(t *testing.T) {
+	baseline := runRulesSamples(9, 11, 9, 11, 9, 11, 9, 11, 9, 11, 9, 11, 9, 11, 9, 11, 9, 11, 9, 11)
+	result := EvaluateEWMA(baseline, runRulesSamples(10, 10.5, 9.5, 10), EWMASpec{}, false)
+	if result.Hit || result.Status != statusOK {
+		t.Fatalf("expected in-control result, got %+v", result)
+	}
+	if result.Metadata["lambda"] != defaultEWMALambda {
+		t.Fatalf("expected default lambda")
+	}
+}
+
+func TestFoldEWMACarriesState(t *testing.T) {
+	mu, sigma := 10.0, 1.0
+	spec := EWMASpec{Lambda: 0.2, L: 3, Mu: &mu, Sigma: &sigma}
+	values := make([]float64, 0, 20)
+	for i := 0; i < 20; i++ {
+		values = append(values, 11.2)
+	}
+	samples := runRulesSamples(values...)
+	whole := EvaluateEWMA(nil, samples, spec, false)
+	state := EWMAState{}
+	for i := 0; i < len(samples); i += 5 {
+		foldEWMA(nil, samples[i:i+5], spec, &state, false)
+	}
+	if state.Count != 20 || math.Abs(state.EWMA-whole.Metadata["ewma"].(float64)) > 1e-9 {
+		t.Fatalf("expected polled ewma to match a single pass, got %+v", state)
+	}
+	restarted := EvaluateEWMA(nil, samples[15:], spec, false)
+	if restarted.Hit {
+		t.Fatalf("expected a restarted statistic to miss the shift")
+	}
+	next := foldEWMA(nil, runRulesSamples(11.2), spec, &state, false)
+	if !next.Hit || state.Count != 21 {
+		t.Fatalf("expected carried statistic to stay above UCL, got %+v", next)
+	}
+}
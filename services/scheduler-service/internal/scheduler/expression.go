@@ -0,0 +1,26 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"predixaai-backend/pkg/expression"
+)
+
+// ValidateExpressionColumns parses expression and checks that every column it
+// references exists in colTypes with a numeric type.
+func ValidateExpressionColumns(src string, colTypes map[string]string) error {
+	expr, err := expression.Parse(src)
+	if err != nil {
+		return err
+	}
+	for _, col := range expr.Columns() {
+		colType, ok := colTypes[col]
+		if !ok {
+			return fmt.Errorf("expression column %s not found", col)
+		}
+		if !isNumericType(colType) {
+			return fmt.Errorf("expression column %s must be numeric", col)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package scheduler
+
+import "testing"
+
+func TestValidateExpressionColumns(t *testing.T) {
+	colTypes := map[string]string{"a": "double precision", "b": "integer", "label": "text"}
+	if err := ValidateExpressionColumns("a / b", colTypes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateExpressionColumns("a / missing", colTypes); err == nil {
+		t.Fatalf("expected missing column error")
+	}
+	if err := ValidateExpressionColumns("a + label", colTypes); err == nil {
+		t.Fatalf("expected non-numeric column error")
+	}
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const defaultFlatlineEvalWindow = 100
+
+// EvaluateFlatline flags runs of samples whose values stay within epsilon of
+// each other for at least minSamples points or minDurationSeconds. Scheduled
+// runs only look at the run ending at the latest sample.
+func EvaluateFlatline(samples []Sample, spec FlatlineSpec, latestOnly bool) DetectorResult {
+	if spec.MinSamples <= 0 && spec.MinDurationSeconds <= 0 {
+		return invalidConfig("flatline requires minSamples or minDurationSeconds")
+	}
+	if len(samples) < 2 {
+		return insufficientData("not enough samples")
+	}
+	last := samples[len(samples)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(last.Value),
+		LimitExpr: flatlineLimitExpr(spec),
+		Metadata: map[string]any{
+			"epsilon": spec.Epsilon,
+		},
+	}
+	runs := [][2]int{}
+	if latestOnly {
+		runs = append(runs, [2]int{flatRunStart(samples, spec.Epsilon), len(samples) - 1})
+	} else {
+		for start := 0; start < len(samples); {
+			end := flatRunEnd(samples, start, spec.Epsilon)
+			runs = append(runs, [2]int{start, end})
+			start = end + 1
+		}
+	}
+	for _, run := range runs {
+		start, end := run[0], run[1]
+		count := end - start + 1
+		seconds, timed := flatRunSeconds(samples[start], samples[end])
+		if end == len(samples)-1 {
+			result.Metadata["flatSamples"] = count
+			result.Metadata["flatValue"] = samples[end].Value
+			if timed {
+				result.Metadata["flatSeconds"] = seconds
+				result.Metadata["flatSince"] = samples[start].TS.UTC().Format(time.RFC3339)
+			}
+			if samples[start].Order != nil {
+				result.Metadata["flatSinceOrder"] = *samples[start].Order
+			}
+		}
+		if count < 2 {
+			continue
+		}
+		limitName, limitValue, delta := "", 0.0, 0.0
+		switch {
+		case spec.MinSamples > 0 && count >= spec.MinSamples:
+			limitName, limitValue, delta = "minSamples", float64(spec.MinSamples), float64(count-spec.MinSamples)
+		case spec.MinDurationSeconds > 0 && timed && seconds >= float64(spec.MinDurationSeconds):
+			limitName, limitValue, delta = "minDurationSeconds", float64(spec.MinDurationSeconds), seconds-float64(spec.MinDurationSeconds)
+		default:
+			continue
+		}
+		idx, changePoint := end, start
+		addViolation(&result, Violation{
+			Timestamp:   timePtr(samples[end].TS),
+			Index:       &idx,
+			Order:       samples[end].Order,
+			Value:       samples[end].Value,
+			Reason:      "flatline",
+			LimitName:   limitName,
+			LimitValue:  limitValue,
+			Delta:       delta,
+			ChangePoint: &changePoint,
+		})
+	}
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+	}
+	return result
+}
+
+func flatRunStart(samples []Sample, epsilon float64) int {
+	end := len(samples) - 1
+	lo, hi := samples[end].Value, samples[end].Value
+	start := end
+	for start > 0 {
+		value := samples[start-1].Value
+		if math.Max(hi, value)-math.Min(lo, value) > epsilon {
+			break
+		}
+		lo, hi = math.Min(lo, value), math.Max(hi, value)
+		start--
+	}
+	return start
+}
+
+func flatRunEnd(samples []Sample, start int, epsilon float64) int {
+	lo, hi := samples[start].Value, samples[start].Value
+	end := start
+	for end+1 < len(samples) {
+		value := samples[end+1].Value
+		if math.Max(hi, value)-math.Min(lo, value) > epsilon {
+			break
+		}
+		lo, hi = math.Min(lo, value), math.Max(hi, value)
+		end++
+	}
+	return end
+}
+
+func flatRunSeconds(first, last Sample) (float64, bool) {
+	if first.TS.IsZero() || last.TS.IsZero() {
+		return 0, false
+	}
+	return last.TS.Sub(first.TS).Seconds(), true
+}
+
+func flatlineLimitExpr(spec FlatlineSpec) string {
+	parts := []string{}
+	if spec.MinSamples > 0 {
+		parts = append(parts, fmt.Sprintf("n>=%d", spec.MinSamples))
+	}
+	if spec.MinDurationSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("t>=%ds", spec.MinDurationSeconds))
+	}
+	return fmt.Sprintf("flatline ε=%g %s", spec.Epsilon, strings.Join(parts, " or "))
+}
+
+// flatlineSpan is the time span scheduled runs fetch before each new sample
+// when only a duration is configured, or 0 when they fetch evalWindow samples.
+func flatlineSpan(spec FlatlineSpec) time.Duration {
+	if spec.MinSamples > 0 || spec.EvalWindow > 0 {
+		return 0
+	}
+	return time.Duration(spec.MinDurationSeconds) * time.Second
+}
+
+func flatlineEvalWindow(spec FlatlineSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	if spec.MinSamples > defaultFlatlineEvalWindow {
+		return spec.MinSamples
+	}
+	return defaultFlatlineEvalWindow
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluateFlatlineMinSamples(t *testing.T) {
+	samples := runRulesSamples(10.2, 9.7, 10.4, 10, 10.01, 10, 9.99, 10)
+	result := EvaluateFlatline(samples, FlatlineSpec{Epsilon: 0.05, MinSamples: 5}, true)
+	if !result.Hit || len(result.Violations) != 1 {
+		t.Fatalf("expected flatline hit, got %+v", result)
+	}
+	if v := result.Violations[0]; v.LimitName != "minSamples" || *v.ChangePoint != 3 || *v.Index != 7 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+	if result.Metadata["flatSamples"] != 5 {
+		t.Fatalf("expected 5 flat samples, got %v", result.Metadata["flatSamples"])
+	}
+	if healthy := EvaluateFlatline(samples, FlatlineSpec{Epsilon: 0.005, MinSamples: 5}, true); healthy.Hit {
+		t.Fatalf("expected changes beyond epsilon to stay healthy, got %+v", healthy)
+	}
+}
+
+func TestEvaluateFlatlineDuration(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{}
+	for i, v := range []float64{3, 4, 5, 5, 5, 5} {
+		samples = append(samples, Sample{TS: t0.Add(time.Duration(i) * time.Minute), Value: v})
+	}
+	result := EvaluateFlatline(samples, FlatlineSpec{MinDurationSeconds: 180}, true)
+	if !result.Hit || result.Violations[0].LimitName != "minDurationSeconds" || result.Metadata["flatSeconds"] != 180.0 {
+		t.Fatalf("expected duration hit, got %+v", result)
+	}
+	if short := EvaluateFlatline(samples, FlatlineSpec{MinDurationSeconds: 240}, true); short.Hit {
+		t.Fatalf("expected 3 minute run to stay below 4 minutes")
+	}
+	samples = append([]Sample{{TS: t0.Add(-time.Minute), Value: 7}, {TS: t0.Add(-30 * time.Second), Value: 7}, {TS: t0.Add(-20 * time.Second), Value: 7}}, samples...)
+	preview := EvaluateFlatline(samples, FlatlineSpec{MinSamples: 3}, false)
+	if len(preview.Violations) != 2 || *preview.Violations[0].Index != 2 {
+		t.Fatalf("expected both flat runs in preview, got %+v", preview.Violations)
+	}
+}
+
+func TestFlatlineSpan(t *testing.T) {
+	if span := flatlineSpan(FlatlineSpec{MinDurationSeconds: 3600}); span != time.Hour {
+		t.Fatalf("expected a duration-only config to fetch by time, got %v", span)
+	}
+	if span := flatlineSpan(FlatlineSpec{MinDurationSeconds: 3600, MinSamples: 5}); span != 0 {
+		t.Fatalf("expected minSamples to keep the sample window, got %v", span)
+	}
+	if span := flatlineSpan(FlatlineSpec{MinDurationSeconds: 3600, EvalWindow: 500}); span != 0 {
+		t.Fatalf("expected an explicit evalWindow to win, got %v", span)
+	}
+}
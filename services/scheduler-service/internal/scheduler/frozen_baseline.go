@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+const baselineSourceActivation = "activation"
+
+type BaselineStats struct {
+	ParameterName string     `json:"parameterName"`
+	DetectorType  string     `json:"detectorType"`
+	Mu            *float64   `json:"mu,omitempty"`
+	Sigma         *float64   `json:"sigma,omitempty"`
+	RBar          *float64   `json:"rbar,omitempty"`
+	Median        *float64   `json:"median,omitempty"`
+	MAD           *float64   `json:"mad,omitempty"`
+	N             int        `json:"n"`
+	SubgroupCount int        `json:"subgroupCount,omitempty"`
+	WindowStart   *time.Time `json:"windowStart,omitempty"`
+	WindowEnd     *time.Time `json:"windowEnd,omitempty"`
+	OrderStart    *int64     `json:"orderStart,omitempty"`
+	OrderEnd      *int64     `json:"orderEnd,omitempty"`
+	DataHash      string     `json:"dataHash"`
+}
+
+func ComputeBaselineStats(param ParameterSpec, samples []Sample, groups [][]Sample) BaselineStats {
+	values := extractValues(samples)
+	stats := BaselineStats{
+		ParameterName: param.ParameterName,
+		DetectorType:  param.Detector.Type,
+		N:             len(values),
+		DataHash:      baselineDataHash(samples),
+	}
+	if len(values) == 0 {
+		return stats
+	}
+	population := param.Detector.Shewhart != nil && param.Detector.Shewhart.PopulationSigma
+	mu, sigma, median := Mean(values), StdDev(values, population), Median(values)
+	mad := MAD(values, median)
+	stats.Mu, stats.Sigma, stats.Median, stats.MAD = &mu, &sigma, &median, &mad
+	if len(groups) > 0 {
+		ranges := make([]float64, 0, len(groups))
+		for _, group := range groups {
+			ranges = append(ranges, subgroupRange(group))
+		}
+		rbar := Mean(ranges)
+		stats.RBar = &rbar
+		stats.SubgroupCount = len(groups)
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	if !first.TS.IsZero() {
+		stats.WindowStart = timePtr(first.TS)
+	}
+	if !last.TS.IsZero() {
+		stats.WindowEnd = timePtr(last.TS)
+	}
+	stats.OrderStart, stats.OrderEnd = first.Order, last.Order
+	return stats
+}
+
+func baselineDataHash(samples []Sample) string {
+	hash := sha1.New()
+	for _, sample := range samples {
+		if sample.Order != nil {
+			fmt.Fprintf(hash, "%d:", *sample.Order)
+		} else {
+			fmt.Fprintf(hash, "%d:", sample.TS.UnixNano())
+		}
+		fmt.Fprintf(hash, "%g;", sample.Value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *Registry) activeBaseline(ctx context.Context, run JobRun, param ParameterSpec) (*storage.BaselineRecord, error) {
+	if r.repo == nil || !freezesBaseline(run) {
+		return nil, nil
+	}
+	rec, err := r.repo.GetActiveBaseline(ctx, run.ruleID, param.ParameterName, param.Detector.Type)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// freezeBaseline persists the statistics of the first clean evaluation so
+// later polls compare against them instead of a sliding window. A baseline is
+// clean when the evaluation is OK, every baseline point is in control and
+// its spread is non-zero; otherwise the next poll tries again.
+func (r *Registry) freezeBaseline(ctx context.Context, run JobRun, result DetectorResult, stats BaselineStats, inControl bool) error {
+	if r.repo == nil || !freezesBaseline(run) || result.Status != statusOK || !inControl || degenerateBaseline(stats) {
+		return nil
+	}
+	rec := storage.BaselineRecord{
+		ParameterName: stats.ParameterName,
+		DetectorType:  stats.DetectorType,
+		Source:        baselineSourceActivation,
+		Mu:            stats.Mu,
+		Sigma:         stats.Sigma,
+		RBar:          stats.RBar,
+		Median:        stats.Median,
+		MAD:           stats.MAD,
+		N:             stats.N,
+		SubgroupCount: stats.SubgroupCount,
+		WindowStart:   stats.WindowStart,
+		WindowEnd:     stats.WindowEnd,
+		OrderStart:    stats.OrderStart,
+		OrderEnd:      stats.OrderEnd,
+		DataHash:      stats.DataHash,
+	}
+	if run.stepper {
+		rec.UIRuleID = run.ruleID
+	} else {
+		rec.RuleID = run.ruleID
+	}
+	return r.repo.CreateActiveBaseline(ctx, rec)
+}
+
+// freezesBaseline reports whether the rule uses frozen baselines. Stepper
+// rules always do and have endpoints to review them; legacy rules opt in
+// with freezeBaseline.
+func freezesBaseline(run JobRun) bool {
+	return run.stepper || run.spec.FreezeBaseline
+}
+
+func degenerateBaseline(stats BaselineStats) bool {
+	switch stats.DetectorType {
+	case "robust_zscore":
+		return stats.MAD == nil || *stats.MAD <= 0
+	case "range_chart":
+		return stats.RBar == nil || *stats.RBar <= 0
+	default:
+		return stats.Sigma == nil || *stats.Sigma <= 0
+	}
+}
+
+// samplesInControl reports whether no baseline sample violates the limits
+// derived from the baseline itself.
+func samplesInControl(samples []Sample, violates func(Sample) bool) bool {
+	for _, sample := range samples {
+		if violates(sample) {
+			return false
+		}
+	}
+	return true
+}
+
+func applyFrozenBaseline(result *DetectorResult, samples []Sample, baseline storage.BaselineRecord) {
+	applyWindowAndBaseline(result, samples, nil, nil, false)
+	result.BaselineStart = baseline.WindowStart
+	result.BaselineEnd = baseline.WindowEnd
+	if result.Metadata == nil {
+		result.Metadata = map[string]any{}
+	}
+	result.Metadata["baselineSource"] = "frozen"
+	result.Metadata["baselineId"] = baseline.ID
+	result.Metadata["baselineN"] = baseline.N
+}
@@ -0,0 +1,66 @@
+package scheduler
+
+import "testing"
+
+func TestComputeBaselineStats(t *testing.T) {
+	param := ParameterSpec{ParameterName: "thickness", Detector: DetectorSpec{Type: "range_chart"}}
+	samples := runRulesSamples(9, 11, 10, 12, 8, 10)
+	stats := ComputeBaselineStats(param, samples, groupConsecutive(samples, 3))
+	if stats.N != 6 || stats.DetectorType != "range_chart" || stats.SubgroupCount != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if *stats.Mu != 10 || *stats.Median != 10 || *stats.RBar != 3 {
+		t.Fatalf("unexpected mu/median/rbar %v %v %v", *stats.Mu, *stats.Median, *stats.RBar)
+	}
+	if stats.DataHash != ComputeBaselineStats(param, runRulesSamples(9, 11, 10, 12, 8, 10), nil).DataHash {
+		t.Fatalf("expected stable data hash")
+	}
+	if stats.DataHash == ComputeBaselineStats(param, runRulesSamples(9, 11, 10, 12, 8, 11), nil).DataHash {
+		t.Fatalf("expected hash to change with data")
+	}
+}
+
+func TestFrozenBaselineDoesNotAbsorbDrift(t *testing.T) {
+	drifted := []float64{}
+	for i := 0; i < 20; i++ {
+		drifted = append(drifted, 14+float64(i%2))
+	}
+	drifted = append(drifted, 15)
+	if sliding := EvaluateShewhart(runRulesSamples(drifted...), ShewhartSpec{}, 3); sliding.Hit {
+		t.Fatalf("expected sliding baseline to absorb the drift")
+	}
+	mu, sigma := 10.0, 1.0
+	frozen := EvaluateShewhartFrozen(Sample{Value: 15}, mu, sigma, 3)
+	if !frozen.Hit || frozen.Violations[0].Reason != "above_ucl" {
+		t.Fatalf("expected frozen baseline to flag drift, got %+v", frozen)
+	}
+	if result := EvaluateRangeChartFrozen(runRulesSamples(5, 15, 10), 2, 3); !result.Hit {
+		t.Fatalf("expected range above D4·R̄, got %+v", result)
+	}
+	if result := EvaluateRobustZFrozen(10.5, 10, 1, 3, 5); result.Hit {
+		t.Fatalf("expected robust z within limits, got %+v", result)
+	}
+}
+
+func TestFreezeBaselineNeedsCleanOptIn(t *testing.T) {
+	if freezesBaseline(JobRun{}) {
+		t.Fatalf("expected legacy rules without freezeBaseline to keep sliding baselines")
+	}
+	if !freezesBaseline(JobRun{spec: RuleSpec{FreezeBaseline: true}}) || !freezesBaseline(JobRun{stepper: true}) {
+		t.Fatalf("expected opted-in and stepper rules to freeze")
+	}
+	zero, one := 0.0, 1.0
+	if !degenerateBaseline(BaselineStats{DetectorType: "shewhart", Sigma: &zero}) {
+		t.Fatalf("expected σ=0 baseline to be degenerate")
+	}
+	if !degenerateBaseline(BaselineStats{DetectorType: "robust_zscore", Sigma: &one, MAD: &zero}) {
+		t.Fatalf("expected MAD=0 baseline to be degenerate")
+	}
+	if degenerateBaseline(BaselineStats{DetectorType: "range_chart", RBar: &one}) {
+		t.Fatalf("expected R̄>0 baseline to be usable")
+	}
+	samples := runRulesSamples(10, 11, 9, 30)
+	if samplesInControl(samples, func(s Sample) bool { return EvaluateShewhartFrozen(s, 10, 1, 3).Hit }) {
+		t.Fatalf("expected violating baseline to be refused")
+	}
+}
@@ -24,6 +24,9 @@ func EvaluateSpecLimit(sample Sample, spec SpecLimitSpec) DetectorResult {
 	if spec.Epsilon != nil {
 		epsilon = *spec.Epsilon
 	}
+	rowLimits := hasSpecLimitColumns(spec.SpecLimits) || hasControlLimitColumns(spec.ControlLimits)
+	spec.SpecLimits = resolveSpecLimits(spec.SpecLimits, sample)
+	spec.ControlLimits = resolveControlLimits(spec.ControlLimits, sample)
 	result := DetectorResult{
 		Hit:       false,
 		Status:    statusOK,
@@ -54,8 +57,14 @@ func EvaluateSpecLimit(sample Sample, spec SpecLimitSpec) DetectorResult {
 			Delta:      sample.Value - limit,
 		})
 	}
+	if rowLimits {
+		result.Metadata["limitSource"] = "row"
+	}
 	if mode == "spec" || mode == "both" {
 		if spec.SpecLimits == nil || (spec.SpecLimits.USL == nil && spec.SpecLimits.LSL == nil) {
+			if hasSpecLimitColumns(spec.SpecLimits) {
+				return insufficientData("row limit values missing")
+			}
 			return invalidConfig("spec limits required")
 		}
 		if spec.SpecLimits.USL != nil {
@@ -73,6 +82,9 @@ func EvaluateSpecLimit(sample Sample, spec SpecLimitSpec) DetectorResult {
 	}
 	if mode == "control" || mode == "both" {
 		if spec.ControlLimits == nil || (spec.ControlLimits.UCL == nil && spec.ControlLimits.LCL == nil) {
+			if hasControlLimitColumns(spec.ControlLimits) {
+				return insufficientData("row limit values missing")
+			}
 			return invalidConfig("control limits required")
 		}
 		if spec.ControlLimits.UCL != nil {
@@ -100,9 +112,10 @@ func EvaluateShewhart(samples []Sample, spec ShewhartSpec, sigmaMultiplier float
 	if len(values) < minBaseline {
 		return insufficientData("baseline too small")
 	}
-	lastSample := samples[len(samples)-1]
-	mean := Mean(values)
-	sigma := StdDev(values, spec.PopulationSigma)
+	return EvaluateShewhartFrozen(samples[len(samples)-1], Mean(values), StdDev(values, spec.PopulationSigma), sigmaMultiplier)
+}
+
+func EvaluateShewhartFrozen(lastSample Sample, mean, sigma, sigmaMultiplier float64) DetectorResult {
 	ucl := mean + sigmaMultiplier*sigma
 	lcl := mean - sigmaMultiplier*sigma
 	latest := lastSample.Value
@@ -245,11 +258,24 @@ func EvaluateRangeChart(groups [][]Sample, spec RangeChartSpec) DetectorResult {
 	for _, group := range groups {
 		ranges = append(ranges, subgroupRange(group))
 	}
-	avg := Mean(ranges)
-	ucl := consts.D4 * avg
-	lcl := consts.D3 * avg
-	latestRange := ranges[len(ranges)-1]
-	lastGroup := groups[len(groups)-1]
+	return evaluateRangeChartLimits(groups[len(groups)-1], Mean(ranges), spec.SubgroupSize, consts.D3, consts.D4)
+}
+
+func EvaluateRangeChartFrozen(lastGroup []Sample, rbar float64, subgroupSize int) DetectorResult {
+	if len(lastGroup) == 0 {
+		return insufficientData("no valid subgroups")
+	}
+	consts, ok := rangeChartConstants[subgroupSize]
+	if !ok {
+		return invalidConfig("unsupported subgroup size")
+	}
+	return evaluateRangeChartLimits(lastGroup, rbar, subgroupSize, consts.D3, consts.D4)
+}
+
+func evaluateRangeChartLimits(lastGroup []Sample, avg float64, subgroupSize int, d3, d4 float64) DetectorResult {
+	ucl := d4 * avg
+	lcl := d3 * avg
+	latestRange := subgroupRange(lastGroup)
 	lastSample := lastGroup[len(lastGroup)-1]
 	result := DetectorResult{
 		Hit:       false,
@@ -261,7 +287,7 @@ func EvaluateRangeChart(groups [][]Sample, spec RangeChartSpec) DetectorResult {
 			"rbar":         avg,
 			"ucl_r":        ucl,
 			"lcl_r":        lcl,
-			"subgroupSize": spec.SubgroupSize,
+			"subgroupSize": subgroupSize,
 		},
 	}
 	if latestRange > ucl || latestRange < lcl {
@@ -296,6 +322,7 @@ func EvaluateTPA(samples []Sample, spec TPASpec) DetectorResult {
 	}
 	window := samples[len(samples)-spec.WindowN:]
 	lastSample := window[len(window)-1]
+	spec.SpecLimits = resolveSpecLimits(spec.SpecLimits, lastSample)
 	basis := spec.RegressionTimeBasis
 	if basis == "" {
 		basis = defaultRegressionBasis
@@ -376,6 +403,78 @@ func EvaluateTPA(samples []Sample, spec TPASpec) DetectorResult {
 	return result
 }
 
+func resolveSpecLimits(bounds *SpecLimitBounds, sample Sample) *SpecLimitBounds {
+	if bounds == nil {
+		return nil
+	}
+	resolved := *bounds
+	if bounds.USLColumn != "" {
+		resolved.USL = sampleColumn(sample, bounds.USLColumn, bounds.USL)
+	}
+	if bounds.LSLColumn != "" {
+		resolved.LSL = sampleColumn(sample, bounds.LSLColumn, bounds.LSL)
+	}
+	return &resolved
+}
+
+func resolveControlLimits(bounds *ControlLimitBounds, sample Sample) *ControlLimitBounds {
+	if bounds == nil {
+		return nil
+	}
+	resolved := *bounds
+	if bounds.UCLColumn != "" {
+		resolved.UCL = sampleColumn(sample, bounds.UCLColumn, bounds.UCL)
+	}
+	if bounds.LCLColumn != "" {
+		resolved.LCL = sampleColumn(sample, bounds.LCLColumn, bounds.LCL)
+	}
+	return &resolved
+}
+
+func sampleColumn(sample Sample, column string, fallback *float64) *float64 {
+	if value, ok := sample.Columns[column]; ok {
+		return &value
+	}
+	return fallback
+}
+
+func hasSpecLimitColumns(bounds *SpecLimitBounds) bool {
+	return bounds != nil && (bounds.USLColumn != "" || bounds.LSLColumn != "")
+}
+
+func hasControlLimitColumns(bounds *ControlLimitBounds) bool {
+	return bounds != nil && (bounds.UCLColumn != "" || bounds.LCLColumn != "")
+}
+
+func LimitColumns(detector DetectorSpec) []string {
+	columns := []string{}
+	add := func(values ...string) {
+		for _, value := range values {
+			if value != "" {
+				columns = append(columns, value)
+			}
+		}
+	}
+	if detector.SpecLimit != nil {
+		if detector.SpecLimit.SpecLimits != nil {
+			add(detector.SpecLimit.SpecLimits.USLColumn, detector.SpecLimit.SpecLimits.LSLColumn)
+		}
+		if detector.SpecLimit.ControlLimits != nil {
+			add(detector.SpecLimit.ControlLimits.UCLColumn, detector.SpecLimit.ControlLimits.LCLColumn)
+		}
+	}
+	if detector.TPA != nil && detector.TPA.SpecLimits != nil {
+		add(detector.TPA.SpecLimits.USLColumn, detector.TPA.SpecLimits.LSLColumn)
+	}
+	if detector.Attribute != nil {
+		add(detector.Attribute.SampleSizeColumn)
+	}
+	if detector.Capability != nil && detector.Capability.SpecLimits != nil {
+		add(detector.Capability.SpecLimits.USLColumn, detector.Capability.SpecLimits.LSLColumn)
+	}
+	return columns
+}
+
 func computeTimeToSpec(slope float64, current float64, limits *SpecLimitBounds) (float64, bool) {
 	if slope > 0 && limits.USL != nil {
 		return (*limits.USL - current) / slope, true
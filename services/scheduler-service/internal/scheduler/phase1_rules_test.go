@@ -11,6 +11,22 @@ func TestEvaluateSpecLimit(t *testing.T) {
 	}
 }
 
+func TestEvaluateSpecLimitRowColumns(t *testing.T) {
+	spec := SpecLimitSpec{SpecLimits: &SpecLimitBounds{USLColumn: "rate_upper_limit", LSLColumn: "rate_lower_limit"}, Mode: "spec"}
+	sample := Sample{Value: 12, Columns: map[string]float64{"rate_upper_limit": 11, "rate_lower_limit": 2}}
+	result := EvaluateSpecLimit(sample, spec)
+	if !result.Hit || result.Metadata["limitValue"] != 11.0 {
+		t.Fatalf("expected USL breach from row limit, got %+v", result)
+	}
+	sample.Columns["rate_upper_limit"] = 15
+	if EvaluateSpecLimit(sample, spec).Hit {
+		t.Fatalf("expected no breach with wider row limit")
+	}
+	if result := EvaluateSpecLimit(Sample{Value: 12}, spec); result.Status != statusInsufficient {
+		t.Fatalf("expected insufficient data without row limits, got %s", result.Status)
+	}
+}
+
 func TestEvaluateShewhart(t *testing.T) {
 	samples := []Sample{{Value: 10}, {Value: 10}, {Value: 10}, {Value: 10}, {Value: 10}, {Value: 10}, {Value: 20}}
 	spec := ShewhartSpec{MinBaselineN: 5}
@@ -68,6 +84,16 @@ func TestEvaluateTPA(t *testing.T) {
 	}
 }
 
+func TestEvaluateTPATimeToSpecRowLimit(t *testing.T) {
+	threshold := 3.0
+	spec := TPASpec{WindowN: 5, TimeToSpecThreshold: &threshold, RegressionTimeBasis: "index", SpecLimits: &SpecLimitBounds{USLColumn: "usl"}}
+	samples := []Sample{{Value: 1}, {Value: 2}, {Value: 3}, {Value: 4}, {Value: 5, Columns: map[string]float64{"usl": 7}}}
+	result := EvaluateTPA(samples, spec)
+	if !result.Hit || result.Metadata["timeToSpec"] != 2.0 {
+		t.Fatalf("expected time-to-spec hit from row limit, got %+v", result.Metadata)
+	}
+}
+
 func TestEvaluateShewhartInsufficient(t *testing.T) {
 	result := EvaluateShewhart([]Sample{{Value: 1}}, ShewhartSpec{MinBaselineN: 3}, 3)
 	if result.Status != statusInsufficient {
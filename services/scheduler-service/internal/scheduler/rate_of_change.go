@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	rateBasisSecond = "second"
+	rateBasisRun    = "run"
+)
+
+// EvaluateRateOfChange computes the derivative between consecutive samples,
+// per second of timestamp or per run of ordering column, averages it over the
+// last smoothingWindow pairs and checks it against minRate/maxRate. With
+// latestOnly only the pair ending at the latest sample is checked; scheduled
+// runs call it once for every sample since the watermark.
+func EvaluateRateOfChange(samples []Sample, spec RateOfChangeSpec, latestOnly bool) DetectorResult {
+	if spec.MaxRate == nil && spec.MinRate == nil {
+		return invalidConfig("rate_of_change requires minRate or maxRate")
+	}
+	smoothing := rateSmoothingWindow(spec)
+	if len(samples) < smoothing+1 {
+		return insufficientData("not enough samples")
+	}
+	basis := spec.Basis
+	if basis == "" {
+		basis = rateBasisSecond
+		if samples[0].TS.IsZero() {
+			basis = rateBasisRun
+		}
+	}
+	last := samples[len(samples)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(last.Value),
+		LimitExpr: rateOfChangeLimitExpr(spec, basis),
+		Metadata: map[string]any{
+			"basis":           basis,
+			"smoothingWindow": smoothing,
+		},
+	}
+	rates := make([]*float64, len(samples))
+	for i := 1; i < len(samples); i++ {
+		if rate, ok := pairRate(samples[i-1], samples[i], basis); ok {
+			rates[i] = &rate
+		}
+	}
+	first := smoothing
+	if latestOnly {
+		first = len(samples) - 1
+	}
+	series := []map[string]any{}
+	for i := first; i < len(samples); i++ {
+		rate, ok := smoothedRate(rates[i-smoothing+1 : i+1])
+		if !ok {
+			continue
+		}
+		point := map[string]any{"index": i, "value": samples[i].Value, "rate": rate}
+		if !samples[i].TS.IsZero() {
+			point["timestamp"] = samples[i].TS.UTC().Format(time.RFC3339)
+		}
+		if samples[i].Order != nil {
+			point["order"] = *samples[i].Order
+		}
+		series = append(series, point)
+		if i == len(samples)-1 {
+			result.Metadata["rate"] = rate
+		}
+		idx := i
+		violation := Violation{Timestamp: timePtr(samples[i].TS), Index: &idx, Order: samples[i].Order, Value: rate, Indices: []int{i - smoothing, i}}
+		if spec.MaxRate != nil && rate > *spec.MaxRate {
+			violation.Reason, violation.LimitName, violation.LimitValue, violation.Delta = "rate_above_max", "maxRate", *spec.MaxRate, rate-*spec.MaxRate
+			addViolation(&result, violation)
+		}
+		if spec.MinRate != nil && rate < *spec.MinRate {
+			violation.Reason, violation.LimitName, violation.LimitValue, violation.Delta = "rate_below_min", "minRate", *spec.MinRate, rate-*spec.MinRate
+			addViolation(&result, violation)
+		}
+	}
+	if len(series) == 0 {
+		return insufficientData("no valid sample pairs")
+	}
+	if !latestOnly {
+		result.Metadata["series"] = series
+	}
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+	}
+	return result
+}
+
+// pairRate returns the change from prev to next per second or per run. Runs
+// are measured by the ordering column when present, otherwise one per sample.
+func pairRate(prev, next Sample, basis string) (float64, bool) {
+	delta := next.Value - prev.Value
+	if basis == rateBasisSecond {
+		if prev.TS.IsZero() || next.TS.IsZero() {
+			return 0, false
+		}
+		seconds := next.TS.Sub(prev.TS).Seconds()
+		if seconds <= 0 {
+			return 0, false
+		}
+		return delta / seconds, true
+	}
+	if prev.Order != nil && next.Order != nil {
+		runs := *next.Order - *prev.Order
+		if runs <= 0 {
+			return 0, false
+		}
+		return delta / float64(runs), true
+	}
+	return delta, true
+}
+
+func smoothedRate(rates []*float64) (float64, bool) {
+	sum := 0.0
+	for _, rate := range rates {
+		if rate == nil {
+			return 0, false
+		}
+		sum += *rate
+	}
+	return sum / float64(len(rates)), true
+}
+
+func rateOfChangeLimitExpr(spec RateOfChangeSpec, basis string) string {
+	expr := "rate_of_change/" + basis
+	if spec.MinRate != nil {
+		expr += fmt.Sprintf(" >= %g", *spec.MinRate)
+	}
+	if spec.MaxRate != nil {
+		expr += fmt.Sprintf(" <= %g", *spec.MaxRate)
+	}
+	return expr
+}
+
+func rateSmoothingWindow(spec RateOfChangeSpec) int {
+	if spec.SmoothingWindow > 0 {
+		return spec.SmoothingWindow
+	}
+	return 1
+}
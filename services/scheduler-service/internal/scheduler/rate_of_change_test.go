@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEvaluateRateOfChangePerSecond(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{}
+	for i, v := range []float64{20, 21, 22, 40, 41} {
+		samples = append(samples, Sample{TS: t0.Add(time.Duration(i*10) * time.Second), Value: v})
+	}
+	maxRate := 1.0
+	preview := EvaluateRateOfChange(samples, RateOfChangeSpec{MaxRate: &maxRate}, false)
+	if !preview.Hit || len(preview.Violations) != 1 {
+		t.Fatalf("expected one violating pair, got %+v", preview)
+	}
+	if v := preview.Violations[0]; *v.Index != 3 || v.Value != 1.8 || v.Reason != "rate_above_max" || v.Indices[0] != 2 || v.Indices[1] != 3 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+	if preview.Metadata["basis"] != rateBasisSecond {
+		t.Fatalf("expected per second basis, got %v", preview.Metadata["basis"])
+	}
+	if latest := EvaluateRateOfChange(samples, RateOfChangeSpec{MaxRate: &maxRate}, true); latest.Hit {
+		t.Fatalf("expected latest pair to stay within bounds, got %+v", latest)
+	}
+	smoothed := EvaluateRateOfChange(samples, RateOfChangeSpec{MaxRate: &maxRate, SmoothingWindow: 2}, true)
+	if rate, _ := smoothed.Metadata["rate"].(float64); smoothed.Status != statusOK || math.Abs(rate-0.95) > 1e-9 {
+		t.Fatalf("expected smoothed rate 0.95, got %+v", smoothed.Metadata)
+	}
+}
+
+func TestEvaluateRateOfChangePerRun(t *testing.T) {
+	samples := []Sample{}
+	for i, v := range []float64{5, 4, 1} {
+		order := int64(i * 2)
+		samples = append(samples, Sample{Order: &order, Value: v})
+	}
+	minRate := -1.0
+	result := EvaluateRateOfChange(samples, RateOfChangeSpec{MinRate: &minRate}, true)
+	if !result.Hit || result.Violations[0].Value != -1.5 || result.Violations[0].LimitName != "minRate" || result.Metadata["basis"] != rateBasisRun {
+		t.Fatalf("expected per run drop of -1.5, got %+v", result)
+	}
+	if invalid := EvaluateRateOfChange(samples, RateOfChangeSpec{}, true); invalid.Status != statusInvalidConfig {
+		t.Fatalf("expected invalid config without bounds")
+	}
+}
+
+func TestBacktestRateOfChangeChecksEveryNewPair(t *testing.T) {
+	maxRate := 1.0
+	spec := RuleSpec{Parameters: []ParameterSpec{{Detector: DetectorSpec{Type: "rate_of_change", RateOfChange: &RateOfChangeSpec{MaxRate: &maxRate, Basis: rateBasisRun}}}}}
+	evaluate, err := backtestEvaluator("RATE_OF_CHANGE", spec, nil, 2, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := evaluate(runRulesSamples(1, 1.5, 5, 5.5))
+	if !result.Hit || len(result.Violations) != 1 || result.Violations[0].Value != 3.5 {
+		t.Fatalf("expected the jump before the latest pair, got %+v", result)
+	}
+	if again := evaluate(runRulesSamples(1, 1.5, 5, 5.5, 6)); again.Hit {
+		t.Fatalf("expected evaluated pairs to be skipped, got %+v", again.Violations)
+	}
+}
@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const defaultSameSideRun = 9
+
+var allRunRules = []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+// EvaluateRunRules checks eval against the Nelson rules. When newPoints is
+// positive only runs ending at one of the last newPoints samples are reported.
+func EvaluateRunRules(baseline []Sample, eval []Sample, spec RunRulesSpec, newPoints int) DetectorResult {
+	rules := spec.Rules
+	if len(rules) == 0 {
+		rules = allRunRules
+	}
+	mu, sigma, source, ok := baselineStats(baseline, spec.Mu, spec.Sigma, spec.MinBaselineN, spec.PopulationSigma)
+	if !ok {
+		return insufficientData("baseline too small")
+	}
+	if sigma <= 0 {
+		return insufficientData("baseline sigma is zero")
+	}
+	if len(eval) == 0 {
+		return insufficientData("not enough samples")
+	}
+	sameSide := spec.SameSideRun
+	if sameSide == 0 {
+		sameSide = defaultSameSideRun
+	}
+	z := make([]float64, len(eval))
+	for i, sample := range eval {
+		z[i] = (sample.Value - mu) / sigma
+	}
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(eval[len(eval)-1].Value),
+		LimitExpr: fmt.Sprintf("nelson rules %v", rules),
+		Metadata: map[string]any{
+			"mu":             mu,
+			"sigma":          sigma,
+			"rules":          rules,
+			"baselineSource": source,
+		},
+	}
+	triggered := []int{}
+	for _, rule := range rules {
+		length := runRuleLength(rule, sameSide)
+		if length == 0 || len(eval) < length {
+			continue
+		}
+		lastIdx, lastEnd := -1, -1
+		first := length - 1
+		if newPoints > 0 && len(eval)-newPoints > first {
+			first = len(eval) - newPoints
+		}
+		for end := first; end < len(eval); end++ {
+			start := end - length + 1
+			points := matchRunRule(rule, z[start:end+1])
+			if points == nil {
+				continue
+			}
+			for i := range points {
+				points[i] += start
+			}
+			violation := runRuleViolation(rule, eval[end], end, z[end], mu, sigma)
+			if lastIdx >= 0 && start <= lastEnd {
+				violation.Indices = mergeIndices(result.Violations[lastIdx].Indices, points)
+				result.Violations[lastIdx] = violation
+			} else {
+				violation.Indices = points
+				addViolation(&result, violation)
+				lastIdx = len(result.Violations) - 1
+			}
+			lastEnd = end
+		}
+		if lastIdx >= 0 {
+			triggered = append(triggered, rule)
+		}
+	}
+	if len(triggered) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Metadata["triggeredRules"] = triggered
+	}
+	return result
+}
+
+func runRulesEvalWindow(spec RunRulesSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	rules := spec.Rules
+	if len(rules) == 0 {
+		rules = allRunRules
+	}
+	sameSide := spec.SameSideRun
+	if sameSide == 0 {
+		sameSide = defaultSameSideRun
+	}
+	window := 1
+	for _, rule := range rules {
+		if length := runRuleLength(rule, sameSide); length > window {
+			window = length
+		}
+	}
+	return window
+}
+
+func runRuleLength(rule, sameSideRun int) int {
+	switch rule {
+	case 1:
+		return 1
+	case 2:
+		return sameSideRun
+	case 3:
+		return 6
+	case 4:
+		return 14
+	case 5:
+		return 3
+	case 6:
+		return 5
+	case 7:
+		return 15
+	case 8:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// matchRunRule returns the window offsets of the points that trigger the rule, or nil.
+func matchRunRule(rule int, z []float64) []int {
+	switch rule {
+	case 1:
+		if math.Abs(z[0]) > 3 {
+			return []int{0}
+		}
+	case 2:
+		if allZ(z, func(v float64) bool { return v > 0 }) || allZ(z, func(v float64) bool { return v < 0 }) {
+			return windowIndices(len(z))
+		}
+	case 3:
+		increasing, decreasing := true, true
+		for i := 1; i < len(z); i++ {
+			increasing = increasing && z[i] > z[i-1]
+			decreasing = decreasing && z[i] < z[i-1]
+		}
+		if increasing || decreasing {
+			return windowIndices(len(z))
+		}
+	case 4:
+		for i := 1; i < len(z); i++ {
+			delta := z[i] - z[i-1]
+			if delta == 0 {
+				return nil
+			}
+			if i > 1 && (delta > 0) == (z[i-1]-z[i-2] > 0) {
+				return nil
+			}
+		}
+		return windowIndices(len(z))
+	case 5:
+		return beyondSameSide(z, 2, 2)
+	case 6:
+		return beyondSameSide(z, 1, 4)
+	case 7:
+		if allZ(z, func(v float64) bool { return math.Abs(v) < 1 }) {
+			return windowIndices(len(z))
+		}
+	case 8:
+		// Every point is beyond 1σ and the run crosses the center line.
+		bothSides := !allZ(z, func(v float64) bool { return v > 0 }) && !allZ(z, func(v float64) bool { return v < 0 })
+		if bothSides && allZ(z, func(v float64) bool { return math.Abs(v) > 1 }) {
+			return windowIndices(len(z))
+		}
+	}
+	return nil
+}
+
+func runRuleViolation(rule int, sample Sample, index int, z, mu, sigma float64) Violation {
+	side := 1.0
+	if z < 0 {
+		side = -1
+	}
+	limitName, limitValue := "mean", mu
+	switch rule {
+	case 1:
+		limitName, limitValue = "3σ", mu+side*3*sigma
+	case 5:
+		limitName, limitValue = "2σ", mu+side*2*sigma
+	case 6, 7, 8:
+		limitName, limitValue = "1σ", mu+side*sigma
+	}
+	idx := index
+	return Violation{
+		Timestamp:  timePtr(sample.TS),
+		Index:      &idx,
+		Order:      sample.Order,
+		Value:      sample.Value,
+		Reason:     fmt.Sprintf("nelson_rule_%d", rule),
+		LimitName:  limitName,
+		LimitValue: limitValue,
+		Delta:      sample.Value - mu,
+		Rule:       rule,
+	}
+}
+
+func beyondSameSide(z []float64, limit float64, count int) []int {
+	above := []int{}
+	below := []int{}
+	for i, v := range z {
+		if v > limit {
+			above = append(above, i)
+		}
+		if v < -limit {
+			below = append(below, i)
+		}
+	}
+	if len(above) >= count {
+		return above
+	}
+	if len(below) >= count {
+		return below
+	}
+	return nil
+}
+
+func allZ(z []float64, fn func(float64) bool) bool {
+	for _, v := range z {
+		if !fn(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func windowIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+func mergeIndices(current, next []int) []int {
+	seen := map[int]struct{}{}
+	merged := make([]int, 0, len(current)+len(next))
+	for _, list := range [][]int{current, next} {
+		for _, idx := range list {
+			if _, ok := seen[idx]; ok {
+				continue
+			}
+			seen[idx] = struct{}{}
+			merged = append(merged, idx)
+		}
+	}
+	sort.Ints(merged)
+	return merged
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import "testing"
+
+func runRulesSamples(values ...float64) []Sample {
+	samples := make([]Sample, 0, len(values))
+	for _, v := range values {
+		samples = append(samples, Sample{Value: v})
+	}
+	return samples
+}
+
+func TestEvaluateRunRulesTwoOfThree(t *testing.T) {
+	mu, sigma := 10.0, 1.0
+	spec := RunRulesSpec{Rules: []int{1, 5}, Mu: &mu, Sigma: &sigma}
+	result := EvaluateRunRules(nil, runRulesSamples(10, 12.5, 9.8, 12.2), spec, 0)
+	if !result.Hit || len(result.Violations) != 1 {
+		t.Fatalf("expected one rule 5 violation, got %+v", result.Violations)
+	}
+	v := result.Violations[0]
+	if v.Rule != 5 || len(v.Indices) != 2 || v.Indices[0] != 1 || v.Indices[1] != 3 {
+		t.Fatalf("unexpected violation %+v", v)
+	}
+}
+
+func TestEvaluateRunRulesSameSideMerged(t *testing.T) {
+	mu, sigma := 0.0, 1.0
+	spec := RunRulesSpec{Rules: []int{2}, Mu: &mu, Sigma: &sigma, SameSideRun: 8}
+	values := []float64{-1, 0.5, 0.4, 0.3, 0.6, 0.2, 0.1, 0.7, 0.5, 0.3, 0.2}
+	result := EvaluateRunRules(nil, runRulesSamples(values...), spec, 0)
+	if len(result.Violations) != 1 || len(result.Violations[0].Indices) != 10 {
+		t.Fatalf("expected a single merged run, got %+v", result.Violations)
+	}
+	latest := EvaluateRunRules(nil, runRulesSamples(values[:len(values)-1]...), spec, 1)
+	if !latest.Hit {
+		t.Fatalf("expected run ending at latest point")
+	}
+	broken := append(append([]float64{}, values...), -0.5)
+	if result := EvaluateRunRules(nil, runRulesSamples(broken...), spec, 1); result.Hit {
+		t.Fatalf("expected no hit when latest point breaks the run")
+	}
+}
+
+func TestEvaluateRunRulesMixtureNeedsBothSides(t *testing.T) {
+	mu, sigma := 0.0, 1.0
+	spec := RunRulesSpec{Rules: []int{8}, Mu: &mu, Sigma: &sigma}
+	if result := EvaluateRunRules(nil, runRulesSamples(1.5, 1.5, 1.5, 1.5, 1.5, 1.5, 1.5, 1.5), spec, 0); result.Hit {
+		t.Fatalf("expected no rule 8 hit for a one-sided run, got %+v", result.Violations)
+	}
+	if result := EvaluateRunRules(nil, runRulesSamples(1.5, -1.5, 1.5, -1.5, 1.5, -1.5, 1.5, -1.5), spec, 0); !result.Hit {
+		t.Fatalf("expected rule 8 hit for a run on both sides")
+	}
+}
+
+func TestEvaluateRunRulesChecksEveryNewPoint(t *testing.T) {
+	mu, sigma := 0.0, 1.0
+	spec := RunRulesSpec{Rules: []int{1}, Mu: &mu, Sigma: &sigma}
+	values := runRulesSamples(0, 0, 4, 0, 0)
+	if result := EvaluateRunRules(nil, values, spec, 1); result.Hit {
+		t.Fatalf("expected only the latest point to be checked")
+	}
+	result := EvaluateRunRules(nil, values, spec, 3)
+	if !result.Hit || len(result.Violations) != 1 || *result.Violations[0].Index != 2 {
+		t.Fatalf("expected the spike among the new points, got %+v", result.Violations)
+	}
+	if result := EvaluateRunRules(nil, values, spec, 2); result.Hit {
+		t.Fatalf("expected the spike before the new points to be skipped")
+	}
+}
+
+func TestEvaluateRunRulesBaselineDerived(t *testing.T) {
+	baseline := runRulesSamples(9, 11, 9, 11, 9, 11, 9, 11, 9, 11)
+	spec := RunRulesSpec{Rules: []int{3}, MinBaselineN: 10}
+	result := EvaluateRunRules(baseline, runRulesSamples(1, 2, 3, 4, 5, 6), spec, 1)
+	if !result.Hit || result.Metadata["baselineSource"] != "baseline" {
+		t.Fatalf("expected trend violation from baseline limits, got %+v", result)
+	}
+	if result := EvaluateRunRules(baseline[:5], runRulesSamples(1, 2), spec, 1); result.Status != statusInsufficient {
+		t.Fatalf("expected insufficient baseline")
+	}
+}
+
+func TestRunRulesEvalWindow(t *testing.T) {
+	if got := runRulesEvalWindow(RunRulesSpec{}); got != 15 {
+		t.Fatalf("expected 15, got %d", got)
+	}
+	if got := runRulesEvalWindow(RunRulesSpec{Rules: []int{1, 5}}); got != 3 {
+		t.Fatalf("expected 3, got %d", got)
+	}
+}
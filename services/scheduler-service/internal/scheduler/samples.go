@@ -6,73 +6,262 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
+	"predixaai-backend/pkg/expression"
 	"predixaai-backend/services/scheduler-service/internal/mcp"
 )
 
 type Sample struct {
 	TS       time.Time
 	Value    float64
+	Text     string
 	Subgroup string
+	Order    *int64
+	Key      *int64
+	Columns  map[string]float64
+
+	pos int // position within an incremental batch
+}
+
+// sampleLookback bounds how far back recent-row fetches reach when no explicit
+// range is given.
+const sampleLookback = 365 * 24 * time.Hour
+
+func lookbackSince() time.Time {
+	return time.Now().UTC().Add(-sampleLookback)
+}
+
+// sampleWindow selects the rows to fetch. Rows come back oldest first; by
+// default the newest Limit rows are returned, with Ascending the oldest.
+type sampleWindow struct {
+	Since     time.Time
+	Until     *time.Time
+	Limit     int
+	MinOrder  *int64
+	MaxOrder  *int64
+	After     *Watermark
+	Ascending bool
 }
 
-func fetchSamples(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSpec, param ParameterSpec, columns []string, since time.Time, limit int, subgroupColumn string) ([]Sample, error) {
+func fetchSamples(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSpec, param ParameterSpec, columns []string, window sampleWindow, subgroupColumn string) ([]Sample, error) {
 	if adapter == nil {
 		return nil, errors.New("adapter not configured")
 	}
-	cols := append([]string{param.ValueColumn, spec.Source.TimestampColumn}, columns...)
+	valueColumns, expr, err := parameterColumns(param)
+	if err != nil {
+		return nil, err
+	}
+	source := spec.Source
+	ordered := source.OrderingColumn != ""
+	keyed := source.KeyColumn != "" && !ordered
+	if window.After != nil {
+		if window.After.LastTS != nil && window.After.LastTS.After(window.Since) {
+			window.Since = *window.After.LastTS
+		}
+		if ordered && window.After.LastOrder != nil && (window.MinOrder == nil || *window.After.LastOrder > *window.MinOrder) {
+			window.MinOrder = window.After.LastOrder
+		}
+	}
+	cols := append([]string{}, valueColumns...)
+	if source.TimestampColumn != "" {
+		cols = append(cols, source.TimestampColumn)
+	}
+	if ordered {
+		cols = append(cols, source.OrderingColumn)
+	}
+	if keyed {
+		cols = append(cols, source.KeyColumn)
+	}
+	cols = append(cols, columns...)
 	if subgroupColumn != "" {
 		cols = append(cols, subgroupColumn)
 	}
-	rows, err := adapter.FetchRecentRows(ctx, mcp.FetchRecentRowsRequest{
+	req := mcp.FetchRecentRowsRequest{
 		ConnectionRef:   spec.ConnectionRef,
-		Table:           spec.Source.Table,
+		Table:           source.Table,
 		Columns:         cols,
-		TimestampColumn: spec.Source.TimestampColumn,
-		Where:           toWhere(spec.Source.Where),
-		Since:           since.UTC().Format(time.RFC3339),
-		Limit:           limit,
-	})
+		TimestampColumn: source.TimestampColumn,
+		Where:           toWhere(source.Where),
+		Limit:           window.Limit,
+	}
+	if source.TimestampColumn != "" && !window.Since.IsZero() {
+		req.Since = window.Since.UTC().Format(time.RFC3339Nano)
+	}
+	if source.TimestampColumn != "" && window.Until != nil {
+		req.Until = window.Until.UTC().Format(time.RFC3339Nano)
+	}
+	req.Ascending = window.Ascending
+	if keyed {
+		req.KeyColumn = source.KeyColumn
+		if window.After != nil && window.After.LastTS != nil && window.After.LastKey != nil {
+			req.AfterTS = window.After.LastTS.UTC().Format(time.RFC3339Nano)
+			req.AfterKey = window.After.LastKey
+		}
+	}
+	if ordered {
+		req.OrderColumn = source.OrderingColumn
+		req.MinOrder = window.MinOrder
+		req.MaxOrder = window.MaxOrder
+	}
+	rows, err := adapter.FetchRecentRows(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	samples := make([]Sample, 0, len(rows.Rows))
 	for _, row := range rows.Rows {
-		val, ok := row[param.ValueColumn]
-		if !ok {
-			continue
+		sample := Sample{}
+		if param.Detector.Type == "text_match" {
+			text, ok := rowText(row, param.ValueColumn)
+			if !ok {
+				continue
+			}
+			sample.Text = text
+		} else {
+			floatVal, ok := rowValue(row, param.ValueColumn, expr)
+			if !ok {
+				continue
+			}
+			sample.Value = floatVal
 		}
-		floatVal, err := toFloat(val)
-		if err != nil || math.IsNaN(floatVal) || math.IsInf(floatVal, 0) {
-			continue
+		if source.TimestampColumn != "" {
+			tsValue, ok := row[source.TimestampColumn]
+			if !ok {
+				continue
+			}
+			ts, err := parseTimeValue(tsValue)
+			if err != nil {
+				continue
+			}
+			sample.TS = ts
 		}
-		tsValue, ok := row[spec.Source.TimestampColumn]
-		if !ok {
-			continue
+		if ordered {
+			order, err := toOrder(row[source.OrderingColumn])
+			if err != nil {
+				continue
+			}
+			if (window.MinOrder != nil && order < *window.MinOrder) || (window.MaxOrder != nil && order > *window.MaxOrder) {
+				continue
+			}
+			sample.Order = &order
 		}
-		ts, err := parseTimeValue(tsValue)
-		if err != nil {
-			continue
+		if keyed {
+			key, err := toOrder(row[source.KeyColumn])
+			if err != nil {
+				continue
+			}
+			sample.Key = &key
+		}
+		for _, col := range columns {
+			if raw, ok := row[col]; ok && raw != nil {
+				if colVal, err := toFloat(raw); err == nil {
+					if sample.Columns == nil {
+						sample.Columns = map[string]float64{}
+					}
+					sample.Columns[col] = colVal
+				}
+			}
 		}
-		sample := Sample{TS: ts, Value: floatVal}
 		if subgroupColumn != "" {
 			if subgroupVal, ok := row[subgroupColumn]; ok {
 				sample.Subgroup = fmt.Sprint(subgroupVal)
 			}
 		}
+		if window.Until != nil && source.TimestampColumn != "" && sample.TS.After(*window.Until) {
+			continue
+		}
 		samples = append(samples, sample)
 	}
-	if len(samples) == 0 {
-		return samples, nil
+	if !window.Ascending {
+		// rows are returned in DESC order, reverse to ASC
+		for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
+			samples[i], samples[j] = samples[j], samples[i]
+		}
 	}
-	// rows are returned in DESC order, reverse to ASC
-	for i, j := 0, len(samples)-1; i < j; i, j = i+1, j-1 {
-		samples[i], samples[j] = samples[j], samples[i]
+	if window.After != nil {
+		samples = samplesAfter(samples, *window.After)
+		if window.Ascending && window.Limit > 0 && len(rows.Rows) >= window.Limit && len(samples) == 0 {
+			return nil, fmt.Errorf("watermark cannot advance: a full page of %d rows holds no sample after it; set source.keyColumn when more rows than that share one timestamp", window.Limit)
+		}
 	}
 	return samples, nil
 }
 
+// parameterColumns returns the columns to fetch for a parameter and, for
+// derived parameters, the expression that computes its value from them.
+func parameterColumns(param ParameterSpec) ([]string, *expression.Expression, error) {
+	if strings.TrimSpace(param.Expression) == "" {
+		return []string{param.ValueColumn}, nil, nil
+	}
+	expr, err := expression.Parse(param.Expression)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parameter %s: %w", param.ParameterName, err)
+	}
+	return expr.Columns(), expr, nil
+}
+
+func rowValue(row mcp.Row, valueColumn string, expr *expression.Expression) (float64, bool) {
+	if expr == nil {
+		raw, ok := row[valueColumn]
+		if !ok {
+			return 0, false
+		}
+		value, err := toFloat(raw)
+		if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+			return 0, false
+		}
+		return value, true
+	}
+	values := make(map[string]float64, len(expr.Columns()))
+	for _, col := range expr.Columns() {
+		raw, ok := row[col]
+		if !ok || raw == nil {
+			return 0, false
+		}
+		value, err := toFloat(raw)
+		if err != nil {
+			return 0, false
+		}
+		values[col] = value
+	}
+	value, err := expr.Eval(values)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// rowText returns the raw column value as text; NULL values are skipped.
+func rowText(row mcp.Row, valueColumn string) (string, bool) {
+	raw, ok := row[valueColumn]
+	if !ok || raw == nil {
+		return "", false
+	}
+	if b, ok := raw.([]byte); ok {
+		return string(b), true
+	}
+	return fmt.Sprint(raw), true
+}
+
+func toOrder(value any) (int64, error) {
+	floatVal, err := toFloat(value)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(floatVal) || math.IsInf(floatVal, 0) || floatVal != math.Trunc(floatVal) {
+		return 0, errors.New("ordering value must be an integer")
+	}
+	return int64(floatVal), nil
+}
+
+// samplesAfter drops ascending samples at or before the last persisted
+// position.
+func samplesAfter(samples []Sample, mark Watermark) []Sample {
+	_, fresh := mark.split(samples)
+	return fresh
+}
+
 func filterSamplesByRange(samples []Sample, start *time.Time, end *time.Time) []Sample {
 	if start == nil && end == nil {
 		return samples
@@ -127,6 +316,18 @@ func groupBySubgroup(samples []Sample, size int) [][]Sample {
 	return groups
 }
 
+func hasConsecutiveOrder(samples []Sample) bool {
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Order == nil || samples[i-1].Order == nil {
+			return false
+		}
+		if *samples[i].Order-*samples[i-1].Order != 1 {
+			return false
+		}
+	}
+	return true
+}
+
 func hasConsecutiveTimestamps(samples []Sample) bool {
 	if len(samples) < 2 {
 		return true
@@ -1,8 +1,11 @@
 package scheduler
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
 )
 
 func TestHasConsecutiveTimestampsEqual(t *testing.T) {
@@ -37,3 +40,74 @@ func TestHasConsecutiveTimestampsOK(t *testing.T) {
 		t.Fatalf("expected true for consistent timestamps")
 	}
 }
+
+func TestHasConsecutiveOrder(t *testing.T) {
+	orders := []int64{10, 11, 12, 14}
+	samples := make([]Sample, 0, len(orders))
+	for i := range orders {
+		samples = append(samples, Sample{Value: float64(i), Order: &orders[i]})
+	}
+	if !hasConsecutiveOrder(samples[:3]) {
+		t.Fatalf("expected consecutive runs")
+	}
+	if hasConsecutiveOrder(samples) {
+		t.Fatalf("expected false for run gap")
+	}
+}
+
+func TestFetchSamplesOrderedRunRange(t *testing.T) {
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{
+		{"value": 4.0, "run_order": int64(104)},
+		{"value": 3.0, "run_order": int64(103)},
+		{"value": 2.0, "run_order": int64(102)},
+		{"value": 1.0, "run_order": int64(101)},
+	}}}
+	spec := RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "runs", OrderingColumn: "run_order"}}
+	param := ParameterSpec{ValueColumn: "value"}
+	window, _, _, err := buildBaselineWindow(time.Now(), BaselineSpec{RunRange: &RunRangeSpec{From: 102, To: 103}}, 100)
+	if err != nil {
+		t.Fatalf("window failed: %v", err)
+	}
+	samples, err := fetchSamples(context.Background(), adapter, spec, param, nil, window, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(samples) != 2 || *samples[0].Order != 102 || *samples[1].Order != 103 {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+}
+
+func TestFetchSamplesDerivedParameter(t *testing.T) {
+	now := time.Now().UTC()
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{
+		{"gas_cl2_flow": 30.0, "gas_ar_flow": 60.0, "ts": now.Format(time.RFC3339)},
+		{"gas_cl2_flow": 30.0, "gas_ar_flow": 0.0, "ts": now.Add(-time.Minute).Format(time.RFC3339)},
+		{"gas_cl2_flow": 20.0, "gas_ar_flow": 80.0, "ts": now.Add(-2 * time.Minute).Format(time.RFC3339)},
+	}}}
+	spec := RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "etchers_data", TimestampColumn: "ts"}}
+	param := ParameterSpec{ParameterName: "cl2_ar_ratio", ValueColumn: "cl2_ar_ratio", Expression: "gas_cl2_flow / gas_ar_flow"}
+	samples, err := fetchSamples(context.Background(), adapter, spec, param, nil, sampleWindow{Limit: 10}, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(samples) != 2 || samples[0].Value != 0.25 || samples[1].Value != 0.5 {
+		t.Fatalf("expected derived values with the division by zero row skipped, got %+v", samples)
+	}
+}
+
+func TestRobustZWindowOrderedSource(t *testing.T) {
+	now := time.Now().UTC()
+	spec := RobustZSpec{BaselineWindowSeconds: 3600, MinSamples: 80}
+	timed := robustZWindow(SourceSpec{TimestampColumn: "ts"}, spec, now, 2000)
+	if !timed.Since.Equal(now.Add(-time.Hour)) || timed.Limit != 2000 {
+		t.Fatalf("expected a time window, got %+v", timed)
+	}
+	ordered := robustZWindow(SourceSpec{OrderingColumn: "run_order"}, spec, now, 2000)
+	if !ordered.Since.IsZero() || ordered.Limit != 80 {
+		t.Fatalf("expected the last minSamples runs, got %+v", ordered)
+	}
+	spec.BaselineRuns = 5000
+	if ordered := robustZWindow(SourceSpec{OrderingColumn: "run_order"}, spec, now, 2000); ordered.Limit != 2000 {
+		t.Fatalf("expected baselineRuns clamped to the row limit, got %d", ordered.Limit)
+	}
+}
@@ -5,16 +5,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
+	"predixaai-backend/services/scheduler-service/internal/bus"
 	"predixaai-backend/services/scheduler-service/internal/mcp"
 	"predixaai-backend/services/scheduler-service/internal/monitor"
+	"predixaai-backend/services/scheduler-service/internal/notify"
 	"predixaai-backend/services/scheduler-service/internal/security"
 	"predixaai-backend/services/scheduler-service/internal/storage"
 )
 
+const defaultAutoResolveAfter = 3
+
 type Registry struct {
 	mu         sync.Mutex
 	jobs       map[string]*Job
@@ -25,10 +30,15 @@ type Registry struct {
 	cancel     context.CancelFunc
 	jobTimeout time.Duration
 	limits     security.Limits
+	notifier   *notify.Dispatcher
+	events     *bus.Publisher
+	logger     *slog.Logger
 }
 
 type Job struct {
 	ruleID  string
+	stepper bool
+	shadow  bool
 	spec    RuleSpec
 	adapter mcp.DbMcpAdapter
 	stop    chan struct{}
@@ -36,11 +46,15 @@ type Job struct {
 
 type JobInfo struct {
 	RuleID             string `json:"ruleId"`
+	Stepper            bool   `json:"stepper,omitempty"`
+	Shadow             bool   `json:"shadow,omitempty"`
 	PollIntervalSecond int    `json:"pollIntervalSeconds"`
 }
 
 type JobRun struct {
 	ruleID  string
+	stepper bool
+	shadow  bool
 	spec    RuleSpec
 	adapter mcp.DbMcpAdapter
 }
@@ -56,6 +70,7 @@ func NewRegistry(repo *storage.Repository, limits security.Limits, workers int,
 		cancel:     cancel,
 		jobTimeout: jobTimeout,
 		limits:     limits,
+		logger:     slog.Default(),
 	}
 	for i := 0; i < workers; i++ {
 		go reg.worker()
@@ -63,6 +78,38 @@ func NewRegistry(repo *storage.Repository, limits security.Limits, workers int,
 	return reg
 }
 
+// SetNotifier makes new live alerts notify their subscribed channels.
+func (r *Registry) SetNotifier(notifier *notify.Dispatcher) {
+	r.notifier = notifier
+}
+
+// SetPublisher makes the registry publish live alert and rule status events.
+func (r *Registry) SetPublisher(events *bus.Publisher) {
+	r.events = events
+}
+
+// SetLogger replaces the default logger used for failed publishes and
+// notifications.
+func (r *Registry) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+// publish sends an event and logs a failure; a lost event only delays live
+// views, so the run carries on.
+func (r *Registry) publish(subject string, payload any) {
+	if err := r.events.Publish(subject, payload); err != nil {
+		r.logger.Error("failed to publish event", slog.String("subject", subject), slog.String("error", err.Error()))
+	}
+}
+
+// PublishRuleStatus announces a rule moving to a different status.
+func (r *Registry) PublishRuleStatus(ruleID string, stepper bool, previous, status string, lastError []byte) {
+	if previous == status {
+		return
+	}
+	r.publish(bus.SubjectRuleStatusChanged, bus.NewRuleStatusChanged(ruleID, stepper, previous, status, lastError, time.Now()))
+}
+
 func (r *Registry) Stop() {
 	r.cancel()
 	r.mu.Lock()
@@ -73,13 +120,21 @@ func (r *Registry) Stop() {
 	r.jobs = map[string]*Job{}
 }
 
-func (r *Registry) Schedule(ruleID string, spec RuleSpec, adapter mcp.DbMcpAdapter) {
+func (r *Registry) Schedule(ruleID string, spec RuleSpec, adapter mcp.DbMcpAdapter, shadow bool) {
+	r.schedule(ruleID, false, shadow, spec, adapter)
+}
+
+func (r *Registry) ScheduleStepper(ruleID string, spec RuleSpec, adapter mcp.DbMcpAdapter, shadow bool) {
+	r.schedule(ruleID, true, shadow, spec, adapter)
+}
+
+func (r *Registry) schedule(ruleID string, stepper, shadow bool, spec RuleSpec, adapter mcp.DbMcpAdapter) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if existing, ok := r.jobs[ruleID]; ok {
 		close(existing.stop)
 	}
-	job := &Job{ruleID: ruleID, spec: spec, adapter: adapter, stop: make(chan struct{})}
+	job := &Job{ruleID: ruleID, stepper: stepper, shadow: shadow, spec: spec, adapter: adapter, stop: make(chan struct{})}
 	r.jobs[ruleID] = job
 	go r.runTicker(job)
 }
@@ -98,7 +153,7 @@ func (r *Registry) ListJobs() []JobInfo {
 	defer r.mu.Unlock()
 	jobs := make([]JobInfo, 0, len(r.jobs))
 	for id, job := range r.jobs {
-		jobs = append(jobs, JobInfo{RuleID: id, PollIntervalSecond: job.spec.PollIntervalSeconds})
+		jobs = append(jobs, JobInfo{RuleID: id, Stepper: job.stepper, Shadow: job.shadow, PollIntervalSecond: job.spec.PollIntervalSeconds})
 	}
 	return jobs
 }
@@ -109,7 +164,7 @@ func (r *Registry) runTicker(job *Job) {
 	for {
 		select {
 		case <-ticker.C:
-			r.queue <- JobRun{ruleID: job.ruleID, spec: job.spec, adapter: job.adapter}
+			r.queue <- JobRun{ruleID: job.ruleID, stepper: job.stepper, shadow: job.shadow, spec: job.spec, adapter: job.adapter}
 		case <-job.stop:
 			return
 		case <-r.ctx.Done():
@@ -136,49 +191,50 @@ func (r *Registry) execute(run JobRun) {
 	if len(params) == 0 {
 		return
 	}
-	for _, param := range params {
-		result, err := r.evaluateParameter(ctx, run.spec, param, run.adapter)
-		if err != nil || !result.Hit {
-			continue
-		}
-		cooldown := 0
-		if run.spec.CooldownSeconds != nil {
-			cooldown = *run.spec.CooldownSeconds
-		}
-		if cooldown > 0 {
-			lastAlert, err := r.repo.GetLastAlertForKey(ctx, run.ruleID, param.ParameterName, param.Detector.Type)
-			if err == nil && monitor.WithinCooldown(lastAlert, cooldown) {
-				continue
+	var runOrder *int64
+	idle := false
+	if run.spec.Source.OrderingColumn != "" {
+		startedAt := time.Now().UTC()
+		latest, mark, err := r.loadRunOrder(ctx, run)
+		if err != nil {
+			for _, param := range params {
+				r.recordRun(ctx, run, param, startedAt, DetectorResult{}, err)
 			}
+			return
 		}
-		metadataMap := map[string]any{
-			"table":           run.spec.Source.Table,
-			"valueColumn":     param.ValueColumn,
-			"timestampColumn": run.spec.Source.TimestampColumn,
-			"detector":        param.Detector.Type,
-		}
-		for k, v := range result.Metadata {
-			metadataMap[k] = v
-		}
-		if result.WindowStart != nil {
-			metadataMap["windowStart"] = result.WindowStart.Format(time.RFC3339)
+		// Without a new run only missing_data has anything to check, and a
+		// composite waits for the next run.
+		idle = mark != nil && latest <= *mark
+		if idle && run.spec.Composite != nil {
+			return
 		}
-		if result.WindowEnd != nil {
-			metadataMap["windowEnd"] = result.WindowEnd.Format(time.RFC3339)
+		runOrder = &latest
+	}
+	outcomes := make([]parameterOutcome, 0, len(params))
+	failed := false
+	for _, param := range params {
+		if idle && param.Detector.Type != "missing_data" {
+			continue
 		}
-		if result.BaselineStart != nil {
-			metadataMap["baselineStart"] = result.BaselineStart.Format(time.RFC3339)
+		startedAt := time.Now().UTC()
+		result, err := r.evaluateParameter(ctx, run, param)
+		r.recordRun(ctx, run, param, startedAt, result, err)
+		failed = failed || err != nil
+		if run.spec.Composite != nil {
+			outcomes = append(outcomes, parameterOutcome{Param: param, Result: result, Err: err})
+			continue
 		}
-		if result.BaselineEnd != nil {
-			metadataMap["baselineEnd"] = result.BaselineEnd.Format(time.RFC3339)
+		if err != nil {
+			continue
 		}
-		if len(result.Violations) > 0 {
-			metadataMap["violations"] = result.Violations
+		if !result.Hit {
+			if runStatus(result, nil) == statusOK {
+				r.recordAlertOK(ctx, run, param.ParameterName, param.Detector.Type)
+			}
+			continue
 		}
-		metadataMap["explain"] = buildExplain(result, param)
-		metadata, _ := json.Marshal(metadataMap)
-		_ = r.repo.CreateAlert(ctx, storage.AlertRecord{
-			RuleID:         run.ruleID,
+		metadata, _ := json.Marshal(alertMetadata(run, param, result))
+		r.raiseAlert(ctx, run, storage.AlertRecord{
 			TSUTC:          time.Now().UTC(),
 			ParameterName:  param.ParameterName,
 			ObservedValue:  result.Observed,
@@ -193,9 +249,159 @@ func (r *Registry) execute(run JobRun) {
 			Metadata:       metadata,
 		})
 	}
+	if run.spec.Composite != nil {
+		r.executeComposite(ctx, run, *run.spec.Composite, outcomes, runOrder)
+	}
+	if runOrder != nil && !idle && !failed {
+		_ = r.saveRunOrder(ctx, run, *runOrder)
+	}
+}
+
+func (r *Registry) executeComposite(ctx context.Context, run JobRun, spec CompositeSpec, outcomes []parameterOutcome, runOrder *int64) {
+	result := EvaluateComposite(spec, outcomes, runOrder)
+	name := compositeName(spec)
+	if !result.Hit {
+		if result.Status == statusOK {
+			r.recordAlertOK(ctx, run, name, compositeDetectorType)
+		}
+		return
+	}
+	metadataMap := map[string]any{
+		"table":           run.spec.Source.Table,
+		"timestampColumn": run.spec.Source.TimestampColumn,
+		"detector":        compositeDetectorType,
+		"condition":       spec.Condition,
+		"contributors":    result.Metadata["contributors"],
+		"explain":         result.LimitExpr,
+	}
+	if runOrder != nil {
+		metadataMap["orderingColumn"] = run.spec.Source.OrderingColumn
+		metadataMap["runOrder"] = *runOrder
+	}
+	metadata, _ := json.Marshal(metadataMap)
+	r.raiseAlert(ctx, run, storage.AlertRecord{
+		TSUTC:         time.Now().UTC(),
+		ParameterName: name,
+		ObservedValue: result.Observed,
+		LimitExpr:     result.LimitExpr,
+		DetectorType:  compositeDetectorType,
+		Severity:      result.Severity,
+		Hit:           true,
+		Treated:       false,
+		Metadata:      metadata,
+	})
+}
+
+func (r *Registry) raiseAlert(ctx context.Context, run JobRun, alert storage.AlertRecord) {
+	alert.Shadow = run.shadow
+	if run.stepper {
+		alert.UIRuleID = run.ruleID
+	} else {
+		alert.RuleID = run.ruleID
+	}
+	openID, updated, err := r.repo.UpdateOpenAlert(ctx, run.ruleID, alert)
+	if err != nil {
+		r.logAlertError("failed to update open alert", run, alert.ParameterName, err)
+		return
+	}
+	if updated {
+		if !alert.Shadow {
+			r.publish(bus.SubjectAlertUpdated, bus.NewAlertUpdated(openID, alert, time.Now()))
+		}
+		return
+	}
+	cooldown := 0
+	if run.spec.CooldownSeconds != nil {
+		cooldown = *run.spec.CooldownSeconds
+	}
+	if cooldown > 0 {
+		lastAlert, err := r.repo.GetLastAlertForKey(ctx, run.ruleID, alert.ParameterName, alert.DetectorType, run.shadow)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			r.logAlertError("failed to check alert cooldown", run, alert.ParameterName, err)
+		}
+		if err == nil && monitor.WithinCooldown(lastAlert, cooldown) {
+			return
+		}
+	}
+	alertID, err := r.repo.CreateAlert(ctx, alert)
+	if err != nil {
+		r.logAlertError("failed to create alert", run, alert.ParameterName, err)
+		return
+	}
+	if alert.Shadow {
+		return
+	}
+	r.publish(bus.SubjectAlertCreated, bus.NewAlertCreated(alertID, alert, time.Now()))
+	if r.notifier != nil {
+		if err := r.notifier.Enqueue(ctx, notify.NewAlertEvent(notify.EventAlertCreated, alertID, alert)); err != nil {
+			r.logger.Error("failed to queue alert notifications", slog.Int64("alertId", alertID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (r *Registry) recordAlertOK(ctx context.Context, run JobRun, parameterName, detectorType string) {
+	resolved, ok, err := r.repo.RecordAlertOK(ctx, run.ruleID, parameterName, detectorType, autoResolveAfter(run.spec), run.shadow)
+	if err != nil {
+		r.logAlertError("failed to record alert ok", run, parameterName, err)
+		return
+	}
+	if !ok || run.shadow {
+		return
+	}
+	r.publish(bus.SubjectAlertResolved, bus.NewAlertResolved(resolved, time.Now()))
+}
+
+func (r *Registry) logAlertError(msg string, run JobRun, parameterName string, err error) {
+	r.logger.Error(msg, slog.String("ruleId", run.ruleID), slog.String("parameter", parameterName), slog.String("error", err.Error()))
+}
+
+func alertMetadata(run JobRun, param ParameterSpec, result DetectorResult) map[string]any {
+	metadataMap := map[string]any{
+		"table":           run.spec.Source.Table,
+		"valueColumn":     param.ValueColumn,
+		"timestampColumn": run.spec.Source.TimestampColumn,
+		"detector":        param.Detector.Type,
+	}
+	if run.spec.Source.OrderingColumn != "" {
+		metadataMap["orderingColumn"] = run.spec.Source.OrderingColumn
+	}
+	for k, v := range result.Metadata {
+		metadataMap[k] = v
+	}
+	if result.WindowStart != nil {
+		metadataMap["windowStart"] = result.WindowStart.Format(time.RFC3339)
+	}
+	if result.WindowEnd != nil {
+		metadataMap["windowEnd"] = result.WindowEnd.Format(time.RFC3339)
+	}
+	if result.BaselineStart != nil {
+		metadataMap["baselineStart"] = result.BaselineStart.Format(time.RFC3339)
+	}
+	if result.BaselineEnd != nil {
+		metadataMap["baselineEnd"] = result.BaselineEnd.Format(time.RFC3339)
+	}
+	if result.OrderStart != nil {
+		metadataMap["windowStartOrder"] = *result.OrderStart
+	}
+	if result.OrderEnd != nil {
+		metadataMap["windowEndOrder"] = *result.OrderEnd
+	}
+	if len(result.Violations) > 0 {
+		metadataMap["violations"] = result.Violations
+	}
+	metadataMap["explain"] = buildExplain(result, param)
+	return metadataMap
+}
+
+func autoResolveAfter(spec RuleSpec) int {
+	if spec.AutoResolveAfter != nil && *spec.AutoResolveAfter > 0 {
+		return *spec.AutoResolveAfter
+	}
+	return defaultAutoResolveAfter
 }
 
-func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param ParameterSpec, adapter mcp.DbMcpAdapter) (DetectorResult, error) {
+func (r *Registry) evaluateParameter(ctx context.Context, run JobRun, param ParameterSpec) (DetectorResult, error) {
+	spec, adapter := run.spec, run.adapter
 	if adapter == nil {
 		return DetectorResult{}, errors.New("adapter not configured")
 	}
@@ -206,6 +412,28 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 		}
 		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
 		defer cancel()
+		if spec.Source.OrderingColumn != "" && param.Detector.MissingData.MaxGapRuns != nil {
+			rows, err := adapter.FetchRecentRows(queryCtx, mcp.FetchRecentRowsRequest{
+				ConnectionRef: spec.ConnectionRef,
+				Table:         spec.Source.Table,
+				Columns:       []string{spec.Source.OrderingColumn},
+				OrderColumn:   spec.Source.OrderingColumn,
+				Where:         toWhere(spec.Source.Where),
+				Limit:         clampLimit(defaultBaselineLastN, r.limits.MaxSampleRows),
+			})
+			if err != nil {
+				return DetectorResult{}, err
+			}
+			orders := make([]int64, 0, len(rows.Rows))
+			for _, row := range rows.Rows {
+				if order, err := toOrder(row[spec.Source.OrderingColumn]); err == nil {
+					orders = append(orders, order)
+				}
+			}
+			result := EvaluateMissingRuns(orders, *param.Detector.MissingData.MaxGapRuns)
+			result.SampleCount = len(orders)
+			return result, nil
+		}
 		resp, err := adapter.QueryLatestValue(queryCtx, mcp.LatestValueRequest{
 			ConnectionRef:   spec.ConnectionRef,
 			Table:           spec.Source.Table,
@@ -226,50 +454,45 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 				return DetectorResult{}, err
 			}
 		}
-		return EvaluateMissingData(timestamp, param.Detector.MissingData.MaxGapSeconds, time.Now().UTC()), nil
+		result := EvaluateMissingData(timestamp, param.Detector.MissingData.MaxGapSeconds, time.Now().UTC())
+		result.SampleCount = 1
+		return result, nil
 	case "robust_zscore":
 		if param.Detector.RobustZ == nil {
 			return DetectorResult{}, errors.New("robust_zscore detector missing config")
 		}
-		baseline := param.Detector.RobustZ.BaselineWindowSeconds
-		since := time.Now().Add(-time.Duration(baseline) * time.Second).UTC().Format(time.RFC3339)
-		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
-		defer cancel()
-		rows, err := adapter.FetchRecentRows(queryCtx, mcp.FetchRecentRowsRequest{
-			ConnectionRef:   spec.ConnectionRef,
-			Table:           spec.Source.Table,
-			Columns:         []string{param.ValueColumn, spec.Source.TimestampColumn},
-			TimestampColumn: spec.Source.TimestampColumn,
-			Where:           toWhere(spec.Source.Where),
-			Since:           since,
-			Limit:           r.limits.MaxSampleRows,
-		})
+		frozen, err := r.activeBaseline(ctx, run, param)
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		if len(rows.Rows) < param.Detector.RobustZ.MinSamples {
-			return DetectorResult{Hit: false}, nil
+		if frozen != nil && frozen.Median != nil && frozen.MAD != nil {
+			return r.evaluateFrozenLatest(ctx, run, param, *frozen, func(latest Sample) DetectorResult {
+				return EvaluateRobustZFrozen(latest.Value, *frozen.Median, *frozen.MAD, param.Detector.RobustZ.ZWarn, param.Detector.RobustZ.ZCrit)
+			})
 		}
-		samples := make([]float64, 0, len(rows.Rows))
-		latest := 0.0
-		for i, row := range rows.Rows {
-			val, ok := row[param.ValueColumn]
-			if !ok {
-				continue
-			}
-			floatVal, err := toFloat(val)
-			if err != nil {
-				continue
-			}
-			if i == 0 {
-				latest = floatVal
-			}
-			samples = append(samples, floatVal)
+		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
+		defer cancel()
+		samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, robustZWindow(spec.Source, *param.Detector.RobustZ, time.Now().UTC(), r.limits.MaxSampleRows), "")
+		if err != nil {
+			return DetectorResult{}, err
 		}
 		if len(samples) < param.Detector.RobustZ.MinSamples {
-			return DetectorResult{Hit: false}, nil
+			result := insufficientData("not enough samples")
+			result.SampleCount = len(samples)
+			return result, nil
+		}
+		latest := samples[len(samples)-1].Value
+		values := extractValues(samples)
+		result := EvaluateRobustZ(values, latest, param.Detector.RobustZ.ZWarn, param.Detector.RobustZ.ZCrit)
+		result.SampleCount = len(samples)
+		median, mad := *result.BaselineMedian, *result.BaselineMAD
+		frozenStats := BaselineStats{ParameterName: param.ParameterName, DetectorType: param.Detector.Type, Median: &median, MAD: &mad, N: len(samples), DataHash: baselineDataHash(samples)}
+		inControl := samplesInControl(samples, func(sample Sample) bool {
+			return EvaluateRobustZFrozen(sample.Value, median, mad, param.Detector.RobustZ.ZWarn, param.Detector.RobustZ.ZCrit).Severity == "high"
+		})
+		if err := r.freezeBaseline(ctx, run, result, frozenStats, inControl); err != nil {
+			return DetectorResult{}, err
 		}
-		result := EvaluateRobustZ(samples, latest, param.Detector.RobustZ.ZWarn, param.Detector.RobustZ.ZCrit)
 		return result, nil
 	case "spec_limit":
 		if param.Detector.SpecLimit == nil {
@@ -277,6 +500,19 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 		}
 		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
 		defer cancel()
+		rowLimitColumns := LimitColumns(param.Detector)
+		if spec.Source.OrderingColumn != "" || len(rowLimitColumns) > 0 || param.Expression != "" {
+			samples, err := fetchSamples(queryCtx, adapter, spec, param, rowLimitColumns, sampleWindow{Since: lookbackSince(), Limit: 1}, "")
+			if err != nil {
+				return DetectorResult{}, err
+			}
+			if len(samples) == 0 {
+				return insufficientData("not enough samples"), nil
+			}
+			result := EvaluateSpecLimit(samples[0], *param.Detector.SpecLimit)
+			applyWindowAndBaseline(&result, samples, nil, nil, false)
+			return result, nil
+		}
 		resp, err := adapter.QueryLatestValue(queryCtx, mcp.LatestValueRequest{
 			ConnectionRef:   spec.ConnectionRef,
 			Table:           spec.Source.Table,
@@ -297,61 +533,243 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 				sampleTS = parsed
 			}
 		}
-		return EvaluateSpecLimit(Sample{TS: sampleTS, Value: floatVal}, *param.Detector.SpecLimit), nil
+		result := EvaluateSpecLimit(Sample{TS: sampleTS, Value: floatVal}, *param.Detector.SpecLimit)
+		result.SampleCount = 1
+		return result, nil
 	case "shewhart":
 		if param.Detector.Shewhart == nil {
 			return DetectorResult{}, errors.New("shewhart detector missing config")
 		}
-		now := time.Now().UTC()
-		since, start, end, limit, err := buildBaselineWindow(now, param.Detector.Shewhart.Baseline, r.limits.MaxSampleRows)
+		sigma := param.Detector.Shewhart.SigmaMultiplier
+		if sigma == 0 {
+			sigma = 3
+		}
+		frozen, err := r.activeBaseline(ctx, run, param)
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
-		defer cancel()
-		samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, since, limit, "")
+		if frozen != nil && frozen.Mu != nil && frozen.Sigma != nil {
+			return r.evaluateFrozenLatest(ctx, run, param, *frozen, func(latest Sample) DetectorResult {
+				return EvaluateShewhartFrozen(latest, *frozen.Mu, *frozen.Sigma, sigma)
+			})
+		}
+		batch, size, start, end, err := r.windowIncremental(ctx, run, param, param.Detector.Shewhart.Baseline, nil, "")
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		samples = filterSamplesByRange(samples, start, end)
-		sigma := param.Detector.Shewhart.SigmaMultiplier
-		if sigma == 0 {
-			sigma = 3
+		if batch == nil {
+			return insufficientData("no new samples"), nil
 		}
-		result := EvaluateShewhart(samples, *param.Detector.Shewhart, sigma)
+		result := evaluateNewPoints(batch.samples, batch.fresh, func(history []Sample) DetectorResult {
+			return EvaluateShewhart(lastSamples(history, size), *param.Detector.Shewhart, sigma)
+		})
+		samples := lastSamples(batch.samples, size)
 		applyWindowAndBaseline(&result, samples, start, end, true)
+		stats := ComputeBaselineStats(param, samples, nil)
+		inControl := stats.Mu != nil && stats.Sigma != nil && samplesInControl(samples, func(sample Sample) bool {
+			return EvaluateShewhartFrozen(sample, *stats.Mu, *stats.Sigma, sigma).Hit
+		})
+		if err := r.freezeBaseline(ctx, run, result, stats, inControl); err != nil {
+			return DetectorResult{}, err
+		}
+		if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+			return DetectorResult{}, err
+		}
 		return result, nil
+	case "run_rules":
+		if param.Detector.RunRules == nil {
+			return DetectorResult{}, errors.New("run_rules detector missing config")
+		}
+		runRules := *param.Detector.RunRules
+		baselineUsed := runRules.Mu == nil || runRules.Sigma == nil
+		window := runRulesEvalWindow(runRules)
+		return r.evaluateWindowed(ctx, run, param, runRules.Baseline, baselineUsed, window, func(baseline, history []Sample) DetectorResult {
+			return EvaluateRunRules(baseline, lastSamples(history, window), runRules, 1)
+		})
+	case "ewma":
+		if param.Detector.EWMA == nil {
+			return DetectorResult{}, errors.New("ewma detector missing config")
+		}
+		return r.evaluateEWMA(ctx, run, param, *param.Detector.EWMA)
+	case "cusum":
+		if param.Detector.CUSUM == nil {
+			return DetectorResult{}, errors.New("cusum detector missing config")
+		}
+		return r.evaluateCUSUM(ctx, run, param, *param.Detector.CUSUM)
+	case "attribute":
+		if param.Detector.Attribute == nil {
+			return DetectorResult{}, errors.New("attribute detector missing config")
+		}
+		attribute := *param.Detector.Attribute
+		window := attributeEvalWindow(attribute)
+		return r.evaluateWindowed(ctx, run, param, attribute.Baseline, true, window, func(baseline, history []Sample) DetectorResult {
+			return EvaluateAttributeChart(baseline, lastSamples(history, window), attribute, true)
+		})
 	case "range_chart":
 		if param.Detector.RangeChart == nil {
 			return DetectorResult{}, errors.New("range_chart detector missing config")
 		}
-		now := time.Now().UTC()
-		since, start, end, limit, err := buildBaselineWindow(now, param.Detector.RangeChart.Baseline, r.limits.MaxSampleRows)
-		if err != nil {
-			return DetectorResult{}, err
-		}
 		mode := param.Detector.RangeChart.Subgrouping.Mode
 		subgroupColumn := ""
 		if mode == "column" {
 			subgroupColumn = param.Detector.RangeChart.Subgrouping.Column
 		}
-		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
-		defer cancel()
-		samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, since, limit, subgroupColumn)
+		size := param.Detector.RangeChart.SubgroupSize
+		group := func(samples []Sample) [][]Sample {
+			if subgroupColumn != "" {
+				return groupBySubgroup(samples, size)
+			}
+			return groupConsecutive(samples, size)
+		}
+		frozen, err := r.activeBaseline(ctx, run, param)
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		samples = filterSamplesByRange(samples, start, end)
-		groups := [][]Sample{}
-		size := param.Detector.RangeChart.SubgroupSize
-		if subgroupColumn != "" {
-			groups = groupBySubgroup(samples, size)
-		} else {
-			groups = groupConsecutive(samples, size)
+		if frozen != nil && frozen.RBar != nil {
+			batch, err := r.fetchIncremental(ctx, run, param, size*defaultBaselineSubgroups, nil, subgroupColumn)
+			if err != nil {
+				return DetectorResult{}, err
+			}
+			if batch == nil {
+				return insufficientData("no new samples"), nil
+			}
+			samples := batch.samples
+			results := []DetectorResult{}
+			for _, g := range group(samples) {
+				if batch.isNew(g[len(g)-1]) {
+					results = append(results, EvaluateRangeChartFrozen(g, *frozen.RBar, size))
+				}
+			}
+			result := insufficientData("no valid subgroups")
+			if len(results) > 0 {
+				result = mergeNewResults(results, batch.fresh)
+			}
+			applyFrozenBaseline(&result, samples, *frozen)
+			if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+				return DetectorResult{}, err
+			}
+			return result, nil
+		}
+		batch, window, start, end, err := r.windowIncremental(ctx, run, param, param.Detector.RangeChart.Baseline, nil, subgroupColumn)
+		if err != nil {
+			return DetectorResult{}, err
+		}
+		if batch == nil {
+			return insufficientData("no new samples"), nil
 		}
-		result := EvaluateRangeChart(groups, *param.Detector.RangeChart)
+		result := evaluateNewPoints(batch.samples, batch.fresh, func(history []Sample) DetectorResult {
+			return EvaluateRangeChart(group(lastSamples(history, window)), *param.Detector.RangeChart)
+		})
+		samples := lastSamples(batch.samples, window)
 		applyWindowAndBaseline(&result, samples, start, end, true)
+		groups := group(samples)
+		stats := ComputeBaselineStats(param, samples, groups)
+		inControl := stats.RBar != nil
+		for _, g := range groups {
+			if inControl && EvaluateRangeChartFrozen(g, *stats.RBar, size).Hit {
+				inControl = false
+			}
+		}
+		if err := r.freezeBaseline(ctx, run, result, stats, inControl); err != nil {
+			return DetectorResult{}, err
+		}
+		if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+			return DetectorResult{}, err
+		}
 		return result, nil
+	case "xbar_r", "xbar_s":
+		if param.Detector.XbarChart == nil {
+			return DetectorResult{}, errors.New(param.Detector.Type + " detector missing config")
+		}
+		xbar := *param.Detector.XbarChart
+		subgroupColumn := ""
+		if xbar.Subgrouping.Mode == "column" {
+			subgroupColumn = xbar.Subgrouping.Column
+		}
+		batch, window, start, end, err := r.windowIncremental(ctx, run, param, xbar.Baseline, nil, subgroupColumn)
+		if err != nil {
+			return DetectorResult{}, err
+		}
+		if batch == nil {
+			return insufficientData("no new samples"), nil
+		}
+		result := evaluateNewPoints(batch.samples, batch.fresh, func(history []Sample) DetectorResult {
+			samples := lastSamples(history, window)
+			groups := groupConsecutive(samples, xbar.SubgroupSize)
+			if subgroupColumn != "" {
+				groups = groupBySubgroup(samples, xbar.SubgroupSize)
+			}
+			evalGroups := groups
+			if evalWindow := xbarEvalWindow(xbar); len(groups) > evalWindow {
+				evalGroups = groups[len(groups)-evalWindow:]
+			}
+			return EvaluateXbarChart(groups, evalGroups, xbar, xbarDispersion(param.Detector.Type), true)
+		})
+		samples := lastSamples(batch.samples, window)
+		applyWindowAndBaseline(&result, samples, start, end, true)
+		if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+			return DetectorResult{}, err
+		}
+		return result, nil
+	case "i_mr":
+		if param.Detector.IMR == nil {
+			return DetectorResult{}, errors.New("i_mr detector missing config")
+		}
+		imr := *param.Detector.IMR
+		window := imrEvalWindow(imr) + 1
+		return r.evaluateWindowed(ctx, run, param, imr.Baseline, true, window, func(baseline, history []Sample) DetectorResult {
+			return EvaluateIMR(baseline, lastSamples(history, window), imr, true)
+		})
+	case "capability":
+		if param.Detector.Capability == nil {
+			return DetectorResult{}, errors.New("capability detector missing config")
+		}
+		capability := *param.Detector.Capability
+		window := capabilityWindow(capability)
+		return r.evaluateWindowed(ctx, run, param, BaselineSpec{}, false, window, func(_, history []Sample) DetectorResult {
+			return EvaluateCapability(lastSamples(history, window), capability, true)
+		})
+	case "flatline":
+		if param.Detector.Flatline == nil {
+			return DetectorResult{}, errors.New("flatline detector missing config")
+		}
+		flatline := *param.Detector.Flatline
+		if span := flatlineSpan(flatline); span > 0 {
+			batch, err := r.fetchIncrementalSpan(ctx, run, param, span, LimitColumns(param.Detector))
+			if err != nil {
+				return DetectorResult{}, err
+			}
+			return r.evaluateBatch(ctx, run, param, batch, BaselineSpec{}, false, 0, func(_, history []Sample) DetectorResult {
+				return EvaluateFlatline(history, flatline, true)
+			})
+		}
+		window := flatlineEvalWindow(flatline)
+		return r.evaluateWindowed(ctx, run, param, BaselineSpec{}, false, window, func(_, history []Sample) DetectorResult {
+			return EvaluateFlatline(lastSamples(history, window), flatline, true)
+		})
+	case "step_change":
+		if param.Detector.StepChange == nil {
+			return DetectorResult{}, errors.New("step_change detector missing config")
+		}
+		stepChange := *param.Detector.StepChange
+		window := 2 * stepChangeWindow(stepChange)
+		return r.evaluateWindowed(ctx, run, param, BaselineSpec{}, false, window, func(_, history []Sample) DetectorResult {
+			return EvaluateStepChange(lastSamples(history, window), stepChange, 1)
+		})
+	case "rate_of_change":
+		if param.Detector.RateOfChange == nil {
+			return DetectorResult{}, errors.New("rate_of_change detector missing config")
+		}
+		rate := *param.Detector.RateOfChange
+		window := rateSmoothingWindow(rate) + 1
+		return r.evaluateWindowed(ctx, run, param, BaselineSpec{}, false, window, func(_, history []Sample) DetectorResult {
+			return EvaluateRateOfChange(lastSamples(history, window), rate, true)
+		})
+	case "text_match":
+		if param.Detector.TextMatch == nil {
+			return DetectorResult{}, errors.New("text_match detector missing config")
+		}
+		return r.evaluateTextMatch(ctx, run, param, *param.Detector.TextMatch)
 	case "trend":
 		if param.Detector.Trend == nil {
 			return DetectorResult{}, errors.New("trend detector missing config")
@@ -360,42 +778,65 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 		if window == 0 {
 			window = 6
 		}
-		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
-		defer cancel()
-		samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, time.Now().UTC().Add(-time.Hour*24*365), clampLimit(window, r.limits.MaxSampleRows), "")
+		batch, err := r.fetchIncremental(ctx, run, param, window-1, nil, "")
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		if param.Detector.Trend.RequireConsecutiveTimestamps && !hasConsecutiveTimestamps(samples) {
-			result := insufficientData("non-consecutive timestamps")
-			applyWindowAndBaseline(&result, samples, nil, nil, false)
-			return result, nil
+		if batch == nil {
+			return insufficientData("no new samples"), nil
 		}
-		result := EvaluateTrend6(samples, *param.Detector.Trend)
+		samples := batch.samples
+		result := evaluateNewPoints(samples, batch.fresh, func(history []Sample) DetectorResult {
+			segment := lastSamples(history, window)
+			if param.Detector.Trend.RequireConsecutiveTimestamps && spec.Source.OrderingColumn != "" && !hasConsecutiveOrder(segment) {
+				return insufficientData("non-consecutive runs")
+			}
+			if param.Detector.Trend.RequireConsecutiveTimestamps && spec.Source.OrderingColumn == "" && !hasConsecutiveTimestamps(segment) {
+				return insufficientData("non-consecutive timestamps")
+			}
+			return EvaluateTrend6(segment, *param.Detector.Trend)
+		})
 		applyWindowAndBaseline(&result, samples, nil, nil, false)
+		if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+			return DetectorResult{}, err
+		}
 		return result, nil
 	case "tpa":
 		if param.Detector.TPA == nil {
 			return DetectorResult{}, errors.New("tpa detector missing config")
 		}
-		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
-		defer cancel()
 		limit := param.Detector.TPA.WindowN
 		if limit == 0 {
 			limit = 3
 		}
-		samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, time.Now().UTC().Add(-time.Hour*24*365), clampLimit(limit, r.limits.MaxSampleRows), "")
+		batch, err := r.fetchIncremental(ctx, run, param, limit-1, LimitColumns(param.Detector), "")
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		result := EvaluateTPA(samples, *param.Detector.TPA)
+		if batch == nil {
+			return insufficientData("no new samples"), nil
+		}
+		samples := batch.samples
+		tpa := *param.Detector.TPA
+		if tpa.RegressionTimeBasis == "" && spec.Source.TimestampColumn == "" {
+			tpa.RegressionTimeBasis = "index"
+		}
+		result := evaluateNewPoints(samples, batch.fresh, func(history []Sample) DetectorResult {
+			return EvaluateTPA(lastSamples(history, limit), tpa)
+		})
 		applyWindowAndBaseline(&result, samples, nil, nil, false)
+		if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+			return DetectorResult{}, err
+		}
 		return result, nil
 	default:
 		if param.Detector.Threshold == nil {
 			return DetectorResult{}, errors.New("threshold detector missing config")
 		}
 		if spec.Aggregation != "" && spec.Aggregation != "latest" && spec.WindowSeconds != nil {
+			if param.Expression != "" {
+				return DetectorResult{}, errors.New("aggregation is not supported for derived parameters")
+			}
 			queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
 			defer cancel()
 			resp, err := adapter.QueryAggregate(queryCtx, mcp.AggregateRequest{
@@ -410,22 +851,351 @@ func (r *Registry) evaluateParameter(ctx context.Context, spec RuleSpec, param P
 			if err != nil {
 				return DetectorResult{}, err
 			}
-			return EvaluateThresholdDetector(*param.Detector.Threshold, resp.Value)
+			result, err := EvaluateThresholdDetector(*param.Detector.Threshold, resp.Value)
+			result.SampleCount = 1
+			return result, err
 		}
 		queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
 		defer cancel()
+		if param.Expression != "" {
+			samples, err := fetchSamples(queryCtx, adapter, spec, param, nil, sampleWindow{Limit: 1}, "")
+			if err != nil {
+				return DetectorResult{}, err
+			}
+			if len(samples) == 0 {
+				return insufficientData("not enough samples"), nil
+			}
+			result, err := EvaluateThresholdDetector(*param.Detector.Threshold, samples[len(samples)-1].Value)
+			result.SampleCount = 1
+			return result, err
+		}
 		resp, err := adapter.QueryLatestValue(queryCtx, mcp.LatestValueRequest{
 			ConnectionRef:   spec.ConnectionRef,
 			Table:           spec.Source.Table,
 			ValueColumn:     param.ValueColumn,
-			TimestampColumn: spec.Source.TimestampColumn,
+			TimestampColumn: sortColumn(spec.Source),
 			Where:           toWhere(spec.Source.Where),
 		})
 		if err != nil {
 			return DetectorResult{}, err
 		}
-		return EvaluateThresholdDetector(*param.Detector.Threshold, resp.Value)
+		result, err := EvaluateThresholdDetector(*param.Detector.Threshold, resp.Value)
+		result.SampleCount = 1
+		return result, err
+	}
+}
+
+func (r *Registry) evaluateCUSUM(ctx context.Context, run JobRun, param ParameterSpec, cusum CUSUMSpec) (DetectorResult, error) {
+	state := CUSUMState{}
+	hash := configHash(cusum)
+	found, err := r.loadDetectorState(ctx, run, param, &state)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if !found || state.ConfigHash != hash {
+		state = CUSUMState{ConfigHash: hash}
+	}
+	mark := stateWatermark(state.Watermark)
+	contextSize := 0
+	if mark == nil {
+		contextSize = cusumEvalWindow(cusum) - 1
+	}
+	batch, err := r.fetchAfter(ctx, run, param, mark, contextSize, LimitColumns(param.Detector), "")
+	if err != nil {
+		return DetectorResult{}, err
 	}
+	if batch == nil {
+		return insufficientData("no new samples"), nil
+	}
+	samples := newCUSUMSamples(batch.samples, state)
+	baselineUsed := (cusum.Target == nil || cusum.Sigma == nil) && state.Target == nil
+	baseline, start, end, err := r.fetchBaseline(ctx, run, param, cusum.Baseline, baselineUsed)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	result := EvaluateCUSUM(baseline, samples, cusum, &state)
+	applyWindowAndBaseline(&result, samples, start, end, baselineUsed && (start != nil || end != nil))
+	if result.Status == statusInsufficient {
+		return result, nil
+	}
+	if err := r.saveDetectorState(ctx, run, param, state); err != nil {
+		return DetectorResult{}, err
+	}
+	return result, nil
+}
+
+// evaluateEWMA continues the persisted statistic over the samples that arrived
+// since the last poll. The first poll warms up over the last evalWindow points
+// and only checks the latest one.
+func (r *Registry) evaluateEWMA(ctx context.Context, run JobRun, param ParameterSpec, ewma EWMASpec) (DetectorResult, error) {
+	state := EWMAState{}
+	hash := configHash(ewma)
+	found, err := r.loadDetectorState(ctx, run, param, &state)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if !found || state.ConfigHash != hash {
+		state = EWMAState{ConfigHash: hash}
+	}
+	warmUp := state.Count == 0
+	mark, contextSize := stateWatermark(state.Watermark), 0
+	if warmUp {
+		mark, contextSize = nil, ewmaEvalWindow(ewma)-1
+	}
+	batch, err := r.fetchAfter(ctx, run, param, mark, contextSize, LimitColumns(param.Detector), "")
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if batch == nil {
+		return insufficientData("no new samples"), nil
+	}
+	samples := batch.samples
+	baselineUsed := (ewma.Mu == nil || ewma.Sigma == nil) && state.Mu == nil
+	baseline, start, end, err := r.fetchBaseline(ctx, run, param, ewma.Baseline, baselineUsed)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	result := foldEWMA(baseline, samples, ewma, &state, warmUp)
+	applyWindowAndBaseline(&result, samples, start, end, baselineUsed && (start != nil || end != nil))
+	if result.Status == statusInsufficient {
+		return result, nil
+	}
+	if err := r.saveDetectorState(ctx, run, param, state); err != nil {
+		return DetectorResult{}, err
+	}
+	return result, nil
+}
+
+// loadDetectorState decodes the persisted state of a parameter into state. It
+// reports false when nothing usable is stored.
+func (r *Registry) loadDetectorState(ctx context.Context, run JobRun, param ParameterSpec, state any) (bool, error) {
+	raw, err := r.repo.GetDetectorState(ctx, run.ruleID, param.ParameterName, param.Detector.Type)
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return json.Unmarshal(raw, state) == nil, nil
+}
+
+func (r *Registry) saveDetectorState(ctx context.Context, run JobRun, param ParameterSpec, state any) error {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	rec := storage.DetectorStateRecord{ParameterName: param.ParameterName, DetectorType: param.Detector.Type, State: encoded}
+	if run.stepper {
+		rec.UIRuleID = run.ruleID
+	} else {
+		rec.RuleID = run.ruleID
+	}
+	return r.repo.SaveDetectorState(ctx, rec)
+}
+
+func (r *Registry) evaluateTextMatch(ctx context.Context, run JobRun, param ParameterSpec, textMatch TextMatchSpec) (DetectorResult, error) {
+	state := TextMatchState{}
+	hash := configHash(textMatch)
+	found, err := r.loadDetectorState(ctx, run, param, &state)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if !found || state.ConfigHash != hash {
+		state = TextMatchState{ConfigHash: hash}
+	}
+	mark := stateWatermark(state.Watermark)
+	contextSize := 0
+	if mark == nil {
+		contextSize = textMatchEvalWindow(textMatch) - 1
+	}
+	batch, err := r.fetchAfter(ctx, run, param, mark, contextSize, nil, "")
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if batch == nil {
+		return insufficientData("no new samples"), nil
+	}
+	samples := batch.samples
+	result := EvaluateTextMatch(samples, textMatch)
+	applyWindowAndBaseline(&result, samples, nil, nil, false)
+	if result.Status == statusInvalidConfig {
+		return result, nil
+	}
+	state.Watermark = batch.next()
+	if err := r.saveDetectorState(ctx, run, param, state); err != nil {
+		return DetectorResult{}, err
+	}
+	return result, nil
+}
+
+func (r *Registry) evaluateFrozenLatest(ctx context.Context, run JobRun, param ParameterSpec, baseline storage.BaselineRecord, evaluate func(Sample) DetectorResult) (DetectorResult, error) {
+	batch, err := r.fetchIncremental(ctx, run, param, 0, nil, "")
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	if batch == nil {
+		return insufficientData("no new samples"), nil
+	}
+	result := evaluateNewPoints(batch.samples, batch.fresh, func(history []Sample) DetectorResult {
+		return evaluate(history[len(history)-1])
+	})
+	applyFrozenBaseline(&result, batch.samples, baseline)
+	if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+		return DetectorResult{}, err
+	}
+	return result, nil
+}
+
+// fetchBaseline loads the baseline window of a detector whose limits are not
+// frozen in its config.
+func (r *Registry) fetchBaseline(ctx context.Context, run JobRun, param ParameterSpec, baselineSpec BaselineSpec, baselineUsed bool) ([]Sample, *time.Time, *time.Time, error) {
+	if !baselineUsed {
+		return []Sample{}, nil, nil, nil
+	}
+	window, start, end, err := buildBaselineWindow(time.Now().UTC(), baselineSpec, r.limits.MaxSampleRows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
+	defer cancel()
+	baseline, err := fetchSamples(queryCtx, run.adapter, run.spec, param, LimitColumns(param.Detector), window, "")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	baseline = filterSamplesByRange(baseline, start, end)
+	if start == nil && end == nil && len(baseline) > 0 {
+		start, end = timePtr(baseline[0].TS), timePtr(baseline[len(baseline)-1].TS)
+	}
+	return baseline, start, end, nil
+}
+
+// evaluateWindowed runs a detector over the window ending at each sample that
+// arrived since the watermark, then advances the watermark.
+func (r *Registry) evaluateWindowed(ctx context.Context, run JobRun, param ParameterSpec, baselineSpec BaselineSpec, baselineUsed bool, window int, evaluate func(baseline, history []Sample) DetectorResult) (DetectorResult, error) {
+	batch, err := r.fetchIncremental(ctx, run, param, window-1, LimitColumns(param.Detector), "")
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	return r.evaluateBatch(ctx, run, param, batch, baselineSpec, baselineUsed, window, evaluate)
+}
+
+// evaluateBatch is evaluateWindowed for a batch that is already fetched.
+func (r *Registry) evaluateBatch(ctx context.Context, run JobRun, param ParameterSpec, batch *incrementalBatch, baselineSpec BaselineSpec, baselineUsed bool, window int, evaluate func(baseline, history []Sample) DetectorResult) (DetectorResult, error) {
+	if batch == nil {
+		return insufficientData("no new samples"), nil
+	}
+	baseline, start, end, err := r.fetchBaseline(ctx, run, param, baselineSpec, baselineUsed)
+	if err != nil {
+		return DetectorResult{}, err
+	}
+	result := evaluateNewPoints(batch.samples, batch.fresh, func(history []Sample) DetectorResult {
+		return evaluate(baseline, history)
+	})
+	applyWindowAndBaseline(&result, lastSamples(batch.samples, window), start, end, baselineUsed && (start != nil || end != nil))
+	if err := r.advanceWatermark(ctx, run, param, batch, result); err != nil {
+		return DetectorResult{}, err
+	}
+	return result, nil
+}
+
+func (r *Registry) recordRun(ctx context.Context, run JobRun, param ParameterSpec, startedAt time.Time, result DetectorResult, evalErr error) {
+	finishedAt := time.Now().UTC()
+	rec := storage.RuleRunRecord{
+		ParameterName: param.ParameterName,
+		DetectorType:  param.Detector.Type,
+		StartedAt:     startedAt,
+		FinishedAt:    finishedAt,
+		DurationMS:    finishedAt.Sub(startedAt).Milliseconds(),
+		Status:        runStatus(result, evalErr),
+		Error:         runError(result, evalErr),
+		SampleCount:   result.SampleCount,
+	}
+	if run.stepper {
+		rec.UIRuleID = run.ruleID
+	} else {
+		rec.RuleID = run.ruleID
+	}
+	_ = r.repo.CreateRuleRun(ctx, rec)
+}
+
+func runStatus(result DetectorResult, err error) string {
+	if err != nil {
+		return statusError
+	}
+	if result.Status != "" {
+		return result.Status
+	}
+	return statusFromHit(result.Hit)
+}
+
+func runError(result DetectorResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result.Status == statusInsufficient || result.Status == statusInvalidConfig {
+		if msg, ok := result.Metadata["error"].(string); ok {
+			return msg
+		}
+	}
+	return ""
+}
+
+// loadRunOrder returns the latest run of an ordered source and the last run
+// the rule finished evaluating, or nil when it has not evaluated one since
+// its config last changed.
+func (r *Registry) loadRunOrder(ctx context.Context, run JobRun) (int64, *int64, error) {
+	latest, err := latestOrder(ctx, run.adapter, run.spec, r.limits.MaxQueryDuration)
+	if err != nil {
+		return 0, nil, err
+	}
+	rec, err := r.repo.GetWatermark(ctx, run.ruleID, "", runOrderMarkType)
+	if errors.Is(err, storage.ErrNotFound) {
+		return latest, nil, nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if rec.ConfigHash != runOrderConfigHash(run.spec) {
+		return latest, nil, nil
+	}
+	return latest, rec.LastOrder, nil
+}
+
+// saveRunOrder records that every parameter of the rule evaluated latest.
+func (r *Registry) saveRunOrder(ctx context.Context, run JobRun, latest int64) error {
+	rec := storage.WatermarkRecord{DetectorType: runOrderMarkType, ConfigHash: runOrderConfigHash(run.spec), LastOrder: &latest}
+	if run.stepper {
+		rec.UIRuleID = run.ruleID
+	} else {
+		rec.RuleID = run.ruleID
+	}
+	return r.repo.SaveWatermark(ctx, rec)
+}
+
+func latestOrder(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSpec, timeout time.Duration) (int64, error) {
+	if adapter == nil {
+		return 0, errors.New("adapter not configured")
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	resp, err := adapter.QueryLatestValue(queryCtx, mcp.LatestValueRequest{
+		ConnectionRef:   spec.ConnectionRef,
+		Table:           spec.Source.Table,
+		ValueColumn:     spec.Source.OrderingColumn,
+		TimestampColumn: spec.Source.OrderingColumn,
+		Where:           toWhere(spec.Source.Where),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return toOrder(resp.Value)
+}
+
+func sortColumn(source SourceSpec) string {
+	if source.OrderingColumn != "" {
+		return source.OrderingColumn
+	}
+	return source.TimestampColumn
 }
 
 func toWhere(spec *WhereSpec) *mcp.WhereSpec {
@@ -481,10 +1251,13 @@ func buildExplain(result DetectorResult, param ParameterSpec) string {
 		}
 		return fmt.Sprintf("robust_zscore=%.2f (warn>=%.2f, crit>=%.2f), median=%.2f, mad=%.2f", *result.AnomalyScore, param.Detector.RobustZ.ZWarn, param.Detector.RobustZ.ZCrit, *result.BaselineMedian, *result.BaselineMAD)
 	case "missing_data":
+		if param.Detector.MissingData.MaxGapRuns != nil {
+			return fmt.Sprintf("missing_data max_gap_runs=%d", *param.Detector.MissingData.MaxGapRuns)
+		}
 		return fmt.Sprintf("missing_data max_gap=%ds", param.Detector.MissingData.MaxGapSeconds)
 	case "threshold":
 		return result.LimitExpr
-	case "spec_limit", "shewhart", "range_chart", "trend", "tpa":
+	case "spec_limit", "shewhart", "range_chart", "trend", "tpa", "run_rules", "ewma", "cusum", "attribute", "xbar_r", "xbar_s", "i_mr", "capability", "flatline", "step_change", "rate_of_change", "text_match":
 		return result.LimitExpr
 	default:
 		return "detector"
@@ -492,7 +1265,11 @@ func buildExplain(result DetectorResult, param ParameterSpec) string {
 }
 
 func applyWindowAndBaseline(result *DetectorResult, samples []Sample, baselineStart, baselineEnd *time.Time, baselineUsed bool) {
-	if result == nil || len(samples) == 0 {
+	if result == nil {
+		return
+	}
+	result.SampleCount = len(samples)
+	if len(samples) == 0 {
 		return
 	}
 	first := samples[0].TS
@@ -503,6 +1280,12 @@ func applyWindowAndBaseline(result *DetectorResult, samples []Sample, baselineSt
 	if !last.IsZero() {
 		result.WindowEnd = &last
 	}
+	if samples[0].Order != nil {
+		result.OrderStart = samples[0].Order
+	}
+	if samples[len(samples)-1].Order != nil {
+		result.OrderEnd = samples[len(samples)-1].Order
+	}
 	if baselineUsed {
 		if baselineStart != nil {
 			result.BaselineStart = baselineStart
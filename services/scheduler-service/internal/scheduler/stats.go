@@ -1,6 +1,9 @@
 package scheduler
 
-import "math"
+import (
+	"math"
+	"sort"
+)
 
 func Mean(values []float64) float64 {
 	if len(values) == 0 {
@@ -70,3 +73,61 @@ func LinearRegression(xVals []float64, yVals []float64) (slope float64, intercep
 	r2 = 1 - ssRes/ssTot
 	return slope, intercept, r2, true
 }
+
+func NormalCDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
+
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// ChiSquareQuantile uses the Wilson–Hilferty approximation.
+func ChiSquareQuantile(p float64, dof float64) float64 {
+	if dof <= 0 {
+		return 0
+	}
+	h := 2 / (9 * dof)
+	value := dof * math.Pow(1-h+NormalQuantile(p)*math.Sqrt(h), 3)
+	return math.Max(0, value)
+}
+
+// AndersonDarling returns the small-sample adjusted A² statistic and its
+// approximate p-value for a normal distribution with estimated mean and sigma.
+func AndersonDarling(values []float64) (float64, float64, bool) {
+	n := len(values)
+	if n < 3 {
+		return 0, 0, false
+	}
+	mean := Mean(values)
+	sigma := StdDev(values, false)
+	if sigma == 0 {
+		return 0, 0, false
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	sum := 0.0
+	for i := 0; i < n; i++ {
+		lower := clampProbability(NormalCDF((sorted[i] - mean) / sigma))
+		upper := clampProbability(NormalCDF((sorted[n-1-i] - mean) / sigma))
+		sum += float64(2*i+1) * (math.Log(lower) + math.Log(1-upper))
+	}
+	a2 := -float64(n) - sum/float64(n)
+	adjusted := a2 * (1 + 0.75/float64(n) + 2.25/float64(n*n))
+	var p float64
+	switch {
+	case adjusted >= 0.6:
+		p = math.Exp(1.2937 - 5.709*adjusted + 0.0186*adjusted*adjusted)
+	case adjusted >= 0.34:
+		p = math.Exp(0.9177 - 4.279*adjusted - 1.38*adjusted*adjusted)
+	case adjusted >= 0.2:
+		p = 1 - math.Exp(-8.318+42.796*adjusted-59.938*adjusted*adjusted)
+	default:
+		p = 1 - math.Exp(-13.436+101.14*adjusted-223.73*adjusted*adjusted)
+	}
+	return adjusted, math.Min(1, math.Max(0, p)), true
+}
+
+func clampProbability(p float64) float64 {
+	return math.Min(1-1e-12, math.Max(1e-12, p))
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	defaultStepChangeWindow = 10
+	defaultStepChangeSigma  = 3.0
+)
+
+// EvaluateStepChange compares the means of two adjacent windows of
+// windowSize samples. A shift counts when it is at least minShift and/or at
+// least sigmaMultiplier pooled standard deviations. Preview reports the
+// largest shift of every streak of flagged split points. When newPoints is
+// positive only the splits whose after window ends at one of the last
+// newPoints samples are tested.
+func EvaluateStepChange(samples []Sample, spec StepChangeSpec, newPoints int) DetectorResult {
+	window := stepChangeWindow(spec)
+	k := spec.SigmaMultiplier
+	if k == 0 && spec.MinShift == nil {
+		k = defaultStepChangeSigma
+	}
+	if len(samples) < 2*window {
+		return insufficientData("not enough samples")
+	}
+	last := samples[len(samples)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(last.Value),
+		LimitExpr: stepChangeLimitExpr(window, k, spec.MinShift),
+		Metadata: map[string]any{
+			"windowSize":      window,
+			"sigmaMultiplier": k,
+		},
+	}
+	first := window
+	if newPoints > 0 && len(samples)-window-newPoints+1 > first {
+		first = len(samples) - window - newPoints + 1
+	}
+	var pending *Violation
+	flush := func() {
+		if pending != nil {
+			addViolation(&result, *pending)
+			pending = nil
+		}
+	}
+	for split := first; split+window <= len(samples); split++ {
+		before := extractValues(samples[split-window : split])
+		after := extractValues(samples[split : split+window])
+		meanBefore, meanAfter := Mean(before), Mean(after)
+		shift := meanAfter - meanBefore
+		pooled := math.Sqrt((math.Pow(StdDev(before, false), 2) + math.Pow(StdDev(after, false), 2)) / 2)
+		limit, hit := stepChangeLimit(shift, pooled, k, spec.MinShift)
+		if split+window == len(samples) {
+			result.Metadata["meanBefore"] = meanBefore
+			result.Metadata["meanAfter"] = meanAfter
+			result.Metadata["shift"] = shift
+			result.Metadata["pooledSigma"] = pooled
+		}
+		if !hit {
+			flush()
+			continue
+		}
+		if pending != nil && math.Abs(shift) <= math.Abs(pending.Delta) {
+			continue
+		}
+		reason := "step_up"
+		if shift < 0 {
+			reason = "step_down"
+		}
+		idx, changePoint := split+window-1, split
+		pending = &Violation{
+			Timestamp:   timePtr(samples[split].TS),
+			Index:       &idx,
+			Order:       samples[split].Order,
+			Value:       meanAfter,
+			Reason:      reason,
+			LimitName:   "shift",
+			LimitValue:  limit,
+			Delta:       shift,
+			ChangePoint: &changePoint,
+		}
+	}
+	flush()
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+	}
+	return result
+}
+
+// stepChangeLimit returns the shift magnitude a split must reach and whether
+// shift reaches it. A zero pooled sigma makes any non-zero shift significant.
+func stepChangeLimit(shift, pooled, k float64, minShift *float64) (float64, bool) {
+	limit := 0.0
+	hit := true
+	if minShift != nil {
+		limit = *minShift
+		hit = math.Abs(shift) >= *minShift
+	}
+	if k > 0 {
+		limit = math.Max(limit, k*pooled)
+		if pooled == 0 {
+			hit = hit && shift != 0
+		} else {
+			hit = hit && math.Abs(shift) >= k*pooled
+		}
+	}
+	return limit, hit
+}
+
+func stepChangeLimitExpr(window int, k float64, minShift *float64) string {
+	expr := fmt.Sprintf("step_change w=%d", window)
+	if k > 0 {
+		expr += fmt.Sprintf(" |Δ|>=%.1fσ", k)
+	}
+	if minShift != nil {
+		expr += fmt.Sprintf(" |Δ|>=%g", *minShift)
+	}
+	return expr
+}
+
+func stepChangeWindow(spec StepChangeSpec) int {
+	if spec.WindowSize > 0 {
+		return spec.WindowSize
+	}
+	return defaultStepChangeWindow
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import "testing"
+
+func TestEvaluateStepChange(t *testing.T) {
+	values := []float64{10, 10.2, 9.8, 10.1, 9.9, 12, 12.1, 11.9, 12.2, 11.8}
+	spec := StepChangeSpec{WindowSize: 5}
+	result := EvaluateStepChange(runRulesSamples(values...), spec, 1)
+	if !result.Hit || result.Violations[0].Reason != "step_up" || *result.Violations[0].ChangePoint != 5 {
+		t.Fatalf("expected step up at index 5, got %+v", result)
+	}
+	minShift := 3.0
+	if small := EvaluateStepChange(runRulesSamples(values...), StepChangeSpec{WindowSize: 5, MinShift: &minShift}, 1); small.Hit {
+		t.Fatalf("expected 2 unit shift below minShift")
+	}
+	long := append([]float64{10, 9.9, 10.1, 10, 10.2}, values...)
+	preview := EvaluateStepChange(runRulesSamples(long...), spec, 0)
+	if len(preview.Violations) != 1 || *preview.Violations[0].ChangePoint != 10 {
+		t.Fatalf("expected one streak at the step, got %+v", preview.Violations)
+	}
+	if insufficient := EvaluateStepChange(runRulesSamples(values[:6]...), spec, 1); insufficient.Status != statusInsufficient {
+		t.Fatalf("expected insufficient data")
+	}
+}
+
+func TestEvaluateStepChangeChecksEveryNewSplit(t *testing.T) {
+	values := []float64{10, 10.2, 9.8, 10.1, 9.9, 12, 12.1, 11.9, 12.2, 11.8, 12, 12, 12, 12, 12}
+	spec := StepChangeSpec{WindowSize: 5}
+	if latest := EvaluateStepChange(runRulesSamples(values...), spec, 1); latest.Hit {
+		t.Fatalf("expected no step at the latest split, got %+v", latest.Violations)
+	}
+	result := EvaluateStepChange(runRulesSamples(values...), spec, 6)
+	if !result.Hit || *result.Violations[0].ChangePoint != 5 {
+		t.Fatalf("expected the step among the new splits, got %+v", result.Violations)
+	}
+}
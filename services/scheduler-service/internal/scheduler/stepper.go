@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,8 +16,12 @@ type StepperBaselineRequest struct {
 	ConnectionRef    string      `json:"connectionRef"`
 	Table            string      `json:"table"`
 	TimestampColumn  string      `json:"timestampColumn"`
+	OrderingColumn   string      `json:"orderingColumn,omitempty"`
+	Where            *WhereSpec  `json:"where,omitempty"`
 	ValueColumn      string      `json:"valueColumn"`
+	Expression       string      `json:"expression,omitempty"`
 	RuleType         string      `json:"ruleType"`
+	Config           json.RawMessage `json:"config,omitempty"`
 	BaselineSelector selectorSpec `json:"baselineSelector"`
 	Subgrouping      *subgroupSpec `json:"subgrouping,omitempty"`
 }
@@ -25,7 +30,10 @@ type StepperPreviewRequest struct {
 	ConnectionRef    string          `json:"connectionRef"`
 	Table            string          `json:"table"`
 	TimestampColumn  string          `json:"timestampColumn"`
+	OrderingColumn   string          `json:"orderingColumn,omitempty"`
+	Where            *WhereSpec      `json:"where,omitempty"`
 	ValueColumn      string          `json:"valueColumn"`
+	Expression       string          `json:"expression,omitempty"`
 	RuleType         string          `json:"ruleType"`
 	Config           json.RawMessage `json:"config"`
 	BaselineSelector *selectorSpec   `json:"baselineSelector,omitempty"`
@@ -33,11 +41,32 @@ type StepperPreviewRequest struct {
 	Subgrouping      *subgroupSpec   `json:"subgrouping,omitempty"`
 }
 
+type StepperCapabilityRequest struct {
+	ConnectionRef   string          `json:"connectionRef"`
+	Table           string          `json:"table"`
+	TimestampColumn string          `json:"timestampColumn"`
+	OrderingColumn  string          `json:"orderingColumn,omitempty"`
+	Where           *WhereSpec      `json:"where,omitempty"`
+	ValueColumn     string          `json:"valueColumn"`
+	Expression      string          `json:"expression,omitempty"`
+	SpecLimits      SpecLimitBounds `json:"specLimits"`
+	ConfidenceLevel float64         `json:"confidenceLevel,omitempty"`
+	Selector        *selectorSpec   `json:"selector,omitempty"`
+}
+
+type StepperCapabilityResponse struct {
+	Status     string            `json:"status"`
+	Window     map[string]string `json:"window"`
+	Capability *CapabilityResult `json:"capability,omitempty"`
+	Messages   []string          `json:"messages"`
+}
+
 type StepperBaselineResponse struct {
 	Status     string           `json:"status"`
 	Available  map[string]int   `json:"available"`
 	Required   map[string]int   `json:"required"`
 	Continuity continuitySummary `json:"continuity"`
+	Stats      *BaselineStats   `json:"stats,omitempty"`
 	Messages   []string         `json:"messages"`
 }
 
@@ -55,6 +84,8 @@ type selectorSpec struct {
 	Value int    `json:"value,omitempty"`
 	Start string `json:"start,omitempty"`
 	End   string `json:"end,omitempty"`
+	From  *int64 `json:"from,omitempty"`
+	To    *int64 `json:"to,omitempty"`
 }
 
 type subgroupSpec struct {
@@ -66,13 +97,17 @@ type subgroupSpec struct {
 type continuitySummary struct {
 	GapsDetected      bool    `json:"gapsDetected"`
 	LargestGapSeconds float64 `json:"largestGapSeconds"`
+	LargestGapRuns    int64   `json:"largestGapRuns,omitempty"`
 }
 
 func StepperBaselineCheck(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist security.Allowlist, limits security.Limits, req StepperBaselineRequest) (StepperBaselineResponse, error) {
-	spec, err := buildRuleSpec(req.ConnectionRef, req.Table, req.TimestampColumn, req.ValueColumn, req.RuleType, nil)
+	spec, err := buildRuleSpec(req.ConnectionRef, req.Table, req.TimestampColumn, req.ValueColumn, req.RuleType, req.Config)
 	if err != nil {
 		return StepperBaselineResponse{}, err
 	}
+	spec.Source.OrderingColumn = req.OrderingColumn
+	spec.Source.Where = req.Where
+	spec.Parameters[0].Expression = req.Expression
 	if err := validateStepperMetadata(ctx, adapter, allowlist, spec, req.Subgrouping); err != nil {
 		return StepperBaselineResponse{Status: statusInvalidConfig, Messages: []string{err.Error()}, Available: map[string]int{}, Required: map[string]int{}}, nil
 	}
@@ -82,30 +117,42 @@ func StepperBaselineCheck(ctx context.Context, adapter mcp.DbMcpAdapter, allowli
 	}
 	available := map[string]int{"samples": len(baselineSamples)}
 	required := map[string]int{"minBaselineSamples": 0, "minBaselineSubgroups": 0}
-	if req.RuleType == "RANGE_CHART_R" {
+	if req.RuleType == "RANGE_CHART_R" || req.RuleType == "XBAR_R" || req.RuleType == "XBAR_S" {
 		required["minBaselineSubgroups"] = defaultBaselineSubgroups
 	}
-	if req.RuleType == "SHEWHART_2SIGMA" || req.RuleType == "SHEWHART_3SIGMA" {
+	if req.RuleType == "SHEWHART_2SIGMA" || req.RuleType == "SHEWHART_3SIGMA" || req.RuleType == "RUN_RULES" || req.RuleType == "EWMA" || req.RuleType == "CUSUM" || req.RuleType == "I_MR" || isAttributeRuleType(req.RuleType) {
 		required["minBaselineSamples"] = defaultBaselineMinN
 	}
 	continuity := continuitySummary{GapsDetected: false, LargestGapSeconds: 0}
 	if len(baselineSamples) > 1 {
-		gapsDetected, largestGap := computeTimestampContinuity(baselineSamples)
-		continuity.GapsDetected = gapsDetected
-		continuity.LargestGapSeconds = largestGap
+		if spec.Source.OrderingColumn != "" {
+			gapsDetected, largestGap := computeRunContinuity(baselineSamples)
+			continuity.GapsDetected = gapsDetected
+			continuity.LargestGapRuns = largestGap
+		} else {
+			gapsDetected, largestGap := computeTimestampContinuity(baselineSamples)
+			continuity.GapsDetected = gapsDetected
+			continuity.LargestGapSeconds = largestGap
+		}
 	}
 	status := statusOK
 	if required["minBaselineSamples"] > 0 && len(baselineSamples) < required["minBaselineSamples"] {
 		status = statusInsufficient
 	}
-	if req.RuleType == "RANGE_CHART_R" {
-		groups := buildGroups(baselineSamples, req.Subgrouping)
+	groups := [][]Sample{}
+	if req.RuleType == "RANGE_CHART_R" || req.RuleType == "XBAR_R" || req.RuleType == "XBAR_S" {
+		groups = buildGroups(baselineSamples, req.Subgrouping)
 		available["subgroups"] = len(groups)
 		if len(groups) < required["minBaselineSubgroups"] {
 			status = statusInsufficient
 		}
 	}
-	return StepperBaselineResponse{Status: status, Available: available, Required: required, Continuity: continuity, Messages: []string{}}, nil
+	resp := StepperBaselineResponse{Status: status, Available: available, Required: required, Continuity: continuity, Messages: []string{}}
+	if status == statusOK && len(baselineSamples) > 0 {
+		stats := ComputeBaselineStats(spec.Parameters[0], baselineSamples, groups)
+		resp.Stats = &stats
+	}
+	return resp, nil
 }
 
 func StepperPreview(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist security.Allowlist, limits security.Limits, req StepperPreviewRequest) (StepperPreviewResponse, error) {
@@ -113,6 +160,9 @@ func StepperPreview(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist sec
 	if err != nil {
 		return StepperPreviewResponse{}, err
 	}
+	spec.Source.OrderingColumn = req.OrderingColumn
+	spec.Source.Where = req.Where
+	spec.Parameters[0].Expression = req.Expression
 	if err := validateStepperMetadata(ctx, adapter, allowlist, spec, req.Subgrouping); err != nil {
 		return StepperPreviewResponse{Status: statusInvalidConfig, Explain: err.Error()}, nil
 	}
@@ -142,17 +192,45 @@ func StepperPreview(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist sec
 		result = EvaluateSpecLimit(evalSamples[len(evalSamples)-1], *spec.Parameters[0].Detector.SpecLimit)
 	case "SHEWHART_3SIGMA", "SHEWHART_2SIGMA":
 		result = EvaluateShewhart(baselineSamples, *spec.Parameters[0].Detector.Shewhart, spec.Parameters[0].Detector.Shewhart.SigmaMultiplier)
+	case "RUN_RULES":
+		result = EvaluateRunRules(baselineSamples, evalSamples, *spec.Parameters[0].Detector.RunRules, 0)
+	case "EWMA":
+		result = EvaluateEWMA(baselineSamples, evalSamples, *spec.Parameters[0].Detector.EWMA, false)
+	case "CUSUM":
+		result = EvaluateCUSUM(baselineSamples, evalSamples, *spec.Parameters[0].Detector.CUSUM, nil)
+	case "P_CHART", "NP_CHART", "C_CHART", "U_CHART":
+		result = EvaluateAttributeChart(baselineSamples, evalSamples, *spec.Parameters[0].Detector.Attribute, false)
 	case "RANGE_CHART_R":
 		groups := buildGroups(baselineSamples, req.Subgrouping)
 		result = EvaluateRangeChart(groups, *spec.Parameters[0].Detector.RangeChart)
+	case "XBAR_R", "XBAR_S":
+		xbar := *spec.Parameters[0].Detector.XbarChart
+		xbar.SubgroupSize = subgroupSize(req.Subgrouping)
+		result = EvaluateXbarChart(buildGroups(baselineSamples, req.Subgrouping), buildGroups(evalSamples, req.Subgrouping), xbar, xbarDispersion(spec.Parameters[0].Detector.Type), false)
+	case "I_MR":
+		result = EvaluateIMR(baselineSamples, evalSamples, *spec.Parameters[0].Detector.IMR, false)
+	case "CAPABILITY":
+		result = EvaluateCapability(evalSamples, *spec.Parameters[0].Detector.Capability, false)
+	case "FLATLINE":
+		result = EvaluateFlatline(evalSamples, *spec.Parameters[0].Detector.Flatline, false)
+	case "STEP_CHANGE":
+		result = EvaluateStepChange(evalSamples, *spec.Parameters[0].Detector.StepChange, 0)
+	case "RATE_OF_CHANGE":
+		result = EvaluateRateOfChange(evalSamples, *spec.Parameters[0].Detector.RateOfChange, false)
+	case "TEXT_MATCH":
+		result = EvaluateTextMatch(evalSamples, *spec.Parameters[0].Detector.TextMatch)
 	case "TREND_6_POINTS":
 		result = EvaluateTrend6(evalSamples, *spec.Parameters[0].Detector.Trend)
 	case "TPA":
-		result = EvaluateTPA(evalSamples, *spec.Parameters[0].Detector.TPA)
+		tpa := *spec.Parameters[0].Detector.TPA
+		if tpa.RegressionTimeBasis == "" && spec.Source.TimestampColumn == "" {
+			tpa.RegressionTimeBasis = "index"
+		}
+		result = EvaluateTPA(evalSamples, tpa)
 	default:
 		return StepperPreviewResponse{}, errors.New("unsupported rule type")
 	}
-	applyWindowAndBaseline(&result, evalSamples, nil, nil, req.RuleType == "SHEWHART_3SIGMA" || req.RuleType == "SHEWHART_2SIGMA" || req.RuleType == "RANGE_CHART_R")
+	applyWindowAndBaseline(&result, evalSamples, nil, nil, req.RuleType == "SHEWHART_3SIGMA" || req.RuleType == "SHEWHART_2SIGMA" || req.RuleType == "RANGE_CHART_R" || req.RuleType == "XBAR_R" || req.RuleType == "XBAR_S" || req.RuleType == "I_MR" || req.RuleType == "RUN_RULES" || req.RuleType == "EWMA" || req.RuleType == "CUSUM" || isAttributeRuleType(req.RuleType))
 	computed := map[string]interface{}{}
 	for k, v := range result.Metadata {
 		computed[k] = v
@@ -173,14 +251,38 @@ func StepperPreview(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist sec
 		if v.Index != nil {
 			item["index"] = *v.Index
 		}
+		if v.Order != nil {
+			item["order"] = *v.Order
+		}
+		if v.Rule != 0 {
+			item["kind"] = "run"
+			item["rule"] = v.Rule
+			item["indices"] = v.Indices
+		}
+		if v.ChangePoint != nil {
+			item["changePoint"] = *v.ChangePoint
+		}
+		if v.Text != "" {
+			item["text"] = v.Text
+		}
+		if v.Severity != "" {
+			item["severity"] = v.Severity
+		}
 		violations = append(violations, item)
 	}
+	window := map[string]string{
+		"start": formatTime(result.WindowStart),
+		"end":   formatTime(result.WindowEnd),
+	}
+	if result.OrderStart != nil {
+		window["startOrder"] = strconv.FormatInt(*result.OrderStart, 10)
+	}
+	if result.OrderEnd != nil {
+		window["endOrder"] = strconv.FormatInt(*result.OrderEnd, 10)
+	}
 	return StepperPreviewResponse{
 		Status: result.Status,
-		Window: map[string]string{
-			"start": formatTime(result.WindowStart),
-			"end":   formatTime(result.WindowEnd),
-		},
+		Window: window,
 		Baseline: map[string]interface{}{
 			"start": formatTime(result.BaselineStart),
 			"end":   formatTime(result.BaselineEnd),
@@ -192,6 +294,60 @@ func StepperPreview(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist sec
 	}, nil
 }
 
+func StepperCapability(ctx context.Context, adapter mcp.DbMcpAdapter, allowlist security.Allowlist, limits security.Limits, req StepperCapabilityRequest) (StepperCapabilityResponse, error) {
+	config, err := json.Marshal(CapabilitySpec{SpecLimits: &req.SpecLimits, ConfidenceLevel: req.ConfidenceLevel})
+	if err != nil {
+		return StepperCapabilityResponse{}, err
+	}
+	spec, err := buildRuleSpec(req.ConnectionRef, req.Table, req.TimestampColumn, req.ValueColumn, "CAPABILITY", config)
+	if err != nil {
+		return StepperCapabilityResponse{}, err
+	}
+	spec.Source.OrderingColumn = req.OrderingColumn
+	spec.Source.Where = req.Where
+	spec.Parameters[0].Expression = req.Expression
+	if err := validateStepperMetadata(ctx, adapter, allowlist, spec, nil); err != nil {
+		return StepperCapabilityResponse{Status: statusInvalidConfig, Window: map[string]string{}, Messages: []string{err.Error()}}, nil
+	}
+	selector := req.Selector
+	if selector == nil {
+		selector = &selectorSpec{Kind: "lastN", Value: defaultBaselineLastN}
+	}
+	samples, err := fetchForSelector(ctx, adapter, spec, *selector, nil, limits)
+	if err != nil {
+		return StepperCapabilityResponse{}, err
+	}
+	result := DetectorResult{}
+	applyWindowAndBaseline(&result, samples, nil, nil, false)
+	window := map[string]string{
+		"start": formatTime(result.WindowStart),
+		"end":   formatTime(result.WindowEnd),
+	}
+	if result.OrderStart != nil {
+		window["startOrder"] = strconv.FormatInt(*result.OrderStart, 10)
+	}
+	if result.OrderEnd != nil {
+		window["endOrder"] = strconv.FormatInt(*result.OrderEnd, 10)
+	}
+	bounds := req.SpecLimits
+	if len(samples) > 0 {
+		bounds = *resolveSpecLimits(&req.SpecLimits, samples[len(samples)-1])
+	}
+	capability, err := ComputeCapability(extractValues(samples), bounds, req.ConfidenceLevel)
+	if err != nil {
+		status := statusInsufficient
+		if bounds.USL == nil && bounds.LSL == nil || bounds.USL != nil && bounds.LSL != nil && *bounds.USL <= *bounds.LSL {
+			status = statusInvalidConfig
+		}
+		return StepperCapabilityResponse{Status: status, Window: window, Messages: []string{err.Error()}}, nil
+	}
+	messages := []string{}
+	if capability.Normality.Evaluated && !capability.Normality.Normal {
+		messages = append(messages, "data does not look normal; capability indices may be misleading")
+	}
+	return StepperCapabilityResponse{Status: statusOK, Window: window, Capability: &capability, Messages: messages}, nil
+}
+
 func buildRuleSpec(connectionRef, table, timestampColumn, valueColumn, ruleType string, config json.RawMessage) (RuleSpec, error) {
 	detector, err := buildDetector(ruleType, config)
 	if err != nil {
@@ -226,10 +382,55 @@ func buildDetector(ruleType string, config json.RawMessage) (DetectorSpec, error
 		}
 		spec.SigmaMultiplier = sigma
 		return DetectorSpec{Type: "shewhart", Shewhart: &spec}, nil
+	case "RUN_RULES":
+		var spec RunRulesSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "run_rules", RunRules: &spec}, nil
+	case "EWMA":
+		var spec EWMASpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "ewma", EWMA: &spec}, nil
+	case "CUSUM":
+		var spec CUSUMSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "cusum", CUSUM: &spec}, nil
+	case "P_CHART", "NP_CHART", "C_CHART", "U_CHART":
+		var spec AttributeSpec
+		_ = json.Unmarshal(config, &spec)
+		spec.Chart = strings.ToLower(strings.TrimSuffix(ruleType, "_CHART"))
+		return DetectorSpec{Type: "attribute", Attribute: &spec}, nil
 	case "RANGE_CHART_R":
 		var spec RangeChartSpec
 		_ = json.Unmarshal(config, &spec)
 		return DetectorSpec{Type: "range_chart", RangeChart: &spec}, nil
+	case "XBAR_R", "XBAR_S":
+		var spec XbarChartSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: strings.ToLower(ruleType), XbarChart: &spec}, nil
+	case "I_MR":
+		var spec IMRSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "i_mr", IMR: &spec}, nil
+	case "CAPABILITY":
+		var spec CapabilitySpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "capability", Capability: &spec}, nil
+	case "FLATLINE":
+		var spec FlatlineSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "flatline", Flatline: &spec}, nil
+	case "STEP_CHANGE":
+		var spec StepChangeSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "step_change", StepChange: &spec}, nil
+	case "RATE_OF_CHANGE":
+		var spec RateOfChangeSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "rate_of_change", RateOfChange: &spec}, nil
+	case "TEXT_MATCH":
+		var spec TextMatchSpec
+		_ = json.Unmarshal(config, &spec)
+		return DetectorSpec{Type: "text_match", TextMatch: &spec}, nil
 	case "TREND_6_POINTS":
 		var spec TrendSpec
 		_ = json.Unmarshal(config, &spec)
@@ -247,23 +448,33 @@ func buildDetector(ruleType string, config json.RawMessage) (DetectorSpec, error
 }
 
 func fetchForSelector(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSpec, selector selectorSpec, subgroup *subgroupSpec, limits security.Limits) ([]Sample, error) {
-	since := time.Now().Add(-time.Hour * 24 * 365)
-	limit := limits.MaxSampleRows
+	window := sampleWindow{Since: lookbackSince(), Limit: limits.MaxSampleRows}
 	start := (*time.Time)(nil)
 	end := (*time.Time)(nil)
 	switch selector.Kind {
 	case "lastN":
 		if selector.Value > 0 {
-			limit = clampLimit(selector.Value, limits.MaxSampleRows)
+			window.Limit = clampLimit(selector.Value, limits.MaxSampleRows)
 		}
 	case "timeRange":
+		if spec.Source.TimestampColumn == "" {
+			return nil, errors.New("timeRange selector requires a timestamp column")
+		}
 		parsedStart, parsedEnd, err := parseTimeRange(TimeRangeSpec{Start: selector.Start, End: selector.End})
 		if err != nil {
 			return nil, err
 		}
-		since = parsedStart
+		window.Since = parsedStart
 		start = &parsedStart
 		end = &parsedEnd
+	case "runRange":
+		if spec.Source.OrderingColumn == "" {
+			return nil, errors.New("runRange selector requires an ordering column")
+		}
+		if selector.From == nil || selector.To == nil || *selector.To < *selector.From {
+			return nil, errors.New("invalid runRange selector")
+		}
+		window = sampleWindow{Limit: limits.MaxSampleRows, MinOrder: selector.From, MaxOrder: selector.To}
 	default:
 		return nil, errors.New("invalid selector kind")
 	}
@@ -271,7 +482,7 @@ func fetchForSelector(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSp
 	if subgroup != nil && subgroup.Kind == "column" {
 		subgroupColumn = subgroup.Column
 	}
-	samples, err := fetchSamples(ctx, adapter, spec, spec.Parameters[0], nil, since, limit, subgroupColumn)
+	samples, err := fetchSamples(ctx, adapter, spec, spec.Parameters[0], LimitColumns(spec.Parameters[0].Detector), window, subgroupColumn)
 	if err != nil {
 		return nil, err
 	}
@@ -282,19 +493,20 @@ func fetchForSelector(ctx context.Context, adapter mcp.DbMcpAdapter, spec RuleSp
 }
 
 func buildGroups(samples []Sample, subgroup *subgroupSpec) [][]Sample {
-	size := 0
-	if subgroup != nil {
-		size = subgroup.SubgroupSize
-	}
-	if size <= 0 {
-		size = 5
-	}
+	size := subgroupSize(subgroup)
 	if subgroup != nil && subgroup.Kind == "column" {
 		return groupBySubgroup(samples, size)
 	}
 	return groupConsecutive(samples, size)
 }
 
+func subgroupSize(subgroup *subgroupSpec) int {
+	if subgroup != nil && subgroup.SubgroupSize > 0 {
+		return subgroup.SubgroupSize
+	}
+	return 5
+}
+
 func computeTimestampContinuity(samples []Sample) (bool, float64) {
 	if len(samples) < 2 {
 		return false, 0
@@ -313,6 +525,20 @@ func computeTimestampContinuity(samples []Sample) (bool, float64) {
 	return gapsDetected, largest
 }
 
+func computeRunContinuity(samples []Sample) (bool, int64) {
+	largest := int64(0)
+	for i := 1; i < len(samples); i++ {
+		if samples[i].Order == nil || samples[i-1].Order == nil {
+			continue
+		}
+		gap := *samples[i].Order - *samples[i-1].Order - 1
+		if gap > largest {
+			largest = gap
+		}
+	}
+	return largest > 0, largest
+}
+
 func formatTime(ts *time.Time) string {
 	if ts == nil {
 		return ""
@@ -346,31 +572,82 @@ func validateStepperMetadata(ctx context.Context, adapter mcp.DbMcpAdapter, allo
 	for _, col := range cols {
 		colTypes[col.Name] = col.Type
 	}
-	if _, ok := colTypes[spec.Source.TimestampColumn]; !ok {
-		return errors.New("timestamp column not found")
+	if spec.Source.OrderingColumn != "" {
+		orderType, ok := colTypes[spec.Source.OrderingColumn]
+		if !ok {
+			return errors.New("ordering column not found")
+		}
+		if !isIntegerType(orderType) {
+			return errors.New("ordering column must be integer")
+		}
+	}
+	if spec.Source.TimestampColumn != "" || spec.Source.OrderingColumn == "" {
+		if _, ok := colTypes[spec.Source.TimestampColumn]; !ok {
+			return errors.New("timestamp column not found")
+		}
+	}
+	if spec.Source.Where != nil {
+		for _, clause := range spec.Source.Where.Clauses {
+			if _, ok := colTypes[clause.Column]; !ok {
+				return errors.New("row filter column not found")
+			}
+		}
 	}
 	param := spec.Parameters[0]
-	valueType := colTypes[param.ValueColumn]
-	if valueType == "" {
-		return errors.New("value column not found")
+	if param.Expression != "" && param.Detector.Type == "text_match" {
+		return errors.New("text_match does not support derived parameters")
 	}
-	if needsNumeric(param.Detector.Type) && !isNumericType(valueType) {
-		return errors.New("value column must be numeric")
+	if param.Expression != "" {
+		if err := ValidateExpressionColumns(param.Expression, colTypes); err != nil {
+			return err
+		}
+	} else {
+		valueType := colTypes[param.ValueColumn]
+		if valueType == "" {
+			return errors.New("value column not found")
+		}
+		if needsNumeric(param.Detector.Type) && !isNumericType(valueType) {
+			return errors.New("value column must be numeric")
+		}
+	}
+	for _, col := range LimitColumns(param.Detector) {
+		limitType, ok := colTypes[col]
+		if !ok {
+			return errors.New("limit column not found")
+		}
+		if !isNumericType(limitType) {
+			return errors.New("limit column must be numeric")
+		}
 	}
 	if subgroup != nil && subgroup.Kind == "column" {
 		if _, ok := colTypes[subgroup.Column]; !ok {
 			return errors.New("subgroup column not found")
 		}
 	}
-	if (param.Detector.Type == "trend" || param.Detector.Type == "tpa") && !isTimeType(colTypes[spec.Source.TimestampColumn]) {
+	if (param.Detector.Type == "trend" || param.Detector.Type == "tpa") && spec.Source.OrderingColumn == "" && !isTimeType(colTypes[spec.Source.TimestampColumn]) {
 		return errors.New("timestamp column must be time type")
 	}
+	if param.Detector.Flatline != nil && param.Detector.Flatline.MinSamples <= 0 && (spec.Source.OrderingColumn != "" || !isTimeType(colTypes[spec.Source.TimestampColumn])) {
+		return errors.New("flatline minDurationSeconds requires a time-typed timestamp column")
+	}
+	if param.Detector.RateOfChange != nil && param.Detector.RateOfChange.Basis == rateBasisSecond && (spec.Source.OrderingColumn != "" || !isTimeType(colTypes[spec.Source.TimestampColumn])) {
+		return errors.New("rate_of_change per second requires a time-typed timestamp column")
+	}
 	return nil
 }
 
 func needsNumeric(detectorType string) bool {
 	switch detectorType {
-	case "spec_limit", "shewhart", "range_chart", "trend", "tpa":
+	case "spec_limit", "shewhart", "range_chart", "trend", "tpa", "run_rules", "ewma", "cusum", "attribute", "xbar_r", "xbar_s", "i_mr", "capability", "flatline", "step_change", "rate_of_change":
+		return true
+	default:
+		return false
+	}
+}
+
+func isAttributeRuleType(ruleType string) bool {
+	switch ruleType {
+	case "P_CHART", "NP_CHART", "C_CHART", "U_CHART":
 		return true
 	default:
 		return false
@@ -382,6 +659,11 @@ func isNumericType(t string) bool {
 	return strings.Contains(value, "int") || strings.Contains(value, "decimal") || strings.Contains(value, "numeric") || strings.Contains(value, "float") || strings.Contains(value, "double") || strings.Contains(value, "real")
 }
 
+func isIntegerType(t string) bool {
+	value := strings.ToLower(t)
+	return strings.Contains(value, "int") || value == "serial" || value == "bigserial"
+}
+
 func isTimeType(t string) bool {
 	value := strings.ToLower(t)
 	return strings.Contains(value, "time") || strings.Contains(value, "date")
@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+)
+
+const defaultStepperPollSeconds = 60
+
+type StepperRuleDefinition struct {
+	RuleID            string
+	Name              string
+	RuleType          string
+	ParameterID       string
+	Config            json.RawMessage
+	ConnectionRef     string
+	Table             string
+	TimestampColumn   string
+	OrderingColumn    string
+	RowFilter         json.RawMessage
+	DerivedParameters json.RawMessage
+}
+
+type DerivedParameter struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+type stepperRuleConfig struct {
+	PollIntervalSeconds int  `json:"pollIntervalSeconds"`
+	CooldownSeconds     *int `json:"cooldownSeconds"`
+	AutoResolveAfter    *int `json:"autoResolveAfter"`
+	Baseline            *struct {
+		Selector *selectorSpec `json:"selector"`
+	} `json:"baseline"`
+	Subgrouping *subgroupSpec `json:"subgrouping"`
+}
+
+func BuildStepperRuleSpec(def StepperRuleDefinition) (RuleSpec, error) {
+	table, valueColumn := parseParameterID(def.ParameterID)
+	if table == "" || valueColumn == "" {
+		return RuleSpec{}, errors.New("invalid parameterId")
+	}
+	if table != def.Table {
+		return RuleSpec{}, errors.New("parameter table does not match machine unit table")
+	}
+	if def.TimestampColumn == "" && def.OrderingColumn == "" {
+		return RuleSpec{}, errors.New("timestamp column not configured")
+	}
+	spec, err := buildRuleSpec(def.ConnectionRef, def.Table, def.TimestampColumn, valueColumn, def.RuleType, def.Config)
+	if err != nil {
+		return RuleSpec{}, err
+	}
+	spec.Source.OrderingColumn = def.OrderingColumn
+	if len(def.DerivedParameters) > 0 && string(def.DerivedParameters) != "null" {
+		var derived []DerivedParameter
+		if err := json.Unmarshal(def.DerivedParameters, &derived); err != nil {
+			return RuleSpec{}, errors.New("invalid machine unit derived parameters")
+		}
+		for _, param := range derived {
+			if param.Name == valueColumn {
+				spec.Parameters[0].Expression = param.Expression
+				break
+			}
+		}
+	}
+	if len(def.RowFilter) > 0 && string(def.RowFilter) != "null" {
+		var where WhereSpec
+		if err := json.Unmarshal(def.RowFilter, &where); err != nil {
+			return RuleSpec{}, errors.New("invalid machine unit row filter")
+		}
+		spec.Source.Where = &where
+	}
+	var cfg stepperRuleConfig
+	if len(def.Config) > 0 {
+		if err := json.Unmarshal(def.Config, &cfg); err != nil {
+			return RuleSpec{}, errors.New("invalid rule config")
+		}
+	}
+	if cfg.Baseline != nil && cfg.Baseline.Selector != nil {
+		baseline, err := baselineFromSelector(*cfg.Baseline.Selector)
+		if err != nil {
+			return RuleSpec{}, err
+		}
+		applyStepperBaseline(&spec.Parameters[0].Detector, baseline)
+	}
+	if cfg.Subgrouping != nil {
+		applyStepperSubgrouping(&spec.Parameters[0].Detector, *cfg.Subgrouping)
+	}
+	if rangeChart := spec.Parameters[0].Detector.RangeChart; rangeChart != nil && rangeChart.SubgroupSize == 0 {
+		rangeChart.SubgroupSize = 5
+	}
+	if xbar := spec.Parameters[0].Detector.XbarChart; xbar != nil && xbar.SubgroupSize == 0 {
+		xbar.SubgroupSize = 5
+	}
+	spec.Name = def.Name
+	spec.PollIntervalSeconds = defaultStepperPollSeconds
+	if cfg.PollIntervalSeconds > 0 {
+		spec.PollIntervalSeconds = cfg.PollIntervalSeconds
+	}
+	spec.CooldownSeconds = cfg.CooldownSeconds
+	spec.AutoResolveAfter = cfg.AutoResolveAfter
+	return spec, nil
+}
+
+func ResolveStepperTimestampColumn(ctx context.Context, adapter mcp.DbMcpAdapter, connectionRef, table string) (string, error) {
+	cols, err := adapter.ListColumns(ctx, connectionRef, table)
+	if err != nil {
+		return "", err
+	}
+	candidates := []string{}
+	for _, col := range cols {
+		if isTimeType(col.Type) {
+			candidates = append(candidates, col.Name)
+		}
+	}
+	for _, name := range candidates {
+		lower := strings.ToLower(name)
+		if lower == "ts" || lower == "timestamp" || strings.Contains(lower, "time") {
+			return name, nil
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	return "", errors.New("timestamp column not found")
+}
+
+func parseParameterID(parameterID string) (string, string) {
+	parts := strings.SplitN(parameterID, ".", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], parts[1]
+}
+
+func baselineFromSelector(selector selectorSpec) (BaselineSpec, error) {
+	switch selector.Kind {
+	case "lastN":
+		if selector.Value <= 0 {
+			return BaselineSpec{}, errors.New("baseline lastN must be positive")
+		}
+		value := selector.Value
+		return BaselineSpec{LastN: &value}, nil
+	case "timeRange":
+		return BaselineSpec{TimeRange: &TimeRangeSpec{Start: selector.Start, End: selector.End}}, nil
+	case "runRange":
+		if selector.From == nil || selector.To == nil || *selector.To < *selector.From {
+			return BaselineSpec{}, errors.New("invalid baseline runRange")
+		}
+		return BaselineSpec{RunRange: &RunRangeSpec{From: *selector.From, To: *selector.To}}, nil
+	default:
+		return BaselineSpec{}, errors.New("invalid baseline selector kind")
+	}
+}
+
+func applyStepperBaseline(detector *DetectorSpec, baseline BaselineSpec) {
+	switch {
+	case detector.Shewhart != nil:
+		detector.Shewhart.Baseline = baseline
+	case detector.RangeChart != nil:
+		detector.RangeChart.Baseline = baseline
+	case detector.RunRules != nil:
+		detector.RunRules.Baseline = baseline
+	case detector.EWMA != nil:
+		detector.EWMA.Baseline = baseline
+	case detector.CUSUM != nil:
+		detector.CUSUM.Baseline = baseline
+	case detector.Attribute != nil:
+		detector.Attribute.Baseline = baseline
+	case detector.XbarChart != nil:
+		detector.XbarChart.Baseline = baseline
+	case detector.IMR != nil:
+		detector.IMR.Baseline = baseline
+	}
+}
+
+func applyStepperSubgrouping(detector *DetectorSpec, subgroup subgroupSpec) {
+	if detector.XbarChart != nil {
+		if detector.XbarChart.Subgrouping.Mode == "" {
+			detector.XbarChart.Subgrouping = SubgroupingSpec{Mode: subgroup.Kind, Column: subgroup.Column}
+		}
+		if detector.XbarChart.SubgroupSize == 0 {
+			detector.XbarChart.SubgroupSize = subgroup.SubgroupSize
+		}
+		return
+	}
+	if detector.RangeChart == nil {
+		return
+	}
+	if detector.RangeChart.Subgrouping.Mode == "" {
+		detector.RangeChart.Subgrouping.Mode = subgroup.Kind
+	}
+	if detector.RangeChart.SubgroupSize == 0 {
+		detector.RangeChart.SubgroupSize = subgroup.SubgroupSize
+	}
+}
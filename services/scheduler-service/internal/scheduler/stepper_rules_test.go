@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+)
+
+func TestBuildStepperRuleSpecShewhart(t *testing.T) {
+	spec, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleID:          "rule-1",
+		Name:            "Etch rate 3 sigma",
+		RuleType:        "SHEWHART_3SIGMA",
+		ParameterID:     "etchers_data.si_etch_rate",
+		Config:          json.RawMessage(`{"baseline":{"selector":{"kind":"lastN","value":100}},"minBaselineN":20,"cooldownSeconds":300}`),
+		ConnectionRef:   "conn",
+		Table:           "etchers_data",
+		TimestampColumn: "ts",
+	})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if spec.Source.Table != "etchers_data" || spec.Source.TimestampColumn != "ts" {
+		t.Fatalf("unexpected source %+v", spec.Source)
+	}
+	param := spec.Parameters[0]
+	if param.ValueColumn != "si_etch_rate" || param.Detector.Type != "shewhart" {
+		t.Fatalf("unexpected parameter %+v", param)
+	}
+	if param.Detector.Shewhart.Baseline.LastN == nil || *param.Detector.Shewhart.Baseline.LastN != 100 {
+		t.Fatalf("expected baseline lastN from selector")
+	}
+	if param.Detector.Shewhart.MinBaselineN != 20 {
+		t.Fatalf("expected minBaselineN 20")
+	}
+	if spec.PollIntervalSeconds != defaultStepperPollSeconds {
+		t.Fatalf("expected default poll interval")
+	}
+	if spec.CooldownSeconds == nil || *spec.CooldownSeconds != 300 {
+		t.Fatalf("expected cooldown from config")
+	}
+}
+
+func TestBuildStepperRuleSpecRangeChartSubgrouping(t *testing.T) {
+	spec, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleType:        "RANGE_CHART_R",
+		ParameterID:     "etchers_data.trench_width",
+		Config:          json.RawMessage(`{"subgrouping":{"kind":"column","column":"unit","subgroupSize":4},"pollIntervalSeconds":30}`),
+		ConnectionRef:   "conn",
+		Table:           "etchers_data",
+		TimestampColumn: "ts",
+	})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	rangeChart := spec.Parameters[0].Detector.RangeChart
+	if rangeChart.SubgroupSize != 4 || rangeChart.Subgrouping.Mode != "column" || rangeChart.Subgrouping.Column != "unit" {
+		t.Fatalf("unexpected subgrouping %+v", rangeChart)
+	}
+	if spec.PollIntervalSeconds != 30 {
+		t.Fatalf("expected poll interval from config")
+	}
+}
+
+func TestBuildStepperRuleSpecRejectsForeignTable(t *testing.T) {
+	_, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleType:        "SPEC_LIMIT_VIOLATION",
+		ParameterID:     "other.value",
+		ConnectionRef:   "conn",
+		Table:           "etchers_data",
+		TimestampColumn: "ts",
+	})
+	if err == nil {
+		t.Fatalf("expected table mismatch error")
+	}
+}
+
+func TestResolveStepperTimestampColumn(t *testing.T) {
+	adapter := &mcp.MockAdapter{
+		Columns: map[string][]mcp.Column{
+			"telemetry": {{Name: "value", Type: "float"}, {Name: "created_date", Type: "date"}, {Name: "event_time", Type: "timestamp"}},
+		},
+	}
+	col, err := ResolveStepperTimestampColumn(context.Background(), adapter, "conn", "telemetry")
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if col != "event_time" {
+		t.Fatalf("expected event_time, got %s", col)
+	}
+}
+
+func TestBuildStepperRuleSpecOrdering(t *testing.T) {
+	spec, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleType:       "SHEWHART_3SIGMA",
+		ParameterID:    "lots.thickness",
+		Config:         json.RawMessage(`{"baseline":{"selector":{"kind":"runRange","from":100,"to":199}}}`),
+		ConnectionRef:  "conn",
+		Table:          "lots",
+		OrderingColumn: "run_order",
+	})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if spec.Source.OrderingColumn != "run_order" || spec.Source.TimestampColumn != "" {
+		t.Fatalf("unexpected source %+v", spec.Source)
+	}
+	runRange := spec.Parameters[0].Detector.Shewhart.Baseline.RunRange
+	if runRange == nil || runRange.From != 100 || runRange.To != 199 {
+		t.Fatalf("expected baseline runRange from selector")
+	}
+}
+
+func TestBuildStepperRuleSpecRowFilter(t *testing.T) {
+	spec, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleType:        "SHEWHART_3SIGMA",
+		ParameterID:     "etchers_data.si_etch_rate",
+		ConnectionRef:   "conn",
+		Table:           "etchers_data",
+		TimestampColumn: "ts",
+		RowFilter:       json.RawMessage(`{"type":"and","clauses":[{"column":"unit","op":"=","value":"A"}]}`),
+	})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	where := spec.Source.Where
+	if where == nil || len(where.Clauses) != 1 || where.Clauses[0].Column != "unit" || where.Clauses[0].Value != "A" {
+		t.Fatalf("expected row filter on source, got %+v", where)
+	}
+}
+
+func TestBuildStepperRuleSpecDerivedParameter(t *testing.T) {
+	spec, err := BuildStepperRuleSpec(StepperRuleDefinition{
+		RuleType:          "SHEWHART_3SIGMA",
+		ParameterID:       "etchers_data.cl2_ar_ratio",
+		ConnectionRef:     "conn",
+		Table:             "etchers_data",
+		TimestampColumn:   "ts",
+		DerivedParameters: json.RawMessage(`[{"name":"cl2_ar_ratio","expression":"gas_cl2_flow / gas_ar_flow"}]`),
+	})
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if param := spec.Parameters[0]; param.ValueColumn != "cl2_ar_ratio" || param.Expression != "gas_cl2_flow / gas_ar_flow" {
+		t.Fatalf("expected derived expression on parameter, got %+v", param)
+	}
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	textKindKeyword = "keyword"
+	textKindRegex   = "regex"
+
+	defaultTextMatchEvalWindow = 50
+	maxTextMatchObserved       = 200
+)
+
+// TextMatchState remembers the last row a scheduled text_match rule has
+// scanned so that every row is checked exactly once.
+type TextMatchState struct {
+	ConfigHash string `json:"configHash"`
+	Watermark
+}
+
+type textMatcher struct {
+	pattern TextPattern
+	keyword string
+	re      *regexp.Regexp
+}
+
+// EvaluateTextMatch scans text samples for keyword or regex matches, or for
+// any non-null value when anyValue is set. Each matching row yields one
+// violation under the most severe pattern it matches, and the result takes
+// the highest severity seen.
+func EvaluateTextMatch(samples []Sample, spec TextMatchSpec) DetectorResult {
+	matchers, err := compileTextMatchers(spec)
+	if err != nil {
+		return invalidConfig(err.Error())
+	}
+	if len(samples) == 0 {
+		return insufficientData("not enough samples")
+	}
+	last := samples[len(samples)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  truncateText(last.Text),
+		LimitExpr: textMatchLimitExpr(spec),
+		Metadata: map[string]any{
+			"rowsScanned": len(samples),
+		},
+	}
+	highest := ""
+	for i, sample := range samples {
+		violation, ok := matchText(sample, matchers, spec)
+		if !ok {
+			continue
+		}
+		idx := i
+		violation.Timestamp = timePtr(sample.TS)
+		violation.Index = &idx
+		violation.Order = sample.Order
+		violation.Text = truncateText(sample.Text)
+		addViolation(&result, violation)
+		if highest != "high" {
+			highest = violation.Severity
+		}
+		result.Observed = violation.Text
+	}
+	result.Metadata["matches"] = len(result.Violations)
+	if len(result.Violations) > 0 {
+		result.Hit = true
+		result.Status = statusViolation
+		result.Severity = highest
+	}
+	return result
+}
+
+func compileTextMatchers(spec TextMatchSpec) ([]textMatcher, error) {
+	if len(spec.Patterns) == 0 && !spec.AnyValue {
+		return nil, errors.New("text_match requires patterns or anyValue")
+	}
+	matchers := make([]textMatcher, 0, len(spec.Patterns))
+	for _, pattern := range spec.Patterns {
+		if pattern.Pattern == "" {
+			return nil, errors.New("text_match pattern is empty")
+		}
+		matcher := textMatcher{pattern: pattern, keyword: pattern.Pattern}
+		switch pattern.Kind {
+		case "", textKindKeyword:
+			if !pattern.CaseSensitive {
+				matcher.keyword = strings.ToLower(pattern.Pattern)
+			}
+		case textKindRegex:
+			expr := pattern.Pattern
+			if !pattern.CaseSensitive {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex %q", pattern.Pattern)
+			}
+			matcher.re = re
+		default:
+			return nil, fmt.Errorf("unsupported pattern kind %q", pattern.Kind)
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func matchText(sample Sample, matchers []textMatcher, spec TextMatchSpec) (Violation, bool) {
+	var best *Violation
+	for _, matcher := range matchers {
+		hit := false
+		if matcher.re != nil {
+			hit = matcher.re.MatchString(sample.Text)
+		} else if matcher.pattern.CaseSensitive {
+			hit = strings.Contains(sample.Text, matcher.keyword)
+		} else {
+			hit = strings.Contains(strings.ToLower(sample.Text), matcher.keyword)
+		}
+		if !hit {
+			continue
+		}
+		severity := textSeverity(matcher.pattern.Severity)
+		if best == nil || (severity == "high" && best.Severity != "high") {
+			best = &Violation{Reason: "text_match", LimitName: matcher.pattern.Pattern, Severity: severity}
+		}
+	}
+	if best == nil && spec.AnyValue && strings.TrimSpace(sample.Text) != "" {
+		best = &Violation{Reason: "value_present", LimitName: "anyValue", Severity: textSeverity(spec.AnyValueSeverity)}
+	}
+	if best == nil {
+		return Violation{}, false
+	}
+	return *best, true
+}
+
+func textSeverity(severity string) string {
+	if severity == "medium" {
+		return "medium"
+	}
+	return "high"
+}
+
+func truncateText(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxTextMatchObserved {
+		return text
+	}
+	return string(runes[:maxTextMatchObserved]) + "…"
+}
+
+func textMatchLimitExpr(spec TextMatchSpec) string {
+	parts := make([]string, 0, len(spec.Patterns)+1)
+	for _, pattern := range spec.Patterns {
+		kind := pattern.Kind
+		if kind == "" {
+			kind = textKindKeyword
+		}
+		parts = append(parts, fmt.Sprintf("%s:%q", kind, pattern.Pattern))
+	}
+	if spec.AnyValue {
+		parts = append(parts, "any value")
+	}
+	return "text_match " + strings.Join(parts, " or ")
+}
+
+func textMatchEvalWindow(spec TextMatchSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return defaultTextMatchEvalWindow
+}
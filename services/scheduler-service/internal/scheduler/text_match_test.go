@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+)
+
+func TestEvaluateTextMatchPatterns(t *testing.T) {
+	samples := []Sample{{Text: "chamber ok"}, {Text: "Warning: RF reflected power"}, {Text: "E104 ABORT pressure"}, {Text: "idle"}}
+	spec := TextMatchSpec{Patterns: []TextPattern{
+		{Pattern: "warning", Severity: "medium"},
+		{Pattern: `^E\d+ abort`, Kind: textKindRegex},
+	}}
+	result := EvaluateTextMatch(samples, spec)
+	if !result.Hit || len(result.Violations) != 2 || result.Severity != "high" {
+		t.Fatalf("expected two matches with high severity, got %+v", result)
+	}
+	if v := result.Violations[0]; *v.Index != 1 || v.Severity != "medium" || v.LimitName != "warning" || v.Reason != "text_match" {
+		t.Fatalf("unexpected keyword violation %+v", v)
+	}
+	if v := result.Violations[1]; *v.Index != 2 || v.Severity != "high" || v.Text != "E104 ABORT pressure" {
+		t.Fatalf("unexpected regex violation %+v", v)
+	}
+	if result.Metadata["rowsScanned"] != 4 || result.Metadata["matches"] != 2 {
+		t.Fatalf("unexpected metadata %+v", result.Metadata)
+	}
+	medium := EvaluateTextMatch(samples[:2], spec)
+	if !medium.Hit || medium.Severity != "medium" {
+		t.Fatalf("expected medium severity, got %+v", medium)
+	}
+	caseSensitive := EvaluateTextMatch(samples, TextMatchSpec{Patterns: []TextPattern{{Pattern: "warning", CaseSensitive: true}}})
+	if caseSensitive.Hit {
+		t.Fatalf("expected case sensitive keyword to miss, got %+v", caseSensitive)
+	}
+	if invalid := EvaluateTextMatch(samples, TextMatchSpec{Patterns: []TextPattern{{Pattern: "(", Kind: textKindRegex}}}); invalid.Status != statusInvalidConfig {
+		t.Fatalf("expected invalid regex to be rejected")
+	}
+}
+
+func TestEvaluateTextMatchAnyValue(t *testing.T) {
+	samples := []Sample{{Text: " "}, {Text: "tool swap"}}
+	result := EvaluateTextMatch(samples, TextMatchSpec{AnyValue: true, AnyValueSeverity: "medium"})
+	if !result.Hit || len(result.Violations) != 1 || result.Violations[0].Reason != "value_present" || result.Severity != "medium" {
+		t.Fatalf("expected one value present violation, got %+v", result)
+	}
+	if invalid := EvaluateTextMatch(samples, TextMatchSpec{}); invalid.Status != statusInvalidConfig {
+		t.Fatalf("expected invalid config without patterns")
+	}
+}
+
+func TestFetchSamplesTextColumn(t *testing.T) {
+	now := time.Now().UTC()
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{
+		{"warning_abort": "ABORT", "ts": now.Format(time.RFC3339)},
+		{"warning_abort": nil, "ts": now.Add(-time.Minute).Format(time.RFC3339)},
+		{"warning_abort": []byte("warn"), "ts": now.Add(-2 * time.Minute).Format(time.RFC3339)},
+	}}}
+	spec := RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "etchers_data", TimestampColumn: "ts"}}
+	param := ParameterSpec{ValueColumn: "warning_abort", Detector: DetectorSpec{Type: "text_match", TextMatch: &TextMatchSpec{AnyValue: true}}}
+	samples, err := fetchSamples(context.Background(), adapter, spec, param, nil, sampleWindow{Limit: 10}, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(samples) != 2 || samples[0].Text != "warn" || samples[1].Text != "ABORT" {
+		t.Fatalf("expected text samples with the null row skipped, got %+v", samples)
+	}
+	lastTS := samples[0].TS
+	if fresh := samplesAfter(samples, Watermark{LastTS: &lastTS}); len(fresh) != 1 || fresh[0].Text != "ABORT" {
+		t.Fatalf("expected only the newer row, got %+v", fresh)
+	}
+}
@@ -0,0 +1,348 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/storage"
+)
+
+// Watermark is the position of the last sample a scheduled rule evaluated.
+// Samples are compared by ordering column when both sides have one, and by
+// timestamp otherwise. Rows sharing LastTS are told apart by LastKey, the
+// source key column of the last one evaluated, when the source has one;
+// without it SeenAtTS counts the samples at LastTS already evaluated.
+type Watermark struct {
+	LastTS    *time.Time `json:"lastTs,omitempty"`
+	LastOrder *int64     `json:"lastOrder,omitempty"`
+	LastKey   *int64     `json:"lastKey,omitempty"`
+	SeenAtTS  int        `json:"seenAtTs,omitempty"`
+}
+
+// advancedBy returns the watermark after samples, which follow w in
+// ascending order.
+func (w Watermark) advancedBy(samples []Sample) Watermark {
+	for _, sample := range samples {
+		if !sample.TS.IsZero() {
+			if w.LastTS != nil && sample.TS.Equal(*w.LastTS) {
+				w.SeenAtTS = w.seenAtTS() + 1
+			} else {
+				w.LastTS, w.SeenAtTS = timePtr(sample.TS), 1
+			}
+		}
+		w.LastOrder = sample.Order
+		w.LastKey = sample.Key
+	}
+	return w
+}
+
+// seenAtTS treats a mark stored before ties were counted as one sample seen.
+func (w Watermark) seenAtTS() int {
+	return max(w.SeenAtTS, 1)
+}
+
+// keyed reports whether sample and w can be compared by key at LastTS.
+func (w Watermark) keyed(sample Sample) bool {
+	return w.LastKey != nil && sample.Key != nil
+}
+
+// covers reports whether sample is at or before the watermark.
+func (w Watermark) covers(sample Sample) bool {
+	if w.LastOrder != nil && sample.Order != nil {
+		return *sample.Order <= *w.LastOrder
+	}
+	if w.LastTS != nil && sample.TS.Equal(*w.LastTS) && w.keyed(sample) {
+		return *sample.Key <= *w.LastKey
+	}
+	return w.LastTS != nil && !sample.TS.After(*w.LastTS)
+}
+
+// split divides ascending samples into those at or before the watermark and
+// those after it. Of unkeyed samples at LastTS, the first SeenAtTS are
+// covered.
+func (w Watermark) split(samples []Sample) ([]Sample, []Sample) {
+	covered := make([]Sample, 0, len(samples))
+	fresh := make([]Sample, 0, len(samples))
+	seen := 0
+	for _, sample := range samples {
+		tied := (w.LastOrder == nil || sample.Order == nil) && !w.keyed(sample) && w.LastTS != nil && sample.TS.Equal(*w.LastTS)
+		if tied && seen >= w.seenAtTS() {
+			fresh = append(fresh, sample)
+			continue
+		}
+		if tied {
+			seen++
+		}
+		if w.covers(sample) {
+			covered = append(covered, sample)
+		} else {
+			fresh = append(fresh, sample)
+		}
+	}
+	return covered, fresh
+}
+
+// incrementalBatch holds the samples of one incremental evaluation: the
+// fresh samples after mark preceded by up to the requested context.
+type incrementalBatch struct {
+	samples []Sample
+	fresh   int
+	mark    *Watermark
+	hash    string
+}
+
+// isNew reports whether a sample of the batch, or a copy of one, is among
+// the fresh samples.
+func (b incrementalBatch) isNew(sample Sample) bool {
+	return sample.pos >= len(b.samples)-b.fresh
+}
+
+// next is the watermark once the batch is evaluated. Without a stored mark
+// the context counts as seen too.
+func (b incrementalBatch) next() Watermark {
+	if b.mark == nil {
+		return Watermark{}.advancedBy(b.samples)
+	}
+	return b.mark.advancedBy(b.samples[len(b.samples)-b.fresh:])
+}
+
+// fetchIncremental loads the samples a scheduled rule has not evaluated yet
+// together with contextSize samples before them. Without a stored watermark
+// only the latest sample counts as new. A nil batch means nothing arrived
+// since the last evaluation.
+func (r *Registry) fetchIncremental(ctx context.Context, run JobRun, param ParameterSpec, contextSize int, columns []string, subgroupColumn string) (*incrementalBatch, error) {
+	hash := watermarkConfigHash(run.spec.Source, param)
+	mark, err := r.loadWatermark(ctx, run, param, hash)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := r.fetchAfter(ctx, run, param, mark, contextSize, columns, subgroupColumn)
+	if batch != nil {
+		batch.hash = hash
+	}
+	return batch, err
+}
+
+// fetchAfter pages forward from mark in ascending order, at most
+// MaxSampleRows new samples per call, so a backlog is worked through over
+// several polls. Without a mark it returns the latest sample as new.
+func (r *Registry) fetchAfter(ctx context.Context, run JobRun, param ParameterSpec, mark *Watermark, contextSize int, columns []string, subgroupColumn string) (*incrementalBatch, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
+	defer cancel()
+	if mark == nil {
+		samples, err := fetchSamples(queryCtx, run.adapter, run.spec, param, columns, sampleWindow{Since: lookbackSince(), Limit: clampLimit(contextSize+1, r.limits.MaxSampleRows)}, subgroupColumn)
+		if err != nil || len(samples) == 0 {
+			return nil, err
+		}
+		return newIncrementalBatch(samples, 1, nil), nil
+	}
+	fresh, err := fetchSamples(queryCtx, run.adapter, run.spec, param, columns, sampleWindow{Since: lookbackSince(), Limit: r.limits.MaxSampleRows, After: mark, Ascending: true}, subgroupColumn)
+	if err != nil || len(fresh) == 0 {
+		return nil, err
+	}
+	samples := fresh
+	if contextSize > 0 {
+		before, err := fetchCovered(queryCtx, run, param, *mark, sampleWindow{Since: lookbackSince(), Limit: clampLimit(contextSize, r.limits.MaxSampleRows)}, columns, subgroupColumn)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(before, fresh...)
+	}
+	return newIncrementalBatch(samples, len(fresh), mark), nil
+}
+
+// fetchCovered fetches the latest samples of window that mark has already
+// evaluated.
+func fetchCovered(ctx context.Context, run JobRun, param ParameterSpec, mark Watermark, window sampleWindow, columns []string, subgroupColumn string) ([]Sample, error) {
+	if run.spec.Source.OrderingColumn != "" && mark.LastOrder != nil {
+		window.MaxOrder = mark.LastOrder
+	} else {
+		window.Until = mark.LastTS
+	}
+	before, err := fetchSamples(ctx, run.adapter, run.spec, param, columns, window, subgroupColumn)
+	if err != nil {
+		return nil, err
+	}
+	covered, _ := mark.split(before)
+	return covered, nil
+}
+
+// fetchIncrementalSpan is fetchIncremental for detectors whose context is a
+// time span before the first new sample rather than a number of samples.
+func (r *Registry) fetchIncrementalSpan(ctx context.Context, run JobRun, param ParameterSpec, span time.Duration, columns []string) (*incrementalBatch, error) {
+	batch, err := r.fetchIncremental(ctx, run, param, 0, columns, "")
+	if err != nil || batch == nil {
+		return batch, err
+	}
+	first := batch.samples[len(batch.samples)-batch.fresh]
+	window := sampleWindow{Since: first.TS.Add(-span), Limit: r.limits.MaxSampleRows}
+	queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
+	defer cancel()
+	var samples []Sample
+	if batch.mark == nil {
+		window.Until = &first.TS
+		samples, err = fetchSamples(queryCtx, run.adapter, run.spec, param, columns, window, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(samples) == 0 {
+			samples = batch.samples
+		}
+	} else {
+		before, err := fetchCovered(queryCtx, run, param, *batch.mark, window, columns, "")
+		if err != nil {
+			return nil, err
+		}
+		samples = append(before, batch.samples...)
+	}
+	spanned := newIncrementalBatch(samples, batch.fresh, batch.mark)
+	spanned.hash = batch.hash
+	return spanned, nil
+}
+
+// newIncrementalBatch marks the last fresh samples as new.
+func newIncrementalBatch(samples []Sample, fresh int, mark *Watermark) *incrementalBatch {
+	for i := range samples {
+		samples[i].pos = i
+	}
+	return &incrementalBatch{samples: samples, fresh: min(fresh, len(samples)), mark: mark}
+}
+
+// windowIncremental fetches the samples of a detector whose limits come from
+// its own baseline window. A rolling lastN window is fetched incrementally
+// and size is its length; fixed time and run ranges are fetched whole, with
+// size 0, and the samples after the watermark marked new.
+func (r *Registry) windowIncremental(ctx context.Context, run JobRun, param ParameterSpec, baseline BaselineSpec, columns []string, subgroupColumn string) (*incrementalBatch, int, *time.Time, *time.Time, error) {
+	if baseline.TimeRange == nil && baseline.RunRange == nil {
+		size := defaultBaselineLastN
+		if baseline.LastN != nil {
+			size = *baseline.LastN
+		}
+		if size <= 0 {
+			return nil, 0, nil, nil, errors.New("lastN must be > 0")
+		}
+		size = clampLimit(size, r.limits.MaxSampleRows)
+		batch, err := r.fetchIncremental(ctx, run, param, size-1, columns, subgroupColumn)
+		return batch, size, nil, nil, err
+	}
+	window, start, end, err := buildBaselineWindow(time.Now().UTC(), baseline, r.limits.MaxSampleRows)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	hash := watermarkConfigHash(run.spec.Source, param)
+	mark, err := r.loadWatermark(ctx, run, param, hash)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, r.limits.MaxQueryDuration)
+	defer cancel()
+	samples, err := fetchSamples(queryCtx, run.adapter, run.spec, param, columns, window, subgroupColumn)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	samples = filterSamplesByRange(samples, start, end)
+	fresh := 1
+	if mark != nil {
+		fresh = len(samplesAfter(samples, *mark))
+	}
+	if len(samples) == 0 || fresh == 0 {
+		return nil, 0, start, end, nil
+	}
+	batch := newIncrementalBatch(samples, fresh, mark)
+	batch.hash = hash
+	return batch, 0, start, end, nil
+}
+
+// stateWatermark is the position persisted with a detector's own state, or
+// nil before its first evaluation.
+func stateWatermark(mark Watermark) *Watermark {
+	if mark.LastTS == nil && mark.LastOrder == nil {
+		return nil
+	}
+	return &mark
+}
+
+func (r *Registry) loadWatermark(ctx context.Context, run JobRun, param ParameterSpec, hash string) (*Watermark, error) {
+	rec, err := r.repo.GetWatermark(ctx, run.ruleID, param.ParameterName, param.Detector.Type)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rec.ConfigHash != hash || (rec.LastTS == nil && rec.LastOrder == nil) {
+		return nil, nil
+	}
+	return &Watermark{LastTS: rec.LastTS, LastOrder: rec.LastOrder, LastKey: rec.LastKey, SeenAtTS: rec.SeenAtTS}, nil
+}
+
+// advanceWatermark moves the watermark past the evaluated batch. It is only
+// called for results that completed, so points that failed evaluation or
+// lacked context are retried on the next poll.
+func (r *Registry) advanceWatermark(ctx context.Context, run JobRun, param ParameterSpec, batch *incrementalBatch, result DetectorResult) error {
+	if status := runStatus(result, nil); status != statusOK && status != statusViolation {
+		return nil
+	}
+	mark := batch.next()
+	rec := storage.WatermarkRecord{ParameterName: param.ParameterName, DetectorType: param.Detector.Type, ConfigHash: batch.hash, LastTS: mark.LastTS, LastOrder: mark.LastOrder, LastKey: mark.LastKey, SeenAtTS: mark.SeenAtTS}
+	if run.stepper {
+		rec.UIRuleID = run.ruleID
+	} else {
+		rec.RuleID = run.ruleID
+	}
+	return r.repo.SaveWatermark(ctx, rec)
+}
+
+// evaluateNewPoints evaluates the history ending at each of the last fresh
+// samples. Violations from every hit are kept, and the result reflects the
+// most recent hit, or the latest point when nothing hit.
+func evaluateNewPoints(samples []Sample, fresh int, evaluate func([]Sample) DetectorResult) DetectorResult {
+	results := make([]DetectorResult, 0, fresh)
+	for i := len(samples) - fresh; i < len(samples); i++ {
+		if i >= 0 {
+			results = append(results, evaluate(samples[:i+1]))
+		}
+	}
+	return mergeNewResults(results, fresh)
+}
+
+// mergeNewResults folds per-point results in sample order.
+func mergeNewResults(results []DetectorResult, fresh int) DetectorResult {
+	merged := insufficientData("no new samples")
+	violations := []Violation{}
+	hit := false
+	for _, result := range results {
+		if result.Hit {
+			violations = append(violations, result.Violations...)
+			merged, hit = result, true
+		} else if !hit {
+			merged = result
+		}
+	}
+	if hit {
+		merged.Violations = violations
+	}
+	if merged.Metadata == nil {
+		merged.Metadata = map[string]any{}
+	}
+	merged.Metadata["newSamples"] = fresh
+	return merged
+}
+
+// runOrderMarkType keys the rule-level run order mark of an ordered source in
+// rule_watermarks, next to the per-parameter watermarks.
+const runOrderMarkType = "run_order"
+
+// runOrderConfigHash changes whenever the rule is edited, so an edited rule
+// evaluates the current run again.
+func runOrderConfigHash(spec RuleSpec) string {
+	return configHash(spec)
+}
+
+func watermarkConfigHash(source SourceSpec, param ParameterSpec) string {
+	return configHash(struct {
+		Source SourceSpec    `json:"source"`
+		Param  ParameterSpec `json:"param"`
+	}{source, param})
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"predixaai-backend/services/scheduler-service/internal/mcp"
+	"predixaai-backend/services/scheduler-service/internal/security"
+)
+
+func TestFetchSamplesAfterWatermark(t *testing.T) {
+	now := time.Now().UTC()
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{
+		{"value": 3.0, "ts": now.Format(time.RFC3339)},
+		{"value": 2.0, "ts": now.Add(-time.Minute).Format(time.RFC3339)},
+		{"value": 1.0, "ts": now.Add(-2 * time.Minute).Format(time.RFC3339)},
+	}}}
+	spec := RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "metrics", TimestampColumn: "ts"}}
+	param := ParameterSpec{ValueColumn: "value", Detector: DetectorSpec{Type: "trend"}}
+	mark := Watermark{LastTS: timePtr(now.Add(-time.Minute).Truncate(time.Second))}
+	samples, err := fetchSamples(context.Background(), adapter, spec, param, nil, sampleWindow{Limit: 10, After: &mark}, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(samples) != 1 || samples[0].Value != 3 {
+		t.Fatalf("expected only the sample after the watermark, got %+v", samples)
+	}
+}
+
+func TestWatermarkCoversPrefersOrder(t *testing.T) {
+	now := time.Now().UTC()
+	order := int64(10)
+	mark := Watermark{LastTS: timePtr(now), LastOrder: &order}
+	later := int64(11)
+	if mark.covers(Sample{TS: now.Add(-time.Hour), Order: &later}) {
+		t.Fatalf("expected a newer order to be new despite an older timestamp")
+	}
+	if !mark.covers(Sample{TS: now.Add(-time.Hour)}) {
+		t.Fatalf("expected an older unordered sample to be covered")
+	}
+}
+
+func TestEvaluateNewPointsKeepsEveryHit(t *testing.T) {
+	samples := []Sample{{Value: 1}, {Value: 9}, {Value: 2}, {Value: 8}, {Value: 3}}
+	evaluated := 0
+	result := evaluateNewPoints(samples, 3, func(history []Sample) DetectorResult {
+		evaluated++
+		last := history[len(history)-1]
+		if last.Value > 5 {
+			idx := len(history) - 1
+			return DetectorResult{Hit: true, Status: statusViolation, Violations: []Violation{{Index: &idx, Value: last.Value}}}
+		}
+		return DetectorResult{Status: statusOK}
+	})
+	if evaluated != 3 {
+		t.Fatalf("expected the three new points to be evaluated, got %d", evaluated)
+	}
+	if !result.Hit || len(result.Violations) != 1 || *result.Violations[0].Index != 3 {
+		t.Fatalf("expected the single new hit, got %+v", result)
+	}
+	if result.Metadata["newSamples"] != 3 {
+		t.Fatalf("expected newSamples metadata, got %+v", result.Metadata)
+	}
+	if none := mergeNewResults(nil, 0); none.Status != statusInsufficient {
+		t.Fatalf("expected insufficient data without new points, got %+v", none)
+	}
+}
+
+func TestBacktestNewPointsRetriesIncompleteEvaluations(t *testing.T) {
+	samples := []Sample{{Value: 1}, {Value: 2}, {Value: 3}}
+	seen := 1
+	backtestNewPoints(&seen, samples[:2], func([]Sample) DetectorResult { return insufficientData("not enough samples") })
+	if seen != 1 {
+		t.Fatalf("expected seen to stay put after an incomplete evaluation, got %d", seen)
+	}
+	calls := 0
+	backtestNewPoints(&seen, samples, func([]Sample) DetectorResult {
+		calls++
+		return DetectorResult{Status: statusOK}
+	})
+	if calls != 2 || seen != 3 {
+		t.Fatalf("expected both pending points to be evaluated, got calls=%d seen=%d", calls, seen)
+	}
+}
+
+func TestFetchAfterPagesForwardFromWatermark(t *testing.T) {
+	rows := []mcp.Row{}
+	for order := 9; order <= 13; order++ {
+		rows = append(rows, mcp.Row{"value": float64(order), "run": order})
+	}
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: rows}}
+	reg := &Registry{limits: security.Limits{MaxSampleRows: 3, MaxQueryDuration: time.Second}}
+	run := JobRun{adapter: adapter, spec: RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "metrics", OrderingColumn: "run"}}}
+	param := ParameterSpec{ValueColumn: "value", Detector: DetectorSpec{Type: "trend"}}
+	last := int64(10)
+	batch, err := reg.fetchAfter(context.Background(), run, param, &Watermark{LastOrder: &last}, 0, nil, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if batch == nil || batch.fresh != 3 || batch.samples[0].Value != 11 || batch.samples[2].Value != 13 {
+		t.Fatalf("expected the three samples after the watermark in order, got %+v", batch)
+	}
+	req := adapter.Requests[0]
+	if !req.Ascending || req.Limit != 3 || req.MinOrder == nil || *req.MinOrder != 10 {
+		t.Fatalf("expected an ascending page from the watermark, got %+v", req)
+	}
+}
+
+func TestWatermarkSplitsTimestampTies(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{{TS: t0, Value: 1}, {TS: t0.Add(time.Second), Value: 2}, {TS: t0.Add(time.Second), Value: 3}, {TS: t0.Add(time.Second), Value: 4}, {TS: t0.Add(2 * time.Second), Value: 5}}
+	mark := Watermark{}.advancedBy(samples[:3])
+	if mark.SeenAtTS != 2 || !mark.LastTS.Equal(t0.Add(time.Second)) {
+		t.Fatalf("expected two samples seen at the last timestamp, got %+v", mark)
+	}
+	covered, fresh := mark.split(samples)
+	if len(covered) != 3 || len(fresh) != 2 || fresh[0].Value != 4 || fresh[1].Value != 5 {
+		t.Fatalf("expected the unseen tied sample to stay new, got covered=%+v fresh=%+v", covered, fresh)
+	}
+	if legacy := samplesAfter(samples, Watermark{LastTS: mark.LastTS}); len(legacy) != 3 {
+		t.Fatalf("expected a mark without a count to cover one tied sample, got %+v", legacy)
+	}
+	next := mark.advancedBy(fresh)
+	if next.SeenAtTS != 1 || !next.LastTS.Equal(t0.Add(2*time.Second)) {
+		t.Fatalf("expected the count to restart at a new timestamp, got %+v", next)
+	}
+}
+
+func TestFetchAfterKeepsLateRowAtWatermarkTimestamp(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := func(key int, value float64, ts time.Time) mcp.Row {
+		return mcp.Row{"id": key, "value": value, "ts": ts.Format(time.RFC3339Nano)}
+	}
+	spec := RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "metrics", TimestampColumn: "ts", KeyColumn: "id"}}
+	param := ParameterSpec{ValueColumn: "value", Detector: DetectorSpec{Type: "trend"}}
+	reg := &Registry{limits: security.Limits{MaxSampleRows: 10, MaxQueryDuration: time.Second}}
+
+	first := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{row(1, 1, t0), row(2, 2, t0)}}}
+	batch, err := reg.fetchAfter(context.Background(), JobRun{adapter: first, spec: spec}, param, &Watermark{LastTS: timePtr(t0.Add(-time.Second))}, 0, nil, "")
+	if err != nil || batch == nil || batch.fresh != 2 {
+		t.Fatalf("expected both rows on the first poll, got %+v, %v", batch, err)
+	}
+	mark := batch.next()
+	if !mark.LastTS.Equal(t0) || mark.LastKey == nil || *mark.LastKey != 2 {
+		t.Fatalf("expected the mark at the last key of the timestamp, got %+v", mark)
+	}
+
+	// A row with the same timestamp lands between polls. The source returns
+	// the tied rows in any order; the key decides which are new.
+	second := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: []mcp.Row{row(3, 3, t0), row(2, 2, t0), row(1, 1, t0)}}}
+	batch, err = reg.fetchAfter(context.Background(), JobRun{adapter: second, spec: spec}, param, &mark, 0, nil, "")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if batch == nil || batch.fresh != 1 || batch.samples[0].Value != 3 {
+		t.Fatalf("expected only the late row to be new, got %+v", batch)
+	}
+	req := second.Requests[0]
+	if req.KeyColumn != "id" || req.AfterKey == nil || *req.AfterKey != 2 || req.AfterTS != t0.Format(time.RFC3339Nano) {
+		t.Fatalf("expected a page after the timestamp and key of the mark, got %+v", req)
+	}
+	if next := batch.next(); *next.LastKey != 3 {
+		t.Fatalf("expected the mark to move to the late row, got %+v", next)
+	}
+}
+
+func TestFetchAfterFailsWhenTiedRowsFillThePage(t *testing.T) {
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []mcp.Row{}
+	for i := 0; i < 3; i++ {
+		rows = append(rows, mcp.Row{"value": float64(i), "ts": t0.Format(time.RFC3339)})
+	}
+	adapter := &mcp.MockAdapter{RecentRows: mcp.FetchRecentRowsResult{Rows: rows}}
+	reg := &Registry{limits: security.Limits{MaxSampleRows: 3, MaxQueryDuration: time.Second}}
+	run := JobRun{adapter: adapter, spec: RuleSpec{ConnectionRef: "conn", Source: SourceSpec{Table: "metrics", TimestampColumn: "ts"}}}
+	param := ParameterSpec{ValueColumn: "value", Detector: DetectorSpec{Type: "trend"}}
+	batch, err := reg.fetchAfter(context.Background(), run, param, &Watermark{LastTS: timePtr(t0), SeenAtTS: 3}, 0, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "keyColumn") {
+		t.Fatalf("expected an explicit error instead of a stalled watermark, got %+v, %v", batch, err)
+	}
+}
+
+func TestDetectorStateKeepsWatermarkFields(t *testing.T) {
+	var state TextMatchState
+	if err := json.Unmarshal([]byte(`{"configHash":"h","lastTs":"2026-01-01T00:00:00Z","lastOrder":7}`), &state); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if state.LastTS == nil || state.LastOrder == nil || *state.LastOrder != 7 || state.seenAtTS() != 1 {
+		t.Fatalf("expected stored positions to load into the watermark, got %+v", state)
+	}
+}
+
+func TestRunOrderConfigHashChangesOnEdit(t *testing.T) {
+	spec := RuleSpec{Source: SourceSpec{Table: "etchers_data", OrderingColumn: "run_order"}, PollIntervalSeconds: 60}
+	hash := runOrderConfigHash(spec)
+	if runOrderConfigHash(spec) != hash {
+		t.Fatalf("expected a stable hash")
+	}
+	spec.Parameters = []ParameterSpec{{ParameterName: "rf_power", Detector: DetectorSpec{Type: "missing_data"}}}
+	if runOrderConfigHash(spec) == hash {
+		t.Fatalf("expected an edited rule to evaluate the current run again")
+	}
+}
@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	dispersionRange  = "range"
+	dispersionStdDev = "stddev"
+)
+
+func EvaluateXbarChart(baselineGroups [][]Sample, evalGroups [][]Sample, spec XbarChartSpec, dispersion string, latestOnly bool) DetectorResult {
+	minGroups := spec.MinBaselineSubgroups
+	if minGroups == 0 {
+		minGroups = defaultBaselineSubgroups
+	}
+	if len(baselineGroups) < minGroups {
+		return insufficientData("baseline subgroups too small")
+	}
+	if len(evalGroups) == 0 {
+		return insufficientData("no valid subgroups")
+	}
+	rangeConsts, ok := rangeChartConstants[spec.SubgroupSize]
+	if !ok {
+		return invalidConfig("unsupported subgroup size")
+	}
+	consts := xbarChartConstants[spec.SubgroupSize]
+	means := make([]float64, 0, len(baselineGroups))
+	spreads := make([]float64, 0, len(baselineGroups))
+	for _, group := range baselineGroups {
+		means = append(means, Mean(extractValues(group)))
+		spreads = append(spreads, subgroupDispersion(group, dispersion))
+	}
+	center, avgSpread := Mean(means), Mean(spreads)
+	label, key := "R", "r"
+	width, uclSpread, lclSpread := consts.A2*avgSpread, rangeConsts.D4*avgSpread, rangeConsts.D3*avgSpread
+	if dispersion == dispersionStdDev {
+		label, key = "S", "s"
+		width, uclSpread, lclSpread = consts.A3*avgSpread, consts.B4*avgSpread, consts.B3*avgSpread
+	}
+	uclX, lclX := center+width, center-width
+	lastGroup := evalGroups[len(evalGroups)-1]
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(Mean(extractValues(lastGroup))),
+		LimitExpr: fmt.Sprintf("xbar_%s n=%d", key, spec.SubgroupSize),
+		Metadata: map[string]any{
+			"xbarbar":               center,
+			"ucl_x":                 uclX,
+			"lcl_x":                 lclX,
+			"subgroupSize":          spec.SubgroupSize,
+			key + "bar":             avgSpread,
+			"ucl_" + key:            uclSpread,
+			"lcl_" + key:            lclSpread,
+			"baselineSubgroupCount": len(baselineGroups),
+		},
+	}
+	series := make([]map[string]any, 0, len(evalGroups))
+	charts := map[string]bool{}
+	for i, group := range evalGroups {
+		mean := Mean(extractValues(group))
+		spread := subgroupDispersion(group, dispersion)
+		last := group[len(group)-1]
+		point := map[string]any{"index": i, "mean": mean, key: spread}
+		if !last.TS.IsZero() {
+			point["timestamp"] = last.TS.UTC().Format(time.RFC3339)
+		}
+		if last.Order != nil {
+			point["order"] = *last.Order
+		}
+		series = append(series, point)
+		if latestOnly && i != len(evalGroups)-1 {
+			continue
+		}
+		idx := i
+		if mean > uclX {
+			addViolation(&result, Violation{Timestamp: timePtr(last.TS), Index: &idx, Order: last.Order, Value: mean, Reason: "above_ucl_x", LimitName: "Xbar", LimitValue: uclX, Delta: mean - uclX})
+			charts["Xbar"] = true
+		}
+		if mean < lclX {
+			addViolation(&result, Violation{Timestamp: timePtr(last.TS), Index: &idx, Order: last.Order, Value: mean, Reason: "below_lcl_x", LimitName: "Xbar", LimitValue: lclX, Delta: mean - lclX})
+			charts["Xbar"] = true
+		}
+		if spread > uclSpread {
+			addViolation(&result, Violation{Timestamp: timePtr(last.TS), Index: &idx, Order: last.Order, Value: spread, Reason: "above_ucl_" + key, LimitName: label, LimitValue: uclSpread, Delta: spread - uclSpread})
+			charts[label] = true
+		}
+		if spread < lclSpread {
+			addViolation(&result, Violation{Timestamp: timePtr(last.TS), Index: &idx, Order: last.Order, Value: spread, Reason: "below_lcl_" + key, LimitName: label, LimitValue: lclSpread, Delta: spread - lclSpread})
+			charts[label] = true
+		}
+	}
+	result.Metadata["series"] = series
+	finishControlChart(&result, charts, "Xbar", label)
+	return result
+}
+
+func EvaluateIMR(baseline []Sample, eval []Sample, spec IMRSpec, latestOnly bool) DetectorResult {
+	minBaseline := spec.MinBaselineN
+	if minBaseline == 0 {
+		minBaseline = defaultBaselineMinN
+	}
+	if len(baseline) < minBaseline || len(baseline) < 2 {
+		return insufficientData("baseline too small")
+	}
+	if len(eval) == 0 {
+		return insufficientData("not enough samples")
+	}
+	center := Mean(extractValues(baseline))
+	ranges := make([]float64, 0, len(baseline)-1)
+	for i := 1; i < len(baseline); i++ {
+		ranges = append(ranges, math.Abs(baseline[i].Value-baseline[i-1].Value))
+	}
+	mrbar := Mean(ranges)
+	sigma := mrbar / imrD2
+	if sigma <= 0 {
+		return insufficientData("baseline moving range is zero")
+	}
+	uclI, lclI, uclMR := center+3*sigma, center-3*sigma, imrD4*mrbar
+	result := DetectorResult{
+		Hit:       false,
+		Status:    statusOK,
+		Severity:  "high",
+		Observed:  fmt.Sprint(eval[len(eval)-1].Value),
+		LimitExpr: "i_mr",
+		Metadata: map[string]any{
+			"mean":   center,
+			"mrbar":  mrbar,
+			"sigma":  sigma,
+			"ucl_i":  uclI,
+			"lcl_i":  lclI,
+			"ucl_mr": uclMR,
+			"lcl_mr": 0.0,
+		},
+	}
+	series := make([]map[string]any, 0, len(eval))
+	charts := map[string]bool{}
+	for i, sample := range eval {
+		point := map[string]any{"index": i, "value": sample.Value}
+		mr, hasMR := 0.0, i > 0
+		if hasMR {
+			mr = math.Abs(sample.Value - eval[i-1].Value)
+			point["mr"] = mr
+		}
+		if !sample.TS.IsZero() {
+			point["timestamp"] = sample.TS.UTC().Format(time.RFC3339)
+		}
+		if sample.Order != nil {
+			point["order"] = *sample.Order
+		}
+		series = append(series, point)
+		if latestOnly && i != len(eval)-1 {
+			continue
+		}
+		idx := i
+		if sample.Value > uclI {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: sample.Value, Reason: "above_ucl_i", LimitName: "I", LimitValue: uclI, Delta: sample.Value - uclI})
+			charts["I"] = true
+		}
+		if sample.Value < lclI {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: sample.Value, Reason: "below_lcl_i", LimitName: "I", LimitValue: lclI, Delta: sample.Value - lclI})
+			charts["I"] = true
+		}
+		if hasMR && mr > uclMR {
+			addViolation(&result, Violation{Timestamp: timePtr(sample.TS), Index: &idx, Order: sample.Order, Value: mr, Reason: "above_ucl_mr", LimitName: "MR", LimitValue: uclMR, Delta: mr - uclMR})
+			charts["MR"] = true
+		}
+	}
+	result.Metadata["series"] = series
+	finishControlChart(&result, charts, "I", "MR")
+	return result
+}
+
+func finishControlChart(result *DetectorResult, charts map[string]bool, location, dispersion string) {
+	if len(result.Violations) == 0 {
+		return
+	}
+	violated := []string{}
+	for _, chart := range []string{location, dispersion} {
+		if charts[chart] {
+			violated = append(violated, chart)
+		}
+	}
+	result.Hit = true
+	result.Status = statusViolation
+	result.Metadata["violatedCharts"] = violated
+	result.Metadata["limitBreached"] = result.Violations[len(result.Violations)-1].LimitName
+}
+
+func xbarDispersion(detectorType string) string {
+	if detectorType == "xbar_s" {
+		return dispersionStdDev
+	}
+	return dispersionRange
+}
+
+func subgroupDispersion(group []Sample, dispersion string) float64 {
+	if dispersion == dispersionStdDev {
+		return StdDev(extractValues(group), false)
+	}
+	return subgroupRange(group)
+}
+
+func xbarEvalWindow(spec XbarChartSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return 1
+}
+
+func imrEvalWindow(spec IMRSpec) int {
+	if spec.EvalWindow > 0 {
+		return spec.EvalWindow
+	}
+	return 1
+}
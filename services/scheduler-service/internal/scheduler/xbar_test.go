@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+)
+
+func xbarBaselineGroups() [][]Sample {
+	groups := [][]Sample{}
+	for i := 0; i < 10; i++ {
+		groups = append(groups, runRulesSamples(9, 10, 11, 10, 10))
+	}
+	return groups
+}
+
+func TestEvaluateXbarRLocationAndDispersion(t *testing.T) {
+	eval := [][]Sample{runRulesSamples(12, 12, 12, 12, 12), runRulesSamples(5, 15, 10, 10, 10)}
+	result := EvaluateXbarChart(xbarBaselineGroups(), eval, XbarChartSpec{SubgroupSize: 5}, dispersionRange, false)
+	if !result.Hit || len(result.Violations) != 2 {
+		t.Fatalf("expected location and dispersion violations, got %+v", result.Violations)
+	}
+	if result.Violations[0].Reason != "above_ucl_x" || result.Violations[1].Reason != "above_ucl_r" {
+		t.Fatalf("unexpected reasons %+v", result.Violations)
+	}
+	if !reflect.DeepEqual(result.Metadata["violatedCharts"], []string{"Xbar", "R"}) {
+		t.Fatalf("unexpected violated charts %v", result.Metadata["violatedCharts"])
+	}
+	if result.Metadata["rbar"] != 2.0 {
+		t.Fatalf("expected rbar 2, got %v", result.Metadata["rbar"])
+	}
+}
+
+func TestEvaluateXbarSUsesStdDev(t *testing.T) {
+	result := EvaluateXbarChart(xbarBaselineGroups(), [][]Sample{runRulesSamples(11, 11, 11, 11, 11)}, XbarChartSpec{SubgroupSize: 5}, dispersionStdDev, true)
+	if _, ok := result.Metadata["sbar"]; !ok || result.LimitExpr != "xbar_s n=5" {
+		t.Fatalf("expected s chart metadata, got %+v", result.Metadata)
+	}
+	if result.Hit {
+		t.Fatalf("expected mean 11 inside X̿±A3·S̄, got %+v", result.Violations)
+	}
+	if invalid := EvaluateXbarChart(xbarBaselineGroups(), xbarBaselineGroups(), XbarChartSpec{SubgroupSize: 12}, dispersionStdDev, true); invalid.Status != statusInvalidConfig {
+		t.Fatalf("expected unsupported subgroup size")
+	}
+}
+
+func TestEvaluateIMR(t *testing.T) {
+	values := []float64{}
+	for i := 0; i < 20; i++ {
+		values = append(values, 9+float64(i%2)*2)
+	}
+	result := EvaluateIMR(runRulesSamples(values...), runRulesSamples(10, 17), IMRSpec{}, true)
+	if !result.Hit || !reflect.DeepEqual(result.Metadata["violatedCharts"], []string{"I", "MR"}) {
+		t.Fatalf("expected individual and moving range violations, got %+v", result)
+	}
+	if result.Metadata["mrbar"] != 2.0 {
+		t.Fatalf("expected mrbar 2, got %v", result.Metadata["mrbar"])
+	}
+}
@@ -7,6 +7,7 @@ type RuleRecord struct {
 	ConnectionRef string
 	RuleJSON      []byte
 	Enabled       bool
+	Shadow        bool
 	Status        string
 	LastError     []byte
 	LastValidated *time.Time
@@ -14,6 +15,7 @@ type RuleRecord struct {
 
 type AlertRecord struct {
 	RuleID         string
+	UIRuleID       string
 	TSUTC          time.Time
 	ParameterName  string
 	ObservedValue  string
@@ -26,4 +28,88 @@ type AlertRecord struct {
 	Hit            bool
 	Treated        bool
 	Metadata       []byte
+	Shadow         bool
+}
+
+// ResolvedAlert identifies an alert closed by RecordAlertOK.
+type ResolvedAlert struct {
+	ID            int64
+	RuleID        string
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	Severity      string
+	ResolvedAt    time.Time
+}
+
+type RuleRunRecord struct {
+	RuleID        string
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	StartedAt     time.Time
+	FinishedAt    time.Time
+	DurationMS    int64
+	Status        string
+	Error         string
+	SampleCount   int
+}
+
+type DetectorStateRecord struct {
+	RuleID        string
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	State         []byte
+}
+
+type WatermarkRecord struct {
+	RuleID        string
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	ConfigHash    string
+	LastTS        *time.Time
+	LastOrder     *int64
+	LastKey       *int64
+	SeenAtTS      int
+}
+
+type BaselineRecord struct {
+	ID            int64
+	RuleID        string
+	UIRuleID      string
+	ParameterName string
+	DetectorType  string
+	Source        string
+	Mu            *float64
+	Sigma         *float64
+	RBar          *float64
+	Median        *float64
+	MAD           *float64
+	N             int
+	SubgroupCount int
+	WindowStart   *time.Time
+	WindowEnd     *time.Time
+	OrderStart    *int64
+	OrderEnd      *int64
+	DataHash      string
+}
+
+type StepperRuleRecord struct {
+	ID                string
+	UnitID            string
+	Name              string
+	RuleType          string
+	ParameterID       string
+	Config            []byte
+	Enabled           bool
+	Shadow            bool
+	Status            string
+	ConnectionRef     string
+	Table             string
+	TimestampColumn   string
+	OrderingColumn    string
+	RowFilter         []byte
+	DerivedParameters []byte
 }
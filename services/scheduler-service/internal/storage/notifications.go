@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	DeliveryStatusPending   = "PENDING"
+	DeliveryStatusDelivered = "DELIVERED"
+	DeliveryStatusFailed    = "FAILED"
+)
+
+type NotificationChannelRecord struct {
+	ID      string
+	Name    string
+	Type    string
+	Config  []byte
+	Enabled bool
+}
+
+type NotificationDeliveryRecord struct {
+	ID            int64
+	ChannelID     string
+	AlertID       *int64
+	Event         string
+	Payload       []byte
+	Attempts      int
+	NextAttemptAt time.Time
+	Channel       NotificationChannelRecord
+}
+
+type NotificationAttempt struct {
+	DeliveryID    int64
+	Status        string
+	Attempts      int
+	ResponseCode  *int
+	Error         string
+	NextAttemptAt time.Time
+}
+
+// MatchNotificationChannels returns the enabled channels with a subscription
+// covering the rule, its machine unit and the alert severity. Empty
+// subscription fields match everything; a medium minimum also admits high.
+func (r *Repository) MatchNotificationChannels(ctx context.Context, ruleID, severity string) ([]NotificationChannelRecord, error) {
+	rows, err := r.Store.Pool.Query(ctx, `
+		SELECT DISTINCT c.id, c.name, c.type, c.config, c.enabled
+		FROM notification_subscriptions s
+		JOIN notification_channels c ON c.id = s.channel_id
+		WHERE c.enabled
+			AND (s.rule_id IS NULL OR s.rule_id = $1::uuid)
+			AND (s.unit_id IS NULL
+				OR EXISTS (SELECT 1 FROM ui_rules u WHERE u.id = $1::uuid AND u.unit_id = s.unit_id)
+				OR EXISTS (SELECT 1 FROM machine_units m WHERE m.unit_id = s.unit_id AND m.rule_ids ? $3))
+			AND (s.min_severity IS NULL OR s.min_severity = 'medium' OR $2 = 'high')`, ruleID, severity, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []NotificationChannelRecord{}
+	for rows.Next() {
+		var rec NotificationChannelRecord
+		if err := rows.Scan(&rec.ID, &rec.Name, &rec.Type, &rec.Config, &rec.Enabled); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, rows.Err()
+}
+
+// CreateNotificationDelivery queues a delivery, due immediately unless
+// NextAttemptAt is set.
+func (r *Repository) CreateNotificationDelivery(ctx context.Context, rec NotificationDeliveryRecord) error {
+	var due *time.Time
+	if !rec.NextAttemptAt.IsZero() {
+		due = &rec.NextAttemptAt
+	}
+	_, err := r.Store.Pool.Exec(ctx, `
+		INSERT INTO notification_deliveries (channel_id, alert_id, event, payload, status, attempts, next_attempt_at, created_at)
+		VALUES ($1,$2,$3,$4,'PENDING',0,COALESCE($5,now()),now())`,
+		rec.ChannelID, rec.AlertID, rec.Event, rec.Payload, due)
+	return err
+}
+
+// GetRuleUnit returns the machine unit a stepper rule belongs to, or the unit
+// listing a legacy rule. A legacy rule listed by several units has no single
+// unit and yields ErrNotFound rather than an arbitrary one of them.
+func (r *Repository) GetRuleUnit(ctx context.Context, ruleID string) (string, string, error) {
+	var unitID, unitName string
+	err := r.Store.Pool.QueryRow(ctx, `
+		SELECT m.unit_id, m.unit_name FROM ui_rules u
+		JOIN machine_units m ON m.unit_id = u.unit_id
+		WHERE u.id = $1::uuid`, ruleID).Scan(&unitID, &unitName)
+	if err == nil {
+		return unitID, unitName, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", "", err
+	}
+	rows, err := r.Store.Pool.Query(ctx, `
+		SELECT unit_id, unit_name FROM machine_units
+		WHERE rule_ids ? $1
+		ORDER BY unit_id LIMIT 2`, ruleID)
+	if err != nil {
+		return "", "", err
+	}
+	defer rows.Close()
+	count := 0
+	for rows.Next() {
+		if err := rows.Scan(&unitID, &unitName); err != nil {
+			return "", "", err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return "", "", err
+	}
+	if count != 1 {
+		return "", "", ErrNotFound
+	}
+	return unitID, unitName, nil
+}
+
+// ClaimNotificationDeliveries picks up to limit pending deliveries that are
+// due and pushes their next attempt out by lease, so a concurrent worker does
+// not send them again while they are in flight. When a picked delivery
+// belongs to a digest email channel, every other due delivery of that channel
+// is claimed with it regardless of limit, so the digest goes out as one
+// message.
+func (r *Repository) ClaimNotificationDeliveries(ctx context.Context, limit int, lease time.Duration) ([]NotificationDeliveryRecord, error) {
+	rows, err := r.Store.Pool.Query(ctx, `
+		WITH picked AS (
+			SELECT id, channel_id FROM notification_deliveries
+			WHERE status='PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED),
+		digest AS (
+			SELECT d.id FROM notification_deliveries d
+			JOIN notification_channels c ON c.id = d.channel_id
+			WHERE d.status='PENDING' AND d.next_attempt_at <= now()
+				AND c.type = 'email' AND COALESCE(c.config->>'digest', '') <> ''
+				AND d.channel_id IN (SELECT channel_id FROM picked)
+			FOR UPDATE OF d SKIP LOCKED),
+		due AS (
+			SELECT id FROM picked
+			UNION
+			SELECT id FROM digest),
+		claimed AS (
+			UPDATE notification_deliveries d SET next_attempt_at = now() + make_interval(secs => $2)
+			FROM due WHERE d.id = due.id
+			RETURNING d.id, d.channel_id, d.alert_id, d.event, d.payload, d.attempts)
+		SELECT claimed.id, claimed.channel_id, claimed.alert_id, claimed.event, claimed.payload, claimed.attempts, c.name, c.type, c.config, c.enabled
+		FROM claimed JOIN notification_channels c ON c.id = claimed.channel_id
+		ORDER BY claimed.id`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []NotificationDeliveryRecord{}
+	for rows.Next() {
+		var rec NotificationDeliveryRecord
+		if err := rows.Scan(&rec.ID, &rec.ChannelID, &rec.AlertID, &rec.Event, &rec.Payload, &rec.Attempts, &rec.Channel.Name, &rec.Channel.Type, &rec.Channel.Config, &rec.Channel.Enabled); err != nil {
+			return nil, err
+		}
+		rec.Channel.ID = rec.ChannelID
+		results = append(results, rec)
+	}
+	return results, rows.Err()
+}
+
+func (r *Repository) RecordNotificationAttempt(ctx context.Context, attempt NotificationAttempt) error {
+	_, err := r.Store.Pool.Exec(ctx, `
+		UPDATE notification_deliveries
+		SET status=$2, attempts=$3, response_code=$4, last_error=NULLIF($5,''), next_attempt_at=$6,
+			delivered_at=CASE WHEN $2='DELIVERED' THEN now() ELSE delivered_at END
+		WHERE id=$1`,
+		attempt.DeliveryID, attempt.Status, attempt.Attempts, attempt.ResponseCode, attempt.Error, attempt.NextAttemptAt)
+	return err
+}
@@ -2,7 +2,10 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type Repository struct {
@@ -15,7 +18,7 @@ func NewRepository(store *Store) *Repository {
 
 func (r *Repository) ListEnabledRules(ctx context.Context) ([]RuleRecord, error) {
 	rows, err := r.Store.Pool.Query(ctx, `
-		SELECT id, connection_ref, rule_json, enabled, status, last_error, last_validated_at
+		SELECT id, connection_ref, rule_json, enabled, shadow, status, last_error, last_validated_at
 		FROM rules WHERE enabled = true`)
 	if err != nil {
 		return nil, err
@@ -24,7 +27,7 @@ func (r *Repository) ListEnabledRules(ctx context.Context) ([]RuleRecord, error)
 	results := []RuleRecord{}
 	for rows.Next() {
 		var rec RuleRecord
-		if err := rows.Scan(&rec.ID, &rec.ConnectionRef, &rec.RuleJSON, &rec.Enabled, &rec.Status, &rec.LastError, &rec.LastValidated); err != nil {
+		if err := rows.Scan(&rec.ID, &rec.ConnectionRef, &rec.RuleJSON, &rec.Enabled, &rec.Shadow, &rec.Status, &rec.LastError, &rec.LastValidated); err != nil {
 			return nil, err
 		}
 		results = append(results, rec)
@@ -34,10 +37,10 @@ func (r *Repository) ListEnabledRules(ctx context.Context) ([]RuleRecord, error)
 
 func (r *Repository) GetRule(ctx context.Context, id string) (RuleRecord, error) {
 	row := r.Store.Pool.QueryRow(ctx, `
-		SELECT id, connection_ref, rule_json, enabled, status, last_error, last_validated_at
+		SELECT id, connection_ref, rule_json, enabled, shadow, status, last_error, last_validated_at
 		FROM rules WHERE id=$1`, id)
 	var rec RuleRecord
-	if err := row.Scan(&rec.ID, &rec.ConnectionRef, &rec.RuleJSON, &rec.Enabled, &rec.Status, &rec.LastError, &rec.LastValidated); err != nil {
+	if err := row.Scan(&rec.ID, &rec.ConnectionRef, &rec.RuleJSON, &rec.Enabled, &rec.Shadow, &rec.Status, &rec.LastError, &rec.LastValidated); err != nil {
 		return RuleRecord{}, ErrNotFound
 	}
 	return rec, nil
@@ -52,22 +55,160 @@ func (r *Repository) GetConnectionType(ctx context.Context, id string) (string,
 	return connType, nil
 }
 
-func (r *Repository) UpdateRuleStatus(ctx context.Context, id, status string, lastError []byte) error {
+// UpdateRuleStatus sets the rule status and returns the status it replaced.
+func (r *Repository) UpdateRuleStatus(ctx context.Context, id, status string, lastError []byte) (string, error) {
+	var previous string
+	err := r.Store.Pool.QueryRow(ctx, `
+		WITH prev AS (SELECT id, status FROM rules WHERE id=$3 FOR UPDATE)
+		UPDATE rules SET status=$1, last_error=$2, last_validated_at=now(), updated_at=now()
+		FROM prev WHERE rules.id=prev.id
+		RETURNING prev.status`, status, lastError, id).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return previous, err
+}
+
+func (r *Repository) CreateAlert(ctx context.Context, alert AlertRecord) (int64, error) {
+	var id int64
+	err := r.Store.Pool.QueryRow(ctx, `
+		INSERT INTO `+alertsTable(alert.Shadow)+` (rule_id, ui_rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata, state, opened_at, last_seen_at, occurrences)
+		VALUES (NULLIF($1,'')::uuid,NULLIF($2,'')::uuid,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,'OPEN',$3,$3,1)
+		RETURNING id`,
+		alert.RuleID, alert.UIRuleID, alert.TSUTC, alert.ParameterName, alert.ObservedValue, alert.LimitExpr, alert.DetectorType, alert.Severity, alert.AnomalyScore, alert.BaselineMedian, alert.BaselineMAD, alert.Hit, alert.Treated, alert.Metadata).Scan(&id)
+	return id, err
+}
+
+func (r *Repository) UpdateOpenAlert(ctx context.Context, ruleID string, alert AlertRecord) (int64, bool, error) {
+	table := alertsTable(alert.Shadow)
+	var id int64
+	err := r.Store.Pool.QueryRow(ctx, `
+		UPDATE `+table+` SET occurrences=occurrences+1, last_seen_at=$4, observed_value=$5, limit_expression=$6, severity=$7, anomaly_score=$8, baseline_median=$9, baseline_mad=$10, metadata=$11, ok_streak=0
+		WHERE id = (
+			SELECT id FROM `+table+`
+			WHERE (rule_id=$1 OR ui_rule_id=$1) AND parameter_name=$2 AND detector_type=$3 AND state IN ('OPEN','ACKNOWLEDGED')
+			ORDER BY ts_utc DESC LIMIT 1)
+		RETURNING id`,
+		ruleID, alert.ParameterName, alert.DetectorType, alert.TSUTC, alert.ObservedValue, alert.LimitExpr, alert.Severity, alert.AnomalyScore, alert.BaselineMedian, alert.BaselineMAD, alert.Metadata).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+func (r *Repository) RecordAlertOK(ctx context.Context, ruleID, parameterName, detectorType string, resolveAfter int, shadow bool) (ResolvedAlert, bool, error) {
+	var state string
+	var resolvedAt *time.Time
+	rec := ResolvedAlert{ParameterName: parameterName, DetectorType: detectorType}
+	err := r.Store.Pool.QueryRow(ctx, `
+		UPDATE `+alertsTable(shadow)+` SET ok_streak=ok_streak+1,
+			state=CASE WHEN ok_streak+1 >= $4 THEN 'RESOLVED' ELSE state END,
+			resolved_at=CASE WHEN ok_streak+1 >= $4 THEN now() ELSE resolved_at END
+		WHERE (rule_id=$1 OR ui_rule_id=$1) AND parameter_name=$2 AND detector_type=$3 AND state IN ('OPEN','ACKNOWLEDGED')
+		RETURNING id, COALESCE(rule_id::text,''), COALESCE(ui_rule_id::text,''), severity, state, resolved_at`, ruleID, parameterName, detectorType, resolveAfter).
+		Scan(&rec.ID, &rec.RuleID, &rec.UIRuleID, &rec.Severity, &state, &resolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ResolvedAlert{}, false, nil
+	}
+	if err != nil {
+		return ResolvedAlert{}, false, err
+	}
+	if state != "RESOLVED" {
+		return ResolvedAlert{}, false, nil
+	}
+	if resolvedAt != nil {
+		rec.ResolvedAt = *resolvedAt
+	}
+	return rec, true, nil
+}
+
+func (r *Repository) CreateRuleRun(ctx context.Context, run RuleRunRecord) error {
+	_, err := r.Store.Pool.Exec(ctx, `
+		INSERT INTO rule_runs (rule_id, ui_rule_id, parameter_name, detector_type, started_at, finished_at, duration_ms, status, error, sample_count)
+		VALUES (NULLIF($1,'')::uuid,NULLIF($2,'')::uuid,$3,$4,$5,$6,$7,$8,NULLIF($9,''),$10)`,
+		run.RuleID, run.UIRuleID, run.ParameterName, run.DetectorType, run.StartedAt, run.FinishedAt, run.DurationMS, run.Status, run.Error, run.SampleCount)
+	return err
+}
+
+func (r *Repository) GetDetectorState(ctx context.Context, ruleID, parameterName, detectorType string) ([]byte, error) {
+	row := r.Store.Pool.QueryRow(ctx, `
+		SELECT state FROM detector_state
+		WHERE COALESCE(rule_id, ui_rule_id)=$1 AND parameter_name=$2 AND detector_type=$3`, ruleID, parameterName, detectorType)
+	var state []byte
+	if err := row.Scan(&state); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+func (r *Repository) SaveDetectorState(ctx context.Context, rec DetectorStateRecord) error {
 	_, err := r.Store.Pool.Exec(ctx, `
-		UPDATE rules SET status=$1, last_error=$2, last_validated_at=now(), updated_at=now() WHERE id=$3`, status, lastError, id)
+		INSERT INTO detector_state (rule_id, ui_rule_id, parameter_name, detector_type, state, updated_at)
+		VALUES (NULLIF($1,'')::uuid,NULLIF($2,'')::uuid,$3,$4,$5,now())
+		ON CONFLICT ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type)
+		DO UPDATE SET state=EXCLUDED.state, updated_at=now()`,
+		rec.RuleID, rec.UIRuleID, rec.ParameterName, rec.DetectorType, rec.State)
 	return err
 }
 
-func (r *Repository) CreateAlert(ctx context.Context, alert AlertRecord) error {
+func (r *Repository) GetWatermark(ctx context.Context, ruleID, parameterName, detectorType string) (WatermarkRecord, error) {
+	row := r.Store.Pool.QueryRow(ctx, `
+		SELECT COALESCE(rule_id::text,''), COALESCE(ui_rule_id::text,''), parameter_name, detector_type, config_hash, last_ts, last_order, last_key, seen_at_ts
+		FROM rule_watermarks
+		WHERE COALESCE(rule_id, ui_rule_id)=$1 AND parameter_name=$2 AND detector_type=$3`, ruleID, parameterName, detectorType)
+	var rec WatermarkRecord
+	if err := row.Scan(&rec.RuleID, &rec.UIRuleID, &rec.ParameterName, &rec.DetectorType, &rec.ConfigHash, &rec.LastTS, &rec.LastOrder, &rec.LastKey, &rec.SeenAtTS); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return WatermarkRecord{}, ErrNotFound
+		}
+		return WatermarkRecord{}, err
+	}
+	return rec, nil
+}
+
+func (r *Repository) SaveWatermark(ctx context.Context, rec WatermarkRecord) error {
 	_, err := r.Store.Pool.Exec(ctx, `
-		INSERT INTO alerts (rule_id, ts_utc, parameter_name, observed_value, limit_expression, detector_type, severity, anomaly_score, baseline_median, baseline_mad, hit, treated, metadata)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
-		alert.RuleID, alert.TSUTC, alert.ParameterName, alert.ObservedValue, alert.LimitExpr, alert.DetectorType, alert.Severity, alert.AnomalyScore, alert.BaselineMedian, alert.BaselineMAD, alert.Hit, alert.Treated, alert.Metadata)
+		INSERT INTO rule_watermarks (rule_id, ui_rule_id, parameter_name, detector_type, config_hash, last_ts, last_order, last_key, seen_at_ts, updated_at)
+		VALUES (NULLIF($1,'')::uuid,NULLIF($2,'')::uuid,$3,$4,$5,$6,$7,$8,$9,now())
+		ON CONFLICT ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type)
+		DO UPDATE SET config_hash=EXCLUDED.config_hash, last_ts=EXCLUDED.last_ts, last_order=EXCLUDED.last_order, last_key=EXCLUDED.last_key, seen_at_ts=EXCLUDED.seen_at_ts, updated_at=now()`,
+		rec.RuleID, rec.UIRuleID, rec.ParameterName, rec.DetectorType, rec.ConfigHash, rec.LastTS, rec.LastOrder, rec.LastKey, rec.SeenAtTS)
+	return err
+}
+
+func (r *Repository) GetActiveBaseline(ctx context.Context, ruleID, parameterName, detectorType string) (BaselineRecord, error) {
+	row := r.Store.Pool.QueryRow(ctx, `
+		SELECT id, COALESCE(rule_id::text,''), COALESCE(ui_rule_id::text,''), parameter_name, detector_type, source, mu, sigma, rbar, median, mad, n, subgroup_count, window_start, window_end, order_start, order_end, data_hash
+		FROM baselines
+		WHERE COALESCE(rule_id, ui_rule_id)=$1 AND parameter_name=$2 AND detector_type=$3 AND status='ACTIVE'`, ruleID, parameterName, detectorType)
+	var rec BaselineRecord
+	if err := row.Scan(&rec.ID, &rec.RuleID, &rec.UIRuleID, &rec.ParameterName, &rec.DetectorType, &rec.Source, &rec.Mu, &rec.Sigma, &rec.RBar, &rec.Median, &rec.MAD, &rec.N, &rec.SubgroupCount, &rec.WindowStart, &rec.WindowEnd, &rec.OrderStart, &rec.OrderEnd, &rec.DataHash); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return BaselineRecord{}, ErrNotFound
+		}
+		return BaselineRecord{}, err
+	}
+	return rec, nil
+}
+
+func (r *Repository) CreateActiveBaseline(ctx context.Context, rec BaselineRecord) error {
+	_, err := r.Store.Pool.Exec(ctx, `
+		INSERT INTO baselines (rule_id, ui_rule_id, parameter_name, detector_type, status, source, mu, sigma, rbar, median, mad, n, subgroup_count, window_start, window_end, order_start, order_end, data_hash, created_at, approved_at)
+		VALUES (NULLIF($1,'')::uuid,NULLIF($2,'')::uuid,$3,$4,'ACTIVE',$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,now(),now())
+		ON CONFLICT ((COALESCE(rule_id, ui_rule_id)), parameter_name, detector_type) WHERE status = 'ACTIVE'
+		DO NOTHING`,
+		rec.RuleID, rec.UIRuleID, rec.ParameterName, rec.DetectorType, rec.Source, rec.Mu, rec.Sigma, rec.RBar, rec.Median, rec.MAD, rec.N, rec.SubgroupCount, rec.WindowStart, rec.WindowEnd, rec.OrderStart, rec.OrderEnd, rec.DataHash)
 	return err
 }
 
 func (r *Repository) GetLastAlert(ctx context.Context, ruleID string) (time.Time, error) {
-	row := r.Store.Pool.QueryRow(ctx, `SELECT ts_utc FROM alerts WHERE rule_id=$1 ORDER BY ts_utc DESC LIMIT 1`, ruleID)
+	row := r.Store.Pool.QueryRow(ctx, `SELECT ts_utc FROM alerts WHERE rule_id=$1 OR ui_rule_id=$1 ORDER BY ts_utc DESC LIMIT 1`, ruleID)
 	var ts time.Time
 	if err := row.Scan(&ts); err != nil {
 		return time.Time{}, ErrNotFound
@@ -75,14 +216,24 @@ func (r *Repository) GetLastAlert(ctx context.Context, ruleID string) (time.Time
 	return ts, nil
 }
 
-func (r *Repository) GetLastAlertForKey(ctx context.Context, ruleID, parameterName, detectorType string) (time.Time, error) {
+func (r *Repository) GetLastAlertForKey(ctx context.Context, ruleID, parameterName, detectorType string, shadow bool) (time.Time, error) {
 	row := r.Store.Pool.QueryRow(ctx, `
-		SELECT ts_utc FROM alerts
-		WHERE rule_id=$1 AND parameter_name=$2 AND detector_type=$3
+		SELECT COALESCE(last_seen_at, ts_utc) FROM `+alertsTable(shadow)+`
+		WHERE (rule_id=$1 OR ui_rule_id=$1) AND parameter_name=$2 AND detector_type=$3
 		ORDER BY ts_utc DESC LIMIT 1`, ruleID, parameterName, detectorType)
 	var ts time.Time
 	if err := row.Scan(&ts); err != nil {
-		return time.Time{}, ErrNotFound
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, ErrNotFound
+		}
+		return time.Time{}, err
 	}
 	return ts, nil
 }
+
+func alertsTable(shadow bool) string {
+	if shadow {
+		return "shadow_alerts"
+	}
+	return "alerts"
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const stepperRuleSelect = `
+		SELECT r.id, r.unit_id, r.name, r.rule_type, r.parameter_id, r.config, r.enabled, r.shadow, r.status, m.connection_ref, m.selected_table, m.timestamp_column, m.ordering_column, m.row_filter, m.derived_parameters
+		FROM ui_rules r JOIN machine_units m ON m.unit_id = r.unit_id`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *Repository) ListEnabledStepperRules(ctx context.Context) ([]StepperRuleRecord, error) {
+	rows, err := r.Store.Pool.Query(ctx, stepperRuleSelect+` WHERE r.enabled = true`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	results := []StepperRuleRecord{}
+	for rows.Next() {
+		rec, err := scanStepperRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	return results, nil
+}
+
+func (r *Repository) GetStepperRule(ctx context.Context, id string) (StepperRuleRecord, error) {
+	row := r.Store.Pool.QueryRow(ctx, stepperRuleSelect+` WHERE r.id=$1`, id)
+	rec, err := scanStepperRule(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return StepperRuleRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return StepperRuleRecord{}, err
+	}
+	return rec, nil
+}
+
+// UpdateStepperRuleStatus sets the rule status and returns the status it
+// replaced.
+func (r *Repository) UpdateStepperRuleStatus(ctx context.Context, id, status string, lastError []byte) (string, error) {
+	var previous string
+	err := r.Store.Pool.QueryRow(ctx, `
+		WITH prev AS (SELECT id, status FROM ui_rules WHERE id=$3 FOR UPDATE)
+		UPDATE ui_rules SET status=$1, last_error=$2, last_validated_at=now()
+		FROM prev WHERE ui_rules.id=prev.id
+		RETURNING prev.status`, status, lastError, id).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	return previous, err
+}
+
+func scanStepperRule(row scanner) (StepperRuleRecord, error) {
+	var rec StepperRuleRecord
+	if err := row.Scan(&rec.ID, &rec.UnitID, &rec.Name, &rec.RuleType, &rec.ParameterID, &rec.Config, &rec.Enabled, &rec.Shadow, &rec.Status, &rec.ConnectionRef, &rec.Table, &rec.TimestampColumn, &rec.OrderingColumn, &rec.RowFilter, &rec.DerivedParameters); err != nil {
+		return StepperRuleRecord{}, err
+	}
+	return rec, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"predixaai-backend/pkg/expression"
 	"predixaai-backend/services/scheduler-service/internal/mcp"
 	"predixaai-backend/services/scheduler-service/internal/scheduler"
 	"predixaai-backend/services/scheduler-service/internal/security"
@@ -23,9 +24,22 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 			return errors.New("windowSeconds exceeds limit")
 		}
 	}
-	if !security.IsSafeIdentifier(spec.Source.Table) || !security.IsSafeIdentifier(spec.Source.TimestampColumn) {
+	ordered := spec.Source.OrderingColumn != ""
+	if !security.IsSafeIdentifier(spec.Source.Table) {
 		return errors.New("unsafe identifier")
 	}
+	if (spec.Source.TimestampColumn != "" || !ordered) && !security.IsSafeIdentifier(spec.Source.TimestampColumn) {
+		return errors.New("unsafe identifier")
+	}
+	if ordered && !security.IsSafeIdentifier(spec.Source.OrderingColumn) {
+		return errors.New("unsafe ordering column")
+	}
+	if spec.Source.KeyColumn != "" && !security.IsSafeIdentifier(spec.Source.KeyColumn) {
+		return errors.New("unsafe key column")
+	}
+	if spec.Source.TimestampColumn == "" && spec.Aggregation != "" && spec.Aggregation != "latest" {
+		return errors.New("aggregation windows require a timestamp column")
+	}
 	params := normalizeParameters(spec)
 	if len(params) == 0 {
 		return errors.New("parameters required")
@@ -76,27 +90,72 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 		colSet[c.Name] = struct{}{}
 		colTypes[c.Name] = c.Type
 	}
-	if _, ok := colSet[spec.Source.TimestampColumn]; !ok {
-		return errors.New("timestamp column not found")
+	if spec.Source.TimestampColumn != "" || !ordered {
+		if _, ok := colSet[spec.Source.TimestampColumn]; !ok {
+			return errors.New("timestamp column not found")
+		}
+	}
+	if spec.Source.Where != nil {
+		for _, clause := range spec.Source.Where.Clauses {
+			if _, ok := colSet[clause.Column]; !ok {
+				return errors.New("where column not found")
+			}
+		}
+	}
+	if ordered {
+		orderType, ok := colTypes[spec.Source.OrderingColumn]
+		if !ok {
+			return errors.New("ordering column not found")
+		}
+		if !isIntegerType(orderType) {
+			return errors.New("ordering column must be integer")
+		}
+	}
+	if spec.Source.KeyColumn != "" {
+		keyType, ok := colTypes[spec.Source.KeyColumn]
+		if !ok {
+			return errors.New("key column not found")
+		}
+		if !isIntegerType(keyType) {
+			return errors.New("key column must be integer")
+		}
 	}
 	for _, param := range params {
-		if _, ok := colSet[param.ValueColumn]; !ok {
+		valueColumns := []string{param.ValueColumn}
+		if param.Expression != "" {
+			if err := scheduler.ValidateExpressionColumns(param.Expression, colTypes); err != nil {
+				return err
+			}
+			if spec.Aggregation != "" && spec.Aggregation != "latest" {
+				return errors.New("aggregation is not supported for derived parameters")
+			}
+			expr, _ := expression.Parse(param.Expression)
+			valueColumns = expr.Columns()
+		} else if _, ok := colSet[param.ValueColumn]; !ok {
 			return errors.New("value column not found")
 		}
+		numeric := param.Expression != "" || isNumericType(colTypes[param.ValueColumn])
+		if param.Detector.Type == "text_match" {
+			if param.Detector.TextMatch == nil {
+				return errors.New("text_match config missing")
+			}
+			if param.Expression != "" {
+				return errors.New("text_match does not support derived parameters")
+			}
+			queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
+			_, err = adapter.FetchRecentRows(queryCtx, recentRowsProbe(spec, valueColumns, time.Duration(limits.MaxWindowSeconds)*time.Second, 1))
+			cancelQuery()
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if param.Detector.Type == "robust_zscore" {
-			if !isNumericType(colTypes[param.ValueColumn]) {
+			if !numeric {
 				return errors.New("non-numeric column for robust_zscore")
 			}
 			queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
-			_, err = adapter.FetchRecentRows(queryCtx, mcp.FetchRecentRowsRequest{
-				ConnectionRef:   spec.ConnectionRef,
-				Table:           spec.Source.Table,
-				Columns:         []string{param.ValueColumn, spec.Source.TimestampColumn},
-				TimestampColumn: spec.Source.TimestampColumn,
-				Where:           toWhere(spec.Source.Where),
-				Since:           time.Now().Add(-time.Duration(param.Detector.RobustZ.BaselineWindowSeconds) * time.Second).Format(time.RFC3339),
-				Limit:           limits.MaxSampleRows,
-			})
+			_, err = adapter.FetchRecentRows(queryCtx, recentRowsProbe(spec, valueColumns, time.Duration(param.Detector.RobustZ.BaselineWindowSeconds)*time.Second, limits.MaxSampleRows))
 			cancelQuery()
 			if err != nil {
 				return err
@@ -110,7 +169,7 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 			if !isSupportedRangeChartSize(param.Detector.RangeChart.SubgroupSize) {
 				return errors.New("unsupported subgroup size")
 			}
-			if !isNumericType(colTypes[param.ValueColumn]) {
+			if !numeric {
 				return errors.New("non-numeric column for range_chart")
 			}
 			mode := param.Detector.RangeChart.Subgrouping.Mode
@@ -123,26 +182,45 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 				}
 			}
 			queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
-			_, err = adapter.FetchRecentRows(queryCtx, mcp.FetchRecentRowsRequest{
-				ConnectionRef:   spec.ConnectionRef,
-				Table:           spec.Source.Table,
-				Columns:         []string{param.ValueColumn, spec.Source.TimestampColumn},
-				TimestampColumn: spec.Source.TimestampColumn,
-				Where:           toWhere(spec.Source.Where),
-				Since:           time.Now().Add(-time.Duration(limits.MaxWindowSeconds) * time.Second).Format(time.RFC3339),
-				Limit:           limits.MaxSampleRows,
-			})
+			_, err = adapter.FetchRecentRows(queryCtx, recentRowsProbe(spec, valueColumns, time.Duration(limits.MaxWindowSeconds)*time.Second, limits.MaxSampleRows))
 			cancelQuery()
 			if err != nil {
 				return err
 			}
 			continue
 		}
-		if param.Detector.Type == "shewhart" || param.Detector.Type == "trend" || param.Detector.Type == "tpa" || param.Detector.Type == "spec_limit" {
-			if !isNumericType(colTypes[param.ValueColumn]) {
+		if param.Detector.Type == "xbar_r" || param.Detector.Type == "xbar_s" {
+			if param.Detector.XbarChart == nil {
+				return errors.New(param.Detector.Type + " config missing")
+			}
+			if !isSupportedRangeChartSize(param.Detector.XbarChart.SubgroupSize) {
+				return errors.New("unsupported subgroup size")
+			}
+			if param.Detector.XbarChart.Subgrouping.Mode == "column" {
+				if _, ok := colSet[param.Detector.XbarChart.Subgrouping.Column]; !ok {
+					return errors.New("subgrouping column not found")
+				}
+			}
+		}
+		if param.Detector.Type == "shewhart" || param.Detector.Type == "trend" || param.Detector.Type == "tpa" || param.Detector.Type == "spec_limit" || param.Detector.Type == "run_rules" || param.Detector.Type == "ewma" || param.Detector.Type == "cusum" || param.Detector.Type == "attribute" || param.Detector.Type == "xbar_r" || param.Detector.Type == "xbar_s" || param.Detector.Type == "i_mr" || param.Detector.Type == "capability" || param.Detector.Type == "flatline" || param.Detector.Type == "step_change" || param.Detector.Type == "rate_of_change" {
+			if !numeric {
 				return errors.New("non-numeric column for detector")
 			}
 		}
+		for _, col := range scheduler.LimitColumns(param.Detector) {
+			if !security.IsSafeIdentifier(col) {
+				return errors.New("unsafe limit column")
+			}
+			if _, ok := colSet[col]; !ok {
+				return errors.New("limit column not found")
+			}
+			if !isNumericType(colTypes[col]) {
+				return errors.New("non-numeric limit column")
+			}
+		}
+		if param.Detector.Type == "tpa" && param.Detector.TPA != nil && param.Detector.TPA.RegressionTimeBasis == "timestamp" && spec.Source.TimestampColumn == "" {
+			return errors.New("timestamp regression basis requires a timestamp column")
+		}
 		if param.Detector.Type == "threshold" && spec.Aggregation != "" && spec.Aggregation != "latest" && spec.WindowSeconds != nil {
 			queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
 			_, err = adapter.QueryAggregate(queryCtx, mcp.AggregateRequest{
@@ -160,12 +238,25 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 			}
 			continue
 		}
+		if param.Expression != "" {
+			queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
+			_, err = adapter.FetchRecentRows(queryCtx, recentRowsProbe(spec, valueColumns, time.Duration(limits.MaxWindowSeconds)*time.Second, 1))
+			cancelQuery()
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		queryCtx, cancelQuery := context.WithTimeout(ctx, limits.MaxQueryDuration)
+		sortColumn := spec.Source.TimestampColumn
+		if ordered {
+			sortColumn = spec.Source.OrderingColumn
+		}
 		_, err = adapter.QueryLatestValue(queryCtx, mcp.LatestValueRequest{
 			ConnectionRef:   spec.ConnectionRef,
 			Table:           spec.Source.Table,
 			ValueColumn:     param.ValueColumn,
-			TimestampColumn: spec.Source.TimestampColumn,
+			TimestampColumn: sortColumn,
 			Where:           toWhere(spec.Source.Where),
 		})
 		cancelQuery()
@@ -176,6 +267,25 @@ func RuntimeValidateRule(ctx context.Context, adapter mcp.DbMcpAdapter, spec sch
 	return nil
 }
 
+func recentRowsProbe(spec scheduler.RuleSpec, valueColumns []string, lookback time.Duration, limit int) mcp.FetchRecentRowsRequest {
+	req := mcp.FetchRecentRowsRequest{
+		ConnectionRef: spec.ConnectionRef,
+		Table:         spec.Source.Table,
+		Columns:       append([]string{}, valueColumns...),
+		Where:         toWhere(spec.Source.Where),
+		Limit:         limit,
+	}
+	if spec.Source.TimestampColumn != "" {
+		req.Columns = append(req.Columns, spec.Source.TimestampColumn)
+		req.TimestampColumn = spec.Source.TimestampColumn
+		req.Since = time.Now().Add(-lookback).Format(time.RFC3339)
+	}
+	if spec.Source.OrderingColumn != "" {
+		req.OrderColumn = spec.Source.OrderingColumn
+	}
+	return req
+}
+
 func toWhere(spec *scheduler.WhereSpec) *mcp.WhereSpec {
 	if spec == nil {
 		return nil
@@ -214,6 +324,11 @@ func isNumericType(colType string) bool {
 	return strings.Contains(value, "int") || strings.Contains(value, "decimal") || strings.Contains(value, "numeric") || strings.Contains(value, "float") || strings.Contains(value, "double") || strings.Contains(value, "real")
 }
 
+func isIntegerType(colType string) bool {
+	value := strings.ToLower(colType)
+	return strings.Contains(value, "int") || value == "serial" || value == "bigserial"
+}
+
 func isSupportedRangeChartSize(size int) bool {
 	switch size {
 	case 2, 3, 4, 5, 6, 7, 8, 9, 10:
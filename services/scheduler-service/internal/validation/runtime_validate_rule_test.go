@@ -129,6 +129,38 @@ func TestRuntimeValidateRuleRangeChartUnsupportedSize(t *testing.T) {
 	}
 }
 
+func TestRuntimeValidateRuleDerivedParameter(t *testing.T) {
+	adapter := &mcp.MockAdapter{
+		Tables: []string{"metrics"},
+		Columns: map[string][]mcp.Column{
+			"metrics": []mcp.Column{{Name: "cl2", Type: "float"}, {Name: "ar", Type: "float"}, {Name: "ts", Type: "time"}},
+		},
+	}
+	spec := scheduler.RuleSpec{
+		ConnectionRef: "conn-1",
+		Source:        scheduler.SourceSpec{Table: "metrics", TimestampColumn: "ts"},
+		Parameters: []scheduler.ParameterSpec{{
+			ParameterName: "ratio",
+			ValueColumn:   "ratio",
+			Expression:    "cl2 / ar",
+			Detector: scheduler.DetectorSpec{
+				Type:      "threshold",
+				Threshold: &scheduler.ThresholdSpec{Op: ">", Value: 0.5},
+			},
+		}},
+		PollIntervalSeconds: 10,
+	}
+	allowlist := security.Allowlist{Tables: []string{"metrics"}}
+	limits := security.DefaultLimits()
+	if err := RuntimeValidateRule(context.Background(), adapter, spec, allowlist, limits); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Parameters[0].Expression = "cl2 / missing"
+	if err := RuntimeValidateRule(context.Background(), adapter, spec, allowlist, limits); err == nil {
+		t.Fatalf("expected missing expression column error")
+	}
+}
+
 func intPtr(value int) *int {
 	return &value
 }